@@ -2,17 +2,30 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/brainwhocodes/lisa-loop/internal/analysis"
+	"github.com/brainwhocodes/lisa-loop/internal/api"
 	"github.com/brainwhocodes/lisa-loop/internal/circuit"
 	"github.com/brainwhocodes/lisa-loop/internal/codex"
+	"github.com/brainwhocodes/lisa-loop/internal/config"
 	"github.com/brainwhocodes/lisa-loop/internal/loop"
 	"github.com/brainwhocodes/lisa-loop/internal/project"
+	"github.com/brainwhocodes/lisa-loop/internal/state"
 	"github.com/brainwhocodes/lisa-loop/internal/tui"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/keymap"
 	"github.com/charmbracelet/log"
 )
 
@@ -23,21 +36,6 @@ func main() {
 
 	var (
 		projectDir string
-		promptFile string
-		maxCalls   int
-		timeout    int
-		useMonitor bool
-		verbose    bool
-		logFormat  string
-
-		// Backend selection
-		backend string
-
-		// OpenCode backend settings
-		opencodeServerURL string
-		opencodeUsername  string
-		opencodePassword  string
-		opencodeModelID   string
 
 		setupName   string
 		setupPrompt string
@@ -47,26 +45,20 @@ func main() {
 		importName  string
 
 		initMode string
+
+		// config command options
+		userConfig bool
+		effective  bool
+
+		// steer command options
+		steerClear bool
 	)
 
 	fs := flag.NewFlagSet("lisa", flag.ExitOnError)
-	fs.BoolVar(&useMonitor, "monitor", false, "Enable integrated monitoring")
-	fs.BoolVar(&verbose, "verbose", false, "Verbose output")
-	fs.StringVar(&logFormat, "log-format", "", "Log format: text, json, or logfmt (enables CLI log mode)")
-
 	fs.StringVar(&projectDir, "project", ".", "Project directory")
-	fs.StringVar(&promptFile, "prompt", "PROMPT.md", "Prompt file")
-	fs.IntVar(&maxCalls, "calls", 3, "Max loop iterations (default: 3, 10 for opencode backend)")
-	fs.IntVar(&timeout, "timeout", 600, "Codex timeout (seconds)")
-
-	// Backend selection
-	fs.StringVar(&backend, "backend", "opencode", "Backend: cli or opencode (default: opencode)")
 
-	// OpenCode backend settings (with env fallbacks)
-	fs.StringVar(&opencodeServerURL, "opencode-url", "", "OpenCode server URL (env: OPENCODE_SERVER_URL)")
-	fs.StringVar(&opencodeUsername, "opencode-user", "", "OpenCode username (env: OPENCODE_SERVER_USERNAME)")
-	fs.StringVar(&opencodePassword, "opencode-pass", "", "OpenCode password (env: OPENCODE_SERVER_PASSWORD)")
-	fs.StringVar(&opencodeModelID, "opencode-model", "", "OpenCode model ID (env: OPENCODE_MODEL_ID, default: glm-4.7)")
+	// Flags backed by config settings (.lisa/config.yaml, env, defaults)
+	registerConfigFlags(fs)
 
 	fs.StringVar(&setupName, "name", "", "Project name (for setup command)")
 	fs.StringVar(&setupPrompt, "description", "", "Project description for Codex to generate customized templates")
@@ -78,46 +70,52 @@ func main() {
 
 	fs.StringVar(&initMode, "mode", "", "Init mode: implementation, fix, or refactor (auto-detect if empty)")
 
+	fs.BoolVar(&userConfig, "user", false, "Write to the user-level config file (for config set)")
+	fs.BoolVar(&effective, "effective", false, "Show every effective setting and its source (for config show)")
+
+	fs.BoolVar(&steerClear, "clear", false, "Withdraw all standing guidance (for steer command)")
+
 	fs.Usage = printHelp
 
 	if err := fs.Parse(flagArgs); err != nil {
 		os.Exit(1)
 	}
 
-	// Apply environment variable fallbacks for OpenCode settings
-	opencodeServerURL = envFallback(opencodeServerURL, "OPENCODE_SERVER_URL", "")
-	opencodeUsername = envFallback(opencodeUsername, "OPENCODE_SERVER_USERNAME", "opencode")
-	opencodePassword = envFallback(opencodePassword, "OPENCODE_SERVER_PASSWORD", "")
-	opencodeModelID = envFallback(opencodeModelID, "OPENCODE_MODEL_ID", "glm-4.7")
-
-	// Default max calls to 10 for opencode backend if not explicitly set
-	if backend == "opencode" && !isFlagSet(fs, "calls") {
-		maxCalls = 10
+	// The status block schema doesn't depend on the project or its config
+	if command == "schema" {
+		os.Stdout.Write(analysis.StatusSchema)
+		return
 	}
 
-	// Build OpenCode settings struct for passing to handlers
-	ocSettings := openCodeSettings{
-		serverURL: opencodeServerURL,
-		username:  opencodeUsername,
-		password:  opencodePassword,
-		modelID:   opencodeModelID,
+	// The config command handles its own resolution so it can repair a broken file
+	if command == "config" {
+		handleConfigCommand(projectDir, positionalArgs(fs), setFlagValues(fs), userConfig, effective)
+		return
 	}
 
+	cfg := resolveConfigOrExit(projectDir, setFlagValues(fs)).Config
+
 	switch command {
 	case "init":
-		handleInitCommand(initMode, projectDir, maxCalls, timeout, verbose, backend, ocSettings, logFormat)
+		handleInitCommand(initMode, projectDir, cfg)
 	case "setup":
-		handleSetupCommand(setupName, setupPrompt, setupInit, withGit, verbose)
+		handleSetupCommand(setupName, setupPrompt, setupInit, withGit, cfg.Verbose)
 	case "import":
-		handleImportCommand(importSrc, importName, projectDir, verbose)
+		handleImportCommand(importSrc, importName, projectDir, cfg.Verbose)
 	case "status":
 		handleStatusCommand(projectDir)
 	case "reset-circuit":
-		handleResetCircuitCommand(projectDir)
+		handleResetCircuitCommand(projectDir, cfg)
 	case "sync":
-		handleSyncCommand(projectDir, verbose)
+		handleSyncCommand(projectDir, cfg.Verbose)
+	case "state":
+		handleStateCommand(projectDir, positionalArgs(fs))
+	case "attach":
+		handleAttachCommand(projectDir, positionalArgs(fs), cfg)
+	case "steer":
+		handleSteerCommand(projectDir, positionalArgs(fs), steerClear, cfg)
 	case "run", "help", "version":
-		handleSubcommands(command, projectDir, promptFile, maxCalls, timeout, useMonitor, verbose, backend, ocSettings, logFormat)
+		handleSubcommands(command, projectDir, cfg)
 	default:
 		fmt.Fprintf(os.Stderr, "Error: unknown command '%s'\n\n", command)
 		printHelp()
@@ -125,15 +123,36 @@ func main() {
 	}
 }
 
-// openCodeSettings holds OpenCode backend configuration
-type openCodeSettings struct {
-	serverURL string
-	username  string
-	password  string
-	modelID   string
+// registerConfigFlags defines a flag for every config setting that has one
+func registerConfigFlags(fs *flag.FlagSet) {
+	for _, s := range config.Settings {
+		if s.Flag == "" {
+			continue
+		}
+		switch s.Kind {
+		case config.KindInt:
+			def, _ := strconv.Atoi(s.Default)
+			fs.Int(s.Flag, def, s.Usage)
+		case config.KindBool:
+			def, _ := strconv.ParseBool(s.Default)
+			fs.Bool(s.Flag, def, s.Usage)
+		default:
+			fs.String(s.Flag, s.Default, s.Usage)
+		}
+	}
 }
 
-func handleSubcommands(command, projectDir, promptFile string, maxCalls, timeout int, useMonitor, verbose bool, backend string, ocSettings openCodeSettings, logFormat string) {
+// setFlagValues returns the flags given explicitly on the command line, so
+// flag defaults don't mask values from config files and the environment
+func setFlagValues(fs *flag.FlagSet) map[string]string {
+	values := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		values[f.Name] = f.Value.String()
+	})
+	return values
+}
+
+func handleSubcommands(command, projectDir string, cfg config.Config) {
 	switch command {
 	case "help", "--help", "-h":
 		printHelp()
@@ -143,16 +162,19 @@ func handleSubcommands(command, projectDir, promptFile string, maxCalls, timeout
 		fmt.Println("Charm TUI scaffold - Complete")
 		os.Exit(0)
 	default:
-		handleRunCommand(projectDir, promptFile, maxCalls, timeout, useMonitor, verbose, backend, ocSettings, logFormat)
+		handleRunCommand(projectDir, cfg)
 	}
 }
 
-func handleInitCommand(mode string, projectDir string, maxCalls int, timeout int, verbose bool, backend string, ocSettings openCodeSettings, logFormat string) {
+func handleInitCommand(mode string, projectDir string, cfg config.Config) {
 	if err := os.Chdir(projectDir); err != nil {
 		fmt.Fprintf(os.Stderr, "Error changing to project directory: %v\n", err)
 		os.Exit(1)
 	}
 
+	lock := acquireProjectLock("init", "")
+	defer lock.Release()
+
 	// Determine init mode
 	var initMode project.InitMode
 	switch mode {
@@ -221,32 +243,23 @@ func handleInitCommand(mode string, projectDir string, maxCalls int, timeout int
 	}
 
 	// Now launch the TUI
-	config := loop.Config{
-		Backend:           backend,
-		ProjectPath:       ".",
-		PromptPath:        "PROMPT.md",
-		MaxCalls:          maxCalls,
-		Timeout:           timeout,
-		Verbose:           verbose,
-		ResetCircuit:      false,
-		OpenCodeServerURL: ocSettings.serverURL,
-		OpenCodeUsername:  ocSettings.username,
-		OpenCodePassword:  ocSettings.password,
-		OpenCodeModelID:   ocSettings.modelID,
-	}
-
-	rateLimiter := loop.NewRateLimiter(config.MaxCalls, 1)
-	breaker := circuit.NewBreaker(3, 5)
-	controller := loop.NewController(config, rateLimiter, breaker)
+	config := cfg
+	config.ProjectPath = "."
+	config.PromptPath = "PROMPT.md"
+
+	controller := newController(config)
+	if server := startControlAPI(config, controller); server != nil {
+		defer server.Close()
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
-	setupGracefulShutdown(cancel, controller)
+	setupGracefulShutdown(cancel, controller, lock)
 
 	// Use log mode if log format is specified, otherwise use TUI
-	if logFormat != "" {
-		runWithLogs(ctx, controller, config, verbose, logFormat)
+	if config.LogFormat != "" {
+		runWithLogs(ctx, controller, config, config.Verbose, config.LogFormat)
 	} else {
-		runWithMonitor(ctx, controller, config, verbose, loopMode)
+		runWithMonitor(ctx, controller, config, config.Verbose, loopMode)
 	}
 }
 
@@ -367,6 +380,15 @@ func handleStatusCommand(projectPath string) {
 
 	fmt.Printf("   Project root: %s\n", projectRoot)
 
+	if holder, err := state.ReadLock(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	} else if holder != nil && !holder.IsStale() {
+		fmt.Printf("   Running: PID %d on %s since %s\n", holder.PID, holder.Host, formatStateTime(holder.StartedAt))
+		if holder.Profile != "" {
+			fmt.Printf("   Profile: %s\n", holder.Profile)
+		}
+	}
+
 	tasks, err := loop.LoadFixPlan()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: could not load @fix_plan.md: %v\n", err)
@@ -381,13 +403,16 @@ func handleStatusCommand(projectPath string) {
 	}
 }
 
-func handleResetCircuitCommand(projectPath string) {
+func handleResetCircuitCommand(projectPath string, cfg config.Config) {
 	if err := os.Chdir(projectPath); err != nil {
 		fmt.Fprintf(os.Stderr, "Error changing to project directory: %v\n", err)
 		os.Exit(1)
 	}
 
-	breaker := circuit.NewBreaker(3, 5)
+	lock := acquireProjectLock("reset-circuit", "")
+	defer lock.Release()
+
+	breaker := circuit.NewBreaker(cfg.NoProgressThreshold, cfg.SameErrorThreshold)
 	if err := breaker.Reset(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error resetting circuit breaker: %v\n", err)
 		os.Exit(1)
@@ -400,12 +425,175 @@ func handleResetCircuitCommand(projectPath string) {
 	fmt.Println("  lisa --monitor")
 }
 
+func handleStateCommand(projectPath string, args []string) {
+	if err := os.Chdir(projectPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error changing to project directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	action := "show"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	switch action {
+	case "show":
+		st, err := state.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading state: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("State file: %s (schema v%d)\n", state.StatePath, st.Version)
+		fmt.Printf("   Rate limit:       %d calls since %s\n", st.RateLimit.CallCount, formatStateTime(st.RateLimit.LastReset))
+		fmt.Printf("   Circuit breaker:  %s (no progress: %d, errors: %d)\n", st.CircuitBreaker.State, st.CircuitBreaker.NoProgressCount, len(st.CircuitBreaker.ErrorHistory))
+		fmt.Printf("   Exit signals:     %d\n", len(st.ExitSignals))
+		fmt.Printf("   Codex session:    %s\n", formatSessionRef(st.Sessions.Codex))
+		fmt.Printf("   OpenCode session: %s\n", formatSessionRef(st.Sessions.OpenCode))
+	case "clean":
+		lock := acquireProjectLock("state clean", "")
+		defer lock.Release()
+
+		removed, err := state.Clean()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error cleaning state: %v\n", err)
+			os.Exit(1)
+		}
+		if err := state.CleanupOldFiles(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		if len(removed) == 0 {
+			fmt.Println("No state to clean")
+			return
+		}
+		fmt.Println("✅ State cleaned")
+		for _, path := range removed {
+			fmt.Printf("   Removed %s\n", path)
+		}
+	case "export":
+		st, err := state.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading state: %v\n", err)
+			os.Exit(1)
+		}
+		data, err := json.MarshalIndent(st, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding state: %v\n", err)
+			os.Exit(1)
+		}
+		if len(args) > 1 {
+			if err := os.WriteFile(args[1], append(data, '\n'), 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", args[1], err)
+				os.Exit(1)
+			}
+			fmt.Printf("✅ State exported to %s\n", args[1])
+			return
+		}
+		fmt.Println(string(data))
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown state action '%s' (want show, clean or export)\n", action)
+		os.Exit(1)
+	}
+}
+
+// formatStateTime renders a persisted timestamp, or "never" if unset
+func formatStateTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+// formatSessionRef renders a backend session for 'lisa state show'
+func formatSessionRef(ref state.SessionRef) string {
+	if ref.ID == "" {
+		return "none"
+	}
+	return fmt.Sprintf("%s (updated %s)", ref.ID, formatStateTime(ref.UpdatedAt))
+}
+
+func handleConfigCommand(projectPath string, args []string, flags map[string]string, userLevel bool, effective bool) {
+	action := "show"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	switch action {
+	case "get":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, "Usage: lisa config get <key>")
+			os.Exit(1)
+		}
+		eff := resolveConfigOrExit(projectPath, flags)
+		v, ok := eff.Get(args[1])
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown config key %q\n", args[1])
+			os.Exit(1)
+		}
+		fmt.Println(v.Value)
+	case "set":
+		if len(args) != 3 {
+			fmt.Fprintln(os.Stderr, "Usage: lisa config set <key> <value> [--user]")
+			os.Exit(1)
+		}
+		path := filepath.Join(projectPath, config.ProjectFile)
+		if userLevel {
+			var err error
+			if path, err = config.UserFile(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if err := config.SetValue(path, args[1], args[2]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Set %s in %s\n", args[1], path)
+	case "show":
+		eff := resolveConfigOrExit(projectPath, flags)
+		for _, v := range eff.Values {
+			if !effective && v.Source == config.SourceDefault {
+				continue
+			}
+			origin := string(v.Source)
+			if v.Origin != "" {
+				origin += ": " + v.Origin
+			}
+			fmt.Printf("%-30s = %-20s (%s)\n", v.Setting.Key, v.Setting.Display(v.Value), origin)
+		}
+		if !effective {
+			fmt.Println("\nUse --effective to include defaults")
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown config action '%s' (want get, set or show)\n", action)
+		os.Exit(1)
+	}
+}
+
+// resolveConfigOrExit resolves the layered config, exiting on validation errors
+func resolveConfigOrExit(projectPath string, flags map[string]string) *config.Effective {
+	eff, err := config.Resolve(projectPath, flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	// Key bindings are checked here rather than when the TUI starts, so a
+	// conflict is reported before a run begins
+	if _, err := keymap.Load(eff.Config.Keymap, eff.Config.Keys); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return eff
+}
+
 func handleSyncCommand(projectPath string, verbose bool) {
 	if err := os.Chdir(projectPath); err != nil {
 		fmt.Fprintf(os.Stderr, "Error changing to project directory: %v\n", err)
 		os.Exit(1)
 	}
 
+	lock := acquireProjectLock("sync", "")
+	defer lock.Release()
+
 	fmt.Println("🔄 Checking task status against filesystem...")
 
 	result, err := loop.SyncTasksWithFilesystem(projectPath)
@@ -454,7 +642,7 @@ func handleSyncCommand(projectPath string, verbose bool) {
 	}
 }
 
-func handleRunCommand(projectPath string, promptFile string, maxCalls int, timeout int, useMonitor bool, verbose bool, backend string, ocSettings openCodeSettings, logFormat string) {
+func handleRunCommand(projectPath string, config config.Config) {
 	if err := os.Chdir(projectPath); err != nil {
 		fmt.Fprintf(os.Stderr, "Error changing to project directory: %v\n", err)
 		os.Exit(1)
@@ -466,40 +654,67 @@ func handleRunCommand(projectPath string, promptFile string, maxCalls int, timeo
 		os.Exit(1)
 	}
 
-	config := loop.Config{
-		Backend:           backend,
-		ProjectPath:       projectPath,
-		PromptPath:        promptFile,
-		MaxCalls:          maxCalls,
-		Timeout:           timeout,
-		Verbose:           verbose,
-		ResetCircuit:      false,
-		OpenCodeServerURL: ocSettings.serverURL,
-		OpenCodeUsername:  ocSettings.username,
-		OpenCodePassword:  ocSettings.password,
-		OpenCodeModelID:   ocSettings.modelID,
-	}
+	lock := acquireProjectLock("run", config.Profile)
+	defer lock.Release()
 
-	rateLimiter := loop.NewRateLimiter(config.MaxCalls, 1)
-	breaker := circuit.NewBreaker(3, 5)
-	controller := loop.NewController(config, rateLimiter, breaker)
+	controller := newController(config)
+	if server := startControlAPI(config, controller); server != nil {
+		defer server.Close()
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
-	setupGracefulShutdown(cancel, controller)
+	setupGracefulShutdown(cancel, controller, lock)
 
-	if logFormat != "" {
-		runWithLogs(ctx, controller, config, verbose, logFormat)
-	} else if useMonitor {
-		runWithMonitor(ctx, controller, config, verbose)
+	if config.LogFormat != "" {
+		runWithLogs(ctx, controller, config, config.Verbose, config.LogFormat)
+	} else if config.Monitor {
+		runWithMonitor(ctx, controller, config, config.Verbose)
 	} else {
-		runHeadless(ctx, controller, config, verbose)
+		runHeadless(ctx, controller, config, config.Verbose)
+	}
+}
+
+// newController builds a loop controller with the configured rate limit and
+// circuit breaker thresholds
+func newController(cfg config.Config) *loop.Controller {
+	rateLimiter := loop.NewRateLimiter(cfg.MaxCalls, cfg.RateLimitResetHours)
+	breaker := circuit.NewBreaker(cfg.NoProgressThreshold, cfg.SameErrorThreshold)
+	return loop.NewController(cfg, rateLimiter, breaker)
+}
+
+// startControlAPI serves the control API when --listen is set, exiting if it
+// can't listen. It returns nil when the API is disabled.
+func startControlAPI(cfg config.Config, controller *loop.Controller) *api.Server {
+	if cfg.Listen == "" {
+		return nil
+	}
+	server, err := api.Listen(cfg.Listen, controller)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error starting control API: %v\n", err)
+		os.Exit(1)
 	}
+	fmt.Printf("🔌 Control API listening on %s\n", server.Addr())
+	return server
 }
 
 func runWithMonitor(ctx context.Context, controller *loop.Controller, config loop.Config, verbose bool, explicitMode ...loop.ProjectMode) {
 	fmt.Printf("🚀 Starting Lisa Codex with TUI monitoring (max %d calls)...\n", config.MaxCalls)
 
-	tuiConfig := codex.Config{
+	var program *tui.Program
+	if len(explicitMode) > 0 && explicitMode[0] != "" {
+		program = tui.NewProgram(tuiConfig(config), controller, explicitMode[0])
+	} else {
+		program = tui.NewProgram(tuiConfig(config), controller)
+	}
+	if err := program.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error running TUI: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// tuiConfig returns the TUI's view of the loop configuration
+func tuiConfig(config loop.Config) codex.Config {
+	return codex.Config{
 		Backend:      config.Backend,
 		ProjectPath:  config.ProjectPath,
 		PromptPath:   config.PromptPath,
@@ -507,18 +722,100 @@ func runWithMonitor(ctx context.Context, controller *loop.Controller, config loo
 		Timeout:      config.Timeout,
 		Verbose:      config.Verbose,
 		ResetCircuit: false,
+		Profile:      config.Profile,
+		Theme:        config.Theme,
+		Keymap:       config.Keymap,
+		Keys:         config.Keys,
 	}
+}
 
-	var program *tui.Program
-	if len(explicitMode) > 0 && explicitMode[0] != "" {
-		program = tui.NewProgram(tuiConfig, controller, explicitMode[0])
-	} else {
-		program = tui.NewProgram(tuiConfig, controller)
+// handleAttachCommand opens the TUI on a loop already running in another
+// process, reached through its control API. Quitting detaches; the run
+// carries on.
+func handleAttachCommand(projectPath string, args []string, config config.Config) {
+	if err := os.Chdir(projectPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error changing to project directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	addr := config.Listen
+	if len(args) > 0 {
+		addr = args[0]
+	}
+	if addr == "" {
+		fmt.Fprintln(os.Stderr, "Error: no control API address; use 'lisa attach <addr>' or set api.listen")
+		os.Exit(1)
+	}
+
+	token, err := api.ReadToken(".")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	client, err := api.Dial(addr, token)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Start the loop with 'lisa run --listen %s'\n", addr)
+		os.Exit(1)
 	}
+	defer client.Close()
+
+	program := tui.NewAttachedProgram(tuiConfig(config), client, client.Addr())
 	if err := program.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error running TUI: %v\n", err)
 		os.Exit(1)
 	}
+	fmt.Printf("Detached from %s\n", client.Addr())
+}
+
+// handleSteerCommand gives a loop running with --listen standing guidance,
+// or withdraws it with --clear
+func handleSteerCommand(projectPath string, args []string, clearAll bool, config config.Config) {
+	message := strings.TrimSpace(strings.Join(args, " "))
+	if message == "" && !clearAll {
+		fmt.Fprintln(os.Stderr, "Error: no message; use 'lisa steer \"<message>\"' or 'lisa steer --clear'")
+		os.Exit(1)
+	}
+	if config.Listen == "" {
+		fmt.Fprintln(os.Stderr, "Error: no control API address; use --listen <addr> or set api.listen")
+		os.Exit(1)
+	}
+
+	token, err := api.ReadToken(projectPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	client, err := api.Dial(config.Listen, token)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer client.Close()
+
+	if clearAll {
+		err = client.Send(http.MethodDelete, "/steer", nil)
+	} else {
+		err = client.Send(http.MethodPost, "/steer", api.SteerRequest{Message: message})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	status, err := client.Status(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(status.Steering) == 0 {
+		fmt.Println("No standing guidance")
+		return
+	}
+	fmt.Println("Standing guidance:")
+	for _, m := range status.Steering {
+		fmt.Printf("  - %s\n", m)
+	}
 }
 
 func runHeadless(ctx context.Context, controller *loop.Controller, config loop.Config, verbose bool) {
@@ -526,29 +823,38 @@ func runHeadless(ctx context.Context, controller *loop.Controller, config loop.C
 	fmt.Println("Press Ctrl+C to stop")
 	fmt.Println()
 
-	// Set up event callback to print logs in headless mode
-	controller.SetEventCallback(func(event loop.LoopEvent) {
+	// Subscribe to events to print logs in headless mode
+	printed := consumeEvents(controller, func(event loop.LoopEvent) {
 		switch event.Type {
-		case "log":
+		case loop.EventTypeLog:
 			levelEmoji := ""
-			switch event.LogLevel {
-			case "INFO":
+			switch event.Log.Level {
+			case loop.LogLevelInfo:
 				levelEmoji = "ℹ️ "
-			case "WARN":
+			case loop.LogLevelWarn:
 				levelEmoji = "⚠️ "
-			case "ERROR":
+			case loop.LogLevelError:
 				levelEmoji = "❌"
-			case "SUCCESS":
+			case loop.LogLevelSuccess:
 				levelEmoji = "✅"
 			}
-			fmt.Printf("%s %s\n", levelEmoji, event.LogMessage)
-		case "loop_update":
+			fmt.Printf("%s %s\n", levelEmoji, event.Log.Message)
+		case loop.EventTypeLoopUpdate:
 			if verbose {
+				u := event.Update
 				fmt.Printf("📊 Loop %d | Calls: %d | Status: %s | Circuit: %s\n",
-					event.LoopNumber, event.CallsUsed, event.Status, event.CircuitState)
+					u.LoopNumber, u.CallsUsed, u.Status, u.CircuitState)
 			}
 		}
 	})
+	stopWatching := watchPending(ctx, controller,
+		func(esc *analysis.Escalation) {
+			go answerEscalation(ctx, controller, esc, stdinIsTerminal())
+		},
+		func(r *loop.Review) {
+			go answerReview(ctx, controller, r, stdinIsTerminal())
+		})
+	defer stopWatching()
 
 	errCh := make(chan error, 1)
 	go func() {
@@ -560,6 +866,7 @@ func runHeadless(ctx context.Context, controller *loop.Controller, config loop.C
 
 	select {
 	case err := <-errCh:
+		printed()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "\n❌ Loop error: %v\n", err)
 			os.Exit(1)
@@ -599,62 +906,91 @@ func runWithLogs(ctx context.Context, controller *loop.Controller, config loop.C
 		"format", logFormat,
 	)
 
-	// Set up event callback to log events
-	controller.SetEventCallback(func(event loop.LoopEvent) {
+	// Subscribe to events to log them
+	logged := consumeEvents(controller, func(event loop.LoopEvent) {
 		switch event.Type {
-		case "log":
-			switch event.LogLevel {
-			case "INFO":
-				logger.Info(event.LogMessage)
-			case "WARN":
-				logger.Warn(event.LogMessage)
-			case "ERROR":
-				logger.Error(event.LogMessage)
-			case "SUCCESS":
-				logger.Info(event.LogMessage, "status", "success")
+		case loop.EventTypeLog:
+			switch event.Log.Level {
+			case loop.LogLevelInfo:
+				logger.Info(event.Log.Message)
+			case loop.LogLevelWarn:
+				logger.Warn(event.Log.Message)
+			case loop.LogLevelError:
+				logger.Error(event.Log.Message)
+			case loop.LogLevelSuccess:
+				logger.Info(event.Log.Message, "status", "success")
 			default:
-				logger.Debug(event.LogMessage)
+				logger.Debug(event.Log.Message)
 			}
 
-		case "loop_update":
+		case loop.EventTypeLoopUpdate:
 			logger.Info("Loop update",
-				"loop", event.LoopNumber,
-				"calls", event.CallsUsed,
-				"status", event.Status,
-				"circuit", event.CircuitState,
+				"run", event.RunID,
+				"loop", event.Update.LoopNumber,
+				"calls", event.Update.CallsUsed,
+				"status", event.Update.Status,
+				"circuit", event.Update.CircuitState,
 			)
 
-		case "codex_output":
+		case loop.EventTypeCodexOutput:
 			if verbose {
 				logger.Debug("Output",
-					"type", event.OutputType,
-					"line", event.OutputLine,
+					"type", event.Output.Type,
+					"line", event.Output.Line,
 				)
 			}
 
-		case "codex_reasoning":
+		case loop.EventTypeCodexReasoning:
 			if verbose {
-				logger.Debug("Reasoning", "text", event.ReasoningText)
+				logger.Debug("Reasoning", "text", event.Reasoning.Text)
 			}
 
-		case "codex_tool":
+		case loop.EventTypeCodexTool:
 			logger.Info("Tool call",
-				"tool", event.ToolName,
-				"target", event.ToolTarget,
-				"status", event.ToolStatus,
+				"tool", event.Tool.Name,
+				"target", event.Tool.Target,
+				"status", event.Tool.Status,
 			)
 
-		case "analysis":
+		case loop.EventTypeAnalysis:
+			a := event.Analysis
 			logger.Info("Analysis result",
-				"status", event.AnalysisStatus,
-				"tasks_completed", event.TasksCompleted,
-				"files_modified", event.FilesModified,
-				"tests", event.TestsStatus,
-				"exit_signal", event.ExitSignal,
-				"confidence", event.ConfidenceScore,
+				"iteration", event.Iteration,
+				"status", a.Status,
+				"tasks_completed", a.TasksCompleted,
+				"files_modified", a.FilesModified,
+				"tests", a.TestsStatus,
+				"exit_signal", a.ExitSignal,
+				"confidence", a.ConfidenceScore,
+				"warnings", len(a.Warnings),
 			)
+
+		case loop.EventTypeReview:
+			if r := event.Review; r.Decision != "" {
+				logger.Info("Review decided", "loop", r.Loop, "decision", r.Decision)
+			}
 		}
 	})
+	stopWatching := watchPending(ctx, controller,
+		func(esc *analysis.Escalation) {
+			logger.Warn("Waiting for human answer",
+				"blockers", len(esc.Blockers),
+				"questions", len(esc.Questions),
+				"question_file", loop.QuestionFile,
+				"answer_file", loop.AnswerFile,
+			)
+			go answerEscalation(ctx, controller, esc, false)
+		},
+		func(r *loop.Review) {
+			logger.Warn("Waiting for review",
+				"loop", r.Loop,
+				"files", len(r.Files),
+				"review_file", loop.ReviewFile,
+				"decision_file", loop.ReviewDecisionFile,
+			)
+			go answerReview(ctx, controller, r, false)
+		})
+	defer stopWatching()
 
 	errCh := make(chan error, 1)
 	go func() {
@@ -666,6 +1002,7 @@ func runWithLogs(ctx context.Context, controller *loop.Controller, config loop.C
 
 	select {
 	case err := <-errCh:
+		logged()
 		if err != nil {
 			logger.Error("Loop error", "error", err)
 			os.Exit(1)
@@ -677,7 +1014,146 @@ func runWithLogs(ctx context.Context, controller *loop.Controller, config loop.C
 	}
 }
 
-func setupGracefulShutdown(cancel context.CancelFunc, controller *loop.Controller) {
+// consumeEvents subscribes to the controller and handles each event on its own
+// goroutine. The returned function unsubscribes and waits until the events
+// already buffered have been handled.
+func consumeEvents(controller *loop.Controller, handle func(loop.LoopEvent)) func() {
+	sub := controller.Subscribe(loop.DefaultBufferSize, loop.DropOldest)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range sub.Events() {
+			handle(event)
+		}
+	}()
+	return func() {
+		sub.Unsubscribe()
+		<-done
+	}
+}
+
+// pendingPollInterval is how often headless and log modes check for an
+// escalation or review waiting on the human
+const pendingPollInterval = 250 * time.Millisecond
+
+// watchPending polls the controller for an escalation or review awaiting a
+// human and hands each new one to its handler once. Polling the controller's
+// state, rather than relying on the lossy event subscription, means a dropped
+// event can never leave the loop paused with nobody asked to answer. The
+// returned function stops the watcher.
+func watchPending(ctx context.Context, controller *loop.Controller, onEscalation func(*analysis.Escalation), onReview func(*loop.Review)) func() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(pendingPollInterval)
+		defer ticker.Stop()
+		var (
+			lastEscalation *analysis.Escalation
+			lastReview     *loop.Review
+		)
+		for {
+			if esc := controller.PendingEscalation(); esc != nil && esc != lastEscalation {
+				lastEscalation = esc
+				onEscalation(esc)
+			}
+			if r := controller.PendingReview(); r != nil && r != lastReview {
+				lastReview = r
+				onReview(r)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-done
+	}
+}
+
+// answerEscalation collects the human's answer to an agent escalation and resumes
+// the loop. An interactive terminal is prompted on stdin; otherwise the question is
+// written to a file and Lisa waits for the answer file.
+func answerEscalation(ctx context.Context, controller *loop.Controller, esc *analysis.Escalation, interactive bool) {
+	var (
+		answer string
+		err    error
+	)
+	if interactive {
+		answer, err = loop.PromptForAnswer(esc, os.Stdin, os.Stdout)
+	} else {
+		fmt.Printf("\n❓ Agent needs input - see %s and write your answer to %s\n", loop.QuestionFile, loop.AnswerFile)
+		answer, err = loop.WaitForAnswerFile(ctx, esc, 2*time.Second)
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "Error reading answer: %v\n", err)
+		}
+		return
+	}
+	controller.Answer(answer)
+}
+
+// answerReview collects the reviewer's decision on an iteration's changes and
+// resumes the loop. An interactive terminal is prompted on stdin; otherwise the
+// review is written to a file and Lisa waits for the decision file. Invalid
+// decisions are reported and asked for again.
+func answerReview(ctx context.Context, controller *loop.Controller, review *loop.Review, interactive bool) {
+	if !interactive {
+		fmt.Printf("\n🔍 Loop %d awaits review - see %s and write approve or reject to %s\n", review.Loop, loop.ReviewFile, loop.ReviewDecisionFile)
+	}
+	for {
+		var (
+			decision loop.ReviewDecision
+			feedback string
+			err      error
+		)
+		if interactive {
+			decision, feedback, err = loop.PromptForReview(review, os.Stdin, os.Stdout)
+		} else {
+			decision, feedback, err = loop.WaitForReviewFile(ctx, review, 2*time.Second)
+		}
+		if err == nil {
+			controller.Review(decision, feedback)
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Error reading review decision: %v\n", err)
+		if errors.Is(err, io.EOF) {
+			return
+		}
+	}
+}
+
+// stdinIsTerminal reports whether stdin is an interactive terminal
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// acquireProjectLock takes the single-instance project lock for a command that
+// modifies project state, exiting with the holder's details if another Lisa
+// process is already driving the project
+func acquireProjectLock(command, profile string) *state.Lock {
+	lock, stale, err := state.AcquireLock(command, profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if stale != nil {
+		fmt.Fprintf(os.Stderr, "Warning: removed stale lock from PID %d (started %s)\n", stale.PID, formatStateTime(stale.StartedAt))
+	}
+	return lock
+}
+
+func setupGracefulShutdown(cancel context.CancelFunc, controller *loop.Controller, lock *state.Lock) {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
@@ -691,6 +1167,9 @@ func setupGracefulShutdown(cancel context.CancelFunc, controller *loop.Controlle
 		if err := controller.GracefulExit(); err != nil {
 			fmt.Fprintf(os.Stderr, "Error during graceful exit: %v\n", err)
 		}
+		if err := lock.Release(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error releasing project lock: %v\n", err)
+		}
 
 		os.Exit(0)
 	}()
@@ -705,12 +1184,30 @@ func isCommand(arg string) bool {
 		"status":        true,
 		"sync":          true,
 		"reset-circuit": true,
+		"state":         true,
+		"attach":        true,
+		"steer":         true,
+		"config":        true,
+		"schema":        true,
 		"help":          true,
 		"version":       true,
 	}
 	return validCommands[arg]
 }
 
+// positionalArgs returns the arguments left after flag parsing, continuing to
+// parse flags that follow them (e.g. "state export out.json --project dir")
+func positionalArgs(fs *flag.FlagSet) []string {
+	var args []string
+	for fs.NArg() > 0 {
+		args = append(args, fs.Arg(0))
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			os.Exit(1)
+		}
+	}
+	return args
+}
+
 func extractCommand(args []string) (string, []string) {
 	command := "run"
 	flagArgs := []string{}
@@ -743,6 +1240,15 @@ func printHelp() {
 	fmt.Println("  status             Show project status")
 	fmt.Println("  sync               Sync task status with filesystem (detect completed tasks)")
 	fmt.Println("  reset-circuit      Reset circuit breaker state")
+	fmt.Println("  state show         Show persisted loop state (.lisa/state.json)")
+	fmt.Println("  state clean        Remove persisted state and legacy dotfiles")
+	fmt.Println("  state export [f]   Write persisted state as JSON to stdout or a file")
+	fmt.Println("  attach [addr]      Open the TUI on a loop started with --listen (default: api.listen)")
+	fmt.Println("  steer <message>    Give a loop started with --listen standing guidance (--clear withdraws it)")
+	fmt.Println("  config show        Show configured settings (--effective: all, with sources)")
+	fmt.Println("  config get <key>   Print the effective value of a setting")
+	fmt.Println("  config set <k> <v> Write a setting to .lisa/config.yaml (--user: ~/.lisa/config.yaml)")
+	fmt.Println("  schema             Print the JSON Schema for the agent's lisa-status block")
 	fmt.Println("  help               Show this help")
 	fmt.Println("  version            Show version")
 	fmt.Println("")
@@ -754,6 +1260,11 @@ func printHelp() {
 	fmt.Println("  --monitor               Enable integrated TUI monitoring")
 	fmt.Println("  --verbose               Verbose output")
 	fmt.Println("  --log-format <format>   Log format: text, json, or logfmt (enables CLI log mode)")
+	fmt.Println("  --profile <name>        Use a named profile from the config file (env: LISA_PROFILE)")
+	fmt.Println("  --listen <addr>         Serve the control API on 127.0.0.1:PORT or unix:/path (env: LISA_LISTEN)")
+	fmt.Println("  --review                Approve (commit) or reject (revert) each iteration's changes (env: LISA_REVIEW)")
+	fmt.Println("  --theme <name>          TUI theme: auto, dark, light, high-contrast, mono or ~/.lisa/themes/<name>.yaml (env: LISA_THEME)")
+	fmt.Println("  --keymap <preset>       TUI keys: default, vim or emacs; rebind actions in the keys section (env: LISA_KEYMAP)")
 	fmt.Println("")
 	fmt.Println("Backend options:")
 	fmt.Println("  --backend <name>        Backend: cli or opencode (default: opencode)")
@@ -762,6 +1273,11 @@ func printHelp() {
 	fmt.Println("  --opencode-pass <pass>  OpenCode password (env: OPENCODE_SERVER_PASSWORD)")
 	fmt.Println("  --opencode-model <id>   OpenCode model ID (env: OPENCODE_MODEL_ID, default: glm-4.7)")
 	fmt.Println("")
+	fmt.Println("Verification options:")
+	fmt.Println("  --test-cmd <command>    Test command run after each loop (e.g. \"go test -json ./...\")")
+	fmt.Println("  --test-report <file>    Test report to parse (go test -json, JUnit XML, TAP, Jest/Vitest JSON)")
+	fmt.Println("  --test-timeout <dur>    Kill the test command after this long and count it as failed (default: 10m)")
+	fmt.Println("")
 	fmt.Println("Init command options:")
 	fmt.Println("  --mode <mode>           Mode: implementation, fix, or refactor (auto-detect)")
 	fmt.Println("")
@@ -775,6 +1291,12 @@ func printHelp() {
 	fmt.Println("  --source <file>         Source file to import (required)")
 	fmt.Println("  --import-name <name>    Project name (auto-detect if empty)")
 	fmt.Println("")
+	fmt.Println("Config command options:")
+	fmt.Println("  --user                  config set: write ~/.lisa/config.yaml instead of the project file")
+	fmt.Println("  --effective             config show: include defaults and show where each value came from")
+	fmt.Println("")
+	fmt.Println("Settings are read from flags > env > --profile > .lisa/config.yaml > ~/.lisa/config.yaml > defaults.")
+	fmt.Println("")
 	fmt.Println("TUI Keybindings:")
 	fmt.Println("  q / Ctrl+c   Quit")
 	fmt.Println("  r            Run/restart loop")
@@ -782,26 +1304,3 @@ func printHelp() {
 	fmt.Println("  l            Toggle log view")
 	fmt.Println("  ?            Show help")
 }
-
-// envFallback returns the flag value if set, otherwise checks the environment variable,
-// and finally returns the default value.
-func envFallback(flagValue, envName, defaultValue string) string {
-	if flagValue != "" {
-		return flagValue
-	}
-	if env := os.Getenv(envName); env != "" {
-		return env
-	}
-	return defaultValue
-}
-
-// isFlagSet checks if a flag was explicitly set on the command line.
-func isFlagSet(fs *flag.FlagSet, name string) bool {
-	found := false
-	fs.Visit(func(f *flag.Flag) {
-		if f.Name == name {
-			found = true
-		}
-	})
-	return found
-}
@@ -51,6 +51,12 @@ func TestCommandParsing(t *testing.T) {
 			wantCmd:   "reset-circuit",
 			wantSetup: false,
 		},
+		{
+			name:      "schema command",
+			args:      []string{"schema"},
+			wantCmd:   "schema",
+			wantSetup: false,
+		},
 		{
 			name:      "help command",
 			args:      []string{"help"},
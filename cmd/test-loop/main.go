@@ -41,37 +41,17 @@ func main() {
 	breaker := circuit.NewBreaker(3, 5)
 	controller := loop.NewController(config, rateLimiter, breaker)
 
-	// Set up event callback to print all events
+	// Subscribe to print all events
 	eventCount := 0
-	controller.SetEventCallback(func(event loop.LoopEvent) {
-		eventCount++
-		timestamp := time.Now().Format("15:04:05")
-
-		switch event.Type {
-		case "log":
-			// Color code by level
-			levelColor := ""
-			resetColor := "\033[0m"
-			switch event.LogLevel {
-			case "INFO":
-				levelColor = "\033[34m" // Blue
-			case "WARN":
-				levelColor = "\033[33m" // Yellow
-			case "ERROR":
-				levelColor = "\033[31m" // Red
-			case "SUCCESS":
-				levelColor = "\033[32m" // Green
-			}
-			fmt.Printf("[%s] %s[%s]%s %s\n", timestamp, levelColor, event.LogLevel, resetColor, event.LogMessage)
-
-		case "loop_update":
-			fmt.Printf("[%s] 📊 Loop: %d | Calls: %d | Status: %s | Circuit: %s\n",
-				timestamp, event.LoopNumber, event.CallsUsed, event.Status, event.CircuitState)
-
-		default:
-			fmt.Printf("[%s] EVENT: %+v\n", timestamp, event)
+	sub := controller.Subscribe(loop.DefaultBufferSize, loop.DropNewest)
+	printed := make(chan struct{})
+	go func() {
+		defer close(printed)
+		for event := range sub.Events() {
+			printEvent(event)
+			eventCount++
 		}
-	})
+	}()
 
 	// Run the loop
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
@@ -82,10 +62,12 @@ func main() {
 	fmt.Println()
 
 	err := controller.Run(ctx)
+	sub.Unsubscribe()
+	<-printed
 
 	fmt.Println()
 	fmt.Println("=== Loop Finished ===")
-	fmt.Printf("Total events: %d\n", eventCount)
+	fmt.Printf("Total events: %d (dropped %d)\n", eventCount, sub.Dropped())
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 	} else {
@@ -96,3 +78,33 @@ func main() {
 	stats := controller.GetStats()
 	fmt.Printf("\nStats: %+v\n", stats)
 }
+
+// printEvent prints one controller event with a timestamp
+func printEvent(event loop.LoopEvent) {
+	timestamp := event.Time.Format("15:04:05")
+
+	switch event.Type {
+	case "log":
+		// Color code by level
+		levelColor := ""
+		resetColor := "\033[0m"
+		switch event.Log.Level {
+		case "INFO":
+			levelColor = "\033[34m" // Blue
+		case "WARN":
+			levelColor = "\033[33m" // Yellow
+		case "ERROR":
+			levelColor = "\033[31m" // Red
+		case "SUCCESS":
+			levelColor = "\033[32m" // Green
+		}
+		fmt.Printf("[%s] %s[%s]%s %s\n", timestamp, levelColor, event.Log.Level, resetColor, event.Log.Message)
+
+	case "loop_update":
+		fmt.Printf("[%s] 📊 Loop: %d | Calls: %d | Status: %s | Circuit: %s\n",
+			timestamp, event.Update.LoopNumber, event.Update.CallsUsed, event.Update.Status, event.Update.CircuitState)
+
+	default:
+		fmt.Printf("[%s] EVENT: %+v\n", timestamp, event)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/brainwhocodes/lisa-loop/internal/backend"
 	"github.com/brainwhocodes/lisa-loop/internal/config"
 	"github.com/brainwhocodes/lisa-loop/internal/opencode"
 	"github.com/charmbracelet/log"
@@ -97,10 +98,10 @@ func main() {
 
 	runner := opencode.NewRunner(cfg)
 
-	var events []map[string]interface{}
-	runner.SetOutputCallback(func(event map[string]interface{}) {
+	var events []backend.Event
+	runner.SetOutputCallback(func(event backend.Event) {
 		events = append(events, event)
-		logger.Debug("Event received", "event", event["event"])
+		logger.Debug("Event received", "kind", event.Kind)
 	})
 
 	output, sid, err := runner.Run("What is 2 + 2? Reply with just the number.")
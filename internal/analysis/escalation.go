@@ -0,0 +1,56 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Escalation holds blockers and questions the agent needs a human to resolve
+type Escalation struct {
+	Blockers  []string
+	Questions []string
+}
+
+// ExtractEscalation returns the blockers and questions reported in a status, or
+// nil if the agent does not need a human. STATUS: BLOCKED without an explicit
+// blocker uses the recommendation (or a generic message) as the blocker.
+func ExtractEscalation(status *RALPHStatus) *Escalation {
+	if status == nil {
+		return nil
+	}
+
+	esc := &Escalation{
+		Blockers:  append([]string(nil), status.Blockers...),
+		Questions: append([]string(nil), status.Questions...),
+	}
+	if status.Status == "BLOCKED" && len(esc.Blockers) == 0 {
+		blocker := strings.TrimSpace(status.Recommendation)
+		if blocker == "" {
+			blocker = "Agent reported STATUS: BLOCKED without details"
+		}
+		esc.Blockers = append(esc.Blockers, blocker)
+	}
+
+	if len(esc.Blockers) == 0 && len(esc.Questions) == 0 {
+		return nil
+	}
+	return esc
+}
+
+// Prompt formats the escalation for display to the human
+func (e *Escalation) Prompt() string {
+	var b strings.Builder
+	if len(e.Blockers) > 0 {
+		b.WriteString("The agent is blocked:\n")
+		for _, blocker := range e.Blockers {
+			fmt.Fprintf(&b, "  - %s\n", blocker)
+		}
+	}
+	if len(e.Questions) > 0 {
+		b.WriteString("The agent asks:\n")
+		for _, question := range e.Questions {
+			fmt.Fprintf(&b, "  - %s\n", question)
+		}
+	}
+	return b.String()
+}
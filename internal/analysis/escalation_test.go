@@ -0,0 +1,88 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractEscalation(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        *RALPHStatus
+		wantNil       bool
+		wantBlockers  []string
+		wantQuestions []string
+	}{
+		{name: "nil status", status: nil, wantNil: true},
+		{name: "working without questions", status: &RALPHStatus{Status: "WORKING"}, wantNil: true},
+		{
+			name:          "question while working",
+			status:        &RALPHStatus{Status: "WORKING", Questions: []string{"Use Postgres or SQLite?"}},
+			wantQuestions: []string{"Use Postgres or SQLite?"},
+		},
+		{
+			name:         "blocked uses recommendation",
+			status:       &RALPHStatus{Status: "BLOCKED", Recommendation: "Need API credentials"},
+			wantBlockers: []string{"Need API credentials"},
+		},
+		{
+			name:         "blocked without details",
+			status:       &RALPHStatus{Status: "BLOCKED"},
+			wantBlockers: []string{"Agent reported STATUS: BLOCKED without details"},
+		},
+		{
+			name:         "explicit blockers win over recommendation",
+			status:       &RALPHStatus{Status: "BLOCKED", Recommendation: "ignored", Blockers: []string{"Tests need Docker"}},
+			wantBlockers: []string{"Tests need Docker"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			esc := ExtractEscalation(tt.status)
+			if tt.wantNil {
+				if esc != nil {
+					t.Errorf("ExtractEscalation() = %+v, want nil", esc)
+				}
+				return
+			}
+			if esc == nil {
+				t.Fatal("ExtractEscalation() = nil, want escalation")
+			}
+			if strings.Join(esc.Blockers, "|") != strings.Join(tt.wantBlockers, "|") {
+				t.Errorf("ExtractEscalation() Blockers = %v, want %v", esc.Blockers, tt.wantBlockers)
+			}
+			if strings.Join(esc.Questions, "|") != strings.Join(tt.wantQuestions, "|") {
+				t.Errorf("ExtractEscalation() Questions = %v, want %v", esc.Questions, tt.wantQuestions)
+			}
+		})
+	}
+}
+
+func TestParseRALPHStatus_BlockersAndQuestions(t *testing.T) {
+	output := `---LISA_STATUS---
+STATUS: BLOCKED
+BLOCKER: Missing DATABASE_URL
+QUESTION: Which region should I deploy to?
+QUESTION: Should I keep the old endpoint?
+EXIT_SIGNAL: false
+---END_LISA_STATUS---`
+
+	status := ParseRALPHStatus(output)
+
+	if len(status.Blockers) != 1 || status.Blockers[0] != "Missing DATABASE_URL" {
+		t.Errorf("ParseRALPHStatus() Blockers = %v", status.Blockers)
+	}
+	if len(status.Questions) != 2 {
+		t.Errorf("ParseRALPHStatus() Questions = %v, want 2", status.Questions)
+	}
+}
+
+func TestEscalation_Prompt(t *testing.T) {
+	esc := &Escalation{Blockers: []string{"No network"}, Questions: []string{"Retry later?"}}
+	prompt := esc.Prompt()
+
+	if !strings.Contains(prompt, "blocked:\n  - No network") || !strings.Contains(prompt, "asks:\n  - Retry later?") {
+		t.Errorf("Prompt() = %q", prompt)
+	}
+}
@@ -0,0 +1,116 @@
+package analysis
+
+import "fmt"
+
+// discrepancyPenalty is subtracted from the confidence score for each discrepancy
+const discrepancyPenalty = 0.2
+
+// GroundTruth holds facts observed independently of the agent's LISA_STATUS claims
+type GroundTruth struct {
+	PlanKnown      bool // Whether the plan could be read before and after the loop
+	TasksChecked   int  // Checkboxes newly marked [x] during the loop
+	RemainingTasks int  // Unchecked tasks left in the plan after the loop
+
+	GitKnown     bool // Whether git diff --numstat was available
+	FilesChanged int  // Files whose numstat changed during the loop
+
+	TestsStatus string // PASSING or FAILING from a parsed test report; empty if unknown
+}
+
+// Discrepancy describes a mismatch between a claim and the observed ground truth
+type Discrepancy struct {
+	Field    string // LISA_STATUS key that was contradicted
+	Claimed  string
+	Observed string
+	Message  string
+}
+
+// Reconcile cross-checks the agent's status claims against ground truth.
+// Each discrepancy is appended to the analysis warnings and lowers the confidence
+// score. An EXIT_SIGNAL or STATUS: COMPLETE is refused while unchecked tasks
+// remain in the plan.
+func Reconcile(a *Analysis, truth GroundTruth) []Discrepancy {
+	if a == nil || a.Status == nil {
+		return nil
+	}
+
+	var found []Discrepancy
+
+	if truth.PlanKnown {
+		if a.Status.TasksCompleted != truth.TasksChecked {
+			found = append(found, Discrepancy{
+				Field:    "TASKS_COMPLETED_THIS_LOOP",
+				Claimed:  fmt.Sprintf("%d", a.Status.TasksCompleted),
+				Observed: fmt.Sprintf("%d", truth.TasksChecked),
+				Message: fmt.Sprintf("Reported %d task(s) completed but %d checkbox(es) were marked [x] in the plan",
+					a.Status.TasksCompleted, truth.TasksChecked),
+			})
+		}
+
+		if a.Status.ExitSignal && truth.RemainingTasks > 0 {
+			found = append(found, Discrepancy{
+				Field:    "EXIT_SIGNAL",
+				Claimed:  "true",
+				Observed: fmt.Sprintf("%d unchecked", truth.RemainingTasks),
+				Message: fmt.Sprintf("EXIT_SIGNAL refused: %d task(s) are still unchecked in the plan",
+					truth.RemainingTasks),
+			})
+			a.ExitSignal = false
+		}
+
+		if a.Status.Status == "COMPLETE" && truth.RemainingTasks > 0 {
+			found = append(found, Discrepancy{
+				Field:    "STATUS",
+				Claimed:  "COMPLETE",
+				Observed: fmt.Sprintf("%d unchecked", truth.RemainingTasks),
+				Message: fmt.Sprintf("STATUS: COMPLETE refused: %d task(s) are still unchecked in the plan",
+					truth.RemainingTasks),
+			})
+			a.Status.Status = "WORKING"
+		}
+	}
+
+	// Allow an off-by-one so agents that don't count the plan file edit aren't flagged
+	if truth.GitKnown && absInt(a.Status.FilesModified-truth.FilesChanged) > 1 {
+		found = append(found, Discrepancy{
+			Field:    "FILES_MODIFIED",
+			Claimed:  fmt.Sprintf("%d", a.Status.FilesModified),
+			Observed: fmt.Sprintf("%d", truth.FilesChanged),
+			Message: fmt.Sprintf("Reported %d file(s) modified but git diff --numstat shows %d",
+				a.Status.FilesModified, truth.FilesChanged),
+		})
+	}
+
+	// A parsed test report always wins over the agent's claim
+	if truth.TestsStatus != "" && truth.TestsStatus != "UNKNOWN" && a.Status.TestsStatus != truth.TestsStatus {
+		found = append(found, Discrepancy{
+			Field:    "TESTS_STATUS",
+			Claimed:  a.Status.TestsStatus,
+			Observed: truth.TestsStatus,
+			Message:  fmt.Sprintf("Reported tests %s but the test report shows %s", a.Status.TestsStatus, truth.TestsStatus),
+		})
+		a.Status.TestsStatus = truth.TestsStatus
+		if truth.TestsStatus == "FAILING" {
+			a.HasErrors = true
+		}
+	}
+
+	for _, d := range found {
+		a.Warnings = append(a.Warnings, d.Message)
+	}
+
+	a.ConfidenceScore -= discrepancyPenalty * float64(len(found))
+	if a.ConfidenceScore < 0.0 {
+		a.ConfidenceScore = 0.0
+	}
+
+	return found
+}
+
+// absInt returns the absolute value of n
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
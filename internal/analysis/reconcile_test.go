@@ -0,0 +1,153 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         RALPHStatus
+		truth          GroundTruth
+		wantFields     []string
+		wantExitSignal bool
+	}{
+		{
+			name:   "claims match ground truth",
+			status: RALPHStatus{TasksCompleted: 1, FilesModified: 3},
+			truth: GroundTruth{
+				PlanKnown: true, TasksChecked: 1, RemainingTasks: 2,
+				GitKnown: true, FilesChanged: 3,
+			},
+			wantFields: nil,
+		},
+		{
+			name:       "overclaimed tasks",
+			status:     RALPHStatus{TasksCompleted: 2},
+			truth:      GroundTruth{PlanKnown: true, TasksChecked: 0, RemainingTasks: 3},
+			wantFields: []string{"TASKS_COMPLETED_THIS_LOOP"},
+		},
+		{
+			name:       "exit signal with unchecked tasks",
+			status:     RALPHStatus{TasksCompleted: 1, ExitSignal: true},
+			truth:      GroundTruth{PlanKnown: true, TasksChecked: 1, RemainingTasks: 2},
+			wantFields: []string{"EXIT_SIGNAL"},
+		},
+		{
+			name:           "exit signal with plan complete",
+			status:         RALPHStatus{TasksCompleted: 1, ExitSignal: true},
+			truth:          GroundTruth{PlanKnown: true, TasksChecked: 1, RemainingTasks: 0},
+			wantFields:     nil,
+			wantExitSignal: true,
+		},
+		{
+			name:       "complete status with unchecked tasks",
+			status:     RALPHStatus{Status: "COMPLETE", TasksCompleted: 1},
+			truth:      GroundTruth{PlanKnown: true, TasksChecked: 1, RemainingTasks: 1},
+			wantFields: []string{"STATUS"},
+		},
+		{
+			name:       "complete status with plan complete",
+			status:     RALPHStatus{Status: "COMPLETE", TasksCompleted: 1},
+			truth:      GroundTruth{PlanKnown: true, TasksChecked: 1, RemainingTasks: 0},
+			wantFields: nil,
+		},
+		{
+			name:       "file count off by one is tolerated",
+			status:     RALPHStatus{FilesModified: 2},
+			truth:      GroundTruth{GitKnown: true, FilesChanged: 3},
+			wantFields: nil,
+		},
+		{
+			name:       "file count mismatch",
+			status:     RALPHStatus{FilesModified: 5},
+			truth:      GroundTruth{GitKnown: true, FilesChanged: 0},
+			wantFields: []string{"FILES_MODIFIED"},
+		},
+		{
+			name:       "tests claimed passing but report fails",
+			status:     RALPHStatus{TestsStatus: "PASSING"},
+			truth:      GroundTruth{TestsStatus: "FAILING"},
+			wantFields: []string{"TESTS_STATUS"},
+		},
+		{
+			name:       "tests status matches report",
+			status:     RALPHStatus{TestsStatus: "PASSING"},
+			truth:      GroundTruth{TestsStatus: "PASSING"},
+			wantFields: nil,
+		},
+		{
+			name:       "unknown ground truth skips checks",
+			status:     RALPHStatus{TasksCompleted: 4, FilesModified: 9},
+			truth:      GroundTruth{},
+			wantFields: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := tt.status
+			a := &Analysis{
+				Status:          &status,
+				ExitSignal:      status.ExitSignal,
+				ConfidenceScore: 0.9,
+			}
+
+			found := Reconcile(a, tt.truth)
+
+			if len(found) != len(tt.wantFields) {
+				t.Fatalf("Reconcile() found %d discrepancies, want %d: %+v", len(found), len(tt.wantFields), found)
+			}
+			for i, d := range found {
+				if d.Field != tt.wantFields[i] {
+					t.Errorf("discrepancy[%d].Field = %s, want %s", i, d.Field, tt.wantFields[i])
+				}
+			}
+
+			if len(a.Warnings) != len(found) {
+				t.Errorf("Warnings = %d, want %d", len(a.Warnings), len(found))
+			}
+
+			wantConfidence := 0.9 - discrepancyPenalty*float64(len(found))
+			if diff := a.ConfidenceScore - wantConfidence; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("ConfidenceScore = %v, want %v", a.ConfidenceScore, wantConfidence)
+			}
+
+			if tt.truth.TestsStatus != "" && a.Status.TestsStatus != tt.truth.TestsStatus {
+				t.Errorf("TestsStatus = %v, want %v", a.Status.TestsStatus, tt.truth.TestsStatus)
+			}
+
+			if tt.status.Status == "COMPLETE" && tt.truth.RemainingTasks > 0 && a.Status.Status == "COMPLETE" {
+				t.Errorf("Status = COMPLETE, want it refused with %d task(s) remaining", tt.truth.RemainingTasks)
+			}
+
+			if tt.status.ExitSignal && a.ExitSignal != tt.wantExitSignal {
+				t.Errorf("ExitSignal = %v, want %v", a.ExitSignal, tt.wantExitSignal)
+			}
+		})
+	}
+}
+
+func TestReconcile_ConfidenceClampsAtZero(t *testing.T) {
+	a := &Analysis{
+		Status:          &RALPHStatus{TasksCompleted: 3, FilesModified: 10, ExitSignal: true},
+		ExitSignal:      true,
+		ConfidenceScore: 0.3,
+	}
+
+	Reconcile(a, GroundTruth{PlanKnown: true, RemainingTasks: 1, GitKnown: true})
+
+	if a.ConfidenceScore != 0 {
+		t.Errorf("ConfidenceScore = %v, want 0", a.ConfidenceScore)
+	}
+	if !strings.Contains(strings.Join(a.Warnings, "\n"), "EXIT_SIGNAL refused") {
+		t.Errorf("Warnings missing EXIT_SIGNAL refusal: %v", a.Warnings)
+	}
+}
+
+func TestReconcile_NilAnalysis(t *testing.T) {
+	if found := Reconcile(nil, GroundTruth{PlanKnown: true}); found != nil {
+		t.Errorf("Reconcile(nil) = %v, want nil", found)
+	}
+}
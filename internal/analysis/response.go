@@ -14,6 +14,8 @@ const (
 	FormatText OutputFormat = "text"
 )
 
+var textStatusBlockRegex = regexp.MustCompile(`(?s)---(?:RALPH|LISA)_STATUS---([\s\S]+?)---END_(?:RALPH|LISA)_STATUS---`)
+
 // RALPHStatus represents a parsed RALPH_STATUS block
 type RALPHStatus struct {
 	Status         string
@@ -24,6 +26,13 @@ type RALPHStatus struct {
 	WorkType       string
 	ExitSignal     bool
 	Recommendation string
+
+	Blockers  []string // Problems that need human attention (BLOCKER: lines in the text block)
+	Questions []string // Questions for the human operator (QUESTION: lines in the text block)
+
+	// Only populated from the JSON status block
+	TaskResults   []TaskResult // Per-task results
+	FollowUpTasks []string     // New tasks to append to the plan
 }
 
 // Analysis represents the result of analyzing Codex output
@@ -35,21 +44,26 @@ type Analysis struct {
 	ConfidenceScore      float64
 	HasErrors            bool
 	ErrorMessages        []string
+	Warnings             []string // Discrepancies found by Reconcile
+	StatusSource         StatusSource
+	ValidationErrors     []string // JSON status block validation failures
 }
 
 // Analyze analyzes Codex output and extracts status information
 func Analyze(output string, exitSignals []string) (*Analysis, error) {
 	format := DetectFormat(output)
 
-	var status *RALPHStatus
+	// Prefer the JSON status block; fall back to the legacy text block
+	status, source, validationErrors := ParseStatus(output)
+
 	var completionCount int
 
 	if format == FormatJSON {
 		// JSON analysis
-		status, completionCount = analyzeJSONOutput(output)
+		completionCount = analyzeJSONOutput(output, status)
 	} else {
 		// Text analysis
-		status, completionCount = analyzeTextOutput(output)
+		completionCount = analyzeTextOutput(output)
 	}
 
 	// Calculate confidence using the helper function
@@ -69,9 +83,32 @@ func Analyze(output string, exitSignals []string) (*Analysis, error) {
 		ConfidenceScore:      confidenceScore,
 		HasErrors:            hasErrors,
 		ErrorMessages:        errorMessages,
+		StatusSource:         source,
+		ValidationErrors:     validationErrors,
 	}, nil
 }
 
+// ParseStatus extracts the agent's status, preferring a valid JSON status block.
+// If the JSON block is invalid, its validation errors are returned alongside the
+// legacy text block status so the loop keeps working while the agent corrects itself.
+func ParseStatus(output string) (*RALPHStatus, StatusSource, []string) {
+	jsonStatus, validationErrors, found := ParseStatusJSON(output)
+	if found && len(validationErrors) == 0 {
+		return jsonStatus, StatusSourceJSON, nil
+	}
+
+	source := StatusSourceNone
+	if hasTextStatusBlock(output) {
+		source = StatusSourceText
+	}
+	return ParseRALPHStatus(output), source, validationErrors
+}
+
+// hasTextStatusBlock reports whether output contains a legacy status block
+func hasTextStatusBlock(output string) bool {
+	return textStatusBlockRegex.MatchString(output)
+}
+
 // DetectFormat determines if output is JSON or text format
 func DetectFormat(output string) OutputFormat {
 	// Check if output starts with JSON structure
@@ -97,8 +134,7 @@ func DetectFormat(output string) OutputFormat {
 // ParseRALPHStatus extracts status information from a RALPH_STATUS or LISA_STATUS block
 func ParseRALPHStatus(output string) *RALPHStatus {
 	// Find RALPH_STATUS or LISA_STATUS block
-	statusBlockRegex := regexp.MustCompile(`(?s)---(?:RALPH|LISA)_STATUS---([\s\S]+?)---END_(?:RALPH|LISA)_STATUS---`)
-	matches := statusBlockRegex.FindStringSubmatch(output)
+	matches := textStatusBlockRegex.FindStringSubmatch(output)
 
 	if len(matches) < 2 {
 		return &RALPHStatus{
@@ -164,6 +200,14 @@ func ParseRALPHStatus(output string) *RALPHStatus {
 			status.ExitSignal = strings.ToLower(value) == "true"
 		case "RECOMMENDATION":
 			status.Recommendation = value
+		case "BLOCKER":
+			if value != "" {
+				status.Blockers = append(status.Blockers, value)
+			}
+		case "QUESTION":
+			if value != "" {
+				status.Questions = append(status.Questions, value)
+			}
 		}
 	}
 
@@ -171,10 +215,7 @@ func ParseRALPHStatus(output string) *RALPHStatus {
 }
 
 // analyzeJSONOutput analyzes JSON-format output
-func analyzeJSONOutput(output string) (*RALPHStatus, int) {
-	// Try to find LISA_STATUS or RALPH_STATUS in JSON
-	status := ParseRALPHStatus(output)
-
+func analyzeJSONOutput(output string, status *RALPHStatus) int {
 	completionCount := 0
 
 	// Look for completion keywords in JSON output
@@ -195,16 +236,12 @@ func analyzeJSONOutput(output string) (*RALPHStatus, int) {
 		completionCount += 3
 	}
 
-	return status, completionCount
+	return completionCount
 }
 
 // analyzeTextOutput analyzes text-format output
-func analyzeTextOutput(output string) (*RALPHStatus, int) {
-	status := ParseRALPHStatus(output)
-
-	completionCount := DetectCompletionKeywords(output)
-
-	return status, completionCount
+func analyzeTextOutput(output string) int {
+	return DetectCompletionKeywords(output)
 }
 
 // DetectCompletionKeywords counts completion indicator keywords
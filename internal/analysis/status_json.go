@@ -0,0 +1,180 @@
+package analysis
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// StatusSchemaVersion is the only JSON status schema version Lisa accepts
+const StatusSchemaVersion = 1
+
+// StatusSchema is the published JSON Schema for the fenced lisa-status block
+//
+//go:embed lisa_status.schema.json
+var StatusSchema []byte
+
+// StatusSource identifies where the parsed status came from
+type StatusSource string
+
+const (
+	StatusSourceNone StatusSource = "none" // No status block found
+	StatusSourceText StatusSource = "text" // Legacy ---LISA_STATUS--- block
+	StatusSourceJSON StatusSource = "json" // Fenced ```lisa-status JSON block
+)
+
+// Task result values allowed in the JSON status block
+const (
+	TaskResultCompleted  = "completed"
+	TaskResultInProgress = "in_progress"
+	TaskResultBlocked    = "blocked"
+	TaskResultSkipped    = "skipped"
+)
+
+// TaskResult is a per-task entry from the JSON status block
+type TaskResult struct {
+	Task   string `json:"task"`
+	Result string `json:"result"`
+	Notes  string `json:"notes,omitempty"`
+}
+
+// statusJSON mirrors lisa_status.schema.json. Required fields are pointers so
+// missing keys can be told apart from zero values.
+type statusJSON struct {
+	Version        *int         `json:"version"`
+	Status         *string      `json:"status"`
+	CurrentTask    string       `json:"current_task"`
+	Tasks          []TaskResult `json:"tasks"`
+	FilesModified  *int         `json:"files_modified"`
+	TestsStatus    *string      `json:"tests_status"`
+	WorkType       string       `json:"work_type"`
+	ExitSignal     *bool        `json:"exit_signal"`
+	Recommendation string       `json:"recommendation"`
+	Blockers       []string     `json:"blockers"`
+	Questions      []string     `json:"questions"`
+	FollowUpTasks  []string     `json:"follow_up_tasks"`
+}
+
+var statusJSONBlockRegex = regexp.MustCompile("(?s)```lisa-status[ \t]*\r?\n(.*?)\r?\n[ \t]*```")
+
+// ParseStatusJSON extracts and strictly validates the last fenced lisa-status block.
+// found is false when no block is present. When the block is present but invalid,
+// the returned status is nil and errs lists every validation failure.
+func ParseStatusJSON(output string) (status *RALPHStatus, errs []string, found bool) {
+	matches := statusJSONBlockRegex.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return nil, nil, false
+	}
+	body := matches[len(matches)-1][1]
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(body)))
+	decoder.DisallowUnknownFields()
+
+	var raw statusJSON
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, []string{fmt.Sprintf("invalid JSON: %v", err)}, true
+	}
+	if decoder.More() {
+		return nil, []string{"invalid JSON: unexpected data after status object"}, true
+	}
+
+	if errs := validateStatusJSON(&raw); len(errs) > 0 {
+		return nil, errs, true
+	}
+
+	status = &RALPHStatus{
+		Status:         *raw.Status,
+		CurrentTask:    raw.CurrentTask,
+		FilesModified:  *raw.FilesModified,
+		TestsStatus:    *raw.TestsStatus,
+		WorkType:       raw.WorkType,
+		ExitSignal:     *raw.ExitSignal,
+		Recommendation: raw.Recommendation,
+		TaskResults:    raw.Tasks,
+		Blockers:       raw.Blockers,
+		Questions:      raw.Questions,
+		FollowUpTasks:  raw.FollowUpTasks,
+	}
+	if status.WorkType == "" {
+		status.WorkType = "UNKNOWN"
+	}
+	for _, task := range raw.Tasks {
+		if task.Result == TaskResultCompleted {
+			status.TasksCompleted++
+		}
+	}
+
+	return status, nil, true
+}
+
+// validateStatusJSON checks a decoded status object against the schema rules
+func validateStatusJSON(raw *statusJSON) []string {
+	var errs []string
+
+	if raw.Version == nil {
+		errs = append(errs, "missing required field \"version\"")
+	} else if *raw.Version != StatusSchemaVersion {
+		errs = append(errs, fmt.Sprintf("\"version\" must be %d, got %d", StatusSchemaVersion, *raw.Version))
+	}
+
+	if raw.Status == nil {
+		errs = append(errs, "missing required field \"status\"")
+	} else if !oneOf(*raw.Status, "WORKING", "COMPLETE", "BLOCKED") {
+		errs = append(errs, fmt.Sprintf("\"status\" must be WORKING, COMPLETE or BLOCKED, got %q", *raw.Status))
+	}
+
+	if raw.FilesModified == nil {
+		errs = append(errs, "missing required field \"files_modified\"")
+	} else if *raw.FilesModified < 0 {
+		errs = append(errs, "\"files_modified\" must be >= 0")
+	}
+
+	if raw.TestsStatus == nil {
+		errs = append(errs, "missing required field \"tests_status\"")
+	} else if !oneOf(*raw.TestsStatus, "PASSING", "FAILING", "UNKNOWN") {
+		errs = append(errs, fmt.Sprintf("\"tests_status\" must be PASSING, FAILING or UNKNOWN, got %q", *raw.TestsStatus))
+	}
+
+	if raw.ExitSignal == nil {
+		errs = append(errs, "missing required field \"exit_signal\"")
+	}
+
+	for i, task := range raw.Tasks {
+		if strings.TrimSpace(task.Task) == "" {
+			errs = append(errs, fmt.Sprintf("\"tasks[%d].task\" must not be empty", i))
+		}
+		if !oneOf(task.Result, TaskResultCompleted, TaskResultInProgress, TaskResultBlocked, TaskResultSkipped) {
+			errs = append(errs, fmt.Sprintf("\"tasks[%d].result\" must be completed, in_progress, blocked or skipped, got %q", i, task.Result))
+		}
+	}
+
+	errs = append(errs, validateNonEmpty("blockers", raw.Blockers)...)
+	errs = append(errs, validateNonEmpty("questions", raw.Questions)...)
+	errs = append(errs, validateNonEmpty("follow_up_tasks", raw.FollowUpTasks)...)
+
+	return errs
+}
+
+// validateNonEmpty reports blank entries in a string list field
+func validateNonEmpty(field string, values []string) []string {
+	var errs []string
+	for i, v := range values {
+		if strings.TrimSpace(v) == "" {
+			errs = append(errs, fmt.Sprintf("\"%s[%d]\" must not be empty", field, i))
+		}
+	}
+	return errs
+}
+
+// oneOf reports whether value equals one of the allowed values
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
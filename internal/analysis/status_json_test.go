@@ -0,0 +1,137 @@
+package analysis
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const validStatusJSON = "```lisa-status\n" + `{
+  "version": 1,
+  "status": "WORKING",
+  "current_task": "Add login",
+  "tasks": [
+    {"task": "Add login", "result": "completed"},
+    {"task": "Add logout", "result": "in_progress", "notes": "half done"}
+  ],
+  "files_modified": 3,
+  "tests_status": "PASSING",
+  "exit_signal": false,
+  "blockers": ["Need API key"],
+  "questions": ["Use OAuth?"],
+  "follow_up_tasks": ["Write docs"]
+}` + "\n```"
+
+func TestParseStatusJSON_Valid(t *testing.T) {
+	status, errs, found := ParseStatusJSON("Did some work.\n" + validStatusJSON)
+
+	if !found {
+		t.Fatal("ParseStatusJSON() found = false, want true")
+	}
+	if len(errs) != 0 {
+		t.Fatalf("ParseStatusJSON() errs = %v, want none", errs)
+	}
+	if status.Status != "WORKING" || status.CurrentTask != "Add login" {
+		t.Errorf("ParseStatusJSON() status = %q/%q, want WORKING/Add login", status.Status, status.CurrentTask)
+	}
+	if status.TasksCompleted != 1 {
+		t.Errorf("ParseStatusJSON() TasksCompleted = %d, want 1", status.TasksCompleted)
+	}
+	if status.FilesModified != 3 || status.TestsStatus != "PASSING" || status.WorkType != "UNKNOWN" {
+		t.Errorf("ParseStatusJSON() files=%d tests=%q work=%q", status.FilesModified, status.TestsStatus, status.WorkType)
+	}
+	if len(status.TaskResults) != 2 || status.TaskResults[1].Notes != "half done" {
+		t.Errorf("ParseStatusJSON() TaskResults = %+v", status.TaskResults)
+	}
+	if len(status.Blockers) != 1 || len(status.Questions) != 1 || len(status.FollowUpTasks) != 1 {
+		t.Errorf("ParseStatusJSON() blockers=%v questions=%v follow_up=%v", status.Blockers, status.Questions, status.FollowUpTasks)
+	}
+}
+
+func TestParseStatusJSON_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"missing required", `{"version": 1, "status": "WORKING", "files_modified": 0, "tests_status": "UNKNOWN"}`, `missing required field "exit_signal"`},
+		{"wrong version", `{"version": 2, "status": "WORKING", "files_modified": 0, "tests_status": "UNKNOWN", "exit_signal": false}`, `"version" must be 1`},
+		{"bad enum", `{"version": 1, "status": "DONE", "files_modified": 0, "tests_status": "UNKNOWN", "exit_signal": false}`, `"status" must be`},
+		{"negative files", `{"version": 1, "status": "WORKING", "files_modified": -1, "tests_status": "UNKNOWN", "exit_signal": false}`, `"files_modified" must be >= 0`},
+		{"unknown field", `{"version": 1, "status": "WORKING", "files_modified": 0, "tests_status": "UNKNOWN", "exit_signal": false, "mood": "great"}`, `unknown field "mood"`},
+		{"bad task result", `{"version": 1, "status": "WORKING", "files_modified": 0, "tests_status": "UNKNOWN", "exit_signal": false, "tasks": [{"task": "x", "result": "done"}]}`, `"tasks[0].result"`},
+		{"empty blocker", `{"version": 1, "status": "BLOCKED", "files_modified": 0, "tests_status": "UNKNOWN", "exit_signal": false, "blockers": [""]}`, `"blockers[0]" must not be empty`},
+		{"trailing data", `{"version": 1, "status": "WORKING", "files_modified": 0, "tests_status": "UNKNOWN", "exit_signal": false} {}`, "unexpected data"},
+		{"malformed", `{"version": 1,`, "invalid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, errs, found := ParseStatusJSON("```lisa-status\n" + tt.body + "\n```")
+			if !found {
+				t.Fatal("ParseStatusJSON() found = false, want true")
+			}
+			if status != nil {
+				t.Errorf("ParseStatusJSON() status = %+v, want nil", status)
+			}
+			if !strings.Contains(strings.Join(errs, "; "), tt.wantErr) {
+				t.Errorf("ParseStatusJSON() errs = %v, want containing %q", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseStatusJSON_LastBlockWins(t *testing.T) {
+	first := "```lisa-status\n" + `{"version": 1, "status": "WORKING", "files_modified": 0, "tests_status": "UNKNOWN", "exit_signal": false}` + "\n```"
+	last := "```lisa-status\n" + `{"version": 1, "status": "COMPLETE", "files_modified": 0, "tests_status": "PASSING", "exit_signal": true}` + "\n```"
+
+	status, _, _ := ParseStatusJSON(first + "\nmore work\n" + last)
+	if status == nil || status.Status != "COMPLETE" {
+		t.Errorf("ParseStatusJSON() status = %+v, want COMPLETE from last block", status)
+	}
+}
+
+func TestParseStatusJSON_NotFound(t *testing.T) {
+	if _, _, found := ParseStatusJSON("```json\n{}\n```"); found {
+		t.Error("ParseStatusJSON() found = true, want false for non lisa-status fence")
+	}
+}
+
+func TestParseStatus_FallsBackToText(t *testing.T) {
+	output := "```lisa-status\n{\"version\": 1}\n```\n" +
+		"---LISA_STATUS---\nSTATUS: WORKING\nTASKS_COMPLETED_THIS_LOOP: 2\nEXIT_SIGNAL: false\n---END_LISA_STATUS---"
+
+	status, source, errs := ParseStatus(output)
+	if source != StatusSourceText {
+		t.Errorf("ParseStatus() source = %q, want %q", source, StatusSourceText)
+	}
+	if status.TasksCompleted != 2 {
+		t.Errorf("ParseStatus() TasksCompleted = %d, want 2", status.TasksCompleted)
+	}
+	if len(errs) == 0 {
+		t.Error("ParseStatus() errs empty, want validation errors from the JSON block")
+	}
+}
+
+func TestAnalyze_JSONStatus(t *testing.T) {
+	result, err := Analyze("All done.\n"+strings.Replace(validStatusJSON, `"exit_signal": false`, `"exit_signal": true`, 1), []string{})
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if result.StatusSource != StatusSourceJSON {
+		t.Errorf("Analyze() StatusSource = %q, want %q", result.StatusSource, StatusSourceJSON)
+	}
+	if !result.ExitSignal {
+		t.Error("Analyze() ExitSignal = false, want true")
+	}
+}
+
+func TestStatusSchema_IsValidJSON(t *testing.T) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal(StatusSchema, &schema); err != nil {
+		t.Fatalf("StatusSchema is not valid JSON: %v", err)
+	}
+	if schema["additionalProperties"] != false {
+		t.Error("StatusSchema should reject additional properties")
+	}
+}
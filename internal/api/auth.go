@@ -0,0 +1,123 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/brainwhocodes/lisa-loop/internal/state"
+)
+
+// TokenPath holds the running loop's API token. It is written with mode 0600
+// when the server starts and removed when it closes.
+var TokenPath = filepath.Join(state.Dir, "api-token")
+
+// NewToken returns a random token for one run of the API
+func NewToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate API token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// writeToken stores token in TokenPath, readable only by the current user
+func writeToken(token string) error {
+	if err := os.MkdirAll(filepath.Dir(TokenPath), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(TokenPath), err)
+	}
+	tmp := TokenPath + ".tmp"
+	if err := os.WriteFile(tmp, []byte(token+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", TokenPath, err)
+	}
+	// WriteFile keeps the mode of an existing file, so tighten it explicitly
+	if err := os.Chmod(tmp, 0600); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", TokenPath, err)
+	}
+	if err := os.Rename(tmp, TokenPath); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", TokenPath, err)
+	}
+	return nil
+}
+
+// ReadToken reads the API token of the loop running in projectDir
+func ReadToken(projectDir string) (string, error) {
+	path := filepath.Join(projectDir, TokenPath)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("no API token at %s; is a loop running with --listen in this project?", path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read API token: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// removeToken deletes TokenPath if it still holds token, so a newer run's
+// token is never removed
+func removeToken(token string) {
+	if data, err := os.ReadFile(TokenPath); err == nil && strings.TrimSpace(string(data)) == token {
+		_ = os.Remove(TokenPath)
+	}
+}
+
+// guard rejects requests that may come from a web page rather than a local
+// client: any request with an Origin header, TCP requests whose Host is not
+// a loopback name (DNS rebinding), requests without the run's bearer token,
+// and control requests whose body is not declared as JSON (which a page
+// could otherwise send without a CORS preflight).
+func guard(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Origin") != "" {
+			writeError(w, http.StatusForbidden, "cross-origin requests are not allowed")
+			return
+		}
+		if !isUnixRequest(r) && !isLoopbackHost(r.Host) {
+			writeError(w, http.StatusForbidden, fmt.Sprintf("host %q is not a loopback address", r.Host))
+			return
+		}
+		auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(auth), []byte(token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "missing or invalid API token")
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil || mediaType != "application/json" {
+				writeError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isUnixRequest reports whether the request arrived over a unix socket
+func isUnixRequest(r *http.Request) bool {
+	addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr)
+	return ok && addr.Network() == "unix"
+}
+
+// isLoopbackHost reports whether a Host header names this machine
+func isLoopbackHost(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
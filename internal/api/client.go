@@ -0,0 +1,318 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+)
+
+// requestTimeout bounds status and control requests; the event stream has none
+const requestTimeout = 10 * time.Second
+
+// ErrConnectionLost is returned by Client.Run when the event stream ends
+// before the loop's run does
+var ErrConnectionLost = errors.New("lost connection to the loop")
+
+// Client drives a loop served by the control API from another process. It
+// has the same control methods as loop.Controller, so the TUI can attach to a
+// running loop through it. Closing the client detaches without touching the
+// run.
+type Client struct {
+	addr  string
+	base  string
+	token string
+	http  *http.Client
+	bus   *loop.Bus
+
+	streamOnce sync.Once
+	cancel     context.CancelFunc
+	ended      chan struct{} // Closed when the run ends or the stream stops
+
+	mu        sync.Mutex
+	synced    bool  // The stream's snapshot has arrived
+	runOver   bool  // The run ended after the snapshot
+	streamErr error // Why the stream stopped, if the run had not ended
+	endOnce   sync.Once
+}
+
+// Dial connects to the control API at addr (host:port or UnixPrefix followed
+// by a socket path) with the run's token (see ReadToken) and checks that it
+// answers
+func Dial(addr, token string) (*Client, error) {
+	c := &Client{
+		addr:  addr,
+		base:  "http://" + addr,
+		token: token,
+		http:  &http.Client{},
+		bus:   loop.NewBus(),
+		ended: make(chan struct{}),
+	}
+	if path, ok := strings.CutPrefix(addr, UnixPrefix); ok {
+		if path == "" {
+			return nil, errors.New("unix socket path is empty")
+		}
+		c.base = "http://lisa"
+		c.http.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	if _, err := c.Status(ctx); err != nil {
+		return nil, fmt.Errorf("failed to reach the loop at %s: %w", addr, err)
+	}
+	return c, nil
+}
+
+// Addr returns the address the client is connected to
+func (c *Client) Addr() string {
+	return c.addr
+}
+
+// Status fetches the loop's status
+func (c *Client) Status(ctx context.Context) (loop.Status, error) {
+	var status loop.Status
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base+"/status", nil)
+	if err != nil {
+		return status, err
+	}
+	err = c.do(req, &status)
+	return status, err
+}
+
+// Subscribe registers a subscriber for the loop's events. The first call
+// opens the event stream, which starts with recent events and a snapshot.
+func (c *Client) Subscribe(buffer int, policy loop.DropPolicy) *loop.Subscription {
+	sub := c.bus.Subscribe(buffer, policy)
+	c.startStream()
+	return sub
+}
+
+// Run waits for the attached loop's current run to end. It never starts a
+// run: it returns nil when the run ends, ErrConnectionLost if the stream
+// stops first, or ctx's error when the caller detaches.
+func (c *Client) Run(ctx context.Context) error {
+	c.startStream()
+	select {
+	case <-c.ended:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.runOver {
+			return nil
+		}
+		return c.streamErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close detaches from the loop, ending the event stream and subscriptions
+func (c *Client) Close() {
+	c.streamOnce.Do(func() {})
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.bus.Close()
+}
+
+// Pause pauses the loop once the current iteration finishes
+func (c *Client) Pause() { c.control(http.MethodPost, "/pause", nil) }
+
+// PauseNow pauses the loop immediately, interrupting the current iteration
+func (c *Client) PauseNow() { c.control(http.MethodPost, "/pause-now", nil) }
+
+// Resume resumes a paused loop
+func (c *Client) Resume() { c.control(http.MethodPost, "/resume", nil) }
+
+// StopAfterIteration stops the loop after the current iteration
+func (c *Client) StopAfterIteration() { c.control(http.MethodPost, "/stop", nil) }
+
+// Abort ends the run now, cancelling the backend call
+func (c *Client) Abort() { c.control(http.MethodPost, "/abort", nil) }
+
+// SkipTask skips the task the loop is working on
+func (c *Client) SkipTask() { c.control(http.MethodPost, "/skip", nil) }
+
+// Steer adds standing guidance for the agent
+func (c *Client) Steer(message string) {
+	c.control(http.MethodPost, "/steer", SteerRequest{Message: message})
+}
+
+// ClearSteering withdraws all standing guidance
+func (c *Client) ClearSteering() { c.control(http.MethodDelete, "/steer", nil) }
+
+// Answer answers the agent's pending question and resumes the loop
+func (c *Client) Answer(answer string) {
+	c.control(http.MethodPost, "/answer", AnswerRequest{Answer: answer})
+}
+
+// Review approves or rejects the iteration awaiting review
+func (c *Client) Review(decision loop.ReviewDecision, feedback string) {
+	c.control(http.MethodPost, "/review", ReviewRequest{Decision: string(decision), Feedback: feedback})
+}
+
+// control posts a control request. Failures are published as error logs,
+// since the control methods have no error result.
+func (c *Client) control(method, path string, body interface{}) {
+	if err := c.Send(method, path, body); err != nil {
+		c.bus.Publish(loop.LoopEvent{
+			Type: loop.EventTypeLog,
+			Time: time.Now(),
+			Log:  &loop.LogEntry{Level: loop.LogLevelError, Message: fmt.Sprintf("Control request %s failed: %v", path, err)},
+		})
+	}
+}
+
+// Send makes a control request, discarding the status it responds with
+func (c *Client) Send(method, path string, body interface{}) error {
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req, nil)
+}
+
+// do sends a request and decodes a JSON response into out, if given
+func (c *Client) do(req *http.Request, out interface{}) error {
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr ErrorResponse
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("%s", apiErr.Error)
+		}
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// startStream opens the event stream once and republishes its events on the
+// client's bus
+func (c *Client) startStream() {
+	c.streamOnce.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		c.cancel = cancel
+		go func() {
+			err := c.stream(ctx)
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			c.end(false, fmt.Errorf("%w: %v", ErrConnectionLost, err))
+		}()
+	})
+}
+
+// stream reads server-sent events until the stream ends
+func (c *Client) stream(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base+"/events", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	var data strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return errors.New("stream closed by the loop")
+			}
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "":
+			if data.Len() > 0 {
+				c.dispatch(data.String())
+				data.Reset()
+			}
+		case strings.HasPrefix(line, "data: "):
+			if data.Len() > 0 {
+				data.WriteString("\n")
+			}
+			data.WriteString(strings.TrimPrefix(line, "data: "))
+		}
+	}
+}
+
+// dispatch publishes one streamed event and tracks whether the run has ended.
+// State changes replayed before the snapshot are history, not the end of the
+// run the client attached to.
+func (c *Client) dispatch(data string) {
+	var event loop.LoopEvent
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		return
+	}
+	c.bus.Publish(event)
+
+	c.mu.Lock()
+	ended := false
+	switch {
+	case event.Type == loop.EventTypeSnapshot && event.Snapshot != nil:
+		c.synced = true
+		ended = event.Snapshot.State == loop.RunStateStopped
+	case event.Type == loop.EventTypeStateChange && event.State != nil && c.synced:
+		ended = event.State.To == loop.RunStateStopped
+	}
+	c.mu.Unlock()
+
+	if ended {
+		c.end(true, nil)
+	}
+}
+
+// end records why Run should return and releases it, once
+func (c *Client) end(runOver bool, err error) {
+	c.endOnce.Do(func() {
+		c.mu.Lock()
+		c.runOver = runOver
+		c.streamErr = err
+		c.mu.Unlock()
+		close(c.ended)
+	})
+}
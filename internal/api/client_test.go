@@ -0,0 +1,195 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+)
+
+func dialTestServer(t *testing.T, fake *fakeController) (*Client, *httptest.Server) {
+	t.Helper()
+	srv := httptest.NewServer(Handler(fake, nil, testToken))
+	client, err := Dial(strings.TrimPrefix(srv.URL, "http://"), testToken)
+	if err != nil {
+		srv.Close()
+		t.Fatalf("Dial() error = %v", err)
+	}
+	return client, srv
+}
+
+func nextEvent(t *testing.T, sub *loop.Subscription) loop.LoopEvent {
+	t.Helper()
+	select {
+	case event := <-sub.Events():
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for an event")
+		return loop.LoopEvent{}
+	}
+}
+
+func TestDial_Unreachable(t *testing.T) {
+	srv := httptest.NewServer(nil)
+	addr := strings.TrimPrefix(srv.URL, "http://")
+	srv.Close()
+
+	if _, err := Dial(addr, testToken); err == nil {
+		t.Error("Dial() to a closed server succeeded")
+	}
+}
+
+func TestDial_WrongToken(t *testing.T) {
+	srv := httptest.NewServer(Handler(newFakeController(), nil, testToken))
+	defer srv.Close()
+
+	if _, err := Dial(strings.TrimPrefix(srv.URL, "http://"), "stale-token"); err == nil {
+		t.Error("Dial() with the wrong token succeeded")
+	}
+}
+
+func TestClient_Controls(t *testing.T) {
+	fake := newFakeController()
+	client, srv := dialTestServer(t, fake)
+	defer srv.Close()
+	defer client.Close()
+
+	client.Pause()
+	client.PauseNow()
+	client.Resume()
+	client.StopAfterIteration()
+	client.Abort()
+	client.SkipTask()
+	client.Steer("Keep the public API")
+	client.Answer("Yes")
+
+	if got := strings.Join(fake.calls, ","); got != "pause,pause-now,resume,stop,abort,skip" {
+		t.Errorf("controls called = %s", got)
+	}
+	if len(fake.steering) != 1 || fake.steering[0] != "Keep the public API" {
+		t.Errorf("Steer() received %v", fake.steering)
+	}
+	client.ClearSteering()
+	if len(fake.steering) != 0 {
+		t.Errorf("ClearSteering() left %v", fake.steering)
+	}
+	if len(fake.answers) != 1 || fake.answers[0] != "Yes" {
+		t.Errorf("Answer() received %v", fake.answers)
+	}
+	fake.mu.Lock()
+	fake.review = &loop.Review{Loop: 2}
+	fake.mu.Unlock()
+	client.Review(loop.ReviewApprove, "")
+	if len(fake.reviews) != 1 || fake.reviews[0] != "approve: " {
+		t.Errorf("Review() received %v", fake.reviews)
+	}
+}
+
+func TestClient_ControlFailureIsLogged(t *testing.T) {
+	fake := newFakeController()
+	client, srv := dialTestServer(t, fake)
+	defer client.Close()
+	sub := client.Subscribe(16, loop.DropNewest)
+	if event := nextEvent(t, sub); event.Type != loop.EventTypeSnapshot {
+		t.Fatalf("first event = %s, want snapshot", event.Type)
+	}
+	srv.CloseClientConnections()
+	srv.Close()
+
+	client.Pause()
+	for {
+		event := nextEvent(t, sub)
+		if event.Type == loop.EventTypeLog && strings.Contains(event.Log.Message, "Control request /pause failed") {
+			break
+		}
+	}
+}
+
+func TestClient_SubscribeStreamsEvents(t *testing.T) {
+	fake := newFakeController()
+	client, srv := dialTestServer(t, fake)
+	defer srv.Close()
+	defer client.Close()
+
+	sub := client.Subscribe(16, loop.DropNewest)
+	snapshot := nextEvent(t, sub)
+	if snapshot.Type != loop.EventTypeSnapshot || snapshot.Snapshot.Loop != 2 {
+		t.Fatalf("first event = %+v, want the snapshot", snapshot)
+	}
+
+	fake.bus.Publish(loop.LoopEvent{Type: loop.EventTypeCodexOutput, Output: &loop.OutputLine{Line: "line one\nline two", Type: loop.OutputTypeAgentMessage}})
+	event := nextEvent(t, sub)
+	if event.Output == nil || event.Output.Line != "line one\nline two" {
+		t.Errorf("streamed event = %+v, want the published output", event)
+	}
+}
+
+func TestClient_RunReturnsWhenRunEnds(t *testing.T) {
+	fake := newFakeController()
+	client, srv := dialTestServer(t, fake)
+	defer srv.Close()
+	defer client.Close()
+
+	sub := client.Subscribe(16, loop.DropNewest)
+	nextEvent(t, sub) // snapshot
+
+	done := make(chan error, 1)
+	go func() { done <- client.Run(context.Background()) }()
+
+	fake.bus.Publish(loop.LoopEvent{Type: loop.EventTypeStateChange, State: &loop.StateChange{From: loop.RunStateRunning, To: loop.RunStateStopped}})
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() error = %v, want nil once the run stops", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return after the run stopped")
+	}
+	if len(fake.calls) != 0 {
+		t.Errorf("Run() sent controls %v, want none", fake.calls)
+	}
+}
+
+func TestClient_RunDetachesOnCancel(t *testing.T) {
+	fake := newFakeController()
+	client, srv := dialTestServer(t, fake)
+	defer srv.Close()
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- client.Run(ctx) }()
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Run() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return after cancel")
+	}
+	if len(fake.calls) != 0 {
+		t.Errorf("detaching sent controls %v, want none", fake.calls)
+	}
+}
+
+func TestClient_RunReportsLostConnection(t *testing.T) {
+	fake := newFakeController()
+	client, srv := dialTestServer(t, fake)
+	defer client.Close()
+
+	sub := client.Subscribe(16, loop.DropNewest)
+	nextEvent(t, sub) // snapshot
+	srv.CloseClientConnections()
+	srv.Close()
+
+	err := client.Run(context.Background())
+	if !errors.Is(err, ErrConnectionLost) {
+		t.Errorf("Run() error = %v, want ErrConnectionLost", err)
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"sync"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+)
+
+// DefaultRecentEvents is how many events a Recorder keeps for late clients
+const DefaultRecentEvents = 1000
+
+// Recorder keeps the most recent loop events so a client attaching mid-run
+// can replay the transcript tail before following the live stream. Debug
+// logs are not kept.
+type Recorder struct {
+	sub  *loop.Subscription
+	done chan struct{}
+
+	mu     sync.Mutex
+	events []loop.LoopEvent
+	size   int
+}
+
+// NewRecorder starts recording controller's events, keeping the last size
+// (DefaultRecentEvents if <= 0). Call Close to stop.
+func NewRecorder(controller Controller, size int) *Recorder {
+	if size <= 0 {
+		size = DefaultRecentEvents
+	}
+	r := &Recorder{
+		sub:  controller.Subscribe(loop.DefaultBufferSize, loop.DropOldest),
+		done: make(chan struct{}),
+		size: size,
+	}
+	go func() {
+		defer close(r.done)
+		for event := range r.sub.Events() {
+			r.record(event)
+		}
+	}()
+	return r
+}
+
+func (r *Recorder) record(event loop.LoopEvent) {
+	if event.Type == loop.EventTypeLog && event.Log != nil && event.Log.Level == loop.LogLevelDebug {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	if len(r.events) > r.size {
+		r.events = r.events[len(r.events)-r.size:]
+	}
+}
+
+// Recent returns the recorded events, oldest first
+func (r *Recorder) Recent() []loop.LoopEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]loop.LoopEvent(nil), r.events...)
+}
+
+// Close stops recording and waits for events already delivered to be kept
+func (r *Recorder) Close() {
+	r.sub.Unsubscribe()
+	<-r.done
+}
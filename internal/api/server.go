@@ -0,0 +1,331 @@
+// Package api serves a small HTTP/JSON API for controlling and observing a
+// running loop over a loopback TCP address or a unix socket. Every request
+// must carry the run's bearer token, which is written to TokenPath.
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+)
+
+// UnixPrefix marks a listen address as a unix socket path, e.g. "unix:.lisa/lisa.sock"
+const UnixPrefix = "unix:"
+
+// maxBodyBytes caps the size of a steering, answer or review request body
+const maxBodyBytes = 64 << 10
+
+// Controller is the part of the loop controller the API drives
+type Controller interface {
+	Status() loop.Status
+	Subscribe(buffer int, policy loop.DropPolicy) *loop.Subscription
+	Pause()
+	PauseNow()
+	Resume()
+	StopAfterIteration()
+	Abort()
+	SkipTask()
+	Steer(message string)
+	ClearSteering()
+	Answer(answer string)
+	Review(decision loop.ReviewDecision, feedback string)
+}
+
+// SteerRequest is the body of POST /steer
+type SteerRequest struct {
+	Message string `json:"message"`
+}
+
+// AnswerRequest is the body of POST /answer
+type AnswerRequest struct {
+	Answer string `json:"answer"`
+}
+
+// ReviewRequest is the body of POST /review
+type ReviewRequest struct {
+	Decision string `json:"decision"`           // approve or reject
+	Feedback string `json:"feedback,omitempty"` // Note to the agent on a rejection
+}
+
+// ErrorResponse is the body of every failed request
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server serves the control API until it is closed
+type Server struct {
+	listener net.Listener
+	http     *http.Server
+	recorder *Recorder
+	token    string
+}
+
+// Listen starts serving the API for controller on addr, either a loopback
+// host:port or UnixPrefix followed by a socket path. A leftover socket file
+// from an earlier run is replaced. A new token is generated for the run and
+// written to TokenPath.
+func Listen(addr string, controller Controller) (*Server, error) {
+	network, address, err := parseAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	token, err := NewToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := writeToken(token); err != nil {
+		return nil, err
+	}
+	if network == "unix" {
+		if info, statErr := os.Stat(address); statErr == nil && info.Mode()&os.ModeSocket != 0 {
+			_ = os.Remove(address)
+		}
+	}
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		removeToken(token)
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	recorder := NewRecorder(controller, DefaultRecentEvents)
+	s := &Server{
+		listener: listener,
+		http:     &http.Server{Handler: Handler(controller, recorder, token)},
+		recorder: recorder,
+		token:    token,
+	}
+	go func() { _ = s.http.Serve(listener) }()
+	return s, nil
+}
+
+// parseAddr splits a listen address into its network and address, refusing
+// TCP addresses reachable from other machines
+func parseAddr(addr string) (string, string, error) {
+	if path, ok := strings.CutPrefix(addr, UnixPrefix); ok {
+		if path == "" {
+			return "", "", errors.New("unix socket path is empty")
+		}
+		return "unix", path, nil
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid listen address %q: %w", addr, err)
+	}
+	if host != "localhost" {
+		ip := net.ParseIP(host)
+		if ip == nil || !ip.IsLoopback() {
+			return "", "", fmt.Errorf("listen address %q is not a loopback address; use 127.0.0.1, localhost or a unix socket", addr)
+		}
+	}
+	return "tcp", addr, nil
+}
+
+// Addr returns the address the server is listening on
+func (s *Server) Addr() string {
+	if s.listener.Addr().Network() == "unix" {
+		return UnixPrefix + s.listener.Addr().String()
+	}
+	return s.listener.Addr().String()
+}
+
+// Token returns the bearer token clients must send
+func (s *Server) Token() string {
+	return s.token
+}
+
+// Close stops the server, ending open event streams, and removes its token file
+func (s *Server) Close() error {
+	err := s.http.Close()
+	s.recorder.Close()
+	removeToken(s.token)
+	return err
+}
+
+// Handler returns the API's HTTP handler:
+//
+//	GET  /status     Status snapshot (loop, plan, breaker, rate limit, context)
+//	GET  /events     Server-sent events: recent events, a snapshot, then live events
+//	POST /pause      Pause after the current iteration
+//	POST /pause-now  Pause now, interrupting the current iteration
+//	POST /resume     Resume a paused loop
+//	POST /stop       Stop after the current iteration
+//	POST /abort      Abort now, cancelling the backend call
+//	POST /skip       Skip the current task
+//	POST /steer      Add standing guidance for the agent ({"message": "..."})
+//	DELETE /steer    Clear all standing guidance
+//	POST /answer     Answer the agent's pending question ({"answer": "..."})
+//	POST /review     Approve or reject the iteration awaiting review ({"decision": "reject", "feedback": "..."})
+//
+// Every control endpoint responds with the status after the action. Requests
+// must send "Authorization: Bearer <token>", no Origin header, a loopback
+// Host over TCP, and a JSON Content-Type on POST and DELETE. The recorder
+// supplies the events replayed to new streams and may be nil.
+func Handler(controller Controller, recorder *Recorder, token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, controller.Status())
+	})
+	mux.HandleFunc("GET /events", func(w http.ResponseWriter, r *http.Request) {
+		streamEvents(w, r, controller, recorder)
+	})
+
+	controls := map[string]func(){
+		"/pause":     controller.Pause,
+		"/pause-now": controller.PauseNow,
+		"/resume":    controller.Resume,
+		"/stop":      controller.StopAfterIteration,
+		"/abort":     controller.Abort,
+		"/skip":      controller.SkipTask,
+	}
+	for path, action := range controls {
+		mux.HandleFunc("POST "+path, func(w http.ResponseWriter, r *http.Request) {
+			action()
+			writeJSON(w, http.StatusOK, controller.Status())
+		})
+	}
+
+	mux.HandleFunc("POST /steer", func(w http.ResponseWriter, r *http.Request) {
+		var req SteerRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid steering request: %v", err))
+			return
+		}
+		if strings.TrimSpace(req.Message) == "" {
+			writeError(w, http.StatusBadRequest, "message is required")
+			return
+		}
+		controller.Steer(req.Message)
+		writeJSON(w, http.StatusOK, controller.Status())
+	})
+
+	mux.HandleFunc("DELETE /steer", func(w http.ResponseWriter, r *http.Request) {
+		controller.ClearSteering()
+		writeJSON(w, http.StatusOK, controller.Status())
+	})
+
+	mux.HandleFunc("POST /answer", func(w http.ResponseWriter, r *http.Request) {
+		var req AnswerRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid answer request: %v", err))
+			return
+		}
+		controller.Answer(req.Answer)
+		writeJSON(w, http.StatusOK, controller.Status())
+	})
+
+	mux.HandleFunc("POST /review", func(w http.ResponseWriter, r *http.Request) {
+		var req ReviewRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid review request: %v", err))
+			return
+		}
+		decision, err := loop.ParseReviewDecision(req.Decision)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if controller.Status().Review == nil {
+			writeError(w, http.StatusConflict, "no iteration is awaiting review")
+			return
+		}
+		controller.Review(decision, req.Feedback)
+		writeJSON(w, http.StatusOK, controller.Status())
+	})
+	return guard(token, mux)
+}
+
+// streamEvents writes the recorded events, a status snapshot and then every
+// new loop event as server-sent events until the client disconnects. Events
+// a slow client can't keep up with are dropped oldest first; the seq field
+// shows the gap.
+func streamEvents(w http.ResponseWriter, r *http.Request, controller Controller, recorder *Recorder) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	sub := controller.Subscribe(loop.DefaultBufferSize, loop.DropOldest)
+	defer sub.Unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	// Replay first so the snapshot that follows corrects any stale state.
+	// Events published since subscribing may also be in the replay; the seq
+	// filter below drops those duplicates.
+	var lastSeq uint64
+	if recorder != nil {
+		for _, event := range recorder.Recent() {
+			if err := writeEvent(w, string(event.Type), event.Seq, event); err != nil {
+				return
+			}
+			lastSeq = event.Seq
+		}
+	}
+	status := controller.Status()
+	snapshot := loop.LoopEvent{
+		Type:      loop.EventTypeSnapshot,
+		RunID:     status.RunID,
+		Iteration: status.Iteration,
+		Time:      time.Now(),
+		Snapshot:  &status,
+	}
+	if err := writeEvent(w, string(snapshot.Type), 0, snapshot); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if event.Seq <= lastSeq {
+				continue
+			}
+			if err := writeEvent(w, string(event.Type), event.Seq, event); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvent writes one server-sent event with a JSON payload
+func writeEvent(w http.ResponseWriter, name string, id uint64, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if id > 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+	return err
+}
+
+func writeJSON(w http.ResponseWriter, code int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, ErrorResponse{Error: message})
+}
@@ -0,0 +1,501 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+)
+
+// fakeController records the controls it receives
+type fakeController struct {
+	mu       sync.Mutex
+	calls    []string
+	steering []string
+	answers  []string
+	reviews  []string
+	review   *loop.Review // Pending review reported by Status
+	bus      *loop.Bus
+}
+
+func newFakeController() *fakeController {
+	return &fakeController{bus: loop.NewBus()}
+}
+
+func (f *fakeController) record(call string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+}
+
+func (f *fakeController) Status() loop.Status {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return loop.Status{
+		State:    loop.RunStateRunning,
+		Loop:     2,
+		Plan:     loop.PlanProgress{Total: 3, Completed: 1, Remaining: 2, CurrentTask: "Second task"},
+		Breaker:  map[string]interface{}{"state": "CLOSED"},
+		Context:  &loop.ContextUsage{UsagePercent: 0.5, TotalTokens: 1000, Limit: 2000},
+		Steering: append([]string(nil), f.steering...),
+		Review:   f.review,
+	}
+}
+
+func (f *fakeController) Subscribe(buffer int, policy loop.DropPolicy) *loop.Subscription {
+	return f.bus.Subscribe(buffer, policy)
+}
+
+func (f *fakeController) Pause()              { f.record("pause") }
+func (f *fakeController) PauseNow()           { f.record("pause-now") }
+func (f *fakeController) Resume()             { f.record("resume") }
+func (f *fakeController) StopAfterIteration() { f.record("stop") }
+func (f *fakeController) Abort()              { f.record("abort") }
+func (f *fakeController) SkipTask()           { f.record("skip") }
+
+func (f *fakeController) ClearSteering() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.steering = nil
+}
+
+func (f *fakeController) Answer(answer string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.answers = append(f.answers, answer)
+}
+
+func (f *fakeController) Review(decision loop.ReviewDecision, feedback string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reviews = append(f.reviews, string(decision)+": "+feedback)
+	f.review = nil
+}
+
+func (f *fakeController) Steer(message string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.steering = append(f.steering, message)
+}
+
+// testToken is the API token the handler tests serve with
+const testToken = "test-token"
+
+// TestMain runs the package tests from a scratch directory so the token files
+// written by Listen never land in the source tree
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "lisa-api-test")
+	if err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// apiRequest builds a request the way Client sends it: with the test token
+// and a JSON body
+func apiRequest(t *testing.T, method, url, body string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestHandler_Status(t *testing.T) {
+	srv := httptest.NewServer(Handler(newFakeController(), nil, testToken))
+	defer srv.Close()
+
+	resp, err := http.DefaultClient.Do(apiRequest(t, http.MethodGet, srv.URL+"/status", ""))
+	if err != nil {
+		t.Fatalf("GET /status error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /status code = %d, want 200", resp.StatusCode)
+	}
+	var status loop.Status
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	if status.State != loop.RunStateRunning || status.Loop != 2 || status.Plan.Remaining != 2 {
+		t.Errorf("status = %+v, want running loop 2 with 2 tasks remaining", status)
+	}
+	if status.Breaker["state"] != "CLOSED" {
+		t.Errorf("status circuit_breaker = %v, want state CLOSED", status.Breaker)
+	}
+	if status.Context == nil || status.Context.TotalTokens != 1000 {
+		t.Errorf("status context = %+v, want 1000 tokens", status.Context)
+	}
+}
+
+func TestHandler_Controls(t *testing.T) {
+	fake := newFakeController()
+	srv := httptest.NewServer(Handler(fake, nil, testToken))
+	defer srv.Close()
+
+	paths := []string{"pause", "pause-now", "resume", "stop", "abort", "skip"}
+	for _, path := range paths {
+		resp, err := http.DefaultClient.Do(apiRequest(t, http.MethodPost, srv.URL+"/"+path, ""))
+		if err != nil {
+			t.Fatalf("POST /%s error = %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("POST /%s code = %d, want 200", path, resp.StatusCode)
+		}
+	}
+	if got := strings.Join(fake.calls, ","); got != strings.Join(paths, ",") {
+		t.Errorf("controls called = %s, want %s", got, strings.Join(paths, ","))
+	}
+
+	resp, err := http.DefaultClient.Do(apiRequest(t, http.MethodGet, srv.URL+"/pause", ""))
+	if err != nil {
+		t.Fatalf("GET /pause error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET /pause code = %d, want 405", resp.StatusCode)
+	}
+}
+
+func TestHandler_Steer(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{name: "message", body: `{"message": "Use the existing logger"}`, wantCode: http.StatusOK},
+		{name: "empty message", body: `{"message": "  "}`, wantCode: http.StatusBadRequest},
+		{name: "invalid json", body: `not json`, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeController()
+			srv := httptest.NewServer(Handler(fake, nil, testToken))
+			defer srv.Close()
+
+			resp, err := http.DefaultClient.Do(apiRequest(t, http.MethodPost, srv.URL+"/steer", tt.body))
+			if err != nil {
+				t.Fatalf("POST /steer error = %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("POST /steer code = %d, want %d", resp.StatusCode, tt.wantCode)
+			}
+			if tt.wantCode == http.StatusOK {
+				var status loop.Status
+				if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+					t.Fatalf("decode status: %v", err)
+				}
+				if len(status.Steering) != 1 || status.Steering[0] != "Use the existing logger" {
+					t.Errorf("status steering = %v, want the queued message", status.Steering)
+				}
+			} else if len(fake.steering) != 0 {
+				t.Errorf("Steer() called with %v for a rejected request", fake.steering)
+			}
+		})
+	}
+}
+
+func TestHandler_EventsStream(t *testing.T) {
+	fake := newFakeController()
+	srv := httptest.NewServer(Handler(fake, nil, testToken))
+	defer srv.Close()
+
+	resp, err := http.DefaultClient.Do(apiRequest(t, http.MethodGet, srv.URL+"/events", ""))
+	if err != nil {
+		t.Fatalf("GET /events error = %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	lines := make(chan string, 64)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	next := func() string {
+		t.Helper()
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for an event")
+			return ""
+		}
+	}
+
+	if line := next(); line != "event: snapshot" {
+		t.Fatalf("first line = %q, want the snapshot event", line)
+	}
+	var snapshot loop.LoopEvent
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(next(), "data: ")), &snapshot); err != nil {
+		t.Fatalf("decode snapshot: %v", err)
+	}
+	if snapshot.Type != loop.EventTypeSnapshot || snapshot.Snapshot == nil || snapshot.Snapshot.Plan.CurrentTask != "Second task" {
+		t.Errorf("snapshot = %+v, want the controller status", snapshot)
+	}
+	next() // blank line ending the event
+
+	fake.bus.Publish(loop.LoopEvent{Type: loop.EventTypeLog, Log: &loop.LogEntry{Level: loop.LogLevelInfo, Message: "hello"}})
+
+	if line := next(); line != "id: 1" {
+		t.Errorf("event id line = %q, want id: 1", line)
+	}
+	if line := next(); line != "event: log" {
+		t.Errorf("event name line = %q, want event: log", line)
+	}
+	var event loop.LoopEvent
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(next(), "data: ")), &event); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if event.Log == nil || event.Log.Message != "hello" {
+		t.Errorf("event = %+v, want the published log", event)
+	}
+}
+
+func TestListen(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    string
+		wantErr bool
+	}{
+		{name: "loopback", addr: "127.0.0.1:0"},
+		{name: "localhost", addr: "localhost:0"},
+		{name: "unix socket", addr: UnixPrefix + filepath.Join(t.TempDir(), "lisa.sock")},
+		{name: "all interfaces", addr: ":0", wantErr: true},
+		{name: "public address", addr: "0.0.0.0:0", wantErr: true},
+		{name: "missing port", addr: "127.0.0.1", wantErr: true},
+		{name: "empty socket path", addr: UnixPrefix, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, err := Listen(tt.addr, newFakeController())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Listen(%q) error = %v, wantErr %v", tt.addr, err, tt.wantErr)
+			}
+			if err == nil {
+				if token, err := ReadToken("."); err != nil || token != server.Token() {
+					t.Errorf("ReadToken() = %q, %v, want the server's token", token, err)
+				}
+				if info, err := os.Stat(TokenPath); err != nil || info.Mode().Perm() != 0600 {
+					t.Errorf("token file mode = %v, %v, want 0600", info, err)
+				}
+				server.Close()
+				if _, err := os.Stat(TokenPath); !os.IsNotExist(err) {
+					t.Error("Close() left the token file behind")
+				}
+			}
+		})
+	}
+}
+
+func TestListen_UnixSocketServes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lisa.sock")
+	server, err := Listen(UnixPrefix+path, newFakeController())
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer server.Close()
+
+	if server.Addr() != UnixPrefix+path {
+		t.Errorf("Addr() = %q, want %q", server.Addr(), UnixPrefix+path)
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	req := apiRequest(t, http.MethodGet, "http://lisa/status", "")
+	req.Header.Set("Authorization", "Bearer "+server.Token())
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("GET /status over unix socket error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /status code = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestHandler_ClearSteering(t *testing.T) {
+	fake := newFakeController()
+	fake.Steer("Leave the migrations alone")
+	srv := httptest.NewServer(Handler(fake, nil, testToken))
+	defer srv.Close()
+
+	resp, err := http.DefaultClient.Do(apiRequest(t, http.MethodDelete, srv.URL+"/steer", ""))
+	if err != nil {
+		t.Fatalf("DELETE /steer error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	var status loop.Status
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || len(status.Steering) != 0 {
+		t.Errorf("DELETE /steer = %d with steering %v, want 200 and none", resp.StatusCode, status.Steering)
+	}
+}
+
+func TestHandler_Answer(t *testing.T) {
+	fake := newFakeController()
+	srv := httptest.NewServer(Handler(fake, nil, testToken))
+	defer srv.Close()
+
+	resp, err := http.DefaultClient.Do(apiRequest(t, http.MethodPost, srv.URL+"/answer", `{"answer": "Use port 8080"}`))
+	if err != nil {
+		t.Fatalf("POST /answer error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("POST /answer code = %d, want 200", resp.StatusCode)
+	}
+	if len(fake.answers) != 1 || fake.answers[0] != "Use port 8080" {
+		t.Errorf("Answer() calls = %v, want the posted answer", fake.answers)
+	}
+}
+
+func TestHandler_Review(t *testing.T) {
+	fake := newFakeController()
+	srv := httptest.NewServer(Handler(fake, nil, testToken))
+	defer srv.Close()
+
+	post := func(body string) int {
+		t.Helper()
+		resp, err := http.DefaultClient.Do(apiRequest(t, http.MethodPost, srv.URL+"/review", body))
+		if err != nil {
+			t.Fatalf("POST /review error = %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := post(`{"decision": "approve"}`); code != http.StatusConflict {
+		t.Errorf("POST /review with nothing pending = %d, want 409", code)
+	}
+
+	fake.review = &loop.Review{Loop: 1}
+	if code := post(`{"decision": "maybe"}`); code != http.StatusBadRequest {
+		t.Errorf("POST /review with an unknown decision = %d, want 400", code)
+	}
+	if code := post(`{"decision": "reject", "feedback": "Keep the old API"}`); code != http.StatusOK {
+		t.Errorf("POST /review = %d, want 200", code)
+	}
+	if len(fake.reviews) != 1 || fake.reviews[0] != "reject: Keep the old API" {
+		t.Errorf("Review() calls = %v, want the posted rejection", fake.reviews)
+	}
+}
+
+func TestHandler_EventsReplaysRecentEvents(t *testing.T) {
+	fake := newFakeController()
+	recorder := NewRecorder(fake, 2)
+	defer recorder.Close()
+
+	for _, message := range []string{"one", "two", "three"} {
+		fake.bus.Publish(loop.LoopEvent{Type: loop.EventTypeLog, Log: &loop.LogEntry{Level: loop.LogLevelInfo, Message: message}})
+	}
+	fake.bus.Publish(loop.LoopEvent{Type: loop.EventTypeLog, Log: &loop.LogEntry{Level: loop.LogLevelDebug, Message: "noise"}})
+	deadline := time.Now().Add(5 * time.Second)
+	for len(recorder.Recent()) < 2 || recorder.Recent()[1].Log.Message != "three" {
+		if time.Now().After(deadline) {
+			t.Fatalf("Recent() = %v, want the last two events", recorder.Recent())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	srv := httptest.NewServer(Handler(fake, recorder, testToken))
+	defer srv.Close()
+	resp, err := http.DefaultClient.Do(apiRequest(t, http.MethodGet, srv.URL+"/events", ""))
+	if err != nil {
+		t.Fatalf("GET /events error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			var event loop.LoopEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				t.Fatalf("decode event: %v", err)
+			}
+			if event.Type == loop.EventTypeSnapshot {
+				break
+			}
+			names = append(names, event.Log.Message)
+		}
+	}
+	if got := strings.Join(names, ","); got != "two,three" {
+		t.Errorf("replayed events = %s, want two,three before the snapshot", got)
+	}
+}
+
+func TestHandler_RejectsUntrustedRequests(t *testing.T) {
+	fake := newFakeController()
+	srv := httptest.NewServer(Handler(fake, nil, testToken))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		modify   func(req *http.Request)
+		wantCode int
+	}{
+		{name: "trusted request", modify: func(req *http.Request) {}, wantCode: http.StatusOK},
+		{name: "missing token", modify: func(req *http.Request) { req.Header.Del("Authorization") }, wantCode: http.StatusUnauthorized},
+		{name: "wrong token", modify: func(req *http.Request) { req.Header.Set("Authorization", "Bearer nope") }, wantCode: http.StatusUnauthorized},
+		{name: "browser origin", modify: func(req *http.Request) { req.Header.Set("Origin", "https://example.com") }, wantCode: http.StatusForbidden},
+		{name: "rebound host", modify: func(req *http.Request) { req.Host = "attacker.example:7777" }, wantCode: http.StatusForbidden},
+		{name: "text/plain body", modify: func(req *http.Request) { req.Header.Set("Content-Type", "text/plain") }, wantCode: http.StatusUnsupportedMediaType},
+		{name: "no content type", modify: func(req *http.Request) { req.Header.Del("Content-Type") }, wantCode: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := apiRequest(t, http.MethodPost, srv.URL+"/steer", `{"message": "Run rm -rf /"}`)
+			tt.modify(req)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("POST /steer error = %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("POST /steer code = %d, want %d", resp.StatusCode, tt.wantCode)
+			}
+		})
+	}
+
+	if len(fake.steering) != 1 {
+		t.Errorf("Steer() called %d times, want only the trusted request", len(fake.steering))
+	}
+}
@@ -0,0 +1,142 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ProtocolVersion is the version of the event model below. It is bumped when a
+// field changes meaning or is removed; adding optional fields does not bump it.
+const ProtocolVersion = 1
+
+// Kind identifies what an Event carries
+type Kind string
+
+// Event kinds produced by backend adapters
+const (
+	KindMessageDelta Kind = "message_delta" // Agent message text
+	KindReasoning    Kind = "reasoning"     // Model reasoning text
+	KindToolStart    Kind = "tool_start"    // A tool call began
+	KindToolEnd      Kind = "tool_end"      // A tool call finished
+	KindFileChange   Kind = "file_change"   // A file was changed in the workspace
+	KindUsage        Kind = "usage"         // Token usage for the session
+	KindLifecycle    Kind = "lifecycle"     // Session/status transitions and progress notes
+	KindError        Kind = "error"         // A backend-reported failure
+)
+
+// Event is a single streaming event from a backend. Exactly one payload is set
+// for tool, file change and usage kinds; the rest use Text.
+type Event struct {
+	Version int  `json:"v"`
+	Kind    Kind `json:"kind"`
+
+	// Text is the message, reasoning, lifecycle note or error message
+	Text string `json:"text,omitempty"`
+	// Cumulative reports that Text is the full text so far rather than an
+	// increment, so consumers should replace what they have instead of appending
+	Cumulative bool `json:"cumulative,omitempty"`
+
+	Tool      *Tool       `json:"tool,omitempty"`
+	File      *FileChange `json:"file,omitempty"`
+	Usage     *Usage      `json:"usage,omitempty"`
+	Lifecycle *Lifecycle  `json:"lifecycle,omitempty"`
+}
+
+// Tool describes a tool call for tool_start and tool_end events
+type Tool struct {
+	ID     string          `json:"id,omitempty"`
+	Name   string          `json:"name"`
+	Target string          `json:"target,omitempty"` // File path or shortened command
+	Input  json.RawMessage `json:"input,omitempty"`
+	Output string          `json:"output,omitempty"` // Only on tool_end
+	// ExitCode is a command's exit status, when the backend reports one
+	ExitCode *int `json:"exit_code,omitempty"`
+	// Error is why the call failed, when it did
+	Error string `json:"error,omitempty"`
+}
+
+// FileChange describes one changed file
+type FileChange struct {
+	Path      string `json:"path"`
+	Additions int    `json:"additions,omitempty"`
+	Deletions int    `json:"deletions,omitempty"`
+}
+
+// Usage reports the session's token usage against its context window
+type Usage struct {
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	ContextLimit     int     `json:"context_limit"`
+	UsagePercent     float64 `json:"usage_percent"`
+	ThresholdReached bool    `json:"threshold_reached,omitempty"`
+	WasCompacted     bool    `json:"was_compacted,omitempty"`
+	Cost             float64 `json:"cost,omitempty"` // Session cost in USD, when reported
+}
+
+// Lifecycle describes a session or status transition
+type Lifecycle struct {
+	Phase   string `json:"phase"`            // e.g. "status", "session", "context_threshold"
+	Status  string `json:"status,omitempty"` // e.g. "processing", "retry", "complete", "error"
+	Attempt int    `json:"attempt,omitempty"`
+}
+
+// MessageDelta creates a message event
+func MessageDelta(text string, cumulative bool) Event {
+	return Event{Version: ProtocolVersion, Kind: KindMessageDelta, Text: text, Cumulative: cumulative}
+}
+
+// Reasoning creates a reasoning event
+func Reasoning(text string, cumulative bool) Event {
+	return Event{Version: ProtocolVersion, Kind: KindReasoning, Text: text, Cumulative: cumulative}
+}
+
+// ToolStart creates a tool_start event
+func ToolStart(tool Tool) Event {
+	return Event{Version: ProtocolVersion, Kind: KindToolStart, Tool: &tool}
+}
+
+// ToolEnd creates a tool_end event
+func ToolEnd(tool Tool) Event {
+	return Event{Version: ProtocolVersion, Kind: KindToolEnd, Tool: &tool}
+}
+
+// FileChanged creates a file_change event
+func FileChanged(change FileChange) Event {
+	return Event{Version: ProtocolVersion, Kind: KindFileChange, File: &change}
+}
+
+// UsageReport creates a usage event
+func UsageReport(usage Usage) Event {
+	return Event{Version: ProtocolVersion, Kind: KindUsage, Usage: &usage}
+}
+
+// LifecycleEvent creates a lifecycle event with an optional human-readable note
+func LifecycleEvent(lifecycle Lifecycle, text string) Event {
+	return Event{Version: ProtocolVersion, Kind: KindLifecycle, Text: text, Lifecycle: &lifecycle}
+}
+
+// Note creates a lifecycle event carrying only a progress note
+func Note(text string) Event {
+	return LifecycleEvent(Lifecycle{Phase: "note"}, text)
+}
+
+// Error creates an error event
+func Error(message string) Event {
+	return Event{Version: ProtocolVersion, Kind: KindError, Text: message}
+}
+
+// Decode parses a JSON-encoded event, rejecting events from a newer protocol
+func Decode(data []byte) (Event, error) {
+	var event Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		return Event{}, fmt.Errorf("failed to decode backend event: %w", err)
+	}
+	if event.Version > ProtocolVersion {
+		return Event{}, fmt.Errorf("backend event version %d is newer than supported version %d", event.Version, ProtocolVersion)
+	}
+	if event.Kind == "" {
+		return Event{}, fmt.Errorf("backend event has no kind")
+	}
+	return event, nil
+}
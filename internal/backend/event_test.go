@@ -0,0 +1,69 @@
+package backend
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEvent_JSONRoundTrip(t *testing.T) {
+	events := []Event{
+		MessageDelta("Hello", true),
+		Reasoning("Thinking", false),
+		ToolStart(Tool{ID: "call-1", Name: "edit", Target: "main.go", Input: json.RawMessage(`{"filePath":"main.go"}`)}),
+		ToolEnd(Tool{ID: "call-1", Name: "edit", Output: "ok"}),
+		FileChanged(FileChange{Path: "README.md", Additions: 3, Deletions: 1}),
+		UsageReport(Usage{PromptTokens: 100, CompletionTokens: 20, TotalTokens: 120, ContextLimit: 1000, UsagePercent: 0.12}),
+		LifecycleEvent(Lifecycle{Phase: "status", Status: "retry", Attempt: 2}, "rate limited"),
+		Note("Starting server..."),
+		Error("boom"),
+	}
+
+	for _, want := range events {
+		t.Run(string(want.Kind), func(t *testing.T) {
+			data, err := json.Marshal(want)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			got, err := Decode(data)
+			if err != nil {
+				t.Fatalf("Decode(%s) error = %v", data, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Decode(Marshal()) = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestEvent_WireFormat(t *testing.T) {
+	data, err := json.Marshal(MessageDelta("hi", false))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != `{"v":1,"kind":"message_delta","text":"hi"}` {
+		t.Errorf("Marshal() = %s", data)
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"invalid json", `{`, "failed to decode"},
+		{"newer version", `{"v":99,"kind":"message_delta"}`, "newer than supported"},
+		{"missing kind", `{"v":1}`, "no kind"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Decode() error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
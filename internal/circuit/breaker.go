@@ -154,40 +154,26 @@ func (b *Breaker) Reset() error {
 
 // LoadState loads circuit breaker state from file
 func (b *Breaker) LoadState() (*Breaker, error) {
-	stateMap, err := state.LoadCircuitBreakerState()
+	saved, err := state.LoadCircuitBreakerState()
 	if err != nil {
 		return nil, err
 	}
 
-	loaded := NewBreaker(3, 5)
+	loaded := NewBreaker(b.noProgressThreshold, b.sameErrorThreshold)
 
-	if stateVal, ok := stateMap["state"].(string); ok {
-		switch stateVal {
-		case "CLOSED":
-			loaded.state = StateClosed
-		case "HALF_OPEN":
-			loaded.state = StateHalfOpen
-		case "OPEN":
-			loaded.state = StateOpen
-		}
-	}
-
-	if lastCheck, ok := stateMap["last_check_time"].(string); ok {
-		loaded.lastCheckTime, _ = time.Parse(time.RFC3339, lastCheck)
+	switch saved.State {
+	case "CLOSED":
+		loaded.state = StateClosed
+	case "HALF_OPEN":
+		loaded.state = StateHalfOpen
+	case "OPEN":
+		loaded.state = StateOpen
 	}
 
-	// Load counters
-	if noProg, ok := stateMap["no_progress_count"].(float64); ok {
-		loaded.noProgressCount = int(noProg)
-	}
-
-	if errHist, ok := stateMap["error_history"].([]interface{}); ok {
-		loaded.sameErrorHistory = make([]string, len(errHist))
-		for i, err := range errHist {
-			if errStr, ok := err.(string); ok {
-				loaded.sameErrorHistory[i] = errStr
-			}
-		}
+	loaded.lastCheckTime = saved.LastCheckTime
+	loaded.noProgressCount = saved.NoProgressCount
+	if saved.ErrorHistory != nil {
+		loaded.sameErrorHistory = saved.ErrorHistory
 	}
 
 	return loaded, nil
@@ -195,14 +181,14 @@ func (b *Breaker) LoadState() (*Breaker, error) {
 
 // SaveState saves circuit breaker state to file
 func (b *Breaker) SaveState() error {
-	stateMap := map[string]interface{}{
-		"state":             b.state.String(),
-		"no_progress_count": b.noProgressCount,
-		"error_history":     b.sameErrorHistory,
-		"last_check_time":   b.lastCheckTime.Format(time.RFC3339),
+	saved := state.CircuitBreakerState{
+		State:           b.state.String(),
+		NoProgressCount: b.noProgressCount,
+		ErrorHistory:    b.sameErrorHistory,
+		LastCheckTime:   b.lastCheckTime,
 	}
 
-	if err := state.SaveCircuitBreakerState(stateMap); err != nil {
+	if err := state.SaveCircuitBreakerState(saved); err != nil {
 		return fmt.Errorf("failed to save circuit breaker state: %w", err)
 	}
 
@@ -8,6 +8,21 @@ import (
 	"github.com/brainwhocodes/lisa-loop/internal/state"
 )
 
+// TestMain runs the package tests from a scratch directory so state written by
+// tests that don't chdir themselves never lands in the source tree
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "lisa-circuit-test")
+	if err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func TestNewBreaker(t *testing.T) {
 	breaker := NewBreaker(3, 5)
 
@@ -236,11 +251,11 @@ func TestLoadState(t *testing.T) {
 	os.Chdir(tmpDir)
 	defer os.Chdir(origDir)
 
-	testState := map[string]interface{}{
-		"state":             "HALF_OPEN",
-		"no_progress_count": 2,
-		"error_history":     []interface{}{"error1", "error1"},
-		"last_check_time":   time.Now().Format(time.RFC3339),
+	testState := state.CircuitBreakerState{
+		State:           "HALF_OPEN",
+		NoProgressCount: 2,
+		ErrorHistory:    []string{"error1", "error1"},
+		LastCheckTime:   time.Now(),
 	}
 
 	state.SaveCircuitBreakerState(testState)
@@ -0,0 +1,116 @@
+package codex
+
+import (
+	"encoding/json"
+
+	"github.com/brainwhocodes/lisa-loop/internal/backend"
+)
+
+// ToBackendEvent converts a Codex JSONL event into the typed backend protocol.
+// It returns false for events that carry nothing worth forwarding.
+func ToBackendEvent(event Event) (backend.Event, bool) {
+	parsed := ParseEvent(event)
+	if parsed == nil {
+		return backend.Event{}, false
+	}
+
+	if parsed.RawType == "error" {
+		if parsed.Text == "" {
+			return backend.Event{}, false
+		}
+		return backend.Error(parsed.Text), true
+	}
+
+	switch parsed.Type {
+	case "reasoning":
+		if parsed.Text != "" {
+			return backend.Reasoning(parsed.Text, false), true
+		}
+
+	case "message", "delta":
+		if parsed.Text != "" {
+			return backend.MessageDelta(parsed.Text, false), true
+		}
+
+	case "tool_call", "tool_result":
+		if parsed.ToolName == "" {
+			return backend.Event{}, false
+		}
+		tool := toolFromEvent(event, parsed)
+		if parsed.ToolStatus == "completed" {
+			return backend.ToolEnd(tool), true
+		}
+		return backend.ToolStart(tool), true
+
+	case "lifecycle":
+		if parsed.RawType != "" {
+			return backend.LifecycleEvent(backend.Lifecycle{Phase: parsed.RawType}, ""), true
+		}
+	}
+
+	return backend.Event{}, false
+}
+
+// toolFromEvent collects the tool call's ID, input and output, looking inside
+// item for item.completed events
+func toolFromEvent(event Event, parsed *ParsedEvent) backend.Tool {
+	tool := backend.Tool{Name: parsed.ToolName, Target: parsed.ToolTarget}
+
+	data := map[string]interface{}(event)
+	if item, ok := event["item"].(map[string]interface{}); ok {
+		data = item
+	}
+
+	for _, key := range []string{"id", "call_id", "tool_use_id"} {
+		if id, ok := data[key].(string); ok && id != "" {
+			tool.ID = id
+			break
+		}
+	}
+
+	for _, key := range []string{"input", "arguments", "parameters"} {
+		if raw := rawJSON(data[key]); raw != nil {
+			tool.Input = raw
+			break
+		}
+	}
+	// Command executions carry the command itself rather than arguments
+	if cmd, ok := data["command"].(string); ok && tool.Input == nil {
+		tool.Input = rawJSON(map[string]interface{}{"command": cmd})
+	}
+
+	for _, key := range []string{"output", "aggregated_output", "content"} {
+		if out, ok := data[key].(string); ok && out != "" {
+			tool.Output = out
+			break
+		}
+	}
+
+	if code, ok := data["exit_code"].(float64); ok {
+		exitCode := int(code)
+		tool.ExitCode = &exitCode
+	}
+
+	return tool
+}
+
+// rawJSON re-encodes a decoded JSON value. Strings that already hold JSON
+// (Codex sends function arguments that way) are passed through as-is.
+func rawJSON(v interface{}) json.RawMessage {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case string:
+		if json.Valid([]byte(val)) {
+			return json.RawMessage(val)
+		}
+		data, _ := json.Marshal(val)
+		return data
+	default:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return nil
+		}
+		return data
+	}
+}
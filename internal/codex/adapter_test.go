@@ -0,0 +1,78 @@
+package codex
+
+import (
+	"testing"
+
+	"github.com/brainwhocodes/lisa-loop/internal/backend"
+)
+
+func TestToBackendEvent(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		wantOK     bool
+		wantKind   backend.Kind
+		wantText   string
+		wantTool   string
+		wantTarget string
+		wantInput  string
+	}{
+		{"agent message", `{"type":"item.completed","item":{"type":"agent_message","text":"Done"}}`, true, backend.KindMessageDelta, "Done", "", "", ""},
+		{"reasoning", `{"type":"item.completed","item":{"type":"reasoning","text":"Hmm"}}`, true, backend.KindReasoning, "Hmm", "", "", ""},
+		{"delta", `{"type":"content_block_delta","delta":{"text":"par"}}`, true, backend.KindMessageDelta, "par", "", "", ""},
+		{"tool use", `{"type":"tool_use","id":"t1","name":"read_file","input":{"path":"a.go"}}`, true, backend.KindToolStart, "", "read_file", "a.go", `{"path":"a.go"}`},
+		{"function call", `{"type":"item.completed","item":{"type":"function_call","name":"shell","arguments":"{\"command\":\"ls\"}"}}`, true, backend.KindToolEnd, "", "shell", "", `{"command":"ls"}`},
+		{"command started", `{"type":"item.started","item":{"id":"item_1","type":"command_execution","command":"go test ./...","status":"in_progress"}}`, true, backend.KindToolStart, "", "shell", "go test ./...", `{"command":"go test ./..."}`},
+		{"tool result", `{"type":"tool_result","name":"read_file","output":"package main"}`, true, backend.KindToolEnd, "", "read_file", "", ""},
+		{"error", `{"type":"error","message":"stream disconnected"}`, true, backend.KindError, "stream disconnected", "", "", ""},
+		{"lifecycle", `{"type":"turn.started"}`, true, backend.KindLifecycle, "", "", "", ""},
+		{"empty message", `{"type":"item.completed","item":{"type":"agent_message","text":""}}`, false, "", "", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := ParseJSONLLine(tt.line)
+			if err != nil {
+				t.Fatalf("ParseJSONLLine() error = %v", err)
+			}
+
+			got, ok := ToBackendEvent(event)
+			if ok != tt.wantOK {
+				t.Fatalf("ToBackendEvent() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if got.Version != backend.ProtocolVersion || got.Kind != tt.wantKind || got.Text != tt.wantText {
+				t.Errorf("ToBackendEvent() = %+v, want kind %s text %q", got, tt.wantKind, tt.wantText)
+			}
+			if tt.wantTool == "" {
+				return
+			}
+			if got.Tool == nil || got.Tool.Name != tt.wantTool || got.Tool.Target != tt.wantTarget || string(got.Tool.Input) != tt.wantInput {
+				t.Errorf("ToBackendEvent() tool = %+v, want %s on %q with input %s", got.Tool, tt.wantTool, tt.wantTarget, tt.wantInput)
+			}
+		})
+	}
+}
+
+func TestToBackendEvent_CommandExecution(t *testing.T) {
+	event, err := ParseJSONLLine(`{"type":"item.completed","item":{"id":"item_1","type":"command_execution","command":"go test ./...","aggregated_output":"FAIL\tpkg\n","exit_code":1,"status":"failed"}}`)
+	if err != nil {
+		t.Fatalf("ParseJSONLLine() error = %v", err)
+	}
+
+	got, ok := ToBackendEvent(event)
+	if !ok || got.Kind != backend.KindToolEnd || got.Tool == nil {
+		t.Fatalf("ToBackendEvent() = %+v, %v, want a tool_end", got, ok)
+	}
+	if got.Tool.ID != "item_1" || got.Tool.Output != "FAIL\tpkg\n" || got.Tool.ExitCode == nil || *got.Tool.ExitCode != 1 {
+		t.Errorf("ToBackendEvent() tool = %+v, want item_1 with output and exit code 1", got.Tool)
+	}
+
+	// Other items are not reported until they complete
+	event, _ = ParseJSONLLine(`{"type":"item.started","item":{"type":"agent_message","text":""}}`)
+	if got, ok := ToBackendEvent(event); !ok || got.Kind != backend.KindLifecycle {
+		t.Errorf("ToBackendEvent() on a started message = %+v, %v, want lifecycle", got, ok)
+	}
+}
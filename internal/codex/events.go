@@ -28,6 +28,9 @@ func ParseEvent(event Event) *ParsedEvent {
 	case "item.completed":
 		parseItemCompleted(event, result)
 
+	case "item.started":
+		parseItemStarted(event, result)
+
 	case "content_block_delta":
 		parseDelta(event, result)
 
@@ -91,6 +94,11 @@ func parseItemCompleted(event Event, result *ParsedEvent) {
 		result.ToolName, _ = item["name"].(string)
 		result.ToolTarget = extractToolTarget(item)
 		result.ToolStatus = "completed"
+	case "command_execution":
+		result.Type = "tool_call"
+		result.ToolName = "shell"
+		result.ToolTarget = extractTargetFromArgs(item)
+		result.ToolStatus = "completed"
 	default:
 		if text != "" {
 			result.Type = "message"
@@ -99,6 +107,23 @@ func parseItemCompleted(event Event, result *ParsedEvent) {
 	}
 }
 
+// parseItemStarted handles item.started events. Only commands are reported
+// when they start; other items arrive whole in item.completed.
+func parseItemStarted(event Event, result *ParsedEvent) {
+	result.Type = "lifecycle"
+	item, ok := event["item"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if itemType, _ := item["type"].(string); itemType == "command_execution" {
+		result.Type = "tool_call"
+		result.ToolName = "shell"
+		result.ToolTarget = extractTargetFromArgs(item)
+		result.ToolStatus = "started"
+	}
+}
+
 // parseDelta handles content_block_delta events
 func parseDelta(event Event, result *ParsedEvent) {
 	if delta, ok := event["delta"].(map[string]interface{}); ok {
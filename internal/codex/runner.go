@@ -2,18 +2,19 @@ package codex
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"os/exec"
 	"strings"
 	"time"
 
+	"github.com/brainwhocodes/lisa-loop/internal/backend"
 	"github.com/brainwhocodes/lisa-loop/internal/state"
 )
 
-// OutputCallback is called for each line of streaming output
-type OutputCallback func(event Event)
+// OutputCallback is called with each streaming event, converted to the backend protocol
+type OutputCallback func(event backend.Event)
 
 // Runner executes Codex commands
 type Runner struct {
@@ -33,11 +34,16 @@ func (r *Runner) SetOutputCallback(cb OutputCallback) {
 
 // Run executes a Codex command using the CLI with streaming
 func (r *Runner) Run(prompt string) (output string, threadID string, err error) {
-	return r.runCLI(prompt)
+	return r.RunContext(context.Background(), prompt)
+}
+
+// RunContext is like Run but kills the codex process when ctx is cancelled
+func (r *Runner) RunContext(ctx context.Context, prompt string) (output string, threadID string, err error) {
+	return r.runCLI(ctx, prompt)
 }
 
 // runCLI executes Codex CLI in non-interactive mode with streaming
-func (r *Runner) runCLI(prompt string) (string, string, error) {
+func (r *Runner) runCLI(ctx context.Context, prompt string) (string, string, error) {
 	args := []string{
 		"exec",
 		"--json",
@@ -50,7 +56,7 @@ func (r *Runner) runCLI(prompt string) (string, string, error) {
 		args = append(args, "resume", "--last")
 	}
 
-	cmd := exec.Command("codex", args...)
+	cmd := exec.CommandContext(ctx, "codex", args...)
 	cmd.Stdin = strings.NewReader(prompt)
 
 	if r.config.Verbose {
@@ -114,7 +120,9 @@ func (r *Runner) runCLI(prompt string) (string, string, error) {
 
 		// Call output callback for real-time updates
 		if r.outputCallback != nil && event != nil {
-			r.outputCallback(event)
+			if ev, ok := ToBackendEvent(event); ok {
+				r.outputCallback(ev)
+			}
 		}
 	}
 
@@ -140,6 +148,9 @@ func (r *Runner) runCLI(prompt string) (string, string, error) {
 
 	// Wait for command to complete
 	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return "", "", fmt.Errorf("codex execution cancelled: %w", context.Cause(ctx))
+		}
 		errMsg := stderrOutput.String()
 		if errMsg == "" {
 			errMsg = outputBuilder.String()
@@ -284,13 +295,12 @@ func SessionAgeHours() (int, error) {
 		return 0, nil
 	}
 
-	sessionFile := ".codex_session_id"
-	info, err := os.Stat(sessionFile)
+	s, err := state.Load()
 	if err != nil {
 		return 0, err
 	}
 
-	age := time.Since(info.ModTime()).Hours()
+	age := time.Since(s.Sessions.Codex.UpdatedAt).Hours()
 	return int(age), nil
 }
 
@@ -322,32 +332,18 @@ func LoadSessionMetadata() (*SessionMetadata, error) {
 		return nil, err
 	}
 
-	meta := &SessionMetadata{
-		ID: "",
-	}
-
-	if id, ok := sess["id"].(string); ok {
-		meta.ID = id
-	}
-
-	if created, ok := sess["created_at"].(string); ok {
-		meta.CreatedAt, _ = time.Parse(time.RFC3339, created)
-	}
-
-	if lastUsed, ok := sess["last_used"].(string); ok {
-		meta.LastUsed, _ = time.Parse(time.RFC3339, lastUsed)
-	}
-
-	return meta, nil
+	return &SessionMetadata{
+		ID:        sess.ID,
+		CreatedAt: sess.CreatedAt,
+		LastUsed:  sess.LastUsed,
+	}, nil
 }
 
 // SaveSessionMetadata saves session metadata
 func SaveSessionMetadata(meta *SessionMetadata) error {
-	sess := map[string]interface{}{
-		"id":         meta.ID,
-		"created_at": meta.CreatedAt.Format(time.RFC3339),
-		"last_used":  meta.LastUsed.Format(time.RFC3339),
-	}
-
-	return state.SaveLisaSession(sess)
+	return state.SaveLisaSession(state.LisaSession{
+		ID:        meta.ID,
+		CreatedAt: meta.CreatedAt,
+		LastUsed:  meta.LastUsed,
+	})
 }
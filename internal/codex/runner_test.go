@@ -4,6 +4,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/brainwhocodes/lisa-loop/internal/state"
 )
@@ -433,10 +434,10 @@ func TestStatePersistence(t *testing.T) {
 
 		os.Chdir(tmpDir)
 
-		sessionData := map[string]interface{}{
-			"id":         "ralph-session-123",
-			"created_at": "2024-01-01T00:00:00Z",
-			"last_used":  "2024-01-01T01:00:00Z",
+		sessionData := state.LisaSession{
+			ID:        "ralph-session-123",
+			CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			LastUsed:  time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC),
 		}
 
 		err := state.SaveLisaSession(sessionData)
@@ -449,8 +450,8 @@ func TestStatePersistence(t *testing.T) {
 			t.Fatalf("state.LoadLisaSession() error = %v", err)
 		}
 
-		if loadedData["id"] != sessionData["id"] {
-			t.Errorf("state.LoadLisaSession() id = %v, want %v", loadedData["id"], sessionData["id"])
+		if loadedData.ID != sessionData.ID {
+			t.Errorf("state.LoadLisaSession() id = %v, want %v", loadedData.ID, sessionData.ID)
 		}
 	})
 }
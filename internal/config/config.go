@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 // Config holds unified configuration for Lisa Codex
 type Config struct {
 	Backend      string
@@ -9,6 +11,31 @@ type Config struct {
 	Timeout      int
 	Verbose      bool
 	ResetCircuit bool
+	Monitor      bool   // Run with the TUI
+	Profile      string // Active named profile from the config file, if any
+	LogFormat    string // text, json or logfmt enables CLI log mode
+	Listen       string // Control API address: loopback host:port or unix:/path
+	Review       bool   // Pause after each iteration for a human to approve its changes
+	Theme        string // TUI theme: auto, a built-in, or a file in ~/.lisa/themes
+	Keymap       string // TUI key preset: default, vim or emacs
+
+	// Keys rebinds TUI actions, from the config files' keys section: each
+	// action's keys, with the project file's winning over the user file's
+	Keys map[string][]string
+
+	// Loop tuning
+	NoProgressThreshold int           // Loops without progress before the circuit opens
+	SameErrorThreshold  int           // Repeated errors before the circuit opens
+	RateLimitResetHours int           // Hours before the call counter resets
+	RetryDelay          time.Duration // Delay before retrying a failed iteration (default 5s)
+
+	// Verification: a test command run after each loop and/or a report file it writes
+	TestCommand string        // Shell command run after each loop, e.g. "go test -json ./..."
+	TestReport  string        // Test report path (go test -json, JUnit XML, TAP, Jest/Vitest JSON)
+	TestTimeout time.Duration // Kill the test command after this long and count it as failed (default 10m)
+
+	// Lifecycle hooks: shell commands run at defined points in the loop
+	Hooks Hooks
 
 	// OpenCode backend configuration
 	OpenCodeServerURL string // URL for OpenCode server (env: OPENCODE_SERVER_URL)
@@ -17,6 +44,20 @@ type Config struct {
 	OpenCodeModelID   string // Model ID to use (env: OPENCODE_MODEL_ID, default: glm-4.7)
 }
 
+// Hooks holds the shell commands run at lifecycle points. Each hook receives
+// the loop event as JSON on stdin and as LISA_* environment variables.
+type Hooks struct {
+	PreRun         string // Before the first iteration; a non-zero exit aborts the run
+	PreIteration   string // Before each iteration; a non-zero exit vetoes it and stops the loop
+	PostIteration  string // After each iteration, with its outcome
+	OnTaskComplete string // Once for each plan task checked off during an iteration
+	OnBreakerOpen  string // When the circuit breaker opens
+	OnComplete     string // When the run finishes
+	OnError        string // When an iteration fails
+
+	Timeout time.Duration // Kill a hook after this long and count it as failed (default 1m)
+}
+
 // BackendDisplayName returns a display-friendly name for the backend
 func (c *Config) BackendDisplayName() string {
 	switch c.Backend {
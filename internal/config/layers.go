@@ -0,0 +1,393 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFile is the project-level config file, relative to the project directory
+var ProjectFile = filepath.Join(".lisa", "config.yaml")
+
+// Source identifies the layer an effective value came from
+type Source string
+
+// Layers in increasing order of precedence
+const (
+	SourceDefault Source = "default"
+	SourceUser    Source = "user"
+	SourceProject Source = "project"
+	SourceProfile Source = "profile"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// profilesKey is the top-level config file section holding named profiles
+const profilesKey = "profiles"
+
+// keysKey is the top-level config file section rebinding TUI actions. The
+// TUI's keymap validates the actions and keys.
+const keysKey = "keys"
+
+// File is the parsed content of one config file
+type File struct {
+	Path     string
+	Values   map[string]string            // Top-level settings by dotted key
+	Profiles map[string]map[string]string // Named profiles, each a set of settings
+	Keys     map[string][]string          // TUI actions and the keys bound to them
+}
+
+// Value is the effective value of a setting and where it came from
+type Value struct {
+	Setting *Setting
+	Value   string
+	Source  Source
+	Origin  string // File path, env var or flag that supplied the value
+}
+
+// Effective is a fully resolved configuration
+type Effective struct {
+	Config Config
+	Values []Value // In Settings order
+}
+
+// Get returns the effective value for key
+func (e *Effective) Get(key string) (Value, bool) {
+	for _, v := range e.Values {
+		if v.Setting.Key == key {
+			return v, true
+		}
+	}
+	return Value{}, false
+}
+
+// lookup returns the current value of key, or "" if unknown
+func (e *Effective) lookup(key string) string {
+	v, _ := e.Get(key)
+	return v.Value
+}
+
+// UserFile returns the user-level config path (~/.lisa/config.yaml)
+func UserFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, ".lisa", "config.yaml"), nil
+}
+
+// Resolve layers defaults, the user config file, the project config file, the
+// selected profile, environment variables and explicitly set flags (keyed by
+// flag name), in increasing order of precedence. Every layer is validated.
+func Resolve(projectDir string, flags map[string]string) (*Effective, error) {
+	values := make([]Value, len(Settings))
+	for i := range Settings {
+		values[i] = Value{Setting: &Settings[i], Value: Settings[i].Default, Source: SourceDefault}
+	}
+
+	set := func(key, v string, source Source, origin string) {
+		for i := range values {
+			if values[i].Setting.Key == key {
+				values[i] = Value{Setting: values[i].Setting, Value: v, Source: source, Origin: origin}
+				return
+			}
+		}
+	}
+
+	var files []*File
+	if userPath, err := UserFile(); err == nil {
+		userFile, err := LoadFile(userPath)
+		if err != nil {
+			return nil, err
+		}
+		for key, v := range userFile.Values {
+			set(key, v, SourceUser, userPath)
+		}
+		files = append(files, userFile)
+	}
+
+	projectFile, err := LoadFile(filepath.Join(projectDir, ProjectFile))
+	if err != nil {
+		return nil, err
+	}
+	for key, v := range projectFile.Values {
+		set(key, v, SourceProject, projectFile.Path)
+	}
+	files = append(files, projectFile)
+
+	// Env and flags are collected first so they can select the profile
+	overrides := make(map[string]Value)
+	for _, s := range Settings {
+		if s.Env == "" {
+			continue
+		}
+		if v, ok := os.LookupEnv(s.Env); ok && v != "" {
+			if err := s.Validate(v); err != nil {
+				return nil, fmt.Errorf("invalid $%s: %w", s.Env, err)
+			}
+			overrides[s.Key] = Value{Value: v, Source: SourceEnv, Origin: "$" + s.Env}
+		}
+	}
+	for name, v := range flags {
+		s, ok := LookupFlag(name)
+		if !ok {
+			continue
+		}
+		if err := s.Validate(v); err != nil {
+			return nil, fmt.Errorf("invalid --%s: %w", name, err)
+		}
+		overrides[s.Key] = Value{Value: v, Source: SourceFlag, Origin: "--" + name}
+	}
+
+	profile := (&Effective{Values: values}).lookup("profile")
+	if o, ok := overrides["profile"]; ok {
+		profile = o.Value
+	}
+	if profile != "" {
+		found := false
+		// Later files override earlier ones, so a project profile can refine a user profile
+		for _, f := range files {
+			settings, ok := f.Profiles[profile]
+			if !ok {
+				continue
+			}
+			found = true
+			for key, v := range settings {
+				set(key, v, SourceProfile, fmt.Sprintf("%s (%s)", profile, f.Path))
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown profile %q (available: %s)", profile, strings.Join(profileNames(files), ", "))
+		}
+	}
+
+	for _, s := range Settings {
+		if o, ok := overrides[s.Key]; ok {
+			set(s.Key, o.Value, o.Source, o.Origin)
+		}
+	}
+
+	eff := &Effective{Values: values}
+	backend, _ := eff.Get("backend")
+	for i := range values {
+		// The OpenCode backend gets a larger default iteration budget
+		if values[i].Setting.Key == "calls" && values[i].Source == SourceDefault && backend.Value == "opencode" {
+			values[i].Value = "10"
+			values[i].Origin = "opencode backend"
+		}
+		values[i].Setting.apply(&eff.Config, values[i].Value)
+	}
+	eff.Config.ProjectPath = projectDir
+
+	// Key bindings: the project file's replace the user file's, action by action
+	for _, f := range files {
+		for action, keys := range f.Keys {
+			if eff.Config.Keys == nil {
+				eff.Config.Keys = make(map[string][]string)
+			}
+			eff.Config.Keys[action] = keys
+		}
+	}
+
+	return eff, nil
+}
+
+// LoadFile reads and validates a config file. A missing file yields an
+// empty File.
+func LoadFile(path string) (*File, error) {
+	raw, err := readYAML(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &File{Path: path, Values: make(map[string]string), Profiles: make(map[string]map[string]string), Keys: make(map[string][]string)}
+	var problems []string
+
+	if section, ok := raw[keysKey]; ok {
+		keys, ok := section.(map[string]interface{})
+		if !ok && section != nil {
+			problems = append(problems, "keys must be a mapping of actions to keys")
+		}
+		flatten("", keys, func(action string, v interface{}) {
+			switch v := v.(type) {
+			case []interface{}:
+				f.Keys[action] = []string{}
+				for _, key := range v {
+					f.Keys[action] = append(f.Keys[action], fmt.Sprint(key))
+				}
+			default:
+				f.Keys[action] = []string{fmt.Sprint(v)}
+			}
+		})
+	}
+
+	if section, ok := raw[profilesKey]; ok {
+		profiles, ok := section.(map[string]interface{})
+		if !ok && section != nil {
+			problems = append(problems, "profiles must be a mapping of profile names to settings")
+		}
+		for name, body := range profiles {
+			settings, ok := body.(map[string]interface{})
+			if !ok && body != nil {
+				problems = append(problems, fmt.Sprintf("profile %q must be a mapping of settings", name))
+				continue
+			}
+			values := make(map[string]string)
+			problems = append(problems, collectValues(settings, values, "profile "+name+": ")...)
+			if _, nested := values["profile"]; nested {
+				problems = append(problems, fmt.Sprintf("profile %s: profiles cannot select another profile", name))
+			}
+			f.Profiles[name] = values
+		}
+	}
+
+	rest := make(map[string]interface{}, len(raw))
+	for k, v := range raw {
+		if k != profilesKey && k != keysKey {
+			rest[k] = v
+		}
+	}
+	problems = append(problems, collectValues(rest, f.Values, "")...)
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("invalid config %s: %s", path, strings.Join(problems, "; "))
+	}
+	return f, nil
+}
+
+// collectValues flattens and validates a settings mapping into values,
+// returning a description of each problem found
+func collectValues(m map[string]interface{}, values map[string]string, prefix string) []string {
+	var problems []string
+	flatten("", m, func(key string, v interface{}) {
+		s, ok := Lookup(key)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%sunknown key %q", prefix, key))
+			return
+		}
+		str := fmt.Sprint(v)
+		if err := s.Validate(str); err != nil {
+			problems = append(problems, prefix+err.Error())
+			return
+		}
+		values[key] = str
+	})
+	return problems
+}
+
+// profileNames lists the profiles defined across files, sorted
+func profileNames(files []*File) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, f := range files {
+		for name := range f.Profiles {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return []string{"none defined"}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetValue validates value and writes it under key in the config file at path,
+// creating the file if needed. Keys of the form "profiles.<name>.<key>" set a
+// value inside a named profile.
+func SetValue(path, key, value string) error {
+	settingKey := key
+	if rest, ok := strings.CutPrefix(key, profilesKey+"."); ok {
+		name, k, found := strings.Cut(rest, ".")
+		if !found || name == "" {
+			return fmt.Errorf("profile keys must look like profiles.<name>.<key>, got %q", key)
+		}
+		if k == "profile" {
+			return fmt.Errorf("profiles cannot select another profile")
+		}
+		settingKey = k
+	}
+	s, ok := Lookup(settingKey)
+	if !ok {
+		return fmt.Errorf("unknown config key %q", settingKey)
+	}
+	if err := s.Validate(value); err != nil {
+		return err
+	}
+
+	raw, err := readYAML(path)
+	if err != nil {
+		return err
+	}
+
+	parts := strings.Split(key, ".")
+	node := raw
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := node[part].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			node[part] = child
+		}
+		node = child
+	}
+	node[parts[len(parts)-1]] = s.typed(value)
+
+	data, err := yaml.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config %s: %w", path, err)
+	}
+	return nil
+}
+
+// readYAML parses a YAML mapping, treating a missing or empty file as empty
+func readYAML(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]interface{}), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	raw := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if raw == nil {
+		raw = make(map[string]interface{})
+	}
+	return raw, nil
+}
+
+// flatten walks nested mappings, calling fn with dotted keys for each leaf.
+// Null leaves are skipped so "key:" with no value falls through to lower layers.
+func flatten(prefix string, m map[string]interface{}, fn func(key string, v interface{})) {
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch child := v.(type) {
+		case map[string]interface{}:
+			flatten(key, child, fn)
+		case nil:
+			continue
+		default:
+			fn(key, v)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupConfigDirs isolates the user config under a fake HOME and returns the
+// user config path and a project directory
+func setupConfigDirs(t *testing.T) (string, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, s := range Settings {
+		if s.Env != "" {
+			t.Setenv(s.Env, "")
+		}
+	}
+	return filepath.Join(home, ".lisa", "config.yaml"), t.TempDir()
+}
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestResolve_Defaults(t *testing.T) {
+	_, projectDir := setupConfigDirs(t)
+
+	eff, err := Resolve(projectDir, nil)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	cfg := eff.Config
+	if cfg.Backend != "opencode" || cfg.Timeout != 600 || cfg.PromptPath != "PROMPT.md" {
+		t.Errorf("Resolve() defaults = %+v", cfg)
+	}
+	if cfg.MaxCalls != 10 {
+		t.Errorf("Resolve() MaxCalls = %d, want 10 for opencode backend", cfg.MaxCalls)
+	}
+	if cfg.NoProgressThreshold != 3 || cfg.SameErrorThreshold != 5 || cfg.RetryDelay != 5*time.Second {
+		t.Errorf("Resolve() loop tuning = %d/%d/%s, want 3/5/5s", cfg.NoProgressThreshold, cfg.SameErrorThreshold, cfg.RetryDelay)
+	}
+	if cfg.ProjectPath != projectDir {
+		t.Errorf("Resolve() ProjectPath = %s, want %s", cfg.ProjectPath, projectDir)
+	}
+}
+
+func TestResolve_Precedence(t *testing.T) {
+	userPath, projectDir := setupConfigDirs(t)
+
+	writeConfig(t, userPath, "backend: cli\ntimeout: 100\ncalls: 4\ncircuit:\n  same_error_threshold: 9\n")
+	writeConfig(t, filepath.Join(projectDir, ProjectFile), "timeout: 200\ncalls: 5\n")
+	t.Setenv("LISA_CALLS", "6")
+
+	eff, err := Resolve(projectDir, map[string]string{"prompt": "OTHER.md", "project": "ignored"})
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	tests := []struct {
+		key        string
+		wantValue  string
+		wantSource Source
+	}{
+		{"backend", "cli", SourceUser},
+		{"circuit.same_error_threshold", "9", SourceUser},
+		{"timeout", "200", SourceProject},
+		{"calls", "6", SourceEnv},
+		{"prompt", "OTHER.md", SourceFlag},
+		{"verbose", "false", SourceDefault},
+	}
+	for _, tt := range tests {
+		v, ok := eff.Get(tt.key)
+		if !ok {
+			t.Errorf("Get(%q) not found", tt.key)
+			continue
+		}
+		if v.Value != tt.wantValue || v.Source != tt.wantSource {
+			t.Errorf("Get(%q) = %s from %s, want %s from %s", tt.key, v.Value, v.Source, tt.wantValue, tt.wantSource)
+		}
+	}
+
+	if eff.Config.MaxCalls != 6 || eff.Config.SameErrorThreshold != 9 {
+		t.Errorf("Resolve() Config = %+v, want MaxCalls 6, SameErrorThreshold 9", eff.Config)
+	}
+}
+
+func TestResolve_InvalidValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     [2]string
+		flags   map[string]string
+		wantErr string
+	}{
+		{name: "unknown key", file: "bakend: cli\n", wantErr: `unknown key "bakend"`},
+		{name: "bad int", file: "calls: lots\n", wantErr: "not an integer"},
+		{name: "below minimum", file: "circuit:\n  no_progress_threshold: 0\n", wantErr: "below the minimum"},
+		{name: "bad enum", file: "backend: magic\n", wantErr: "not one of"},
+		{name: "bad duration", file: "loop:\n  retry_delay: soon\n", wantErr: "not a duration"},
+		{name: "bad env", env: [2]string{"LISA_TIMEOUT", "x"}, wantErr: "$LISA_TIMEOUT"},
+		{name: "bad flag", flags: map[string]string{"log-format": "xml"}, wantErr: "--log-format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, projectDir := setupConfigDirs(t)
+			if tt.file != "" {
+				writeConfig(t, filepath.Join(projectDir, ProjectFile), tt.file)
+			}
+			if tt.env[0] != "" {
+				t.Setenv(tt.env[0], tt.env[1])
+			}
+
+			_, err := Resolve(projectDir, tt.flags)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Resolve() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetValue(t *testing.T) {
+	_, projectDir := setupConfigDirs(t)
+	path := filepath.Join(projectDir, ProjectFile)
+
+	if err := SetValue(path, "circuit.no_progress_threshold", "7"); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	if err := SetValue(path, "backend", "cli"); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	if err := SetValue(path, "calls", "many"); err == nil {
+		t.Error("SetValue() with invalid value should fail")
+	}
+	if err := SetValue(path, "nope", "1"); err == nil {
+		t.Error("SetValue() with unknown key should fail")
+	}
+
+	if err := SetValue(path, "profiles.overnight.calls", "50"); err != nil {
+		t.Fatalf("SetValue() profile key error = %v", err)
+	}
+
+	f, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if f.Values["circuit.no_progress_threshold"] != "7" || f.Values["backend"] != "cli" {
+		t.Errorf("LoadFile() values = %v, want threshold 7 and backend cli", f.Values)
+	}
+	if f.Profiles["overnight"]["calls"] != "50" {
+		t.Errorf("LoadFile() profiles = %v, want overnight calls 50", f.Profiles)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "circuit:\n    no_progress_threshold: 7") {
+		t.Errorf("SetValue() wrote %q, want nested YAML", data)
+	}
+}
+
+func TestResolve_Profiles(t *testing.T) {
+	userPath, projectDir := setupConfigDirs(t)
+
+	writeConfig(t, userPath, `
+profiles:
+  overnight:
+    calls: 50
+    test:
+      command: make test
+  quick:
+    calls: 2
+`)
+	writeConfig(t, filepath.Join(projectDir, ProjectFile), `
+timeout: 300
+profile: quick
+profiles:
+  overnight:
+    timeout: 3600
+    circuit:
+      no_progress_threshold: 6
+`)
+
+	tests := []struct {
+		name        string
+		flags       map[string]string
+		env         string
+		wantProfile string
+		wantCalls   int
+		wantTimeout int
+		wantTestCmd string
+	}{
+		{name: "default profile from project file", wantProfile: "quick", wantCalls: 2, wantTimeout: 300},
+		{name: "flag selects profile merged across files", flags: map[string]string{"profile": "overnight"},
+			wantProfile: "overnight", wantCalls: 50, wantTimeout: 3600, wantTestCmd: "make test"},
+		{name: "env selects profile", env: "overnight", wantProfile: "overnight", wantCalls: 50, wantTimeout: 3600, wantTestCmd: "make test"},
+		{name: "flag beats profile", flags: map[string]string{"profile": "overnight", "calls": "7"},
+			wantProfile: "overnight", wantCalls: 7, wantTimeout: 3600, wantTestCmd: "make test"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LISA_PROFILE", tt.env)
+
+			eff, err := Resolve(projectDir, tt.flags)
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			cfg := eff.Config
+			if cfg.Profile != tt.wantProfile || cfg.MaxCalls != tt.wantCalls || cfg.Timeout != tt.wantTimeout || cfg.TestCommand != tt.wantTestCmd {
+				t.Errorf("Resolve() profile=%q calls=%d timeout=%d test=%q, want %q %d %d %q",
+					cfg.Profile, cfg.MaxCalls, cfg.Timeout, cfg.TestCommand,
+					tt.wantProfile, tt.wantCalls, tt.wantTimeout, tt.wantTestCmd)
+			}
+		})
+	}
+
+	eff, _ := Resolve(projectDir, map[string]string{"profile": "overnight"})
+	if v, _ := eff.Get("circuit.no_progress_threshold"); v.Source != SourceProfile || v.Value != "6" {
+		t.Errorf("Get(circuit.no_progress_threshold) = %s from %s, want 6 from profile", v.Value, v.Source)
+	}
+
+	_, err := Resolve(projectDir, map[string]string{"profile": "missing"})
+	if err == nil || !strings.Contains(err.Error(), "overnight, quick") {
+		t.Errorf("Resolve() unknown profile error = %v, want list of available profiles", err)
+	}
+}
+
+func TestLoadFile_InvalidProfile(t *testing.T) {
+	_, projectDir := setupConfigDirs(t)
+	path := filepath.Join(projectDir, ProjectFile)
+	writeConfig(t, path, "profiles:\n  bad:\n    calls: zero\n    profile: other\n")
+
+	_, err := LoadFile(path)
+	if err == nil || !strings.Contains(err.Error(), "profile bad: calls") || !strings.Contains(err.Error(), "cannot select another profile") {
+		t.Errorf("LoadFile() error = %v, want profile validation errors", err)
+	}
+}
+
+func TestResolve_Keys(t *testing.T) {
+	userPath, projectDir := setupConfigDirs(t)
+
+	writeConfig(t, userPath, `
+keymap: vim
+keys:
+  quit: Q
+  tasks:
+    add: [A, "+"]
+`)
+	writeConfig(t, filepath.Join(projectDir, ProjectFile), `
+keys:
+  tasks:
+    add: o
+    delete: []
+`)
+
+	eff, err := Resolve(projectDir, nil)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if eff.Config.Keymap != "vim" {
+		t.Errorf("Resolve() Keymap = %q, want vim", eff.Config.Keymap)
+	}
+	want := map[string][]string{"quit": {"Q"}, "tasks.add": {"o"}, "tasks.delete": {}}
+	if !reflect.DeepEqual(eff.Config.Keys, want) {
+		t.Errorf("Resolve() Keys = %v, want %v", eff.Config.Keys, want)
+	}
+
+	writeConfig(t, userPath, "keys: [q]\n")
+	if _, err := Resolve(projectDir, nil); err == nil || !strings.Contains(err.Error(), "keys must be a mapping") {
+		t.Errorf("Resolve() error = %v, want keys mapping error", err)
+	}
+}
+
+func TestResolve_Hooks(t *testing.T) {
+	userPath, projectDir := setupConfigDirs(t)
+
+	writeConfig(t, userPath, "hooks:\n  on_complete: notify-send lisa done\n")
+	writeConfig(t, filepath.Join(projectDir, ProjectFile), `
+hooks:
+  pre_iteration: make lint
+profiles:
+  ci:
+    hooks:
+      post_iteration: ./scripts/report.sh
+`)
+	t.Setenv("LISA_HOOK_ON_ERROR", "echo failed")
+
+	eff, err := Resolve(projectDir, map[string]string{"profile": "ci"})
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	want := Hooks{
+		PreIteration:  "make lint",
+		PostIteration: "./scripts/report.sh",
+		OnComplete:    "notify-send lisa done",
+		OnError:       "echo failed",
+		Timeout:       time.Minute,
+	}
+	if eff.Config.Hooks != want {
+		t.Errorf("Resolve() Hooks = %+v, want %+v", eff.Config.Hooks, want)
+	}
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the value type of a setting
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindBool
+	KindDuration
+)
+
+// Setting describes one configuration value and every place it can be set
+type Setting struct {
+	Key     string   // Dotted key in config.yaml, e.g. "circuit.no_progress_threshold"
+	Flag    string   // CLI flag name, empty if not settable by flag
+	Env     string   // Environment variable, empty if not settable by env
+	Kind    Kind     // Value type
+	Default string   // Default value
+	Allowed []string // Permitted values for enumerations
+	Min     int      // Minimum for KindInt values
+	Secret  bool     // Mask the value when displaying
+	Usage   string   // One-line description
+
+	apply func(c *Config, v string) // Stores a validated value into Config
+}
+
+// Settings is the registry of every configurable value, in display order
+var Settings = []Setting{
+	{Key: "profile", Flag: "profile", Env: "LISA_PROFILE", Kind: KindString,
+		Usage: "Named profile from the config file's profiles section", apply: func(c *Config, v string) { c.Profile = v }},
+	{Key: "backend", Flag: "backend", Env: "LISA_BACKEND", Kind: KindString, Default: "opencode", Allowed: []string{"cli", "opencode"},
+		Usage: "Backend: cli or opencode", apply: func(c *Config, v string) { c.Backend = v }},
+	{Key: "prompt", Flag: "prompt", Env: "LISA_PROMPT", Kind: KindString, Default: "PROMPT.md",
+		Usage: "Prompt file", apply: func(c *Config, v string) { c.PromptPath = v }},
+	{Key: "calls", Flag: "calls", Env: "LISA_CALLS", Kind: KindInt, Default: "3", Min: 1,
+		Usage: "Max loop iterations (default: 3, 10 for opencode backend)", apply: func(c *Config, v string) { c.MaxCalls = atoi(v) }},
+	{Key: "timeout", Flag: "timeout", Env: "LISA_TIMEOUT", Kind: KindInt, Default: "600", Min: 1,
+		Usage: "Codex timeout (seconds)", apply: func(c *Config, v string) { c.Timeout = atoi(v) }},
+	{Key: "verbose", Flag: "verbose", Env: "LISA_VERBOSE", Kind: KindBool, Default: "false",
+		Usage: "Verbose output", apply: func(c *Config, v string) { c.Verbose = atob(v) }},
+	{Key: "monitor", Flag: "monitor", Env: "LISA_MONITOR", Kind: KindBool, Default: "false",
+		Usage: "Enable integrated monitoring", apply: func(c *Config, v string) { c.Monitor = atob(v) }},
+	{Key: "log_format", Flag: "log-format", Env: "LISA_LOG_FORMAT", Kind: KindString, Default: "", Allowed: []string{"", "text", "json", "logfmt"},
+		Usage: "Log format: text, json, or logfmt (enables CLI log mode)", apply: func(c *Config, v string) { c.LogFormat = v }},
+	{Key: "api.listen", Flag: "listen", Env: "LISA_LISTEN", Kind: KindString,
+		Usage: "Serve the control API on a loopback host:port or unix:/path/to/socket", apply: func(c *Config, v string) { c.Listen = v }},
+	{Key: "review", Flag: "review", Env: "LISA_REVIEW", Kind: KindBool, Default: "false",
+		Usage: "Pause after each iteration until its changes are approved (committed) or rejected (reverted)", apply: func(c *Config, v string) { c.Review = atob(v) }},
+	{Key: "theme", Flag: "theme", Env: "LISA_THEME", Kind: KindString, Default: "auto",
+		Usage: "TUI theme: auto, dark, light, high-contrast, mono, or a theme file's name in ~/.lisa/themes", apply: func(c *Config, v string) { c.Theme = v }},
+	{Key: "keymap", Flag: "keymap", Env: "LISA_KEYMAP", Kind: KindString, Default: "default", Allowed: []string{"default", "vim", "emacs"},
+		Usage: "TUI key preset: default, vim or emacs; the keys section overrides single actions", apply: func(c *Config, v string) { c.Keymap = v }},
+
+	{Key: "test.command", Flag: "test-cmd", Env: "LISA_TEST_CMD", Kind: KindString,
+		Usage: "Test command run after each loop (e.g. \"go test -json ./...\")", apply: func(c *Config, v string) { c.TestCommand = v }},
+	{Key: "test.report", Flag: "test-report", Env: "LISA_TEST_REPORT", Kind: KindString,
+		Usage: "Test report file to parse after each loop (go test -json, JUnit, TAP, Jest/Vitest)", apply: func(c *Config, v string) { c.TestReport = v }},
+	{Key: "test.timeout", Flag: "test-timeout", Env: "LISA_TEST_TIMEOUT", Kind: KindDuration, Default: "10m",
+		Usage: "Kill the test command after this long and count the run as failed", apply: func(c *Config, v string) { c.TestTimeout, _ = time.ParseDuration(v) }},
+
+	{Key: "opencode.url", Flag: "opencode-url", Env: "OPENCODE_SERVER_URL", Kind: KindString,
+		Usage: "OpenCode server URL", apply: func(c *Config, v string) { c.OpenCodeServerURL = v }},
+	{Key: "opencode.username", Flag: "opencode-user", Env: "OPENCODE_SERVER_USERNAME", Kind: KindString, Default: "opencode",
+		Usage: "OpenCode username", apply: func(c *Config, v string) { c.OpenCodeUsername = v }},
+	{Key: "opencode.password", Flag: "opencode-pass", Env: "OPENCODE_SERVER_PASSWORD", Kind: KindString, Secret: true,
+		Usage: "OpenCode password", apply: func(c *Config, v string) { c.OpenCodePassword = v }},
+	{Key: "opencode.model", Flag: "opencode-model", Env: "OPENCODE_MODEL_ID", Kind: KindString, Default: "glm-4.7",
+		Usage: "OpenCode model ID", apply: func(c *Config, v string) { c.OpenCodeModelID = v }},
+
+	{Key: "circuit.no_progress_threshold", Env: "LISA_CIRCUIT_NO_PROGRESS_THRESHOLD", Kind: KindInt, Default: "3", Min: 1,
+		Usage: "Loops without progress before the circuit breaker opens", apply: func(c *Config, v string) { c.NoProgressThreshold = atoi(v) }},
+	{Key: "circuit.same_error_threshold", Env: "LISA_CIRCUIT_SAME_ERROR_THRESHOLD", Kind: KindInt, Default: "5", Min: 1,
+		Usage: "Repeated errors before the circuit breaker opens", apply: func(c *Config, v string) { c.SameErrorThreshold = atoi(v) }},
+	{Key: "rate_limit.reset_hours", Env: "LISA_RATE_LIMIT_RESET_HOURS", Kind: KindInt, Default: "1", Min: 1,
+		Usage: "Hours before the call counter resets", apply: func(c *Config, v string) { c.RateLimitResetHours = atoi(v) }},
+	{Key: "loop.retry_delay", Env: "LISA_RETRY_DELAY", Kind: KindDuration, Default: "5s",
+		Usage: "Delay before retrying a failed loop iteration", apply: func(c *Config, v string) { c.RetryDelay, _ = time.ParseDuration(v) }},
+
+	{Key: "hooks.pre_run", Env: "LISA_HOOK_PRE_RUN", Kind: KindString,
+		Usage: "Shell command run before the loop starts; non-zero exit aborts the run", apply: func(c *Config, v string) { c.Hooks.PreRun = v }},
+	{Key: "hooks.pre_iteration", Env: "LISA_HOOK_PRE_ITERATION", Kind: KindString,
+		Usage: "Shell command run before each iteration; non-zero exit vetoes it", apply: func(c *Config, v string) { c.Hooks.PreIteration = v }},
+	{Key: "hooks.post_iteration", Env: "LISA_HOOK_POST_ITERATION", Kind: KindString,
+		Usage: "Shell command run after each iteration", apply: func(c *Config, v string) { c.Hooks.PostIteration = v }},
+	{Key: "hooks.on_task_complete", Env: "LISA_HOOK_ON_TASK_COMPLETE", Kind: KindString,
+		Usage: "Shell command run for each plan task checked off", apply: func(c *Config, v string) { c.Hooks.OnTaskComplete = v }},
+	{Key: "hooks.on_breaker_open", Env: "LISA_HOOK_ON_BREAKER_OPEN", Kind: KindString,
+		Usage: "Shell command run when the circuit breaker opens", apply: func(c *Config, v string) { c.Hooks.OnBreakerOpen = v }},
+	{Key: "hooks.on_complete", Env: "LISA_HOOK_ON_COMPLETE", Kind: KindString,
+		Usage: "Shell command run when the loop finishes", apply: func(c *Config, v string) { c.Hooks.OnComplete = v }},
+	{Key: "hooks.on_error", Env: "LISA_HOOK_ON_ERROR", Kind: KindString,
+		Usage: "Shell command run when an iteration fails", apply: func(c *Config, v string) { c.Hooks.OnError = v }},
+	{Key: "hooks.timeout", Env: "LISA_HOOK_TIMEOUT", Kind: KindDuration, Default: "1m",
+		Usage: "Kill a hook after this long and count it as failed", apply: func(c *Config, v string) { c.Hooks.Timeout, _ = time.ParseDuration(v) }},
+}
+
+// Lookup returns the setting registered under key
+func Lookup(key string) (*Setting, bool) {
+	for i := range Settings {
+		if Settings[i].Key == key {
+			return &Settings[i], true
+		}
+	}
+	return nil, false
+}
+
+// LookupFlag returns the setting bound to a CLI flag name
+func LookupFlag(flag string) (*Setting, bool) {
+	for i := range Settings {
+		if Settings[i].Flag != "" && Settings[i].Flag == flag {
+			return &Settings[i], true
+		}
+	}
+	return nil, false
+}
+
+// Validate checks that v is an acceptable value for the setting
+func (s *Setting) Validate(v string) error {
+	switch s.Kind {
+	case KindInt:
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not an integer", s.Key, v)
+		}
+		if n < s.Min {
+			return fmt.Errorf("%s: %d is below the minimum of %d", s.Key, n, s.Min)
+		}
+	case KindBool:
+		if _, err := strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("%s: %q is not true or false", s.Key, v)
+		}
+	case KindDuration:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a duration (e.g. 5s, 1m)", s.Key, v)
+		}
+		if d < 0 {
+			return fmt.Errorf("%s: duration must not be negative", s.Key)
+		}
+	}
+	if len(s.Allowed) > 0 && !slices.Contains(s.Allowed, v) {
+		return fmt.Errorf("%s: %q is not one of %s", s.Key, v, strings.Join(nonEmpty(s.Allowed), ", "))
+	}
+	return nil
+}
+
+// Display renders a value for output, masking secrets
+func (s *Setting) Display(v string) string {
+	if s.Secret && v != "" {
+		return "********"
+	}
+	return v
+}
+
+// typed converts a validated string value to its YAML-native type
+func (s *Setting) typed(v string) interface{} {
+	switch s.Kind {
+	case KindInt:
+		return atoi(v)
+	case KindBool:
+		return atob(v)
+	default:
+		return v
+	}
+}
+
+func atoi(v string) int {
+	n, _ := strconv.Atoi(v)
+	return n
+}
+
+func atob(v string) bool {
+	b, _ := strconv.ParseBool(v)
+	return b
+}
+
+func nonEmpty(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
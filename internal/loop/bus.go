@@ -0,0 +1,136 @@
+package loop
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultBufferSize is the per-subscriber buffer used when none is given
+const DefaultBufferSize = 256
+
+// DropPolicy decides which event is lost when a subscriber's buffer is full
+type DropPolicy int
+
+const (
+	// DropOldest discards the oldest buffered event to make room, so a slow
+	// subscriber always sees the most recent state
+	DropOldest DropPolicy = iota
+	// DropNewest discards the incoming event, preserving what is already buffered
+	DropNewest
+)
+
+// Bus fans loop events out to any number of subscribers. Publish never blocks:
+// each subscriber has a bounded buffer and events that don't fit are dropped
+// according to its DropPolicy.
+type Bus struct {
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	seq    uint64
+	closed bool
+}
+
+// Subscription receives events from a Bus until it is unsubscribed
+type Subscription struct {
+	bus     *Bus
+	ch      chan LoopEvent
+	policy  DropPolicy
+	dropped atomic.Uint64
+}
+
+// NewBus creates an event bus with no subscribers
+func NewBus() *Bus {
+	return &Bus{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe registers a subscriber with a buffer of the given size
+// (DefaultBufferSize if <= 0). Subscribing to a closed bus returns a
+// subscription whose channel is already closed.
+func (b *Bus) Subscribe(buffer int, policy DropPolicy) *Subscription {
+	if buffer <= 0 {
+		buffer = DefaultBufferSize
+	}
+	sub := &Subscription{bus: b, ch: make(chan LoopEvent, buffer), policy: policy}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(sub.ch)
+		return sub
+	}
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+// Publish stamps the event with the next sequence number and delivers it to
+// every subscriber without waiting on any of them
+func (b *Bus) Publish(event LoopEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.seq++
+	event.Seq = b.seq
+	for sub := range b.subs {
+		sub.deliver(event)
+	}
+}
+
+// Close unsubscribes everyone, closing their channels once buffered events are read
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// deliver enqueues an event, applying the drop policy when the buffer is full.
+// Called with the bus lock held, so the bus is the only sender.
+func (s *Subscription) deliver(event LoopEvent) {
+	select {
+	case s.ch <- event:
+		return
+	default:
+	}
+
+	if s.policy == DropOldest {
+		select {
+		case <-s.ch:
+		default:
+		}
+		select {
+		case s.ch <- event:
+		default:
+		}
+	}
+	s.dropped.Add(1)
+}
+
+// Events returns the channel events are delivered on. It is closed when the
+// subscription ends; events buffered before that can still be read.
+func (s *Subscription) Events() <-chan LoopEvent {
+	return s.ch
+}
+
+// Dropped returns how many events were lost because the buffer was full
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Unsubscribe stops delivery and closes the events channel. It is safe to call
+// more than once.
+func (s *Subscription) Unsubscribe() {
+	b := s.bus
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[s]; ok {
+		delete(b.subs, s)
+		close(s.ch)
+	}
+}
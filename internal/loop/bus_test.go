@@ -0,0 +1,188 @@
+package loop
+
+import (
+	stdcontext "context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/circuit"
+)
+
+// drainEvents returns the events buffered on a subscription without blocking
+func drainEvents(sub *Subscription) []LoopEvent {
+	var events []LoopEvent
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return events
+			}
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func logEvent(message string) LoopEvent {
+	return LoopEvent{Type: EventTypeLog, Log: &LogEntry{Level: LogLevelInfo, Message: message}}
+}
+
+func messages(events []LoopEvent) []string {
+	var out []string
+	for _, e := range events {
+		out = append(out, e.Log.Message)
+	}
+	return out
+}
+
+func TestBus_FanOutAndSequence(t *testing.T) {
+	bus := NewBus()
+	a := bus.Subscribe(8, DropNewest)
+	b := bus.Subscribe(8, DropNewest)
+
+	bus.Publish(logEvent("one"))
+	bus.Publish(logEvent("two"))
+
+	for name, sub := range map[string]*Subscription{"a": a, "b": b} {
+		events := drainEvents(sub)
+		if len(events) != 2 || events[0].Seq != 1 || events[1].Seq != 2 {
+			t.Errorf("subscriber %s got %+v, want two events with Seq 1 and 2", name, events)
+		}
+	}
+}
+
+func TestBus_DropPolicies(t *testing.T) {
+	tests := []struct {
+		policy DropPolicy
+		want   []string
+	}{
+		{DropNewest, []string{"1", "2"}},
+		{DropOldest, []string{"3", "4"}},
+	}
+
+	for _, tt := range tests {
+		bus := NewBus()
+		sub := bus.Subscribe(2, tt.policy)
+		for _, m := range []string{"1", "2", "3", "4"} {
+			bus.Publish(logEvent(m))
+		}
+
+		got := messages(drainEvents(sub))
+		if len(got) != 2 || got[0] != tt.want[0] || got[1] != tt.want[1] {
+			t.Errorf("policy %d kept %v, want %v", tt.policy, got, tt.want)
+		}
+		if sub.Dropped() != 2 {
+			t.Errorf("policy %d Dropped() = %d, want 2", tt.policy, sub.Dropped())
+		}
+	}
+}
+
+func TestBus_SlowSubscriberDoesNotBlock(t *testing.T) {
+	bus := NewBus()
+	slow := bus.Subscribe(1, DropOldest) // Never read
+	fast := bus.Subscribe(1000, DropNewest)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 1000; i++ {
+			bus.Publish(logEvent("x"))
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Publish blocked on a slow subscriber")
+	}
+	if n := len(drainEvents(fast)); n != 1000 {
+		t.Errorf("fast subscriber got %d events, want 1000", n)
+	}
+	if slow.Dropped() != 999 {
+		t.Errorf("slow subscriber Dropped() = %d, want 999", slow.Dropped())
+	}
+}
+
+func TestBus_Unsubscribe(t *testing.T) {
+	bus := NewBus()
+	sub := bus.Subscribe(4, DropNewest)
+	bus.Publish(logEvent("before"))
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+	bus.Publish(logEvent("after"))
+
+	if got := messages(drainEvents(sub)); len(got) != 1 || got[0] != "before" {
+		t.Errorf("events after Unsubscribe() = %v, want only the buffered one", got)
+	}
+	if _, ok := <-sub.Events(); ok {
+		t.Error("Events() channel still open after Unsubscribe()")
+	}
+
+	bus.Close()
+	if _, ok := <-bus.Subscribe(1, DropNewest).Events(); ok {
+		t.Error("Subscribe() on a closed bus returned an open channel")
+	}
+}
+
+func TestBus_ConcurrentPublishAndUnsubscribe(t *testing.T) {
+	bus := NewBus()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		sub := bus.Subscribe(4, DropOldest)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				bus.Publish(logEvent("x"))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range sub.Events() {
+				sub.Unsubscribe()
+			}
+		}()
+	}
+	bus.Publish(logEvent("x"))
+	wg.Wait()
+}
+
+func TestController_EventsStampedWithRunAndIteration(t *testing.T) {
+	setupHookProject(t)
+
+	controller := NewController(Config{MaxCalls: 5, Backend: "cli"}, NewRateLimiter(10, 1), circuit.NewBreaker(3, 5))
+	controller.SetRunner(&checkOffRunner{planFile: "@fix_plan.md"})
+	sub := controller.Subscribe(4096, DropNewest)
+	defer sub.Unsubscribe()
+
+	if err := controller.Run(stdcontext.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	events := drainEvents(sub)
+	if len(events) == 0 {
+		t.Fatal("Run() published no events")
+	}
+	runID := events[0].RunID
+	if runID == "" {
+		t.Fatal("events are not stamped with a run ID")
+	}
+
+	iterations := make(map[int]int)
+	for _, e := range events {
+		if e.RunID != runID {
+			t.Fatalf("event %+v has run ID %q, want %q", e, e.RunID, runID)
+		}
+		if e.Time.IsZero() {
+			t.Errorf("event %s has no timestamp", e.Type)
+		}
+		if e.Type == EventTypeOutcome {
+			iterations[e.Iteration]++
+		}
+	}
+	if iterations[1] != 1 || iterations[2] != 1 {
+		t.Errorf("outcome events by iteration = %v, want one each for iterations 1 and 2", iterations)
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"os"
 	"strings"
 
+	"github.com/brainwhocodes/lisa-loop/internal/analysis"
 	"github.com/brainwhocodes/lisa-loop/internal/project"
+	"github.com/brainwhocodes/lisa-loop/internal/testreport"
 )
 
 // LoadPlan loads remaining tasks from the plan file based on detected project mode
@@ -48,13 +50,22 @@ func LoadPlanWithFile() ([]string, string, error) {
 		return nil, "", fmt.Errorf("failed to find plan file - need REFACTOR_PLAN.md, IMPLEMENTATION_PLAN.md, or @fix_plan.md")
 	}
 
+	tasks, err := LoadPlanFrom(planFile)
+	return tasks, planFile, err
+}
+
+// LoadPlanFrom loads tasks from an explicit plan file path
+func LoadPlanFrom(planFile string) ([]string, error) {
+	if planFile == "" {
+		return nil, fmt.Errorf("no plan file given")
+	}
+
 	data, err := os.ReadFile(planFile)
 	if err != nil {
-		return nil, planFile, fmt.Errorf("failed to read plan file %s: %w", planFile, err)
+		return nil, fmt.Errorf("failed to read plan file %s: %w", planFile, err)
 	}
 
-	tasks, err := parseTasksFromPlan(string(data), planFile)
-	return tasks, planFile, err
+	return parseTasksFromPlan(string(data), planFile)
 }
 
 // parseTasksFromPlan extracts checklist tasks from a plan file
@@ -99,6 +110,31 @@ func parseTasksFromPlan(content, filename string) ([]string, error) {
 	return tasks, nil
 }
 
+// setAsideTasks lists the plan's "- [-]" (skipped) and "- [!]" (blocked)
+// tasks. They aren't checklist items to the loop, but the agent reads the
+// plan itself and needs telling to leave them alone.
+func setAsideTasks(planFile string) []string {
+	data, err := os.ReadFile(planFile)
+	if err != nil {
+		return nil
+	}
+	var tasks []string
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if len(trimmed) <= 6 {
+			continue
+		}
+		text := strings.TrimSpace(trimmed[5:])
+		switch {
+		case strings.HasPrefix(trimmed, "- [-]"):
+			tasks = append(tasks, text)
+		case strings.HasPrefix(trimmed, "- [!]"):
+			tasks = append(tasks, text+" (blocked)")
+		}
+	}
+	return tasks
+}
+
 // extractChecklistItem attempts to extract a checklist item from a line
 // Returns (isChecked, taskText, found)
 func extractChecklistItem(line string) (bool, string, bool) {
@@ -234,6 +270,28 @@ func BuildContext(loopNum int, remainingTasks []string, circuitState string, pre
 
 // BuildContextWithPlanFile builds loop context with explicit plan file path
 func BuildContextWithPlanFile(loopNum int, remainingTasks []string, circuitState string, prevSummary string, planFile string) (string, error) {
+	return BuildContextWithOptions(loopNum, remainingTasks, circuitState, prevSummary, ContextOptions{PlanFile: planFile})
+}
+
+// ContextOptions holds optional inputs for the loop context
+type ContextOptions struct {
+	PlanFile     string               // Explicit plan file path
+	Warnings     []string             // Discrepancies found when reconciling the previous loop's status
+	StatusErrors []string             // Validation errors from the previous loop's JSON status block
+	TestSummary  *testreport.Summary  // Parsed test results from the previous loop
+	Escalation   *analysis.Escalation // Blockers/questions the human answered
+	HumanAnswer  string               // The human's answer to the escalation
+	SkippedTasks []string             // Open tasks the operator told the agent to leave alone
+	Steering     []string             // Standing guidance from the operator
+	Rejection    *Review              // The previous loop's changes, rejected by the reviewer and reverted
+}
+
+// maxContextFailures caps how many failing tests are listed in the context
+const maxContextFailures = 10
+
+// BuildContextWithOptions builds loop context including optional feedback sections
+func BuildContextWithOptions(loopNum int, remainingTasks []string, circuitState string, prevSummary string, opts ContextOptions) (string, error) {
+	planFile := opts.PlanFile
 	var ctxBuilder strings.Builder
 
 	ctxBuilder.WriteString("\n--- LISA LOOP CONTEXT ---\n")
@@ -257,11 +315,76 @@ func BuildContextWithPlanFile(loopNum int, remainingTasks []string, circuitState
 		}
 	}
 
+	if len(opts.SkippedTasks) > 0 {
+		ctxBuilder.WriteString("\nSkipped by the operator (do NOT work on these):\n")
+		for _, task := range opts.SkippedTasks {
+			fmt.Fprintf(&ctxBuilder, "  - %s\n", task)
+		}
+	}
+
 	if prevSummary != "" {
 		ctxBuilder.WriteString("\nPrevious Loop Output (for context only, do not respond to this):\n")
 		fmt.Fprintf(&ctxBuilder, "```\n%s\n```\n", prevSummary)
 	}
 
+	if len(opts.Warnings) > 0 {
+		ctxBuilder.WriteString("\n** STATUS DISCREPANCIES FROM PREVIOUS LOOP **\n")
+		ctxBuilder.WriteString("Your last LISA_STATUS did not match what Lisa observed. Report accurately:\n")
+		for _, warning := range opts.Warnings {
+			fmt.Fprintf(&ctxBuilder, "  - %s\n", warning)
+		}
+	}
+
+	if len(opts.StatusErrors) > 0 {
+		ctxBuilder.WriteString("\n** INVALID lisa-status JSON BLOCK IN PREVIOUS LOOP **\n")
+		ctxBuilder.WriteString("Fix these schema errors in this loop's status block:\n")
+		for _, statusErr := range opts.StatusErrors {
+			fmt.Fprintf(&ctxBuilder, "  - %s\n", statusErr)
+		}
+	}
+
+	if opts.HumanAnswer != "" {
+		ctxBuilder.WriteString("\n** ANSWER FROM THE HUMAN OPERATOR **\n")
+		if opts.Escalation != nil {
+			ctxBuilder.WriteString(opts.Escalation.Prompt())
+		}
+		fmt.Fprintf(&ctxBuilder, "Answer:\n%s\n", opts.HumanAnswer)
+	}
+
+	if r := opts.Rejection; r != nil {
+		ctxBuilder.WriteString("\n** THE REVIEWER REJECTED THE PREVIOUS LOOP **\n")
+		ctxBuilder.WriteString("Its changes were reverted; do not repeat them:\n")
+		for _, f := range r.Files {
+			fmt.Fprintf(&ctxBuilder, "  - %s %s\n", f.Status, f.Path)
+		}
+		if r.Feedback != "" {
+			fmt.Fprintf(&ctxBuilder, "Reviewer feedback:\n%s\n", r.Feedback)
+		}
+	}
+
+	if len(opts.Steering) > 0 {
+		ctxBuilder.WriteString("\n** GUIDANCE FROM THE HUMAN OPERATOR **\n")
+		ctxBuilder.WriteString("Follow these instructions until they are withdrawn:\n")
+		for _, message := range opts.Steering {
+			fmt.Fprintf(&ctxBuilder, "  - %s\n", message)
+		}
+	}
+
+	if opts.TestSummary != nil {
+		fmt.Fprintf(&ctxBuilder, "\n** TEST RESULTS FROM PREVIOUS LOOP **\n%s\n", opts.TestSummary)
+		for i, failure := range opts.TestSummary.Failures {
+			if i == maxContextFailures {
+				fmt.Fprintf(&ctxBuilder, "  ... and %d more failing test(s)\n", len(opts.TestSummary.Failures)-maxContextFailures)
+				break
+			}
+			fmt.Fprintf(&ctxBuilder, "  - FAIL %s", failure.Name)
+			if failure.Message != "" {
+				fmt.Fprintf(&ctxBuilder, ": %s", firstLine(failure.Message))
+			}
+			ctxBuilder.WriteString("\n")
+		}
+	}
+
 	// Add task completion and status reporting reminder
 	ctxBuilder.WriteString("\n** WORKFLOW REQUIREMENTS **\n")
 	ctxBuilder.WriteString("1. Work on ONE task from the plan\n")
@@ -275,6 +398,12 @@ func BuildContextWithPlanFile(loopNum int, remainingTasks []string, circuitState
 	ctxBuilder.WriteString("TESTS_STATUS: PASSING | FAILING | UNKNOWN\n")
 	ctxBuilder.WriteString("EXIT_SIGNAL: true (if ALL tasks [x]) | false (if work remains)\n")
 	ctxBuilder.WriteString("---END_LISA_STATUS---\n")
+	ctxBuilder.WriteString("If you are blocked or need a decision, add BLOCKER: <problem> or QUESTION: <question> lines; Lisa pauses and asks the human.\n")
+	ctxBuilder.WriteString("Or, instead of the block above, a fenced ```lisa-status JSON object (`lisa schema` prints its JSON Schema):\n")
+	ctxBuilder.WriteString("```lisa-status\n")
+	ctxBuilder.WriteString(`{"version": 1, "status": "WORKING", "current_task": "<task>", "tasks": [{"task": "<task>", "result": "completed"}], `)
+	ctxBuilder.WriteString(`"files_modified": 0, "tests_status": "UNKNOWN", "exit_signal": false, "blockers": [], "questions": [], "follow_up_tasks": []}`)
+	ctxBuilder.WriteString("\n```\n")
 
 	ctxBuilder.WriteString("--- END LOOP CONTEXT ---\n\n")
 
@@ -298,3 +427,11 @@ func GetProjectRoot() (string, error) {
 func CheckProjectRoot() error {
 	return project.ValidateProjectDir(".")
 }
+
+// firstLine returns the first line of s
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
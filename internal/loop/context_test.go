@@ -5,6 +5,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/brainwhocodes/lisa-loop/internal/analysis"
+	"github.com/brainwhocodes/lisa-loop/internal/testreport"
 )
 
 func TestLoadFixPlan(t *testing.T) {
@@ -308,3 +311,124 @@ func TestCheckProjectRootRefactorMode(t *testing.T) {
 		t.Errorf("CheckProjectRoot() should pass for refactor mode: %v", err)
 	}
 }
+
+func TestBuildContextWithOptions_Warnings(t *testing.T) {
+	ctx, err := BuildContextWithOptions(2, []string{"Task 1"}, "CLOSED", "", ContextOptions{
+		PlanFile: "@fix_plan.md",
+		Warnings: []string{"Reported 2 task(s) completed but 0 checkbox(es) were marked [x] in the plan"},
+	})
+	if err != nil {
+		t.Fatalf("BuildContextWithOptions() error = %v", err)
+	}
+
+	if !strings.Contains(ctx, "STATUS DISCREPANCIES FROM PREVIOUS LOOP") {
+		t.Error("BuildContextWithOptions() missing discrepancy section")
+	}
+	if !strings.Contains(ctx, "0 checkbox(es) were marked [x]") {
+		t.Error("BuildContextWithOptions() missing warning text")
+	}
+
+	plain, _ := BuildContextWithPlanFile(2, []string{"Task 1"}, "CLOSED", "", "@fix_plan.md")
+	if strings.Contains(plain, "STATUS DISCREPANCIES") {
+		t.Error("BuildContextWithPlanFile() should not include discrepancy section without warnings")
+	}
+}
+
+func TestBuildContextWithOptions_StatusErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(origDir)
+
+	os.WriteFile("PROMPT.md", []byte("Test prompt"), 0644)
+	os.WriteFile("@fix_plan.md", []byte("- [ ] Task 1\n"), 0644)
+
+	ctx, err := BuildContextWithOptions(2, []string{"[ ] Task 1"}, "CLOSED", "", ContextOptions{
+		StatusErrors: []string{`missing required field "exit_signal"`},
+	})
+	if err != nil {
+		t.Fatalf("BuildContextWithOptions() error = %v", err)
+	}
+	if !strings.Contains(ctx, "INVALID lisa-status JSON BLOCK") {
+		t.Error("BuildContextWithOptions() missing status error section")
+	}
+	if !strings.Contains(ctx, `missing required field "exit_signal"`) {
+		t.Error("BuildContextWithOptions() missing status error detail")
+	}
+}
+
+func TestBuildContextWithOptions_TestSummary(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(origDir)
+
+	os.WriteFile("PROMPT.md", []byte("Test prompt"), 0644)
+	os.WriteFile("@fix_plan.md", []byte("- [ ] Task 1\n"), 0644)
+
+	summary := &testreport.Summary{
+		Passed:   4,
+		Failed:   1,
+		Failures: []testreport.Failure{{Name: "TestSub", Message: "want 2, got 1\nstack"}},
+	}
+	ctx, err := BuildContextWithOptions(2, []string{"[ ] Task 1"}, "CLOSED", "", ContextOptions{TestSummary: summary})
+	if err != nil {
+		t.Fatalf("BuildContextWithOptions() error = %v", err)
+	}
+	if !strings.Contains(ctx, "4 passed, 1 failed, 0 skipped") {
+		t.Error("BuildContextWithOptions() missing test summary line")
+	}
+	if !strings.Contains(ctx, "FAIL TestSub: want 2, got 1\n") {
+		t.Error("BuildContextWithOptions() missing first line of failure message")
+	}
+}
+
+func TestBuildContextWithOptions_HumanAnswer(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(origDir)
+
+	os.WriteFile("PROMPT.md", []byte("Test prompt"), 0644)
+	os.WriteFile("@fix_plan.md", []byte("- [ ] Task 1\n"), 0644)
+
+	ctx, err := BuildContextWithOptions(3, []string{"[ ] Task 1"}, "CLOSED", "", ContextOptions{
+		Escalation:  &analysis.Escalation{Questions: []string{"Which port?"}},
+		HumanAnswer: "Use 8080",
+	})
+	if err != nil {
+		t.Fatalf("BuildContextWithOptions() error = %v", err)
+	}
+	for _, want := range []string{"ANSWER FROM THE HUMAN OPERATOR", "Which port?", "Answer:\nUse 8080"} {
+		if !strings.Contains(ctx, want) {
+			t.Errorf("BuildContextWithOptions() missing %q", want)
+		}
+	}
+}
+
+func TestBuildContextWithOptions_Rejection(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(origDir)
+
+	os.WriteFile("PROMPT.md", []byte("Test prompt"), 0644)
+	os.WriteFile("@fix_plan.md", []byte("- [ ] Task 1\n"), 0644)
+
+	ctx, err := BuildContextWithOptions(4, []string{"[ ] Task 1"}, "CLOSED", "", ContextOptions{
+		Rejection: &Review{
+			Loop:     3,
+			Files:    []ChangedFile{{Path: "db/schema.sql", Status: "M"}},
+			Decision: ReviewReject,
+			Feedback: "Don't change the schema",
+		},
+	})
+	if err != nil {
+		t.Fatalf("BuildContextWithOptions() error = %v", err)
+	}
+	for _, want := range []string{"REVIEWER REJECTED THE PREVIOUS LOOP", "M db/schema.sql", "Reviewer feedback:\nDon't change the schema"} {
+		if !strings.Contains(ctx, want) {
+			t.Errorf("BuildContextWithOptions() missing %q", want)
+		}
+	}
+}
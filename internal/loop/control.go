@@ -0,0 +1,379 @@
+package loop
+
+import (
+	stdcontext "context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/runner"
+)
+
+// RunState is the controller's run state as seen by operators
+type RunState string
+
+// Run states reported in EventTypeStateChange events
+const (
+	RunStateIdle     RunState = "idle"
+	RunStateRunning  RunState = "running"
+	RunStatePausing  RunState = "pausing" // Pause requested; takes effect when the iteration ends
+	RunStatePaused   RunState = "paused"
+	RunStateStopping RunState = "stopping" // Stop requested; Run returns when the iteration ends
+	RunStateStopped  RunState = "stopped"
+)
+
+// ErrInterrupted is returned by ExecuteLoop when an operator interrupted the
+// backend call with PauseNow, Abort or SkipTask
+var ErrInterrupted = errors.New("iteration interrupted")
+
+// Causes attached to the backend call's context when it is interrupted
+var (
+	errAborted   = errors.New("aborted by operator")
+	errPausedNow = errors.New("paused by operator")
+	errSkipped   = errors.New("task skipped by operator")
+)
+
+// State returns the current run state
+func (c *Controller) State() RunState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// setState records a state transition and publishes it if the state changed
+func (c *Controller) setState(to RunState, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setStateLocked(to, reason)
+}
+
+// setStateLocked is setState with mu held. Publishing under the lock keeps
+// state change events in the order the transitions happened.
+func (c *Controller) setStateLocked(to RunState, reason string) {
+	if c.state == to {
+		return
+	}
+	change := &StateChange{From: c.state, To: to, Reason: reason}
+	c.state = to
+	c.bus.Publish(LoopEvent{
+		Type:      EventTypeStateChange,
+		RunID:     c.runID,
+		Iteration: c.iteration,
+		Time:      time.Now(),
+		State:     change,
+	})
+}
+
+// Pause pauses the loop once the current iteration finishes
+func (c *Controller) Pause() {
+	c.pause(false)
+}
+
+// PauseNow pauses the loop immediately, interrupting the in-flight backend
+// call. The interrupted iteration runs again after Resume.
+func (c *Controller) PauseNow() {
+	c.pause(true)
+}
+
+func (c *Controller) pause(now bool) {
+	c.mu.Lock()
+	wasPaused := c.paused
+	c.paused = true
+	if c.state == RunStateRunning {
+		reason := "pause after iteration"
+		if now {
+			reason = "pause now"
+		}
+		c.setStateLocked(RunStatePausing, reason)
+	}
+	interrupted := now && c.interruptLocked(errPausedNow)
+	c.mu.Unlock()
+
+	if !wasPaused {
+		c.emitLog(LogLevelInfo, "Loop paused")
+	}
+	if interrupted {
+		c.emitLog(LogLevelInfo, "Interrupting the current iteration")
+	}
+}
+
+// Resume resumes a paused loop
+func (c *Controller) Resume() {
+	c.mu.Lock()
+	if !c.paused {
+		c.mu.Unlock()
+		return
+	}
+	c.paused = false
+	c.wakeLocked()
+	if c.state == RunStatePaused || c.state == RunStatePausing {
+		c.setStateLocked(RunStateRunning, "resumed")
+	}
+	c.mu.Unlock()
+
+	c.emitLog(LogLevelInfo, "Loop resumed")
+}
+
+// IsPaused returns whether the loop is paused
+func (c *Controller) IsPaused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused
+}
+
+// Stop signals the loop to stop after the current iteration
+func (c *Controller) Stop() {
+	c.StopAfterIteration()
+}
+
+// StopAfterIteration lets the current iteration finish and then ends Run. A
+// paused loop stops without running another iteration.
+func (c *Controller) StopAfterIteration() {
+	if active, _ := c.requestStop(false); active {
+		c.emitLog(LogLevelInfo, "Stop requested; the loop ends after the current iteration")
+	}
+}
+
+// Abort ends Run as soon as possible, cancelling the in-flight backend call
+func (c *Controller) Abort() {
+	if _, interrupted := c.requestStop(true); interrupted {
+		c.emitLog(LogLevelWarn, "Aborting the current iteration")
+	}
+}
+
+// requestStop marks the loop to stop, wakes it if it is waiting and, for an
+// abort, interrupts the backend call. It reports whether Run is active and
+// whether a call was interrupted.
+func (c *Controller) requestStop(abort bool) (active, interrupted bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.shouldStop = true
+	reason := "stop after iteration"
+	if abort {
+		c.aborted = true
+		reason = "abort"
+		interrupted = c.interruptLocked(errAborted)
+	}
+	c.paused = false
+	c.wakeLocked()
+	if c.state == RunStateIdle || c.state == RunStateStopped {
+		return false, interrupted
+	}
+	c.setStateLocked(RunStateStopping, reason)
+	return true, interrupted
+}
+
+// markStop records that the loop should stop at the next check
+func (c *Controller) markStop() {
+	c.mu.Lock()
+	c.shouldStop = true
+	c.mu.Unlock()
+}
+
+// stopRequested reports whether the loop should stop
+func (c *Controller) stopRequested() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.shouldStop
+}
+
+// SkipTask skips the task the current iteration is working on for the rest
+// of the session, interrupting the in-flight backend call. Between iterations
+// it skips the next open task. Skipped tasks are left out of the agent's
+// context and don't keep the loop running.
+func (c *Controller) SkipTask() {
+	c.mu.Lock()
+	task := c.currentTask
+	c.mu.Unlock()
+
+	if task == "" {
+		task = c.nextOpenTask()
+	}
+	if task == "" {
+		c.emitLog(LogLevelWarn, "No open task to skip")
+		return
+	}
+
+	c.mu.Lock()
+	c.skipped[task] = true
+	interrupted := task == c.currentTask && c.interruptLocked(errSkipped)
+	c.mu.Unlock()
+
+	c.emitLog(LogLevelInfo, fmt.Sprintf("Skipping task: %s", task))
+	if interrupted {
+		c.emitLog(LogLevelInfo, "Interrupting the current iteration")
+	}
+}
+
+// Steer adds standing guidance from the operator. It is included in every
+// iteration's context until ClearSteering, and sent into the live session
+// when the backend supports it and a call is in progress.
+func (c *Controller) Steer(message string) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return
+	}
+	c.mu.Lock()
+	c.steering = append(c.steering, message)
+	inCall := c.cancelCall != nil
+	c.mu.Unlock()
+
+	live := false
+	if steerer, ok := c.runner.(runner.Steerer); ok && inCall {
+		delivered, err := steerer.Steer(message)
+		if err != nil {
+			c.emitLog(LogLevelWarn, fmt.Sprintf("Could not send guidance into the live session: %v", err))
+		}
+		live = delivered
+	}
+
+	c.emit(LoopEvent{Type: EventTypeSteering, Steering: &Steering{Message: message, Live: live}})
+	if live {
+		c.emitLog(LogLevelInfo, fmt.Sprintf("Guidance sent to the live session: %s", message))
+	} else {
+		c.emitLog(LogLevelInfo, fmt.Sprintf("Guidance queued for the next iteration: %s", message))
+	}
+}
+
+// ClearSteering withdraws all standing guidance
+func (c *Controller) ClearSteering() {
+	c.mu.Lock()
+	cleared := len(c.steering)
+	c.steering = nil
+	c.mu.Unlock()
+
+	if cleared == 0 {
+		return
+	}
+	c.emit(LoopEvent{Type: EventTypeSteering, Steering: &Steering{Cleared: true}})
+	c.emitLog(LogLevelInfo, fmt.Sprintf("Cleared %d guidance message(s)", cleared))
+}
+
+// SkippedTasks returns the tasks skipped with SkipTask
+func (c *Controller) SkippedTasks() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var tasks []string
+	for task := range c.skipped {
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// isSkipped reports whether a plan task ("[ ] text") was skipped
+func (c *Controller) isSkipped(task string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.skipped[planTaskText(task)]
+}
+
+// openTasks returns the plan tasks that are neither done nor skipped
+func (c *Controller) openTasks(tasks []string) []string {
+	var open []string
+	for _, task := range tasks {
+		if !strings.HasPrefix(task, "[x]") && !c.isSkipped(task) {
+			open = append(open, task)
+		}
+	}
+	return open
+}
+
+// nextOpenTask returns the text of the first open, unskipped task in the plan
+func (c *Controller) nextOpenTask() string {
+	tasks, err := LoadPlan()
+	if err != nil {
+		return ""
+	}
+	if open := c.openTasks(tasks); len(open) > 0 {
+		return planTaskText(open[0])
+	}
+	return ""
+}
+
+// planTaskText strips the checkbox prefix from a parsed plan task
+func planTaskText(task string) string {
+	if len(task) >= 4 && task[0] == '[' && task[2] == ']' {
+		return task[4:]
+	}
+	return task
+}
+
+// beginCall derives the context for a backend call that operators can
+// interrupt. The returned function ends the call and returns the operator's
+// interrupt cause, if any.
+func (c *Controller) beginCall(ctx stdcontext.Context, task string) (stdcontext.Context, func() error) {
+	callCtx, cancel := stdcontext.WithCancelCause(ctx)
+
+	c.mu.Lock()
+	c.cancelCall = cancel
+	c.currentTask = task
+	c.mu.Unlock()
+
+	return callCtx, func() error {
+		c.mu.Lock()
+		c.cancelCall = nil
+		c.currentTask = ""
+		c.mu.Unlock()
+
+		cause := stdcontext.Cause(callCtx)
+		cancel(nil)
+		if errors.Is(cause, errAborted) || errors.Is(cause, errPausedNow) || errors.Is(cause, errSkipped) {
+			return cause
+		}
+		return nil
+	}
+}
+
+// interruptLocked cancels the in-flight backend call, if any
+func (c *Controller) interruptLocked(cause error) bool {
+	if c.cancelCall == nil {
+		return false
+	}
+	c.cancelCall(cause)
+	c.cancelCall = nil
+	return true
+}
+
+// wakeLocked releases anything waiting on the loop (a pause or retry delay)
+func (c *Controller) wakeLocked() {
+	close(c.wakeCh)
+	c.wakeCh = make(chan struct{})
+}
+
+// waitWhilePaused blocks while the loop is paused, returning ctx's error if
+// it is cancelled first
+func (c *Controller) waitWhilePaused(ctx stdcontext.Context) error {
+	for {
+		c.mu.Lock()
+		paused, wake := c.paused, c.wakeCh
+		if paused {
+			c.setStateLocked(RunStatePaused, "")
+		}
+		c.mu.Unlock()
+		if !paused {
+			return nil
+		}
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// sleep waits for d, returning early if the loop is woken or ctx is cancelled
+func (c *Controller) sleep(ctx stdcontext.Context, d time.Duration) {
+	c.mu.Lock()
+	wake := c.wakeCh
+	c.mu.Unlock()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-wake:
+	case <-ctx.Done():
+	}
+}
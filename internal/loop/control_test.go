@@ -0,0 +1,464 @@
+package loop
+
+import (
+	stdcontext "context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/backend"
+	"github.com/brainwhocodes/lisa-loop/internal/circuit"
+	"github.com/brainwhocodes/lisa-loop/internal/runner"
+)
+
+// scriptedRunner hands each call to onCall and records the prompts it was given
+type scriptedRunner struct {
+	mu      sync.Mutex
+	prompts []string
+	started chan int // Receives the call number as each call starts
+	onCall  func(ctx stdcontext.Context, call int) error
+}
+
+func (r *scriptedRunner) Run(ctx stdcontext.Context, prompt string) (string, string, error) {
+	r.mu.Lock()
+	r.prompts = append(r.prompts, prompt)
+	call := len(r.prompts)
+	r.mu.Unlock()
+
+	select {
+	case r.started <- call:
+	default:
+	}
+	return "done", "", r.onCall(ctx, call)
+}
+
+func (r *scriptedRunner) SetOutputCallback(cb runner.OutputCallback) {}
+
+func (r *scriptedRunner) Stop() error { return nil }
+
+func (r *scriptedRunner) prompt(call int) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.prompts[call-1]
+}
+
+// blockFirstCall blocks the first call until it is interrupted, then checks off
+// the named tasks in order on later calls
+func blockFirstCall(tasks ...string) *scriptedRunner {
+	return &scriptedRunner{
+		started: make(chan int, 16),
+		onCall: func(ctx stdcontext.Context, call int) error {
+			if call == 1 {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			if call-2 < len(tasks) {
+				return checkOff(tasks[call-2])
+			}
+			return nil
+		},
+	}
+}
+
+func checkOff(task string) error {
+	data, err := os.ReadFile("@fix_plan.md")
+	if err != nil {
+		return err
+	}
+	content := strings.Replace(string(data), "- [ ] "+task, "- [x] "+task, 1)
+	return os.WriteFile("@fix_plan.md", []byte(content), 0644)
+}
+
+// startRun runs the controller in the background and returns its result channel
+func startRun(c *Controller) chan error {
+	done := make(chan error, 1)
+	go func() { done <- c.Run(stdcontext.Background()) }()
+	return done
+}
+
+func waitStarted(t *testing.T, r *scriptedRunner) {
+	t.Helper()
+	select {
+	case <-r.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("backend call never started")
+	}
+}
+
+func waitState(t *testing.T, c *Controller, want RunState) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for c.State() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("State() = %s, want %s", c.State(), want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func waitDone(t *testing.T, done chan error) {
+	t.Helper()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return")
+	}
+}
+
+func newControlTestController(r runner.Runner) *Controller {
+	c := NewController(Config{MaxCalls: 10, Backend: "cli", RetryDelay: time.Millisecond}, NewRateLimiter(10, 1), circuit.NewBreaker(3, 5))
+	c.SetRunner(r)
+	return c
+}
+
+// stateTransitions returns the To state of each state change event
+func stateTransitions(events []LoopEvent) []RunState {
+	var states []RunState
+	for _, e := range events {
+		if e.Type == EventTypeStateChange {
+			states = append(states, e.State.To)
+		}
+	}
+	return states
+}
+
+func lastStatus(events []LoopEvent) string {
+	status := ""
+	for _, e := range events {
+		if e.Type == EventTypeLoopUpdate {
+			status = e.Update.Status
+		}
+	}
+	return status
+}
+
+func TestController_PauseNowInterruptsAndRerunsIteration(t *testing.T) {
+	setupHookProject(t)
+	fake := blockFirstCall("First task", "Second task")
+	c := newControlTestController(fake)
+	sub := c.Subscribe(4096, DropNewest)
+	defer sub.Unsubscribe()
+
+	done := startRun(c)
+	waitStarted(t, fake)
+	c.PauseNow()
+	waitState(t, c, RunStatePaused)
+
+	if !c.IsPaused() {
+		t.Error("IsPaused() = false after PauseNow()")
+	}
+	if c.breaker.GetState() != circuit.StateClosed {
+		t.Error("interrupted call was recorded as a circuit breaker failure")
+	}
+
+	c.Resume()
+	waitDone(t, done)
+
+	want := []RunState{RunStateRunning, RunStatePausing, RunStatePaused, RunStateRunning, RunStateStopped}
+	if got := stateTransitions(drainEvents(sub)); strings.Join(runStates(got), ",") != strings.Join(runStates(want), ",") {
+		t.Errorf("state transitions = %v, want %v", got, want)
+	}
+	if !strings.Contains(fake.prompt(2), "Loop: 1\n") {
+		t.Error("interrupted iteration was not run again with the same loop number")
+	}
+}
+
+func runStates(states []RunState) []string {
+	var out []string
+	for _, s := range states {
+		out = append(out, string(s))
+	}
+	return out
+}
+
+func TestController_AbortCancelsInFlightCall(t *testing.T) {
+	setupHookProject(t)
+	fake := blockFirstCall()
+	c := newControlTestController(fake)
+	sub := c.Subscribe(4096, DropNewest)
+	defer sub.Unsubscribe()
+
+	done := startRun(c)
+	waitStarted(t, fake)
+	c.Abort()
+	waitDone(t, done)
+
+	events := drainEvents(sub)
+	if status := lastStatus(events); status != "aborted" {
+		t.Errorf("final status = %q, want aborted", status)
+	}
+	if c.State() != RunStateStopped {
+		t.Errorf("State() = %s, want stopped", c.State())
+	}
+	if len(fake.prompts) != 1 {
+		t.Errorf("backend called %d times, want 1", len(fake.prompts))
+	}
+}
+
+func TestController_AbortWhilePaused(t *testing.T) {
+	setupHookProject(t)
+	fake := blockFirstCall()
+	c := newControlTestController(fake)
+
+	done := startRun(c)
+	waitStarted(t, fake)
+	c.PauseNow()
+	waitState(t, c, RunStatePaused)
+	c.Abort()
+	waitDone(t, done)
+}
+
+func TestController_SkipTask(t *testing.T) {
+	setupHookProject(t)
+	fake := blockFirstCall("Second task")
+	c := newControlTestController(fake)
+	sub := c.Subscribe(4096, DropNewest)
+	defer sub.Unsubscribe()
+
+	done := startRun(c)
+	waitStarted(t, fake)
+	c.SkipTask()
+	waitDone(t, done)
+
+	if got := c.SkippedTasks(); len(got) != 1 || got[0] != "First task" {
+		t.Errorf("SkippedTasks() = %v, want [First task]", got)
+	}
+	prompt := fake.prompt(2)
+	if !strings.Contains(prompt, "Skipped by the operator (do NOT work on these):\n  - First task") {
+		t.Errorf("next prompt does not list the skipped task:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "1. [ ] First task") {
+		t.Error("skipped task is still listed as remaining")
+	}
+	if status := lastStatus(drainEvents(sub)); status != "complete" {
+		t.Errorf("final status = %q, want complete once only skipped tasks remain", status)
+	}
+}
+
+func TestController_SetAsideTasksInPlan(t *testing.T) {
+	setupHookProject(t)
+	os.WriteFile("@fix_plan.md", []byte("- [-] Parked task\n- [ ] First task\n  - [!] Waiting task\n"), 0644)
+	fake := &scriptedRunner{onCall: func(stdcontext.Context, int) error { return checkOff("First task") }}
+	c := newControlTestController(fake)
+
+	waitDone(t, startRun(c))
+
+	prompt := fake.prompt(1)
+	if !strings.Contains(prompt, "Skipped by the operator (do NOT work on these):\n  - Parked task\n  - Waiting task (blocked)") {
+		t.Errorf("prompt does not list the plan's set-aside tasks:\n%s", prompt)
+	}
+	if len(fake.prompts) != 1 {
+		t.Errorf("backend called %d times, want 1 once only set-aside tasks remain", len(fake.prompts))
+	}
+}
+
+func TestController_SkipTaskBetweenIterations(t *testing.T) {
+	setupHookProject(t)
+	c := newControlTestController(&scriptedRunner{onCall: func(stdcontext.Context, int) error { return nil }})
+
+	c.SkipTask()
+	preflight, skip := c.RunPreflight()
+	if skip || preflight.RemainingCount != 1 {
+		t.Errorf("RunPreflight() remaining = %d (skip %v), want 1 after skipping the first task", preflight.RemainingCount, skip)
+	}
+}
+
+func TestController_StopAfterIteration(t *testing.T) {
+	setupHookProject(t)
+	var c *Controller
+	fake := &scriptedRunner{onCall: func(ctx stdcontext.Context, call int) error {
+		c.StopAfterIteration()
+		return checkOff("First task")
+	}}
+	c = newControlTestController(fake)
+	sub := c.Subscribe(4096, DropNewest)
+	defer sub.Unsubscribe()
+
+	if err := c.Run(stdcontext.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	events := drainEvents(sub)
+	if len(fake.prompts) != 1 {
+		t.Errorf("backend called %d times, want 1", len(fake.prompts))
+	}
+	if status := lastStatus(events); status != "stopped" {
+		t.Errorf("final status = %q, want stopped", status)
+	}
+	var outcomes int
+	for _, e := range events {
+		if e.Type == EventTypeOutcome && e.Outcome.Success {
+			outcomes++
+		}
+	}
+	if outcomes != 1 {
+		t.Errorf("successful outcomes = %d, want the current iteration to finish", outcomes)
+	}
+}
+
+func TestController_ConcurrentControl(t *testing.T) {
+	setupHookProject(t)
+	fake := &scriptedRunner{onCall: func(ctx stdcontext.Context, call int) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Millisecond):
+			return nil
+		}
+	}}
+	c := newControlTestController(fake)
+	sub := c.Subscribe(64, DropOldest)
+	defer sub.Unsubscribe()
+
+	done := startRun(c)
+	var wg sync.WaitGroup
+	controls := []func(){c.Pause, c.PauseNow, c.Resume, c.SkipTask, c.Resume, func() { c.IsPaused(); c.State() }}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				controls[(i+j)%len(controls)]()
+			}
+		}()
+	}
+	wg.Wait()
+	c.Abort()
+	waitDone(t, done)
+}
+
+func TestController_SteeringIsStandingGuidance(t *testing.T) {
+	setupHookProject(t)
+	var c *Controller
+	fake := &scriptedRunner{onCall: func(ctx stdcontext.Context, call int) error {
+		if call == 1 {
+			return checkOff("First task")
+		}
+		c.ClearSteering()
+		return checkOff("Second task")
+	}}
+	c = newControlTestController(fake)
+	sub := c.Subscribe(4096, DropNewest)
+	defer sub.Unsubscribe()
+
+	c.Steer("  Prefer the standard library  ")
+	c.Steer("")
+	if got := c.Status().Steering; len(got) != 1 || got[0] != "Prefer the standard library" {
+		t.Errorf("Status().Steering = %v, want the trimmed message", got)
+	}
+
+	if err := c.Run(stdcontext.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	for call := 1; call <= 2; call++ {
+		prompt := fake.prompt(call)
+		if !strings.Contains(prompt, "GUIDANCE FROM THE HUMAN OPERATOR") || !strings.Contains(prompt, "  - Prefer the standard library\n") {
+			t.Errorf("prompt %d does not include the standing guidance:\n%s", call, prompt)
+		}
+	}
+	if got := c.Status().Steering; len(got) != 0 {
+		t.Errorf("Status().Steering = %v after ClearSteering(), want none", got)
+	}
+
+	var steering []Steering
+	for _, e := range drainEvents(sub) {
+		if e.Type == EventTypeSteering {
+			steering = append(steering, *e.Steering)
+		}
+	}
+	want := []Steering{{Message: "Prefer the standard library"}, {Cleared: true}}
+	if len(steering) != len(want) || steering[0] != want[0] || steering[1] != want[1] {
+		t.Errorf("steering events = %+v, want %+v", steering, want)
+	}
+}
+
+// steerableRunner is a scriptedRunner whose backend accepts live guidance
+type steerableRunner struct {
+	*scriptedRunner
+	steered []string
+}
+
+func (r *steerableRunner) Steer(message string) (bool, error) {
+	r.steered = append(r.steered, message)
+	return true, nil
+}
+
+func TestController_SteerDuringCallGoesToLiveSession(t *testing.T) {
+	setupHookProject(t)
+	var c *Controller
+	fake := &steerableRunner{}
+	fake.scriptedRunner = &scriptedRunner{onCall: func(ctx stdcontext.Context, call int) error {
+		if call == 1 {
+			c.Steer("Use the helper in pkg/util")
+			c.StopAfterIteration()
+		}
+		return nil
+	}}
+	c = newControlTestController(fake)
+	sub := c.Subscribe(4096, DropNewest)
+	defer sub.Unsubscribe()
+
+	c.Steer("Before the run")
+	if err := c.Run(stdcontext.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(fake.steered) != 1 || fake.steered[0] != "Use the helper in pkg/util" {
+		t.Errorf("live guidance = %v, want only the message sent during the call", fake.steered)
+	}
+	var live []bool
+	for _, e := range drainEvents(sub) {
+		if e.Type == EventTypeSteering {
+			live = append(live, e.Steering.Live)
+		}
+	}
+	if len(live) != 2 || live[0] || !live[1] {
+		t.Errorf("steering events live = %v, want [false true]", live)
+	}
+	if got := c.Status().Steering; len(got) != 2 {
+		t.Errorf("Status().Steering = %v, want both messages kept as standing guidance", got)
+	}
+}
+
+func TestController_Status(t *testing.T) {
+	setupHookProject(t)
+	var c *Controller
+	var during Status
+	fake := &scriptedRunner{onCall: func(ctx stdcontext.Context, call int) error {
+		c.handleBackendEvent(backend.UsageReport(backend.Usage{TotalTokens: 500, ContextLimit: 1000, UsagePercent: 0.5}))
+		during = c.Status()
+		c.StopAfterIteration()
+		return checkOff("First task")
+	}}
+	c = newControlTestController(fake)
+
+	if got := c.Status(); got.State != RunStateIdle || got.Breaker == nil || got.RateLimit == nil {
+		t.Errorf("Status() before Run = %+v, want idle with breaker and rate limit stats", got)
+	}
+	if err := c.Run(stdcontext.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if during.State != RunStateRunning || during.Iteration != 1 || during.RunID == "" {
+		t.Errorf("Status() during the call = state %s iteration %d run %q", during.State, during.Iteration, during.RunID)
+	}
+	if during.Plan.Total != 2 || during.Plan.Remaining != 2 || during.Plan.CurrentTask != "First task" {
+		t.Errorf("Status().Plan during the call = %+v", during.Plan)
+	}
+	if during.Context == nil || during.Context.TotalTokens != 500 {
+		t.Errorf("Status().Context = %+v, want the reported usage", during.Context)
+	}
+
+	after := c.Status()
+	if after.State != RunStateStopped || after.Status != "stopped" {
+		t.Errorf("Status() after Run = state %s status %q, want stopped", after.State, after.Status)
+	}
+	if after.RateLimit["current_calls"] != 1 {
+		t.Errorf("Status().RateLimit current_calls = %v, want 1", after.RateLimit["current_calls"])
+	}
+}
@@ -2,16 +2,23 @@ package loop
 
 import (
 	stdcontext "context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/brainwhocodes/lisa-loop/internal/analysis"
+	"github.com/brainwhocodes/lisa-loop/internal/backend"
 	"github.com/brainwhocodes/lisa-loop/internal/circuit"
 	"github.com/brainwhocodes/lisa-loop/internal/codex"
 	"github.com/brainwhocodes/lisa-loop/internal/config"
 	"github.com/brainwhocodes/lisa-loop/internal/runner"
 	"github.com/brainwhocodes/lisa-loop/internal/state"
+	"github.com/brainwhocodes/lisa-loop/internal/testreport"
 )
 
 // Config is an alias to the unified config type
@@ -31,60 +38,19 @@ type PreflightSummary struct {
 	SkipReason     string   // Reason for skipping (if ShouldSkip is true)
 }
 
-// LoopEvent represents an event from the loop controller
-type LoopEvent struct {
-	Type         EventType
-	LoopNumber   int
-	CallsUsed    int
-	Status       string
-	LogMessage   string
-	LogLevel     LogLevel
-	CircuitState string
-
-	// Codex output streaming fields
-	OutputLine    string // Raw output line
-	OutputType    OutputType
-	ReasoningText string // Reasoning/thinking text
-	ToolName      string // Tool being called
-	ToolTarget    string // File path or command
-	ToolStatus    ToolStatus
-
-	// Analysis result fields (from RALPH_STATUS block)
-	AnalysisStatus  string  // WORKING, COMPLETE, BLOCKED
-	CurrentTask     string  // Current task being worked on or just completed
-	TasksCompleted  int     // Tasks completed this loop
-	FilesModified   int     // Files modified this loop
-	TestsStatus     string  // PASSING, FAILING, UNKNOWN
-	ExitSignal      bool    // Whether exit was signaled
-	ConfidenceScore float64 // Confidence in completion (0-1)
-
-	// Context tracking fields
-	ContextUsagePercent float64 // Current context window usage (0-1)
-	ContextTotalTokens  int     // Total tokens used
-	ContextLimit        int     // Context window limit
-	ContextThreshold    bool    // True if threshold reached
-	ContextWasCompacted bool    // True if OpenCode compacted the session
-
-	// Preflight summary
-	Preflight *PreflightSummary
-
-	// Loop outcome
-	Outcome *LoopOutcome
-}
-
 // LoopOutcome represents the result of a loop iteration
 type LoopOutcome struct {
-	Success        bool
-	TasksCompleted int
-	FilesModified  int
-	TestsStatus    string
-	ExitSignal     bool
-	Error          string
+	Success        bool   `json:"success"`
+	TasksCompleted int    `json:"tasks_completed"`
+	FilesModified  int    `json:"files_modified"`
+	TestsStatus    string `json:"tests_status"`
+	ExitSignal     bool   `json:"exit_signal"`
+	Error          string `json:"error,omitempty"`
+	DurationMS     int64  `json:"duration_ms,omitempty"` // Time from the backend call starting to the outcome
+
+	Tests *testreport.Summary `json:"tests,omitempty"` // Parsed test report, nil when verification is not configured
 }
 
-// EventCallback is called when the controller has an update
-type EventCallback func(event LoopEvent)
-
 // Controller manages the main Lisa loop
 type Controller struct {
 	config        ControllerConfig
@@ -94,11 +60,39 @@ type Controller struct {
 	runner        runner.Runner
 	loopNum       int
 	lastOutput    string
-	shouldStop    bool
-	eventCallback EventCallback
-	paused        bool
-	pauseCh       chan struct{} // Channel to signal resume
-	backend       string
+	lastWarnings  []string            // Reconciliation warnings fed into the next context
+	lastStatusErr []string            // JSON status validation errors fed into the next context
+	lastTests     *testreport.Summary // Test results fed into the next context
+	lastRejection *Review             // Rejected review fed into the next context
+	callStart     time.Time           // When the iteration's backend call started
+
+	bus     *Bus
+	backend string
+
+	// Shared with the control methods, which may be called from any goroutine.
+	// Everything below is guarded by mu.
+	mu          sync.Mutex
+	state       RunState
+	runID       string      // Identifies the current Run, stamped on every event
+	journal     *runJournal // Records the current Run's iterations, nil outside a Run
+	iteration   int         // Iteration in progress, stamped on every event
+	shouldStop  bool
+	aborted     bool
+	paused      bool
+	wakeCh      chan struct{}              // Closed to wake a paused or sleeping loop
+	cancelCall  stdcontext.CancelCauseFunc // Interrupts the in-flight backend call
+	currentTask string                     // Task the in-flight call is working on
+	skipped     map[string]bool            // Tasks skipped with SkipTask
+	// Human escalation: the loop pauses until Answer is called
+	pendingEscalation  *analysis.Escalation
+	answeredEscalation *analysis.Escalation // Escalation the pending answer responds to
+	humanAnswer        string               // Answer injected into the next context
+	steering           []string             // Standing operator guidance added to every context
+	status             Status               // Snapshot served by Status, refreshed by the loop
+	// Review mode: the loop pauses until Review is called
+	pendingReview  *Review
+	reviewDecision ReviewDecision // Set by Review, read when the loop wakes
+	reviewFeedback string
 
 	// Cached plan state (refreshed each loop iteration)
 	cachedMode     ProjectMode
@@ -125,22 +119,23 @@ func NewController(cfg Config, rateLimiter *RateLimiter, breaker *circuit.Breake
 			MaxDuration:   time.Duration(cfg.Timeout) * time.Second,
 			CheckInterval: 5 * time.Second,
 		},
-		cfg:           cfg,
-		rateLimiter:   rateLimiter,
-		breaker:       breaker,
-		runner:        r,
-		loopNum:       0,
-		lastOutput:    "",
-		shouldStop:    false,
-		eventCallback: nil,
-		paused:        false,
-		pauseCh:       make(chan struct{}),
-		backend:       cfg.Backend,
-	}
+		cfg:         cfg,
+		rateLimiter: rateLimiter,
+		breaker:     breaker,
+		runner:      r,
+		loopNum:     0,
+		lastOutput:  "",
+		bus:         NewBus(),
+		backend:     cfg.Backend,
+		state:       RunStateIdle,
+		wakeCh:      make(chan struct{}),
+		skipped:     make(map[string]bool),
+	}
+	c.refreshStatus("idle")
 
 	// Set up output callback for streaming
 	r.SetOutputCallback(func(event runner.Event) {
-		c.handleCodexEvent(codex.Event(event))
+		c.handleBackendEvent(event)
 	})
 
 	// Clear any existing session to start fresh
@@ -152,9 +147,12 @@ func NewController(cfg Config, rateLimiter *RateLimiter, breaker *circuit.Breake
 	return c
 }
 
-// SetEventCallback sets the callback for loop events
-func (c *Controller) SetEventCallback(cb EventCallback) {
-	c.eventCallback = cb
+// Subscribe registers a new event subscriber. Events are buffered up to
+// buffer (DefaultBufferSize if <= 0) and dropped per policy when the
+// subscriber falls behind, so a slow subscriber never blocks the loop.
+// Call Unsubscribe on the result when done.
+func (c *Controller) Subscribe(buffer int, policy DropPolicy) *Subscription {
+	return c.bus.Subscribe(buffer, policy)
 }
 
 // SetRunner injects a custom runner for testing
@@ -162,62 +160,63 @@ func (c *Controller) SetRunner(r runner.Runner) {
 	c.runner = r
 	// Set up output callback for the new runner
 	r.SetOutputCallback(func(event runner.Event) {
-		c.handleCodexEvent(codex.Event(event))
+		c.handleBackendEvent(event)
 	})
 }
 
-// emit sends an event to the callback if set
+// emit stamps an event with the current run and iteration and publishes it
 func (c *Controller) emit(event LoopEvent) {
-	if c.eventCallback != nil {
-		c.eventCallback(event)
-	}
+	c.mu.Lock()
+	event.RunID = c.runID
+	event.Iteration = c.iteration
+	journal := c.journal
+	c.mu.Unlock()
+	event.Time = time.Now()
+	journal.record(event)
+	c.bus.Publish(event)
 }
 
 // emitLog sends a log event
 func (c *Controller) emitLog(level LogLevel, message string) {
-	c.emit(LoopEvent{
-		Type:       EventTypeLog,
-		LogMessage: message,
-		LogLevel:   level,
-	})
+	c.emit(LoopEvent{Type: EventTypeLog, Log: &LogEntry{Level: level, Message: message}})
 }
 
-// emitUpdate sends a loop update event
-func (c *Controller) emitUpdate(status string) {
-	c.emit(LoopEvent{
-		Type:         EventTypeLoopUpdate,
-		LoopNumber:   c.loopNum,
+// loopUpdate snapshots the loop's progress
+func (c *Controller) loopUpdate(loopNumber int, status string) *LoopUpdate {
+	return &LoopUpdate{
+		LoopNumber:   loopNumber,
 		CallsUsed:    c.rateLimiter.CallsMade(),
 		Status:       status,
 		CircuitState: c.breaker.GetState().String(),
-	})
+	}
+}
+
+// emitUpdate sends a loop update event
+func (c *Controller) emitUpdate(status string) {
+	c.refreshStatus(status)
+	c.emit(LoopEvent{Type: EventTypeLoopUpdate, Update: c.loopUpdate(c.loopNum, status)})
 }
 
 // emitCodexOutput sends a codex output event
 func (c *Controller) emitCodexOutput(line string, outputType OutputType) {
-	c.emit(LoopEvent{
-		Type:       EventTypeCodexOutput,
-		OutputLine: line,
-		OutputType: outputType,
-	})
+	c.emit(LoopEvent{Type: EventTypeCodexOutput, Output: &OutputLine{Line: line, Type: outputType}})
 }
 
 // emitCodexReasoning sends a codex reasoning event
 func (c *Controller) emitCodexReasoning(text string) {
-	c.emit(LoopEvent{
-		Type:          EventTypeCodexReasoning,
-		ReasoningText: text,
-	})
+	c.emit(LoopEvent{Type: EventTypeCodexReasoning, Reasoning: &Reasoning{Text: text}})
 }
 
 // emitCodexTool sends a codex tool call event
-func (c *Controller) emitCodexTool(toolName, target string, status ToolStatus) {
-	c.emit(LoopEvent{
-		Type:       EventTypeCodexTool,
-		ToolName:   toolName,
-		ToolTarget: target,
-		ToolStatus: status,
-	})
+func (c *Controller) emitCodexTool(tool ToolCall) {
+	if len(tool.Output) > MaxToolOutput {
+		cut := MaxToolOutput
+		for cut > 0 && !utf8.RuneStart(tool.Output[cut]) {
+			cut--
+		}
+		tool.Output = tool.Output[:cut] + fmt.Sprintf("\n... (%d more bytes not shown)", len(tool.Output)-cut)
+	}
+	c.emit(LoopEvent{Type: EventTypeCodexTool, Tool: &tool})
 }
 
 // emitAnalysis sends analysis results from RALPH_STATUS block
@@ -226,105 +225,140 @@ func (c *Controller) emitAnalysis(result *analysis.Analysis) {
 		return
 	}
 
-	event := LoopEvent{
-		Type:            EventTypeAnalysis,
-		LoopNumber:      c.loopNum,
+	payload := &AnalysisResult{
 		ExitSignal:      result.ExitSignal,
 		ConfidenceScore: result.ConfidenceScore,
+		Warnings:        result.Warnings,
 	}
 
 	if result.Status != nil {
-		event.AnalysisStatus = result.Status.Status
-		event.CurrentTask = result.Status.CurrentTask
-		event.TasksCompleted = result.Status.TasksCompleted
-		event.FilesModified = result.Status.FilesModified
-		event.TestsStatus = result.Status.TestsStatus
+		payload.Status = result.Status.Status
+		payload.CurrentTask = result.Status.CurrentTask
+		payload.TasksCompleted = result.Status.TasksCompleted
+		payload.FilesModified = result.Status.FilesModified
+		payload.TestsStatus = result.Status.TestsStatus
 	}
 
-	c.emit(event)
+	c.emit(LoopEvent{Type: EventTypeAnalysis, Analysis: payload})
 }
 
 // emitPreflight sends a preflight summary event
 func (c *Controller) emitPreflight(summary *PreflightSummary) {
-	c.emit(LoopEvent{
-		Type:       EventTypePreflight,
-		LoopNumber: c.loopNum,
-		Preflight:  summary,
-	})
+	c.refreshStatus("")
+	c.emit(LoopEvent{Type: EventTypePreflight, Preflight: summary})
 }
 
 // emitOutcome sends a loop outcome event
 func (c *Controller) emitOutcome(outcome *LoopOutcome) {
-	c.emit(LoopEvent{
-		Type:    EventTypeOutcome,
-		Outcome: outcome,
-	})
+	c.emit(LoopEvent{Type: EventTypeOutcome, Outcome: outcome})
 }
 
 // emitContextUsage sends context window usage event
-func (c *Controller) emitContextUsage(usagePercent float64, totalTokens, limit int, thresholdReached, wasCompacted bool) {
-	c.emit(LoopEvent{
-		Type:                EventTypeContextUsage,
-		LoopNumber:          c.loopNum,
-		ContextUsagePercent: usagePercent,
-		ContextTotalTokens:  totalTokens,
-		ContextLimit:        limit,
-		ContextThreshold:    thresholdReached,
-		ContextWasCompacted: wasCompacted,
-	})
+func (c *Controller) emitContextUsage(usage *ContextUsage) {
+	c.emit(LoopEvent{Type: EventTypeContextUsage, Context: usage})
 }
 
-// Pause pauses the loop
-func (c *Controller) Pause() {
-	if !c.paused {
-		c.paused = true
-		c.emitLog(LogLevelInfo, "Loop paused")
+// escalate pauses the loop until a human answers the agent's blockers or questions
+func (c *Controller) escalate(esc *analysis.Escalation) {
+	c.mu.Lock()
+	c.pendingEscalation = esc
+	c.mu.Unlock()
+	for _, blocker := range esc.Blockers {
+		c.emitLog(LogLevelWarn, fmt.Sprintf("Agent blocker: %s", blocker))
 	}
-}
-
-// Resume resumes the loop
-func (c *Controller) Resume() {
-	if c.paused {
-		c.paused = false
-		close(c.pauseCh)
-		c.pauseCh = make(chan struct{})
-		c.emitLog(LogLevelInfo, "Loop resumed")
+	for _, question := range esc.Questions {
+		c.emitLog(LogLevelWarn, fmt.Sprintf("Agent question: %s", question))
 	}
+	c.Pause()
+	c.emitUpdate("awaiting_answer")
+	c.emit(LoopEvent{Type: EventTypeEscalation, Escalation: esc})
 }
 
-// IsPaused returns whether the loop is paused
-func (c *Controller) IsPaused() bool {
-	return c.paused
+// PendingEscalation returns the blockers and questions awaiting an answer, or nil
+func (c *Controller) PendingEscalation() *analysis.Escalation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pendingEscalation
 }
 
-// Stop signals the loop to stop
-func (c *Controller) Stop() {
-	c.shouldStop = true
+// Answer records the human's answer to the pending escalation and resumes the loop.
+// The answer is injected into the next iteration's context.
+func (c *Controller) Answer(answer string) {
+	answer = strings.TrimSpace(answer)
+	c.mu.Lock()
+	if c.pendingEscalation != nil {
+		c.answeredEscalation = c.pendingEscalation
+		c.pendingEscalation = nil
+	}
+	c.humanAnswer = answer
+	c.mu.Unlock()
+	if answer != "" {
+		c.emitLog(LogLevelInfo, "Answer received; it will be included in the next loop")
+	}
+	c.Resume()
 }
 
 // Run executes the main loop
 func (c *Controller) Run(ctx stdcontext.Context) error {
+	c.mu.Lock()
+	c.runID = newRunID()
+	c.iteration = c.loopNum
+	c.shouldStop = false
+	c.aborted = false
+	c.mu.Unlock()
+	c.setState(RunStateRunning, "")
+	defer c.setState(RunStateStopped, "")
+	c.openJournal()
+	defer c.closeJournal(ctx)
+
 	c.emitLog(LogLevelInfo, fmt.Sprintf("Starting Lisa Codex loop (max %d calls)", c.config.MaxLoops))
 	c.emitUpdate("starting")
 
+	if c.cfg.Review {
+		if err := CheckReviewable(); err != nil {
+			c.emitLog(LogLevelError, fmt.Sprintf("Run aborted: %v", err))
+			c.emitUpdate("error")
+			return err
+		}
+		c.emitLog(LogLevelInfo, "Review mode: each iteration's changes wait for approval")
+	}
+
+	if err := c.runHook(ctx, HookPayload{Hook: HookPreRun, Event: c.hookEvent(c.loopNum+1, "starting")}); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		c.emitLog(LogLevelError, fmt.Sprintf("Run aborted: %v", err))
+		c.emitUpdate("vetoed")
+		_ = c.activeJournal().end("vetoed", err.Error())
+		return fmt.Errorf("%w: %v", ErrHookVeto, err)
+	}
+
 	for {
 		// Check if paused - wait for resume or context cancellation
-		if c.paused {
-			select {
-			case <-c.pauseCh:
-				// Resumed
-			case <-ctx.Done():
-				c.emitLog(LogLevelWarn, "Loop cancelled while paused")
-				c.emitUpdate("cancelled")
-				return ctx.Err()
-			}
+		if err := c.waitWhilePaused(ctx); err != nil {
+			c.emitLog(LogLevelWarn, "Loop cancelled while paused")
+			c.emitUpdate("cancelled")
+			return err
 		}
 
-		if c.shouldStop {
+		c.mu.Lock()
+		stop, aborted := c.shouldStop, c.aborted
+		c.mu.Unlock()
+		if aborted {
+			c.emitLog(LogLevelWarn, "Loop aborted")
+			c.emitUpdate("aborted")
+			c.finishRun(ctx, c.loopNum, "aborted", "Loop aborted")
+			return nil
+		}
+		if stop {
 			c.emitLog(LogLevelSuccess, "Loop stopped")
 			c.emitUpdate("stopped")
+			c.finishRun(ctx, c.loopNum, "stopped", "Loop stopped")
 			return nil
 		}
+		c.mu.Lock()
+		c.iteration = c.loopNum + 1
+		c.mu.Unlock()
 
 		select {
 		case <-ctx.Done():
@@ -341,7 +375,7 @@ func (c *Controller) Run(ctx stdcontext.Context) error {
 			if shouldSkip {
 				c.emitLog(LogLevelInfo, fmt.Sprintf("Skipped: %s", preflight.SkipReason))
 				c.emitUpdate("skipped")
-				c.shouldStop = true
+				c.markStop()
 
 				// Emit outcome event so TUI knows we're done
 				c.emitOutcome(&LoopOutcome{
@@ -349,20 +383,42 @@ func (c *Controller) Run(ctx stdcontext.Context) error {
 					ExitSignal:     true,
 					TasksCompleted: preflight.TotalTasks - preflight.RemainingCount,
 				})
+				c.finishRun(ctx, c.loopNum, "skipped", preflight.SkipReason)
 
 				return nil
 			}
 
+			if err := c.runHook(ctx, HookPayload{Hook: HookPreIteration, Event: c.hookEvent(c.loopNum+1, "running")}); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				c.emitLog(LogLevelWarn, fmt.Sprintf("Loop %d vetoed: %v", c.loopNum+1, err))
+				c.emitUpdate("vetoed")
+				c.markStop()
+				c.finishRun(ctx, c.loopNum, "vetoed", err.Error())
+				return fmt.Errorf("%w: %v", ErrHookVeto, err)
+			}
+
 			// Execute one iteration
 			err := c.ExecuteLoop(ctx)
 
+			if errors.Is(err, ErrInterrupted) {
+				// A paused iteration runs again on resume; a skipped one moves on
+				if errors.Is(err, errSkipped) {
+					c.loopNum++
+				}
+				continue
+			}
+
 			if err != nil {
 				c.emitLog(LogLevelError, fmt.Sprintf("Loop iteration error: %v", err))
 				c.emitUpdate("error")
+				c.runNotifyHook(ctx, HookPayload{Hook: HookOnError, Event: c.hookEvent(c.loopNum+1, "error"), Error: err.Error()})
 				// Don't return on error - start a new loop iteration instead
 				// This handles message.error and other transient failures
-				c.emitLog(LogLevelInfo, "Waiting 5s before retrying...")
-				time.Sleep(5 * time.Second)
+				delay := c.retryDelay()
+				c.emitLog(LogLevelInfo, fmt.Sprintf("Waiting %s before retrying...", delay))
+				c.sleep(ctx, delay)
 				c.emitLog(LogLevelInfo, "Starting new loop iteration after error...")
 				c.loopNum++
 				continue
@@ -372,6 +428,7 @@ func (c *Controller) Run(ctx stdcontext.Context) error {
 			if c.ShouldContinue() {
 				c.emitLog(LogLevelSuccess, fmt.Sprintf("Lisa Codex loop complete after %d iterations", c.loopNum))
 				c.emitUpdate("complete")
+				c.finishRun(ctx, c.loopNum+1, "complete", "Loop complete")
 				return nil
 			}
 
@@ -386,6 +443,13 @@ func (c *Controller) Run(ctx stdcontext.Context) error {
 	}
 }
 
+// newRunID returns a sortable, practically unique identifier for a run
+func newRunID() string {
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	return time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(b)
+}
+
 // refreshPlanCache reloads plan data once per loop iteration
 func (c *Controller) refreshPlanCache() {
 	c.cachedMode = DetectProjectMode()
@@ -400,6 +464,14 @@ func (c *Controller) refreshPlanCache() {
 	c.cacheValid = true
 }
 
+// retryDelay is how long to wait before retrying a failed iteration
+func (c *Controller) retryDelay() time.Duration {
+	if c.cfg.RetryDelay > 0 {
+		return c.cfg.RetryDelay
+	}
+	return 5 * time.Second
+}
+
 // RunPreflight performs preflight checks and returns a summary
 func (c *Controller) RunPreflight() (*PreflightSummary, bool) {
 	// Refresh cache if needed
@@ -422,13 +494,8 @@ func (c *Controller) RunPreflight() (*PreflightSummary, bool) {
 		}, true
 	}
 
-	// Count remaining tasks
-	remainingTasks := []string{}
-	for _, task := range tasks {
-		if !strings.HasPrefix(task, "[x]") {
-			remainingTasks = append(remainingTasks, task)
-		}
-	}
+	// Count remaining tasks; skipped tasks don't keep the loop running
+	remainingTasks := c.openTasks(tasks)
 
 	// Get circuit breaker state
 	circuitState := c.breaker.GetState().String()
@@ -441,7 +508,10 @@ func (c *Controller) RunPreflight() (*PreflightSummary, bool) {
 	shouldSkip := false
 	skipReason := ""
 
-	if len(remainingTasks) == 0 {
+	if len(remainingTasks) == 0 && len(c.SkippedTasks()) > 0 {
+		shouldSkip = true
+		skipReason = "All tasks complete or skipped"
+	} else if len(remainingTasks) == 0 {
 		shouldSkip = true
 		skipReason = "All tasks complete"
 	} else if c.breaker.ShouldHalt() {
@@ -516,14 +586,35 @@ func (c *Controller) ExecuteLoop(ctx stdcontext.Context) error {
 
 	// Build context
 	circuitState := c.breaker.GetState().String()
-	remainingTasks := []string{}
+	remainingTasks := c.openTasks(tasks)
+	var skippedTasks []string
 	for _, task := range tasks {
-		if !strings.HasPrefix(task, "[x]") {
-			remainingTasks = append(remainingTasks, task)
+		if !strings.HasPrefix(task, "[x]") && c.isSkipped(task) {
+			skippedTasks = append(skippedTasks, planTaskText(task))
 		}
 	}
-
-	loopContext, err := BuildContextWithPlanFile(c.loopNum+1, remainingTasks, circuitState, c.lastOutput, planFile)
+	skippedTasks = append(skippedTasks, setAsideTasks(planFile)...)
+	currentTask := ""
+	if len(remainingTasks) > 0 {
+		currentTask = planTaskText(remainingTasks[0])
+	}
+
+	c.mu.Lock()
+	answeredEscalation, humanAnswer := c.answeredEscalation, c.humanAnswer
+	steering := append([]string(nil), c.steering...)
+	c.mu.Unlock()
+
+	loopContext, err := BuildContextWithOptions(c.loopNum+1, remainingTasks, circuitState, c.lastOutput, ContextOptions{
+		PlanFile:     planFile,
+		Warnings:     c.lastWarnings,
+		StatusErrors: c.lastStatusErr,
+		TestSummary:  c.lastTests,
+		Escalation:   answeredEscalation,
+		HumanAnswer:  humanAnswer,
+		SkippedTasks: skippedTasks,
+		Steering:     steering,
+		Rejection:    c.lastRejection,
+	})
 	if err != nil {
 		c.emitLog(LogLevelError, fmt.Sprintf("Failed to build context: %v", err))
 		c.emitUpdate("error")
@@ -532,13 +623,45 @@ func (c *Controller) ExecuteLoop(ctx stdcontext.Context) error {
 
 	promptWithContext := InjectContext(prompt, loopContext)
 
+	c.activeJournal().beginIteration(ctx, c.loopNum+1, currentTask)
+
 	// Execute runner (Codex CLI or OpenCode)
 	backendName := c.cfg.BackendDisplayName()
 	c.emitLog(LogLevelInfo, fmt.Sprintf("Loop %d: Executing %s", c.loopNum+1, backendName))
 	c.emitUpdate("codex_running")
 	c.emitCodexOutput(fmt.Sprintf("Starting %s execution (loop %d)...", backendName, c.loopNum+1), OutputTypeRaw)
 	c.emitCodexOutput(fmt.Sprintf("Prompt size: %d bytes", len(promptWithContext)), OutputTypeRaw)
-	output, _, err := c.runner.Run(promptWithContext)
+
+	// Snapshot the working tree so claims can be reconciled after the run
+	loopStart := time.Now()
+	c.callStart = loopStart
+	filesBefore, snapErr := TakeFileSnapshot()
+	if snapErr != nil {
+		c.emitLog(LogLevelDebug, fmt.Sprintf("File snapshot unavailable: %v", snapErr))
+	}
+
+	callCtx, endCall := c.beginCall(ctx, currentTask)
+	output, _, err := c.runner.Run(callCtx, promptWithContext)
+	if cause := endCall(); cause != nil {
+		// The operator interrupted the call; it is neither progress nor a backend failure
+		if rlErr := c.rateLimiter.RecordCall(); rlErr != nil {
+			c.emitLog(LogLevelWarn, fmt.Sprintf("Failed to record call: %v", rlErr))
+		}
+		c.emitLog(LogLevelWarn, fmt.Sprintf("Loop %d interrupted: %v", c.loopNum+1, cause))
+		c.emitUpdate("interrupted")
+		c.cacheValid = false
+		return fmt.Errorf("%w: %w", ErrInterrupted, cause)
+	}
+
+	// The answer and any rejection have been delivered unless a new answer
+	// arrived during the call
+	c.lastRejection = nil
+	c.mu.Lock()
+	if c.humanAnswer == humanAnswer {
+		c.answeredEscalation = nil
+		c.humanAnswer = ""
+	}
+	c.mu.Unlock()
 
 	if err != nil {
 		// Don't pass error messages as prevSummary - they confuse the AI
@@ -554,9 +677,10 @@ func (c *Controller) ExecuteLoop(ctx stdcontext.Context) error {
 		}
 		c.emitLog(LogLevelError, fmt.Sprintf("Codex execution failed: %v", err))
 		c.emitUpdate("execution_error")
+		c.checkBreakerOpened(ctx)
 
 		// Emit outcome event for error case
-		c.emitOutcome(&LoopOutcome{
+		c.finishIteration(ctx, &LoopOutcome{
 			Success: false,
 			Error:   err.Error(),
 		})
@@ -591,14 +715,57 @@ func (c *Controller) ExecuteLoop(ctx stdcontext.Context) error {
 		c.emitLog(LogLevelWarn, fmt.Sprintf("Output analysis failed: %v", err))
 	}
 
+	// Echo JSON status validation errors so the agent corrects its format next loop
+	c.lastStatusErr = nil
+	if analysisResult != nil && len(analysisResult.ValidationErrors) > 0 {
+		c.lastStatusErr = analysisResult.ValidationErrors
+		for _, verr := range analysisResult.ValidationErrors {
+			c.emitLog(LogLevelWarn, fmt.Sprintf("Invalid lisa-status block: %s", verr))
+		}
+	}
+
+	// Reconcile the agent's claims against the plan and git before acting on them
+	c.lastWarnings = nil
+	truth := collectGroundTruth(planFile, tasks, filesBefore)
+	c.fireTaskHooks(ctx, planFile, tasks)
+	verifyCtx, endVerify := c.beginCall(ctx, currentTask)
+	c.lastTests = c.runVerification(verifyCtx, loopStart)
+	if cause := endVerify(); cause != nil {
+		c.emitLog(LogLevelWarn, fmt.Sprintf("Loop %d interrupted: %v", c.loopNum+1, cause))
+		c.emitUpdate("interrupted")
+		c.cacheValid = false
+		return fmt.Errorf("%w: %w", ErrInterrupted, cause)
+	}
+	truth.TestsStatus = c.lastTests.TestsStatus()
+	if analysisResult != nil {
+		for _, d := range analysis.Reconcile(analysisResult, truth) {
+			c.emitLog(LogLevelWarn, fmt.Sprintf("Status discrepancy: %s", d.Message))
+		}
+		c.lastWarnings = analysisResult.Warnings
+	}
+
+	var escalation *analysis.Escalation
+	if analysisResult != nil && analysisResult.Status != nil {
+		c.applyStatusReport(analysisResult.Status, planFile)
+		escalation = analysis.ExtractEscalation(analysisResult.Status)
+	}
+
 	// Determine hasErrors and filesChanged from analysis
 	hasErrors := false
 	filesChanged := 0
 	if analysisResult != nil {
 		hasErrors = analysisResult.HasErrors
+		// A blocked agent waiting on a human is not a failure of the loop itself
+		if escalation != nil && analysisResult.Status.Status == "BLOCKED" {
+			hasErrors = len(analysisResult.ErrorMessages) > 0 || analysisResult.Status.TestsStatus == "FAILING"
+		}
 		if analysisResult.Status != nil {
 			filesChanged = analysisResult.Status.FilesModified
 		}
+		// Prefer the observed file count for progress detection
+		if truth.GitKnown {
+			filesChanged = truth.FilesChanged
+		}
 
 		// Emit analysis results to UI
 		c.emitAnalysis(analysisResult)
@@ -608,13 +775,14 @@ func (c *Controller) ExecuteLoop(ctx stdcontext.Context) error {
 			c.emitLog(LogLevelSuccess, "✓ EXIT_SIGNAL: true - Work complete!")
 			exitSignals = append(exitSignals, fmt.Sprintf("loop_%d", c.loopNum+1))
 			_ = state.SaveExitSignals(exitSignals)
-			c.shouldStop = true
+			c.markStop()
 		}
 
-		// Check for completion based on confidence
-		if analysisResult.ConfidenceScore >= 0.9 && analysisResult.Status != nil && analysisResult.Status.Status == "COMPLETE" {
+		// Check for completion based on confidence, never while the plan has unchecked tasks
+		if analysisResult.ConfidenceScore >= 0.9 && analysisResult.Status != nil && analysisResult.Status.Status == "COMPLETE" &&
+			truth.RemainingTasks == 0 {
 			c.emitLog(LogLevelSuccess, "✓ High-confidence completion detected (STATUS: COMPLETE)")
-			c.shouldStop = true
+			c.markStop()
 		}
 	}
 
@@ -625,7 +793,7 @@ func (c *Controller) ExecuteLoop(ctx stdcontext.Context) error {
 		c.emitUpdate("error")
 
 		// Emit outcome event for error case
-		c.emitOutcome(&LoopOutcome{
+		c.finishIteration(ctx, &LoopOutcome{
 			Success: false,
 			Error:   err.Error(),
 		})
@@ -636,14 +804,26 @@ func (c *Controller) ExecuteLoop(ctx stdcontext.Context) error {
 	// Emit outcome event for success case
 	outcome := &LoopOutcome{
 		Success:    true,
-		ExitSignal: c.shouldStop,
+		ExitSignal: c.stopRequested(),
 	}
 	if analysisResult != nil && analysisResult.Status != nil {
 		outcome.TasksCompleted = analysisResult.Status.TasksCompleted
 		outcome.FilesModified = analysisResult.Status.FilesModified
 		outcome.TestsStatus = analysisResult.Status.TestsStatus
 	}
-	c.emitOutcome(outcome)
+	outcome.Tests = c.lastTests
+	c.checkBreakerOpened(ctx)
+	c.finishIteration(ctx, outcome)
+
+	if c.cfg.Review {
+		if err := c.reviewIteration(ctx, currentTask); err != nil {
+			return err
+		}
+	}
+
+	if escalation != nil && !c.stopRequested() {
+		c.escalate(escalation)
+	}
 
 	// Invalidate cache so next iteration reloads plan
 	c.cacheValid = false
@@ -651,6 +831,21 @@ func (c *Controller) ExecuteLoop(ctx stdcontext.Context) error {
 	return nil
 }
 
+// applyStatusReport acts on the structured parts of the agent's status report
+func (c *Controller) applyStatusReport(status *analysis.RALPHStatus, planFile string) {
+	if len(status.FollowUpTasks) == 0 || planFile == "" {
+		return
+	}
+	added, err := AppendFollowUpTasks(planFile, status.FollowUpTasks)
+	if err != nil {
+		c.emitLog(LogLevelWarn, fmt.Sprintf("Failed to append follow-up tasks: %v", err))
+		return
+	}
+	if added > 0 {
+		c.emitLog(LogLevelInfo, fmt.Sprintf("Appended %d follow-up task(s) to %s", added, planFile))
+	}
+}
+
 // ShouldContinue checks if the loop should continue
 func (c *Controller) ShouldContinue() bool {
 	tasks, err := LoadPlan()
@@ -658,35 +853,27 @@ func (c *Controller) ShouldContinue() bool {
 		return false
 	}
 
-	// Check if all tasks are complete
-	allComplete := true
-	for _, task := range tasks {
-		if !strings.HasPrefix(task, "[x]") {
-			allComplete = false
-			break
-		}
-	}
-
-	if allComplete {
-		c.shouldStop = true
+	// Check if all tasks are complete (or skipped)
+	if len(c.openTasks(tasks)) == 0 {
+		c.markStop()
 		return true
 	}
 
 	// Check circuit breaker
 	if c.breaker.ShouldHalt() {
-		c.shouldStop = true
+		c.markStop()
 		return true
 	}
 
 	// Check rate limit
 	if !c.rateLimiter.CanMakeCall() {
-		c.shouldStop = true
+		c.markStop()
 		return true
 	}
 
 	// Check max loops
 	if c.loopNum >= c.config.MaxLoops {
-		c.shouldStop = true
+		c.markStop()
 		return true
 	}
 
@@ -697,7 +884,7 @@ func (c *Controller) ShouldContinue() bool {
 func (c *Controller) GracefulExit() error {
 	fmt.Println("\n🧹 Performing graceful exit...")
 
-	c.shouldStop = true
+	c.markStop()
 
 	// Stop the runner (shuts down managed servers if any)
 	if c.runner != nil {
@@ -724,77 +911,83 @@ func (c *Controller) GracefulExit() error {
 func (c *Controller) GetStats() map[string]interface{} {
 	return map[string]interface{}{
 		"loop_num":        c.loopNum,
-		"should_stop":     c.shouldStop,
+		"should_stop":     c.stopRequested(),
 		"rate_limiter":    c.rateLimiter.GetStats(),
 		"circuit_breaker": c.breaker.GetStats(),
 		"last_output":     c.lastOutput,
 	}
 }
 
-// handleCodexEvent processes streaming events from codex and emits them to TUI
-// Uses the unified event parser from the codex package
-func (c *Controller) handleCodexEvent(event codex.Event) {
-	// Debug: log raw event type
-	eventType, _ := event["type"].(string)
-	if eventType != "" {
-		c.emitLog(LogLevelDebug, fmt.Sprintf("SSE event: %s", eventType))
+// handleBackendEvent forwards a streaming backend event to subscribers
+func (c *Controller) handleBackendEvent(event backend.Event) {
+	c.emitLog(LogLevelDebug, fmt.Sprintf("Backend event: %s", event.Kind))
+	if event.Text != "" {
+		c.emitLog(LogLevelDebug, fmt.Sprintf("Parsed: kind=%s text=%d chars", event.Kind, len(event.Text)))
+		c.emitLog(LogLevelDebug, fmt.Sprintf("Message: %s", event.Text))
 	}
 
-	// Handle context usage events directly (not parsed by codex parser)
-	if eventType == "context.usage" {
-		usagePercent, _ := event["usage_percent"].(float64)
-		totalTokens, _ := event["total_tokens"].(float64)
-		limit, _ := event["context_limit"].(float64)
-		thresholdReached, _ := event["threshold_reached"].(bool)
-		wasCompacted, _ := event["was_compacted"].(bool)
-		c.emitContextUsage(usagePercent, int(totalTokens), int(limit), thresholdReached, wasCompacted)
-		return
-	}
-
-	parsed := codex.ParseEvent(event)
-	if parsed == nil {
-		return
-	}
-
-	// Debug: log parsed type
-	if parsed.Text != "" || parsed.ToolName != "" {
-		c.emitLog(LogLevelDebug, fmt.Sprintf("Parsed: type=%s text=%d chars", parsed.Type, len(parsed.Text)))
-		if parsed.Text != "" {
-			c.emitLog(LogLevelDebug, fmt.Sprintf("Message: %s", parsed.Text))
+	switch event.Kind {
+	case backend.KindReasoning:
+		if event.Text != "" {
+			c.emitCodexReasoning(event.Text)
 		}
-	}
 
-	// Emit based on parsed event type
-	switch parsed.Type {
-	case "reasoning":
-		if parsed.Text != "" {
-			c.emitCodexReasoning(parsed.Text)
+	case backend.KindMessageDelta:
+		if event.Text != "" {
+			c.emitCodexOutput(event.Text, OutputTypeAgentMessage)
 		}
 
-	case "message", "delta":
-		if parsed.Text != "" {
-			c.emitCodexOutput(parsed.Text, OutputTypeAgentMessage)
-		}
-
-	case "tool_call", "tool_result":
-		if parsed.ToolName != "" {
+	case backend.KindToolStart, backend.KindToolEnd:
+		if event.Tool != nil && event.Tool.Name != "" {
 			status := ToolStatusStarted
-			if parsed.ToolStatus == "completed" {
+			if event.Kind == backend.KindToolEnd {
 				status = ToolStatusCompleted
 			}
-			c.emitCodexTool(parsed.ToolName, parsed.ToolTarget, status)
+			c.emitCodexTool(ToolCall{
+				ID:       event.Tool.ID,
+				Name:     event.Tool.Name,
+				Target:   event.Tool.Target,
+				Status:   status,
+				Input:    event.Tool.Input,
+				Output:   event.Tool.Output,
+				ExitCode: event.Tool.ExitCode,
+				Error:    event.Tool.Error,
+			})
+		}
+
+	case backend.KindFileChange:
+		// Reported as a completed patch so file tracking treats it like an edit
+		if event.File != nil && event.File.Path != "" {
+			c.emitCodexTool(ToolCall{Name: "apply_patch", Target: event.File.Path, Status: ToolStatusCompleted})
 		}
 
-	case "lifecycle":
-		// Lifecycle events (start, stop, etc.) - just show the type
-		if parsed.RawType != "" {
-			c.emitCodexOutput(fmt.Sprintf(">>> %s", parsed.RawType), OutputTypeRaw)
+	case backend.KindUsage:
+		if u := event.Usage; u != nil {
+			usage := &ContextUsage{
+				UsagePercent:     u.UsagePercent,
+				TotalTokens:      u.TotalTokens,
+				Limit:            u.ContextLimit,
+				ThresholdReached: u.ThresholdReached,
+				WasCompacted:     u.WasCompacted,
+				Cost:             u.Cost,
+			}
+			c.mu.Lock()
+			c.status.Context = usage
+			c.mu.Unlock()
+			c.emitContextUsage(usage)
 		}
 
-	default:
-		// Unknown event with text
-		if parsed.Text != "" {
-			c.emitCodexOutput(parsed.Text, OutputTypeRaw)
+	case backend.KindLifecycle:
+		switch {
+		case event.Text != "":
+			c.emitCodexOutput(event.Text, OutputTypeRaw)
+		case event.Lifecycle != nil && event.Lifecycle.Status != "":
+			c.emitCodexOutput(fmt.Sprintf(">>> %s: %s", event.Lifecycle.Phase, event.Lifecycle.Status), OutputTypeRaw)
+		case event.Lifecycle != nil:
+			c.emitCodexOutput(fmt.Sprintf(">>> %s", event.Lifecycle.Phase), OutputTypeRaw)
 		}
+
+	case backend.KindError:
+		c.emitLog(LogLevelError, fmt.Sprintf("Backend error: %s", event.Text))
 	}
 }
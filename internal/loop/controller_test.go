@@ -1,10 +1,13 @@
 package loop
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/brainwhocodes/lisa-loop/internal/backend"
 	"github.com/brainwhocodes/lisa-loop/internal/circuit"
 )
 
@@ -199,12 +202,8 @@ func TestEmitPreflight(t *testing.T) {
 	controller := NewController(cfg, rateLimiter, breaker)
 
 	// Set up event capture
-	var capturedEvent *LoopEvent
-	controller.SetEventCallback(func(event LoopEvent) {
-		if event.Type == EventTypePreflight {
-			capturedEvent = &event
-		}
-	})
+	sub := controller.Subscribe(16, DropNewest)
+	defer sub.Unsubscribe()
 
 	summary := &PreflightSummary{
 		Mode:           "fix",
@@ -218,6 +217,13 @@ func TestEmitPreflight(t *testing.T) {
 
 	controller.emitPreflight(summary)
 
+	var capturedEvent *LoopEvent
+	for _, event := range drainEvents(sub) {
+		if event.Type == EventTypePreflight {
+			capturedEvent = &event
+		}
+	}
+
 	if capturedEvent == nil {
 		t.Fatal("emitPreflight() did not capture event")
 	}
@@ -247,12 +253,8 @@ func TestEmitOutcome(t *testing.T) {
 	controller := NewController(cfg, rateLimiter, breaker)
 
 	// Set up event capture
-	var capturedEvent *LoopEvent
-	controller.SetEventCallback(func(event LoopEvent) {
-		if event.Type == EventTypeOutcome {
-			capturedEvent = &event
-		}
-	})
+	sub := controller.Subscribe(16, DropNewest)
+	defer sub.Unsubscribe()
 
 	outcome := &LoopOutcome{
 		Success:        true,
@@ -264,6 +266,13 @@ func TestEmitOutcome(t *testing.T) {
 
 	controller.emitOutcome(outcome)
 
+	var capturedEvent *LoopEvent
+	for _, event := range drainEvents(sub) {
+		if event.Type == EventTypeOutcome {
+			capturedEvent = &event
+		}
+	}
+
 	if capturedEvent == nil {
 		t.Fatal("emitOutcome() did not capture event")
 	}
@@ -328,7 +337,7 @@ func TestRateLimiter_RecordSuccessfulCall(t *testing.T) {
 	}
 }
 
-func TestHandleCodexEvent_LogsMessageContent(t *testing.T) {
+func TestHandleBackendEvent_LogsMessageContent(t *testing.T) {
 	rateLimiter := NewRateLimiter(10, 1)
 	breaker := circuit.NewBreaker(3, 5)
 
@@ -340,26 +349,23 @@ func TestHandleCodexEvent_LogsMessageContent(t *testing.T) {
 	controller := NewController(cfg, rateLimiter, breaker)
 
 	// Capture log events
+	sub := controller.Subscribe(64, DropNewest)
+	defer sub.Unsubscribe()
+
+	controller.handleBackendEvent(backend.MessageDelta("Hello, this is a test message", false))
+
 	var logMessages []string
-	controller.SetEventCallback(func(event LoopEvent) {
+	for _, event := range drainEvents(sub) {
 		if event.Type == EventTypeLog {
-			logMessages = append(logMessages, event.LogMessage)
+			logMessages = append(logMessages, event.Log.Message)
 		}
-	})
-
-	// Create a test event with message content
-	testEvent := map[string]interface{}{
-		"type":    "message",
-		"content": "Hello, this is a test message",
 	}
 
-	controller.handleCodexEvent(testEvent)
-
 	// Verify that both log messages were emitted
 	foundParsed := false
 	foundMessage := false
 	for _, msg := range logMessages {
-		if msg == "Parsed: type=message text=29 chars" {
+		if msg == "Parsed: kind=message_delta text=29 chars" {
 			foundParsed = true
 		}
 		if msg == "Message: Hello, this is a test message" {
@@ -368,9 +374,69 @@ func TestHandleCodexEvent_LogsMessageContent(t *testing.T) {
 	}
 
 	if !foundParsed {
-		t.Errorf("Expected 'Parsed: type=message text=29 chars' log message, got: %v", logMessages)
+		t.Errorf("Expected 'Parsed: kind=message_delta text=29 chars' log message, got: %v", logMessages)
 	}
 	if !foundMessage {
 		t.Errorf("Expected 'Message: Hello, this is a test message' log message, got: %v", logMessages)
 	}
 }
+
+func TestHandleBackendEvent_EmitsLoopEvents(t *testing.T) {
+	tests := []struct {
+		name  string
+		event backend.Event
+		check func(e LoopEvent) bool
+	}{
+		{"message", backend.MessageDelta("hi", true), func(e LoopEvent) bool {
+			return e.Type == EventTypeCodexOutput && e.Output.Line == "hi" && e.Output.Type == OutputTypeAgentMessage
+		}},
+		{"reasoning", backend.Reasoning("hmm", false), func(e LoopEvent) bool {
+			return e.Type == EventTypeCodexReasoning && e.Reasoning.Text == "hmm"
+		}},
+		{"tool start", backend.ToolStart(backend.Tool{Name: "read", Target: "a.go"}), func(e LoopEvent) bool {
+			return e.Type == EventTypeCodexTool && e.Tool.Name == "read" && e.Tool.Target == "a.go" && e.Tool.Status == ToolStatusStarted
+		}},
+		{"tool end", backend.ToolEnd(backend.Tool{Name: "read"}), func(e LoopEvent) bool {
+			return e.Type == EventTypeCodexTool && e.Tool.Status == ToolStatusCompleted
+		}},
+		{"tool details", backend.ToolEnd(backend.Tool{ID: "call-1", Name: "bash", Input: json.RawMessage(`{"command":"make"}`), Output: "ok", ExitCode: new(int)}), func(e LoopEvent) bool {
+			return e.Tool.ID == "call-1" && string(e.Tool.Input) == `{"command":"make"}` && e.Tool.Output == "ok" && e.Tool.ExitCode != nil && *e.Tool.ExitCode == 0
+		}},
+		{"long tool output", backend.ToolEnd(backend.Tool{Name: "bash", Output: strings.Repeat("x", MaxToolOutput+10)}), func(e LoopEvent) bool {
+			return strings.HasPrefix(e.Tool.Output, strings.Repeat("x", MaxToolOutput)) && strings.HasSuffix(e.Tool.Output, "(10 more bytes not shown)")
+		}},
+		{"file change", backend.FileChanged(backend.FileChange{Path: "b.go"}), func(e LoopEvent) bool {
+			return e.Type == EventTypeCodexTool && e.Tool.Name == "apply_patch" && e.Tool.Target == "b.go"
+		}},
+		{"usage", backend.UsageReport(backend.Usage{TotalTokens: 500, ContextLimit: 1000, UsagePercent: 0.5}), func(e LoopEvent) bool {
+			return e.Type == EventTypeContextUsage && e.Context.TotalTokens == 500 && e.Context.Limit == 1000
+		}},
+		{"lifecycle", backend.LifecycleEvent(backend.Lifecycle{Phase: "status", Status: "retry"}, ""), func(e LoopEvent) bool {
+			return e.Type == EventTypeCodexOutput && e.Output.Line == ">>> status: retry"
+		}},
+		{"error", backend.Error("boom"), func(e LoopEvent) bool {
+			return e.Type == EventTypeLog && e.Log.Level == LogLevelError && e.Log.Message == "Backend error: boom"
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := NewController(Config{MaxCalls: 5, Backend: "cli"}, NewRateLimiter(10, 1), circuit.NewBreaker(3, 5))
+			sub := controller.Subscribe(64, DropNewest)
+			defer sub.Unsubscribe()
+
+			controller.handleBackendEvent(tt.event)
+
+			var got []LoopEvent
+			for _, e := range drainEvents(sub) {
+				if e.Type == EventTypeLog && e.Log.Level == LogLevelDebug {
+					continue
+				}
+				got = append(got, e)
+			}
+			if len(got) != 1 || !tt.check(got[0]) {
+				t.Errorf("handleBackendEvent(%s) emitted %+v", tt.event.Kind, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package loop
+
+import (
+	"bufio"
+	stdcontext "context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/analysis"
+)
+
+// Files used to exchange escalations with a human when no terminal is attached
+const (
+	QuestionFile = ".lisa/question.md"
+	AnswerFile   = ".lisa/answer.md"
+)
+
+// PromptForAnswer writes the escalation to out and reads a single-line answer from in
+func PromptForAnswer(esc *analysis.Escalation, in io.Reader, out io.Writer) (string, error) {
+	fmt.Fprintf(out, "\n%s", esc.Prompt())
+	fmt.Fprint(out, "Your answer (Enter to resume without one): ")
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("failed to read answer: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// WaitForAnswerFile writes the escalation to QuestionFile and polls until the
+// human writes AnswerFile. An answer left over from an earlier escalation is
+// removed first. Both files are removed once the answer is read.
+func WaitForAnswerFile(ctx stdcontext.Context, esc *analysis.Escalation, pollInterval time.Duration) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(QuestionFile), 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(QuestionFile), err)
+	}
+	if err := os.Remove(AnswerFile); err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to remove stale %s: %w", AnswerFile, err)
+	}
+	content := esc.Prompt() + fmt.Sprintf("\nWrite your answer to %s to resume the loop.\n", AnswerFile)
+	if err := os.WriteFile(QuestionFile, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", QuestionFile, err)
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		if data, err := os.ReadFile(AnswerFile); err == nil {
+			_ = os.Remove(AnswerFile)
+			_ = os.Remove(QuestionFile)
+			return strings.TrimSpace(string(data)), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package loop
+
+import (
+	"bytes"
+	stdcontext "context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/analysis"
+	"github.com/brainwhocodes/lisa-loop/internal/circuit"
+)
+
+func TestPromptForAnswer(t *testing.T) {
+	esc := &analysis.Escalation{Questions: []string{"Which database?"}}
+	var out bytes.Buffer
+
+	answer, err := PromptForAnswer(esc, strings.NewReader("  Use SQLite \nignored\n"), &out)
+	if err != nil {
+		t.Fatalf("PromptForAnswer() error = %v", err)
+	}
+	if answer != "Use SQLite" {
+		t.Errorf("PromptForAnswer() = %q, want %q", answer, "Use SQLite")
+	}
+	if !strings.Contains(out.String(), "Which database?") {
+		t.Errorf("PromptForAnswer() output = %q, want question shown", out.String())
+	}
+
+	if _, err := PromptForAnswer(esc, strings.NewReader(""), &out); err == nil {
+		t.Error("PromptForAnswer() error = nil, want error on closed input")
+	}
+}
+
+func TestWaitForAnswerFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(origDir)
+
+	esc := &analysis.Escalation{Blockers: []string{"Need credentials"}}
+	go func() {
+		for {
+			if _, err := os.Stat(QuestionFile); err == nil {
+				os.WriteFile(AnswerFile, []byte("Use the staging key\n"), 0644)
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), 5*time.Second)
+	defer cancel()
+	answer, err := WaitForAnswerFile(ctx, esc, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForAnswerFile() error = %v", err)
+	}
+	if answer != "Use the staging key" {
+		t.Errorf("WaitForAnswerFile() = %q", answer)
+	}
+	if _, err := os.Stat(QuestionFile); !os.IsNotExist(err) {
+		t.Error("WaitForAnswerFile() left the question file behind")
+	}
+}
+
+func TestWaitForAnswerFile_IgnoresStaleAnswer(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(origDir)
+
+	os.MkdirAll(filepath.Dir(AnswerFile), 0755)
+	os.WriteFile(AnswerFile, []byte("Answer to an earlier question\n"), 0644)
+
+	ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), 50*time.Millisecond)
+	defer cancel()
+	answer, err := WaitForAnswerFile(ctx, &analysis.Escalation{Questions: []string{"Which database?"}}, 10*time.Millisecond)
+	if err == nil {
+		t.Errorf("WaitForAnswerFile() = %q, want it to wait for a new answer", answer)
+	}
+}
+
+func TestWaitForAnswerFile_Cancelled(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(origDir)
+
+	ctx, cancel := stdcontext.WithCancel(stdcontext.Background())
+	cancel()
+	if _, err := WaitForAnswerFile(ctx, &analysis.Escalation{Questions: []string{"?"}}, time.Hour); err == nil {
+		t.Error("WaitForAnswerFile() error = nil, want context error")
+	}
+}
+
+func TestController_EscalateAndAnswer(t *testing.T) {
+	controller := NewController(Config{MaxCalls: 5, Backend: "cli"}, NewRateLimiter(10, 1), circuit.NewBreaker(3, 5))
+
+	sub := controller.Subscribe(16, DropNewest)
+	defer sub.Unsubscribe()
+
+	esc := &analysis.Escalation{Questions: []string{"Keep the legacy API?"}}
+	controller.escalate(esc)
+
+	var escalations []*analysis.Escalation
+	for _, event := range drainEvents(sub) {
+		if event.Type == EventTypeEscalation {
+			escalations = append(escalations, event.Escalation)
+		}
+	}
+
+	if !controller.IsPaused() {
+		t.Error("escalate() did not pause the controller")
+	}
+	if len(escalations) != 1 || controller.PendingEscalation() != esc {
+		t.Fatalf("escalate() events = %d, pending = %v", len(escalations), controller.PendingEscalation())
+	}
+
+	controller.Answer("  Yes, keep it ")
+
+	if controller.IsPaused() {
+		t.Error("Answer() did not resume the controller")
+	}
+	if controller.PendingEscalation() != nil {
+		t.Error("Answer() did not clear the pending escalation")
+	}
+	if controller.humanAnswer != "Yes, keep it" || controller.answeredEscalation != esc {
+		t.Errorf("Answer() stored answer = %q, escalation = %v", controller.humanAnswer, controller.answeredEscalation)
+	}
+}
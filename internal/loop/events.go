@@ -1,5 +1,12 @@
 package loop
 
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/analysis"
+)
+
 // EventType represents the type of a loop event
 type EventType string
 
@@ -16,6 +23,10 @@ const (
 	EventTypeContextUsage   EventType = "context_usage" // Context window usage tracking
 	EventTypePreflight      EventType = "preflight"     // Preflight check summary
 	EventTypeOutcome        EventType = "outcome"       // Loop iteration outcome
+	EventTypeEscalation     EventType = "escalation"    // Agent blocker or question awaiting a human answer
+	EventTypeSnapshot       EventType = "snapshot"      // Status snapshot sent to clients attaching mid-run
+	EventTypeSteering       EventType = "steering"      // Operator guidance added or cleared
+	EventTypeReview         EventType = "review"        // Iteration changes awaiting, or given, a review decision
 )
 
 // LogLevel represents the severity level of a log entry
@@ -49,3 +60,106 @@ const (
 	ToolStatusStarted   ToolStatus = "started"
 	ToolStatusCompleted ToolStatus = "completed"
 )
+
+// LoopEvent is one event published by the controller. Every event is stamped
+// with the run and iteration it belongs to; exactly one payload, matching Type,
+// is set.
+type LoopEvent struct {
+	Type      EventType `json:"type"`
+	RunID     string    `json:"run_id,omitempty"`
+	Iteration int       `json:"iteration"` // Iteration in progress, 0 before the first
+	Seq       uint64    `json:"seq"`       // Publication order within the bus
+	Time      time.Time `json:"time"`
+
+	Update     *LoopUpdate          `json:"update,omitempty"`
+	State      *StateChange         `json:"state,omitempty"`
+	Log        *LogEntry            `json:"log,omitempty"`
+	Output     *OutputLine          `json:"output,omitempty"`
+	Reasoning  *Reasoning           `json:"reasoning,omitempty"`
+	Tool       *ToolCall            `json:"tool,omitempty"`
+	Analysis   *AnalysisResult      `json:"analysis,omitempty"`
+	Context    *ContextUsage        `json:"context,omitempty"`
+	Preflight  *PreflightSummary    `json:"preflight,omitempty"`
+	Outcome    *LoopOutcome         `json:"outcome,omitempty"`
+	Escalation *analysis.Escalation `json:"escalation,omitempty"`
+	Snapshot   *Status              `json:"snapshot,omitempty"`
+	Steering   *Steering            `json:"steering,omitempty"`
+	Review     *Review              `json:"review,omitempty"`
+}
+
+// LoopUpdate reports the loop's progress and status (EventTypeLoopUpdate)
+type LoopUpdate struct {
+	LoopNumber   int    `json:"loop"`
+	CallsUsed    int    `json:"calls_used"`
+	Status       string `json:"status"`
+	CircuitState string `json:"circuit_state"`
+}
+
+// StateChange reports a run state transition (EventTypeStateChange)
+type StateChange struct {
+	From   RunState `json:"from"`
+	To     RunState `json:"to"`
+	Reason string   `json:"reason,omitempty"`
+}
+
+// LogEntry is a log message (EventTypeLog)
+type LogEntry struct {
+	Level   LogLevel `json:"level"`
+	Message string   `json:"message"`
+}
+
+// OutputLine is a line of backend output (EventTypeCodexOutput)
+type OutputLine struct {
+	Line string     `json:"line"`
+	Type OutputType `json:"type"`
+}
+
+// Reasoning is the agent's reasoning/thinking text (EventTypeCodexReasoning)
+type Reasoning struct {
+	Text string `json:"text"`
+}
+
+// ToolCall is a tool starting or finishing (EventTypeCodexTool). The ID,
+// input and result are filled in where the backend reports them.
+type ToolCall struct {
+	ID       string          `json:"id,omitempty"`
+	Name     string          `json:"name"`
+	Target   string          `json:"target"` // File path or command
+	Status   ToolStatus      `json:"status"`
+	Input    json.RawMessage `json:"input,omitempty"`
+	Output   string          `json:"output,omitempty"` // Capped at MaxToolOutput bytes
+	ExitCode *int            `json:"exit_code,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// MaxToolOutput caps the tool output carried by an event
+const MaxToolOutput = 64 * 1024
+
+// AnalysisResult is the parsed status report for an iteration (EventTypeAnalysis)
+type AnalysisResult struct {
+	Status          string   `json:"status"`                 // WORKING, COMPLETE, BLOCKED
+	CurrentTask     string   `json:"current_task,omitempty"` // Current task being worked on or just completed
+	TasksCompleted  int      `json:"tasks_completed"`
+	FilesModified   int      `json:"files_modified"`
+	TestsStatus     string   `json:"tests_status"` // PASSING, FAILING, UNKNOWN
+	ExitSignal      bool     `json:"exit_signal"`
+	ConfidenceScore float64  `json:"confidence_score"`   // Confidence in completion (0-1)
+	Warnings        []string `json:"warnings,omitempty"` // Discrepancies between claims and ground truth
+}
+
+// Steering is operator guidance being added or cleared (EventTypeSteering)
+type Steering struct {
+	Message string `json:"message,omitempty"`
+	Live    bool   `json:"live,omitempty"`    // Also sent into the backend session in progress
+	Cleared bool   `json:"cleared,omitempty"` // All standing guidance was cleared
+}
+
+// ContextUsage reports context window usage (EventTypeContextUsage)
+type ContextUsage struct {
+	UsagePercent     float64 `json:"usage_percent"` // 0-1
+	TotalTokens      int     `json:"total_tokens"`
+	Limit            int     `json:"limit"`
+	ThresholdReached bool    `json:"threshold_reached"`
+	WasCompacted     bool    `json:"was_compacted"`  // OpenCode compacted the session
+	Cost             float64 `json:"cost,omitempty"` // Session cost in USD, when the backend reports it
+}
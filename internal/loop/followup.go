@@ -0,0 +1,160 @@
+package loop
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// followUpHeader is the plan section that receives agent-reported follow-up tasks
+const followUpHeader = "## Follow-up Tasks"
+
+// followUpWriteAttempts bounds how often a follow-up append is retried when
+// the plan changes underneath it
+const followUpWriteAttempts = 3
+
+// AppendFollowUpTasks appends tasks to the plan file as unchecked items at the
+// end of its "## Follow-up Tasks" section, creating the section at EOF if it
+// is missing. Tasks already present in the plan (checked or not) are skipped.
+// The plan is replaced through a temporary file, and the append is redone if
+// the plan changed while it was being written (e.g. edited from the TUI).
+// Returns the number of tasks added.
+func AppendFollowUpTasks(planFile string, tasks []string) (int, error) {
+	for attempt := 0; attempt < followUpWriteAttempts; attempt++ {
+		data, err := os.ReadFile(planFile)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read plan file %s: %w", planFile, err)
+		}
+		content := string(data)
+
+		updated, added, err := insertFollowUpTasks(content, planFile, tasks)
+		if err != nil || added == 0 {
+			return 0, err
+		}
+
+		err = replacePlanFile(planFile, content, updated)
+		if err == errPlanChanged {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to write plan file %s: %w", planFile, err)
+		}
+		return added, nil
+	}
+	return 0, fmt.Errorf("failed to write plan file %s: %w", planFile, errPlanChanged)
+}
+
+// insertFollowUpTasks returns content with the new tasks added to the
+// follow-up section, and how many tasks were added
+func insertFollowUpTasks(content, planFile string, tasks []string) (string, int, error) {
+	existing, err := parseTasksFromPlan(content, planFile)
+	if err != nil {
+		return "", 0, err
+	}
+	seen := make(map[string]bool, len(existing))
+	for _, task := range existing {
+		seen[normalizeTaskText(task[4:])] = true
+	}
+
+	var items []string
+	for _, task := range tasks {
+		task = strings.TrimSpace(task)
+		key := normalizeTaskText(task)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		items = append(items, "- [ ] "+task)
+	}
+	if len(items) == 0 {
+		return content, 0, nil
+	}
+
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+
+	header := -1
+	for i, line := range lines {
+		if strings.TrimSpace(line) == followUpHeader {
+			header = i
+			break
+		}
+	}
+	if header < 0 {
+		lines = append(lines, "", followUpHeader, "")
+		lines = append(lines, items...)
+		return strings.Join(lines, "\n") + "\n", len(items), nil
+	}
+
+	// The section ends at the next heading; new tasks go after its last non-blank line
+	end := len(lines)
+	for i := header + 1; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), "#") {
+			end = i
+			break
+		}
+	}
+	at := end
+	for at > header+1 && strings.TrimSpace(lines[at-1]) == "" {
+		at--
+	}
+	if at == header+1 {
+		items = append([]string{""}, items...)
+	}
+
+	out := make([]string, 0, len(lines)+len(items)+1)
+	out = append(out, lines[:at]...)
+	out = append(out, items...)
+	if at < len(lines) && at == end {
+		out = append(out, "")
+	}
+	out = append(out, lines[at:]...)
+	return strings.Join(out, "\n") + "\n", len(items), nil
+}
+
+// errPlanChanged is returned when the plan file changes while it is being
+// replaced
+var errPlanChanged = errors.New("plan file changed while saving")
+
+// replacePlanFile writes content through a temporary file and a rename, so
+// readers never see a half-written plan. The rename only happens if the file
+// still holds expected.
+func replacePlanFile(planFile, expected, content string) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(planFile); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(planFile), "."+filepath.Base(planFile)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+
+	current, err := os.ReadFile(planFile)
+	if err != nil {
+		return err
+	}
+	if string(current) != expected {
+		return errPlanChanged
+	}
+	return os.Rename(tmp.Name(), planFile)
+}
+
+// normalizeTaskText lowercases and collapses whitespace for duplicate detection
+func normalizeTaskText(task string) string {
+	return strings.ToLower(strings.Join(strings.Fields(task), " "))
+}
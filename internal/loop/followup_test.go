@@ -0,0 +1,66 @@
+package loop
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAppendFollowUpTasks(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "@fix_plan.md")
+	os.WriteFile(planFile, []byte("# Plan\n\n- [x] Add login\n- [ ] Add logout"), 0644)
+
+	added, err := AppendFollowUpTasks(planFile, []string{"Add  LOGOUT", "Write docs", "write docs", " "})
+	if err != nil {
+		t.Fatalf("AppendFollowUpTasks() error = %v", err)
+	}
+	if added != 1 {
+		t.Errorf("AppendFollowUpTasks() added = %d, want 1", added)
+	}
+
+	data, _ := os.ReadFile(planFile)
+	want := "# Plan\n\n- [x] Add login\n- [ ] Add logout\n\n## Follow-up Tasks\n\n- [ ] Write docs\n"
+	if string(data) != want {
+		t.Errorf("plan content = %q, want %q", string(data), want)
+	}
+
+	// A second append reuses the existing section
+	added, err = AppendFollowUpTasks(planFile, []string{"Add tests"})
+	if err != nil {
+		t.Fatalf("AppendFollowUpTasks() second call error = %v", err)
+	}
+	data, _ = os.ReadFile(planFile)
+	if added != 1 || strings.Count(string(data), followUpHeader) != 1 {
+		t.Errorf("second append added = %d, headers = %d, want 1 and 1", added, strings.Count(string(data), followUpHeader))
+	}
+	if !strings.HasSuffix(string(data), "- [ ] Write docs\n- [ ] Add tests\n") {
+		t.Errorf("plan content = %q, want follow-up tasks appended in order", string(data))
+	}
+}
+
+func TestAppendFollowUpTasks_MissingPlan(t *testing.T) {
+	if _, err := AppendFollowUpTasks(filepath.Join(t.TempDir(), "missing.md"), []string{"task"}); err == nil {
+		t.Error("AppendFollowUpTasks() error = nil, want error for missing plan")
+	}
+}
+
+func TestAppendFollowUpTasks_SectionFollowedByOthers(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "@fix_plan.md")
+	plan := "# Plan\n\n- [ ] Add login\n\n## Follow-up Tasks\n\n- [ ] Write docs\n\n## Notes\n\nKeep it small.\n"
+	os.WriteFile(planFile, []byte(plan), 0644)
+
+	added, err := AppendFollowUpTasks(planFile, []string{"Add tests"})
+	if err != nil {
+		t.Fatalf("AppendFollowUpTasks() error = %v", err)
+	}
+	if added != 1 {
+		t.Errorf("AppendFollowUpTasks() added = %d, want 1", added)
+	}
+
+	data, _ := os.ReadFile(planFile)
+	want := "# Plan\n\n- [ ] Add login\n\n## Follow-up Tasks\n\n- [ ] Write docs\n- [ ] Add tests\n\n## Notes\n\nKeep it small.\n"
+	if string(data) != want {
+		t.Errorf("plan content = %q, want %q", string(data), want)
+	}
+}
@@ -0,0 +1,104 @@
+package loop
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/brainwhocodes/lisa-loop/internal/analysis"
+)
+
+// GitExec runs a git command and returns its output. Tests can replace it.
+var GitExec = func(args ...string) ([]byte, error) {
+	return exec.Command("git", args...).Output()
+}
+
+// FileSnapshot maps file paths to their git diff --numstat entry (or "untracked")
+type FileSnapshot map[string]string
+
+// TakeFileSnapshot records the working tree state using git diff --numstat.
+// Untracked files are included so newly created files are counted as changes.
+func TakeFileSnapshot() (FileSnapshot, error) {
+	numstat, err := GitExec("diff", "--numstat", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("git diff --numstat failed: %w", err)
+	}
+	snapshot := ParseNumstat(string(numstat))
+
+	untracked, err := GitExec("ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files failed: %w", err)
+	}
+	for _, line := range strings.Split(string(untracked), "\n") {
+		path := strings.TrimSpace(line)
+		if path != "" {
+			snapshot[path] = "untracked"
+		}
+	}
+
+	return snapshot, nil
+}
+
+// ParseNumstat parses git diff --numstat output into a snapshot.
+// Each line has the form "<added>\t<deleted>\t<path>".
+func ParseNumstat(output string) FileSnapshot {
+	snapshot := FileSnapshot{}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		snapshot[parts[2]] = parts[0] + "\t" + parts[1]
+	}
+	return snapshot
+}
+
+// ChangedSince counts files whose numstat entry differs from an earlier snapshot
+func (s FileSnapshot) ChangedSince(before FileSnapshot) int {
+	changed := 0
+	for path, stat := range s {
+		if before[path] != stat {
+			changed++
+		}
+	}
+	for path := range before {
+		if _, ok := s[path]; !ok {
+			changed++
+		}
+	}
+	return changed
+}
+
+// countCheckedTasks returns the number of tasks marked [x]
+func countCheckedTasks(tasks []string) int {
+	checked := 0
+	for _, task := range tasks {
+		if strings.HasPrefix(task, "[x]") {
+			checked++
+		}
+	}
+	return checked
+}
+
+// collectGroundTruth compares the loop's plan file and working tree against the pre-loop state
+func collectGroundTruth(planFile string, tasksBefore []string, filesBefore FileSnapshot) analysis.GroundTruth {
+	truth := analysis.GroundTruth{}
+
+	if tasksAfter, err := LoadPlanFrom(planFile); err == nil && tasksBefore != nil {
+		truth.PlanKnown = true
+		truth.TasksChecked = countCheckedTasks(tasksAfter) - countCheckedTasks(tasksBefore)
+		if truth.TasksChecked < 0 {
+			truth.TasksChecked = 0
+		}
+		truth.RemainingTasks = len(tasksAfter) - countCheckedTasks(tasksAfter)
+	}
+
+	if filesBefore != nil {
+		if filesAfter, err := TakeFileSnapshot(); err == nil {
+			truth.GitKnown = true
+			truth.FilesChanged = filesAfter.ChangedSince(filesBefore)
+		}
+	}
+
+	return truth
+}
@@ -0,0 +1,107 @@
+package loop
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseNumstat(t *testing.T) {
+	output := "3\t1\tinternal/foo.go\n-\t-\tassets/logo.png\n\n10\t0\tREADME.md\n"
+
+	snapshot := ParseNumstat(output)
+
+	if len(snapshot) != 3 {
+		t.Fatalf("ParseNumstat() entries = %d, want 3", len(snapshot))
+	}
+	if snapshot["internal/foo.go"] != "3\t1" {
+		t.Errorf("ParseNumstat() foo.go = %q, want %q", snapshot["internal/foo.go"], "3\t1")
+	}
+	if snapshot["assets/logo.png"] != "-\t-" {
+		t.Errorf("ParseNumstat() logo.png = %q, want %q", snapshot["assets/logo.png"], "-\t-")
+	}
+}
+
+func TestFileSnapshot_ChangedSince(t *testing.T) {
+	before := FileSnapshot{
+		"a.go":     "1\t0",
+		"b.go":     "2\t2",
+		"reverted": "5\t0",
+	}
+	after := FileSnapshot{
+		"a.go":   "1\t0",      // unchanged
+		"b.go":   "4\t2",      // edited again
+		"new.go": "untracked", // created
+	}
+
+	if got := after.ChangedSince(before); got != 3 {
+		t.Errorf("ChangedSince() = %d, want 3", got)
+	}
+	if got := before.ChangedSince(before); got != 0 {
+		t.Errorf("ChangedSince(self) = %d, want 0", got)
+	}
+}
+
+func TestTakeFileSnapshot_IncludesUntracked(t *testing.T) {
+	origExec := GitExec
+	defer func() { GitExec = origExec }()
+
+	GitExec = func(args ...string) ([]byte, error) {
+		switch args[0] {
+		case "diff":
+			return []byte("1\t1\tmain.go\n"), nil
+		case "ls-files":
+			return []byte("new_file.go\n"), nil
+		}
+		return nil, errors.New("unexpected git command: " + strings.Join(args, " "))
+	}
+
+	snapshot, err := TakeFileSnapshot()
+	if err != nil {
+		t.Fatalf("TakeFileSnapshot() error = %v", err)
+	}
+	if snapshot["main.go"] != "1\t1" {
+		t.Errorf("snapshot[main.go] = %q, want %q", snapshot["main.go"], "1\t1")
+	}
+	if snapshot["new_file.go"] != "untracked" {
+		t.Errorf("snapshot[new_file.go] = %q, want untracked", snapshot["new_file.go"])
+	}
+}
+
+func TestCollectGroundTruth(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(origDir)
+
+	origExec := GitExec
+	defer func() { GitExec = origExec }()
+	GitExec = func(args ...string) ([]byte, error) {
+		if args[0] == "diff" {
+			return []byte("2\t0\tmain.go\n1\t1\t@fix_plan.md\n"), nil
+		}
+		return nil, nil
+	}
+
+	os.WriteFile("PROMPT.md", []byte("Test prompt"), 0644)
+	os.WriteFile("@fix_plan.md", []byte("- [x] First task\n- [x] Second task\n- [ ] Third task\n"), 0644)
+
+	tasksBefore := []string{"[x] First task", "[ ] Second task", "[ ] Third task"}
+	filesBefore := FileSnapshot{}
+
+	truth := collectGroundTruth("@fix_plan.md", tasksBefore, filesBefore)
+
+	if !truth.PlanKnown || !truth.GitKnown {
+		t.Fatalf("collectGroundTruth() PlanKnown=%v GitKnown=%v, want both true", truth.PlanKnown, truth.GitKnown)
+	}
+	if truth.TasksChecked != 1 {
+		t.Errorf("TasksChecked = %d, want 1", truth.TasksChecked)
+	}
+	if truth.RemainingTasks != 1 {
+		t.Errorf("RemainingTasks = %d, want 1", truth.RemainingTasks)
+	}
+	if truth.FilesChanged != 2 {
+		t.Errorf("FilesChanged = %d, want 2", truth.FilesChanged)
+	}
+}
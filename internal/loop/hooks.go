@@ -0,0 +1,235 @@
+package loop
+
+import (
+	"bytes"
+	stdcontext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Hook identifies a lifecycle point at which a configured shell command runs
+type Hook string
+
+// Lifecycle hooks, configured under hooks.<name> in config.yaml
+const (
+	HookPreRun         Hook = "pre_run"
+	HookPreIteration   Hook = "pre_iteration"
+	HookPostIteration  Hook = "post_iteration"
+	HookOnTaskComplete Hook = "on_task_complete"
+	HookOnBreakerOpen  Hook = "on_breaker_open"
+	HookOnComplete     Hook = "on_complete"
+	HookOnError        Hook = "on_error"
+)
+
+// DefaultHookTimeout bounds each hook when no hooks.timeout is configured
+const DefaultHookTimeout = time.Minute
+
+// ErrHookVeto is returned by Run when a pre_run or pre_iteration hook exits
+// non-zero or times out
+var ErrHookVeto = errors.New("vetoed by hook")
+
+// HookPayload is the JSON document a hook receives on stdin
+type HookPayload struct {
+	Hook    Hook         `json:"hook"`
+	Event   LoopEvent    `json:"event"`             // Loop state when the hook fired
+	Outcome *LoopOutcome `json:"outcome,omitempty"` // Iteration outcome (post_iteration)
+	Task    string       `json:"task,omitempty"`    // Completed task (on_task_complete)
+	Reason  string       `json:"reason,omitempty"`  // Why the run finished (on_complete)
+	Error   string       `json:"error,omitempty"`   // Failure message (on_error)
+}
+
+// HookExec runs a hook command through the shell with the payload on stdin and
+// extra environment variables, returning its combined output. The command is
+// killed when ctx is done. Tests can replace it.
+var HookExec = func(ctx stdcontext.Context, command string, payload []byte, env []string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(), env...)
+	// Don't wait forever on children of the shell that keep output open
+	cmd.WaitDelay = 5 * time.Second
+	return cmd.CombinedOutput()
+}
+
+// hookCommand returns the shell command configured for a hook, if any
+func (c *Controller) hookCommand(hook Hook) string {
+	h := c.cfg.Hooks
+	switch hook {
+	case HookPreRun:
+		return h.PreRun
+	case HookPreIteration:
+		return h.PreIteration
+	case HookPostIteration:
+		return h.PostIteration
+	case HookOnTaskComplete:
+		return h.OnTaskComplete
+	case HookOnBreakerOpen:
+		return h.OnBreakerOpen
+	case HookOnComplete:
+		return h.OnComplete
+	case HookOnError:
+		return h.OnError
+	}
+	return ""
+}
+
+// hookEvent snapshots the loop state for a hook payload
+func (c *Controller) hookEvent(loopNumber int, status string) LoopEvent {
+	return LoopEvent{
+		Type:      EventTypeLoopUpdate,
+		RunID:     c.runID,
+		Iteration: c.iteration,
+		Time:      time.Now(),
+		Update:    c.loopUpdate(loopNumber, status),
+	}
+}
+
+// runHook runs the command configured for payload.Hook, if any. It returns an
+// error when the command fails to run, exits non-zero or outruns hooks.timeout;
+// callers decide whether that vetoes anything.
+func (c *Controller) runHook(ctx stdcontext.Context, payload HookPayload) error {
+	command := c.hookCommand(payload.Hook)
+	if command == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%s hook: failed to encode payload: %w", payload.Hook, err)
+	}
+
+	timeout := c.cfg.Hooks.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHookTimeout
+	}
+	hookCtx, cancel := stdcontext.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	c.emitLog(LogLevelDebug, fmt.Sprintf("Running %s hook: %s", payload.Hook, command))
+	output, err := HookExec(hookCtx, command, data, hookEnv(payload))
+	out := strings.TrimSpace(string(output))
+	if out != "" {
+		for _, line := range strings.Split(out, "\n") {
+			c.emitLog(LogLevelDebug, fmt.Sprintf("[%s] %s", payload.Hook, line))
+		}
+	}
+	if err != nil && ctx.Err() == nil && errors.Is(hookCtx.Err(), stdcontext.DeadlineExceeded) {
+		return fmt.Errorf("%s hook timed out after %s", payload.Hook, timeout)
+	}
+	if err != nil {
+		if out != "" {
+			lines := strings.Split(out, "\n")
+			return fmt.Errorf("%s hook failed: %w: %s", payload.Hook, err, lines[len(lines)-1])
+		}
+		return fmt.Errorf("%s hook failed: %w", payload.Hook, err)
+	}
+	return nil
+}
+
+// runNotifyHook runs a hook whose failure is reported but doesn't affect the loop
+func (c *Controller) runNotifyHook(ctx stdcontext.Context, payload HookPayload) {
+	if err := c.runHook(ctx, payload); err != nil {
+		c.emitLog(LogLevelWarn, err.Error())
+	}
+}
+
+// finishIteration emits an iteration's outcome, journals it and fires post_iteration
+func (c *Controller) finishIteration(ctx stdcontext.Context, outcome *LoopOutcome) {
+	if !c.callStart.IsZero() {
+		outcome.DurationMS = time.Since(c.callStart).Milliseconds()
+	}
+	c.emitOutcome(outcome)
+	if err := c.activeJournal().finishIteration(ctx, outcome); err != nil {
+		c.emitLog(LogLevelWarn, fmt.Sprintf("Run journal: %v", err))
+	}
+	c.runNotifyHook(ctx, HookPayload{Hook: HookPostIteration, Event: c.hookEvent(c.loopNum+1, "iteration_complete"), Outcome: outcome})
+}
+
+// finishRun ends the run's journal and fires on_complete as Run returns
+func (c *Controller) finishRun(ctx stdcontext.Context, loopNumber int, status, reason string) {
+	if err := c.activeJournal().end(status, reason); err != nil {
+		c.emitLog(LogLevelWarn, fmt.Sprintf("Run journal: %v", err))
+	}
+	c.runNotifyHook(ctx, HookPayload{Hook: HookOnComplete, Event: c.hookEvent(loopNumber, status), Reason: reason})
+}
+
+// checkBreakerOpened fires on_breaker_open if the last recorded result tripped
+// the breaker. Iterations only run while it is closed, so any open state is new.
+func (c *Controller) checkBreakerOpened(ctx stdcontext.Context) {
+	if c.breaker.ShouldHalt() {
+		c.runNotifyHook(ctx, HookPayload{Hook: HookOnBreakerOpen, Event: c.hookEvent(c.loopNum+1, "circuit_open")})
+	}
+}
+
+// fireTaskHooks fires on_task_complete for each task in planFile checked off
+// since tasksBefore
+func (c *Controller) fireTaskHooks(ctx stdcontext.Context, planFile string, tasksBefore []string) {
+	if c.hookCommand(HookOnTaskComplete) == "" {
+		return
+	}
+	tasksAfter, err := LoadPlanFrom(planFile)
+	if err != nil {
+		return
+	}
+	for _, task := range newlyCompletedTasks(tasksBefore, tasksAfter) {
+		c.runNotifyHook(ctx, HookPayload{Hook: HookOnTaskComplete, Event: c.hookEvent(c.loopNum+1, "task_complete"), Task: task})
+	}
+}
+
+// hookEnv exposes the payload's key fields as LISA_* environment variables
+func hookEnv(p HookPayload) []string {
+	env := []string{
+		"LISA_HOOK=" + string(p.Hook),
+		"LISA_RUN_ID=" + p.Event.RunID,
+	}
+	if u := p.Event.Update; u != nil {
+		env = append(env,
+			"LISA_LOOP="+strconv.Itoa(u.LoopNumber),
+			"LISA_CALLS_USED="+strconv.Itoa(u.CallsUsed),
+			"LISA_STATUS="+u.Status,
+			"LISA_CIRCUIT_STATE="+u.CircuitState,
+		)
+	}
+	if p.Outcome != nil {
+		env = append(env,
+			"LISA_SUCCESS="+strconv.FormatBool(p.Outcome.Success),
+			"LISA_TASKS_COMPLETED="+strconv.Itoa(p.Outcome.TasksCompleted),
+			"LISA_FILES_MODIFIED="+strconv.Itoa(p.Outcome.FilesModified),
+			"LISA_TESTS_STATUS="+p.Outcome.TestsStatus,
+			"LISA_EXIT_SIGNAL="+strconv.FormatBool(p.Outcome.ExitSignal),
+		)
+	}
+	if p.Task != "" {
+		env = append(env, "LISA_TASK="+p.Task)
+	}
+	if p.Reason != "" {
+		env = append(env, "LISA_REASON="+p.Reason)
+	}
+	if p.Error != "" {
+		env = append(env, "LISA_ERROR="+p.Error)
+	}
+	return env
+}
+
+// newlyCompletedTasks returns the tasks checked off in after that were open in before
+func newlyCompletedTasks(before, after []string) []string {
+	open := make(map[string]bool)
+	for _, task := range before {
+		if text, ok := strings.CutPrefix(task, "[ ] "); ok {
+			open[text] = true
+		}
+	}
+	var completed []string
+	for _, task := range after {
+		if text, ok := strings.CutPrefix(task, "[x] "); ok && open[text] {
+			completed = append(completed, text)
+			delete(open, text)
+		}
+	}
+	return completed
+}
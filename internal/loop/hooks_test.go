@@ -0,0 +1,373 @@
+package loop
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/circuit"
+	"github.com/brainwhocodes/lisa-loop/internal/runner"
+)
+
+// checkOffRunner marks the first open task in the plan complete on each call
+type checkOffRunner struct{ planFile string }
+
+func (r *checkOffRunner) Run(ctx stdcontext.Context, prompt string) (string, string, error) {
+	data, err := os.ReadFile(r.planFile)
+	if err != nil {
+		return "", "", err
+	}
+	content := strings.Replace(string(data), "- [ ]", "- [x]", 1)
+	return "Checked off a task", "", os.WriteFile(r.planFile, []byte(content), 0644)
+}
+
+func (r *checkOffRunner) SetOutputCallback(cb runner.OutputCallback) {}
+
+func (r *checkOffRunner) Stop() error { return nil }
+
+// hookCall records one hook invocation
+type hookCall struct {
+	payload HookPayload
+	env     []string
+}
+
+// recordHooks replaces HookExec, failing the hooks named in fail
+func recordHooks(t *testing.T, fail ...Hook) *[]hookCall {
+	t.Helper()
+	origExec := HookExec
+	t.Cleanup(func() { HookExec = origExec })
+
+	var calls []hookCall
+	HookExec = func(ctx stdcontext.Context, command string, payload []byte, env []string) ([]byte, error) {
+		var p HookPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			t.Fatalf("hook payload is not valid JSON: %v", err)
+		}
+		calls = append(calls, hookCall{payload: p, env: env})
+		for _, h := range fail {
+			if p.Hook == h {
+				return []byte("lint failed\n"), errors.New("exit status 1")
+			}
+		}
+		return nil, nil
+	}
+	return &calls
+}
+
+func allHooks() Config {
+	cfg := Config{MaxCalls: 5, Backend: "cli", RetryDelay: time.Millisecond}
+	cfg.Hooks.PreRun = "pre"
+	cfg.Hooks.PreIteration = "pre-iter"
+	cfg.Hooks.PostIteration = "post-iter"
+	cfg.Hooks.OnTaskComplete = "task"
+	cfg.Hooks.OnBreakerOpen = "breaker"
+	cfg.Hooks.OnComplete = "done"
+	cfg.Hooks.OnError = "error"
+	return cfg
+}
+
+func setupHookProject(t *testing.T) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	t.Cleanup(func() { os.Chdir(origDir) })
+
+	os.WriteFile("@fix_plan.md", []byte("- [ ] First task\n- [ ] Second task\n"), 0644)
+	os.WriteFile("PROMPT.md", []byte("Test prompt"), 0644)
+}
+
+func hookNames(calls []hookCall) []Hook {
+	var names []Hook
+	for _, c := range calls {
+		names = append(names, c.payload.Hook)
+	}
+	return names
+}
+
+func TestRun_FiresLifecycleHooks(t *testing.T) {
+	setupHookProject(t)
+	calls := recordHooks(t)
+
+	controller := NewController(allHooks(), NewRateLimiter(10, 1), circuit.NewBreaker(3, 5))
+	controller.SetRunner(&checkOffRunner{planFile: "@fix_plan.md"})
+
+	if err := controller.Run(stdcontext.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := []Hook{
+		HookPreRun,
+		HookPreIteration, HookOnTaskComplete, HookPostIteration,
+		HookPreIteration, HookOnTaskComplete, HookPostIteration,
+		HookOnComplete,
+	}
+	if got := hookNames(*calls); !reflect.DeepEqual(got, want) {
+		t.Fatalf("hooks fired = %v, want %v", got, want)
+	}
+
+	task := (*calls)[2]
+	if task.payload.Task != "First task" || task.payload.Event.Update.LoopNumber != 1 {
+		t.Errorf("on_task_complete payload = %+v, want First task in loop 1", task.payload)
+	}
+	post := (*calls)[3]
+	if post.payload.Outcome == nil || !post.payload.Outcome.Success {
+		t.Errorf("post_iteration outcome = %+v, want success", post.payload.Outcome)
+	}
+	done := (*calls)[len(*calls)-1]
+	if done.payload.Event.Update.Status != "complete" || done.payload.Event.Update.LoopNumber != 2 {
+		t.Errorf("on_complete update = %+v, want complete after loop 2", done.payload.Event.Update)
+	}
+}
+
+func TestFinishIteration_StampsDuration(t *testing.T) {
+	setupHookProject(t)
+	controller := NewController(Config{MaxCalls: 5, Backend: "cli"}, NewRateLimiter(10, 1), circuit.NewBreaker(3, 5))
+
+	outcome := &LoopOutcome{Success: true}
+	controller.finishIteration(stdcontext.Background(), outcome)
+	if outcome.DurationMS != 0 {
+		t.Errorf("DurationMS = %d before any backend call, want 0", outcome.DurationMS)
+	}
+
+	controller.callStart = time.Now().Add(-1500 * time.Millisecond)
+	controller.finishIteration(stdcontext.Background(), outcome)
+	if outcome.DurationMS < 1500 || outcome.DurationMS > 60000 {
+		t.Errorf("DurationMS = %d, want about 1500", outcome.DurationMS)
+	}
+}
+
+func TestRun_PreIterationHookVetoes(t *testing.T) {
+	setupHookProject(t)
+	calls := recordHooks(t, HookPreIteration)
+
+	fake := &checkOffRunner{planFile: "@fix_plan.md"}
+	controller := NewController(allHooks(), NewRateLimiter(10, 1), circuit.NewBreaker(3, 5))
+	controller.SetRunner(fake)
+
+	err := controller.Run(stdcontext.Background())
+	if !errors.Is(err, ErrHookVeto) || !strings.Contains(err.Error(), "lint failed") {
+		t.Fatalf("Run() error = %v, want ErrHookVeto with hook output", err)
+	}
+
+	want := []Hook{HookPreRun, HookPreIteration, HookOnComplete}
+	if got := hookNames(*calls); !reflect.DeepEqual(got, want) {
+		t.Errorf("hooks fired = %v, want %v", got, want)
+	}
+	if status := (*calls)[2].payload.Event.Update.Status; status != "vetoed" {
+		t.Errorf("on_complete status = %q, want vetoed", status)
+	}
+	if data, _ := os.ReadFile("@fix_plan.md"); strings.Contains(string(data), "[x]") {
+		t.Error("vetoed iteration still ran the agent")
+	}
+}
+
+func TestRun_PreRunHookVetoes(t *testing.T) {
+	setupHookProject(t)
+	calls := recordHooks(t, HookPreRun)
+
+	controller := NewController(allHooks(), NewRateLimiter(10, 1), circuit.NewBreaker(3, 5))
+	controller.SetRunner(&checkOffRunner{planFile: "@fix_plan.md"})
+
+	if err := controller.Run(stdcontext.Background()); !errors.Is(err, ErrHookVeto) {
+		t.Fatalf("Run() error = %v, want ErrHookVeto", err)
+	}
+	if got := hookNames(*calls); !reflect.DeepEqual(got, []Hook{HookPreRun}) {
+		t.Errorf("hooks fired = %v, want only pre_run", got)
+	}
+}
+
+func TestRun_HookTimeoutIsAFailure(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	setupHookProject(t)
+
+	cfg := Config{MaxCalls: 5, Backend: "cli", RetryDelay: time.Millisecond}
+	cfg.Hooks.PreRun = "exec sleep 30"
+	cfg.Hooks.Timeout = 100 * time.Millisecond
+	controller := NewController(cfg, NewRateLimiter(10, 1), circuit.NewBreaker(3, 5))
+	controller.SetRunner(&checkOffRunner{planFile: "@fix_plan.md"})
+
+	start := time.Now()
+	err := controller.Run(stdcontext.Background())
+	if !errors.Is(err, ErrHookVeto) || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Fatalf("Run() error = %v, want ErrHookVeto for a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Run() took %s, want the hung hook killed", elapsed)
+	}
+}
+
+func TestRun_TaskHooksReadTheLoopsPlan(t *testing.T) {
+	setupHookProject(t)
+	calls := recordHooks(t)
+
+	cfg := Config{MaxCalls: 1, Backend: "cli", RetryDelay: time.Millisecond}
+	cfg.Hooks.OnTaskComplete = "task"
+	controller := NewController(cfg, NewRateLimiter(10, 1), circuit.NewBreaker(3, 5))
+	controller.SetRunner(&scriptedRunner{
+		onCall: func(ctx stdcontext.Context, call int) error {
+			// A second plan appearing mid-iteration doesn't change the loop's plan
+			if err := os.WriteFile("REFACTOR_PLAN.md", []byte("- [ ] Unrelated\n"), 0644); err != nil {
+				return err
+			}
+			return checkOff("First task")
+		},
+	})
+
+	if err := controller.Run(stdcontext.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	var tasks []string
+	for _, c := range *calls {
+		if c.payload.Hook == HookOnTaskComplete {
+			tasks = append(tasks, c.payload.Task)
+		}
+	}
+	if !reflect.DeepEqual(tasks, []string{"First task"}) {
+		t.Errorf("on_task_complete tasks = %v, want [First task]", tasks)
+	}
+}
+
+func TestRun_PostHookFailureDoesNotStopLoop(t *testing.T) {
+	setupHookProject(t)
+	recordHooks(t, HookPostIteration, HookOnTaskComplete)
+
+	controller := NewController(allHooks(), NewRateLimiter(10, 1), circuit.NewBreaker(3, 5))
+	controller.SetRunner(&checkOffRunner{planFile: "@fix_plan.md"})
+
+	sub := controller.Subscribe(1024, DropNewest)
+	defer sub.Unsubscribe()
+
+	if err := controller.Run(stdcontext.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	var warnings int
+	for _, event := range drainEvents(sub) {
+		if event.Type == EventTypeLog && event.Log.Level == LogLevelWarn && strings.Contains(event.Log.Message, "hook failed") {
+			warnings++
+		}
+	}
+	if warnings != 4 {
+		t.Errorf("hook failure warnings = %d, want 4", warnings)
+	}
+}
+
+// failingRunner always fails with the same error
+type failingRunner struct{}
+
+func (failingRunner) Run(ctx stdcontext.Context, prompt string) (string, string, error) {
+	return "", "", errors.New("backend unavailable")
+}
+
+func (failingRunner) SetOutputCallback(cb runner.OutputCallback) {}
+
+func (failingRunner) Stop() error { return nil }
+
+func TestRun_BreakerOpenAndErrorHooks(t *testing.T) {
+	setupHookProject(t)
+	calls := recordHooks(t)
+
+	controller := NewController(allHooks(), NewRateLimiter(10, 1), circuit.NewBreaker(3, 2))
+	controller.SetRunner(failingRunner{})
+
+	if err := controller.Run(stdcontext.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	var opened, errs int
+	for _, c := range *calls {
+		switch c.payload.Hook {
+		case HookOnBreakerOpen:
+			opened++
+		case HookOnError:
+			errs++
+			if !strings.Contains(c.payload.Error, "backend unavailable") {
+				t.Errorf("on_error payload error = %q", c.payload.Error)
+			}
+		}
+	}
+	if opened != 1 {
+		t.Errorf("on_breaker_open fired %d times, want 1", opened)
+	}
+	// The breaker opens after twice the same-error threshold
+	if errs != 4 {
+		t.Errorf("on_error fired %d times, want 4", errs)
+	}
+	last := (*calls)[len(*calls)-1].payload
+	if last.Hook != HookOnComplete || last.Reason != "Circuit breaker is OPEN" {
+		t.Errorf("last hook = %s (%q), want on_complete after the breaker opened", last.Hook, last.Reason)
+	}
+}
+
+func TestHookEnv(t *testing.T) {
+	env := hookEnv(HookPayload{
+		Hook:    HookPostIteration,
+		Event:   LoopEvent{RunID: "run-1", Update: &LoopUpdate{LoopNumber: 3, CallsUsed: 2, Status: "iteration_complete", CircuitState: "CLOSED"}},
+		Outcome: &LoopOutcome{Success: true, TasksCompleted: 1, FilesModified: 4, TestsStatus: "PASSING"},
+	})
+
+	for _, want := range []string{
+		"LISA_HOOK=post_iteration", "LISA_RUN_ID=run-1", "LISA_LOOP=3", "LISA_CALLS_USED=2", "LISA_STATUS=iteration_complete",
+		"LISA_CIRCUIT_STATE=CLOSED", "LISA_SUCCESS=true", "LISA_TASKS_COMPLETED=1",
+		"LISA_FILES_MODIFIED=4", "LISA_TESTS_STATUS=PASSING", "LISA_EXIT_SIGNAL=false",
+	} {
+		found := false
+		for _, v := range env {
+			if v == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("hookEnv() missing %s in %v", want, env)
+		}
+	}
+}
+
+func TestHookExec_Shell(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	output, err := HookExec(stdcontext.Background(), `printf "%s " "$LISA_HOOK"; cat`, []byte(`{"hook":"on_error"}`), []string{"LISA_HOOK=on_error"})
+	if err != nil {
+		t.Fatalf("HookExec() error = %v", err)
+	}
+	if string(output) != `on_error {"hook":"on_error"}` {
+		t.Errorf("HookExec() output = %q", output)
+	}
+
+	if _, err := HookExec(stdcontext.Background(), "exit 3", nil, nil); err == nil {
+		t.Error("HookExec() error = nil for a non-zero exit")
+	}
+}
+
+func TestNewlyCompletedTasks(t *testing.T) {
+	tests := []struct {
+		name   string
+		before []string
+		after  []string
+		want   []string
+	}{
+		{"none", []string{"[ ] a"}, []string{"[ ] a"}, nil},
+		{"one checked", []string{"[ ] a", "[ ] b"}, []string{"[x] a", "[ ] b"}, []string{"a"}},
+		{"already done", []string{"[x] a"}, []string{"[x] a"}, nil},
+		{"added and checked", []string{"[ ] a"}, []string{"[ ] a", "[x] new"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newlyCompletedTasks(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newlyCompletedTasks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,504 @@
+package loop
+
+import (
+	"bufio"
+	stdcontext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/state"
+)
+
+// JournalDir holds the run journals, one <run id>.jsonl file per run
+var JournalDir = filepath.Join(state.Dir, "runs")
+
+// MaxJournalDiff caps the patch kept for each iteration
+const MaxJournalDiff = 1 << 20
+
+// MaxJournals caps the run journals kept; the oldest are removed as a new run starts
+const MaxJournals = 50
+
+// JournalKind identifies a run journal entry
+type JournalKind string
+
+// Run journal entries, in the order a run writes them
+const (
+	JournalRunStarted JournalKind = "run_started"
+	JournalIterated   JournalKind = "iteration"
+	JournalRunEnded   JournalKind = "run_ended"
+)
+
+// JournalEntry is one line of a run journal
+type JournalEntry struct {
+	Kind  JournalKind `json:"kind"`
+	RunID string      `json:"run_id"`
+	Time  time.Time   `json:"time"`
+
+	Backend   string            `json:"backend,omitempty"`   // run_started
+	Iteration *JournalIteration `json:"iteration,omitempty"` // iteration
+	Status    string            `json:"status,omitempty"`    // run_ended: complete, stopped, aborted, cancelled...
+	Reason    string            `json:"reason,omitempty"`    // run_ended
+}
+
+// JournalIteration is a finished iteration as the journal keeps it
+type JournalIteration struct {
+	Number  int         `json:"number"`
+	Task    string      `json:"task,omitempty"` // Task the iteration worked on
+	Started time.Time   `json:"started"`
+	Outcome LoopOutcome `json:"outcome"`
+	Tokens  int         `json:"tokens,omitempty"` // Tokens the iteration used
+	Cost    float64     `json:"cost,omitempty"`
+
+	// Output, reasoning and tool events, with streamed messages and
+	// reasoning folded into their final text
+	Events []LoopEvent `json:"events,omitempty"`
+
+	Diff          string `json:"diff,omitempty"` // Changes the iteration made to the working tree
+	DiffTruncated bool   `json:"diff_truncated,omitempty"`
+}
+
+// runJournal appends a run's iterations to its journal file. A nil journal
+// records nothing, so runs go on when it can't be written.
+type runJournal struct {
+	mu    sync.Mutex
+	file  *os.File
+	runID string
+	ended bool
+
+	current   *JournalIteration
+	tree      string // Working tree when the current iteration started
+	message   int    // Index of the current iteration's last agent message, or -1
+	reasoning int    // Index of its last reasoning, or -1
+	tokens    int    // Session totals last reported, to charge each iteration the difference
+	cost      float64
+}
+
+// openJournal creates the journal for runID in JournalDir, removing the
+// oldest journals to make room for it
+func openJournal(runID, backend string) (*runJournal, error) {
+	if err := os.MkdirAll(JournalDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create journal dir: %w", err)
+	}
+	if err := pruneJournals(JournalDir, MaxJournals-1); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(filepath.Join(JournalDir, runID+".jsonl"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	j := &runJournal{file: file, runID: runID}
+	if err := j.write(JournalEntry{Kind: JournalRunStarted, Backend: backend}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+// pruneJournals removes all but the newest keep journals in dir
+func pruneJournals(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read journal dir: %w", err)
+	}
+	type journalFile struct {
+		path     string
+		modified time.Time
+	}
+	var files []journalFile
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".jsonl" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, journalFile{filepath.Join(dir, entry.Name()), info.ModTime()})
+	}
+	if len(files) <= keep {
+		return nil
+	}
+	sort.Slice(files, func(i, k int) bool { return files[i].modified.After(files[k].modified) })
+	for _, f := range files[max(keep, 0):] {
+		if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove old journal: %w", err)
+		}
+	}
+	return nil
+}
+
+// write appends an entry; callers hold mu or own the journal alone
+func (j *runJournal) write(entry JournalEntry) error {
+	entry.RunID = j.runID
+	entry.Time = time.Now()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal entry: %w", err)
+	}
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// beginIteration starts recording iteration n, dropping any iteration that
+// was interrupted before it finished
+func (j *runJournal) beginIteration(ctx stdcontext.Context, n int, task string) {
+	if j == nil {
+		return
+	}
+	tree, _ := SnapshotWorkTree(ctx)
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.current = &JournalIteration{Number: n, Task: task, Started: time.Now()}
+	j.tree = tree
+	j.message, j.reasoning = -1, -1
+}
+
+// record keeps the parts of event a past iteration is browsed by
+func (j *runJournal) record(event LoopEvent) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if u := event.Context; u != nil {
+		// A total lower than the last one means a new session started
+		tokens, cost := u.TotalTokens, u.Cost
+		if tokens >= j.tokens {
+			tokens -= j.tokens
+		}
+		if cost >= j.cost {
+			cost -= j.cost
+		}
+		j.tokens, j.cost = u.TotalTokens, u.Cost
+		if j.current != nil {
+			j.current.Tokens += tokens
+			j.current.Cost += cost
+		}
+		return
+	}
+
+	it := j.current
+	if it == nil {
+		return
+	}
+	switch {
+	case event.Output != nil:
+		if event.Output.Type == OutputTypeAgentMessage {
+			if j.message >= 0 && j.extends(j.message, event.Output.Line, func(e LoopEvent) string { return e.Output.Line }) {
+				return
+			}
+			j.message = len(it.Events)
+		}
+	case event.Reasoning != nil:
+		if j.reasoning >= 0 && j.extends(j.reasoning, event.Reasoning.Text, func(e LoopEvent) string { return e.Reasoning.Text }) {
+			return
+		}
+		j.reasoning = len(it.Events)
+	case event.Tool == nil:
+		return
+	}
+	it.Events = append(it.Events, event)
+}
+
+// extends folds a streamed update into the event at i when one text continues
+// the other, keeping the longer, and reports whether it did
+func (j *runJournal) extends(i int, text string, textOf func(LoopEvent) string) bool {
+	prev := textOf(j.current.Events[i])
+	if !strings.HasPrefix(text, prev) && !strings.HasPrefix(prev, text) {
+		return false
+	}
+	if len(text) > len(prev) {
+		e := j.current.Events[i]
+		switch {
+		case e.Output != nil:
+			out := *e.Output
+			out.Line = text
+			e.Output = &out
+		case e.Reasoning != nil:
+			e.Reasoning = &Reasoning{Text: text}
+		}
+		j.current.Events[i] = e
+	}
+	return true
+}
+
+// finishIteration writes the current iteration with its outcome and the
+// changes it made
+func (j *runJournal) finishIteration(ctx stdcontext.Context, outcome *LoopOutcome) error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	it, from := j.current, j.tree
+	j.current = nil
+	j.mu.Unlock()
+	if it == nil {
+		return nil
+	}
+
+	it.Outcome = *outcome
+	if from != "" {
+		if to, err := SnapshotWorkTree(ctx); err == nil {
+			if patch, err := GitExec("diff", "--no-color", from, to); err == nil {
+				it.Diff = strings.TrimRight(string(patch), "\n")
+			}
+		}
+	}
+	if len(it.Diff) > MaxJournalDiff {
+		it.Diff = it.Diff[:MaxJournalDiff]
+		it.DiffTruncated = true
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.ended {
+		return nil
+	}
+	return j.write(JournalEntry{Kind: JournalIterated, Iteration: it})
+}
+
+// end records how the run ended; only the first call counts
+func (j *runJournal) end(status, reason string) error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.ended {
+		return nil
+	}
+	j.ended = true
+	return j.write(JournalEntry{Kind: JournalRunEnded, Status: status, Reason: reason})
+}
+
+// close ends the run with status unless it already ended, and closes the file
+func (j *runJournal) close(status string) error {
+	if j == nil {
+		return nil
+	}
+	err := j.end(status, "")
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return errors.Join(err, j.file.Close())
+}
+
+// openJournal starts the current run's journal. Runs go on without one.
+func (c *Controller) openJournal() {
+	c.mu.Lock()
+	runID := c.runID
+	c.mu.Unlock()
+	journal, err := openJournal(runID, c.backend)
+	if err != nil {
+		c.emitLog(LogLevelWarn, fmt.Sprintf("Run journal unavailable: %v", err))
+		return
+	}
+	c.mu.Lock()
+	c.journal = journal
+	c.mu.Unlock()
+}
+
+// closeJournal closes the run's journal as Run returns. A run that didn't
+// finish through finishRun was cancelled or failed.
+func (c *Controller) closeJournal(ctx stdcontext.Context) {
+	c.mu.Lock()
+	journal := c.journal
+	c.journal = nil
+	c.mu.Unlock()
+
+	status := "error"
+	if ctx.Err() != nil {
+		status = "cancelled"
+	}
+	_ = journal.close(status)
+}
+
+// activeJournal returns the current run's journal, nil outside a Run
+func (c *Controller) activeJournal() *runJournal {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.journal
+}
+
+// RunHistory is a past run read back from its journal. Its iterations are
+// summaries, without their events and diff; LoadIteration reads one in full.
+type RunHistory struct {
+	ID         string
+	Path       string // Journal the run was read from
+	Backend    string
+	Started    time.Time
+	Ended      time.Time // Zero if the run is still going or died without ending
+	Status     string    // How the run ended, empty if it never recorded an end
+	Reason     string
+	Iterations []JournalIteration
+}
+
+// Duration is how long the run took, or has taken so far
+func (r RunHistory) Duration() time.Duration {
+	end := r.Ended
+	if end.IsZero() {
+		for _, it := range r.Iterations {
+			if done := it.Started.Add(time.Duration(it.Outcome.DurationMS) * time.Millisecond); done.After(end) {
+				end = done
+			}
+		}
+	}
+	if end.Before(r.Started) {
+		return 0
+	}
+	return end.Sub(r.Started)
+}
+
+// TasksCompleted totals the tasks the run's iterations reported completing
+func (r RunHistory) TasksCompleted() int {
+	n := 0
+	for _, it := range r.Iterations {
+		n += it.Outcome.TasksCompleted
+	}
+	return n
+}
+
+// Tokens totals the tokens the run's iterations used
+func (r RunHistory) Tokens() int {
+	n := 0
+	for _, it := range r.Iterations {
+		n += it.Tokens
+	}
+	return n
+}
+
+// LoadRunHistory reads the run journals in dir, newest first, leaving out
+// each iteration's events and diff. A missing dir has no history; lines that
+// can't be read are skipped, so a run cut short still shows what it recorded.
+func LoadRunHistory(dir string) ([]RunHistory, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal dir: %w", err)
+	}
+
+	var runs []RunHistory
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".jsonl" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		run, err := loadRun(path)
+		if err != nil {
+			continue
+		}
+		run.Path = path
+		if run.ID == "" {
+			run.ID = strings.TrimSuffix(entry.Name(), ".jsonl")
+		}
+		runs = append(runs, run)
+	}
+	sort.SliceStable(runs, func(i, k int) bool {
+		if !runs[i].Started.Equal(runs[k].Started) {
+			return runs[i].Started.After(runs[k].Started)
+		}
+		return runs[i].ID > runs[k].ID
+	})
+	return runs, nil
+}
+
+// skipJSON decodes any JSON value to nothing
+type skipJSON struct{}
+
+func (*skipJSON) UnmarshalJSON([]byte) error { return nil }
+
+// journalSummary decodes a journal entry without its iteration's events and
+// diff, which only an opened iteration needs
+type journalSummary struct {
+	JournalEntry
+	Iteration *struct {
+		JournalIteration
+		Events skipJSON `json:"events,omitempty"`
+		Diff   skipJSON `json:"diff,omitempty"`
+	} `json:"iteration,omitempty"`
+}
+
+// loadRun reads one run journal's summary
+func loadRun(path string) (RunHistory, error) {
+	var run RunHistory
+	err := readJournal(path, func(line []byte) bool {
+		var entry journalSummary
+		if json.Unmarshal(line, &entry) != nil {
+			return true
+		}
+		switch entry.Kind {
+		case JournalRunStarted:
+			run.ID, run.Backend, run.Started = entry.RunID, entry.Backend, entry.Time
+		case JournalIterated:
+			if entry.Iteration != nil {
+				run.Iterations = append(run.Iterations, entry.Iteration.JournalIteration)
+			}
+		case JournalRunEnded:
+			run.Ended, run.Status, run.Reason = entry.Time, entry.Status, entry.Reason
+		}
+		return true
+	})
+	return run, err
+}
+
+// LoadIteration reads the run journal at path's index'th iteration in full,
+// events and diff included
+func LoadIteration(path string, index int) (JournalIteration, error) {
+	var found *JournalIteration
+	n := 0
+	err := readJournal(path, func(line []byte) bool {
+		var entry JournalEntry
+		if json.Unmarshal(line, &entry) != nil || entry.Kind != JournalIterated || entry.Iteration == nil {
+			return true
+		}
+		if n == index {
+			found = entry.Iteration
+			return false
+		}
+		n++
+		return true
+	})
+	if err != nil {
+		return JournalIteration{}, fmt.Errorf("failed to read journal: %w", err)
+	}
+	if found == nil {
+		return JournalIteration{}, fmt.Errorf("iteration %d not found in %s", index+1, path)
+	}
+	return *found, nil
+}
+
+// readJournal passes each line of the journal at path to visit until it
+// returns false
+func readJournal(path string, visit func(line []byte) bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 && !visit(line) {
+			return nil
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
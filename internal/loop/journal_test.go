@@ -0,0 +1,170 @@
+package loop
+
+import (
+	stdcontext "context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/circuit"
+)
+
+func TestRun_WritesJournal(t *testing.T) {
+	setupReviewProject(t)
+
+	c := NewController(Config{MaxCalls: 5, Backend: "cli", RetryDelay: time.Millisecond}, NewRateLimiter(10, 1), circuit.NewBreaker(3, 5))
+	c.SetRunner(&scriptedRunner{
+		onCall: func(ctx stdcontext.Context, call int) error {
+			c.emitCodexReasoning("Thinking")
+			c.emitCodexReasoning("Thinking about it")
+			c.emitCodexOutput("I'll add", OutputTypeAgentMessage)
+			c.emitCodexOutput("I'll add the file", OutputTypeAgentMessage)
+			c.emitCodexTool(ToolCall{Name: "write", Target: "feature.go", Status: ToolStatusCompleted})
+			c.emitContextUsage(&ContextUsage{TotalTokens: 1000 * call})
+			if call == 1 {
+				if err := os.WriteFile("feature.go", []byte("package feature\n"), 0644); err != nil {
+					return err
+				}
+				return checkOff("First task")
+			}
+			return checkOff("Second task")
+		},
+	})
+	if err := c.Run(stdcontext.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	runs, err := LoadRunHistory(JournalDir)
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("LoadRunHistory() = %d runs, %v; want 1", len(runs), err)
+	}
+	run := runs[0]
+	if run.Status != "complete" || run.Backend != "cli" || len(run.Iterations) != 2 {
+		t.Fatalf("run = %s with %d iterations, want complete with 2", run.Status, len(run.Iterations))
+	}
+	if run.Tokens() != 2000 {
+		t.Errorf("Tokens() = %d, want 2000", run.Tokens())
+	}
+
+	for _, it := range run.Iterations {
+		if it.Events != nil || it.Diff != "" {
+			t.Errorf("iteration %d summary holds its events or diff", it.Number)
+		}
+	}
+
+	first, err := LoadIteration(run.Path, 0)
+	if err != nil {
+		t.Fatalf("LoadIteration(0) error = %v", err)
+	}
+	if first.Number != 1 || first.Task != "First task" || !first.Outcome.Success {
+		t.Errorf("first iteration = %d %q success=%v, want 1 \"First task\" success", first.Number, first.Task, first.Outcome.Success)
+	}
+	var reasoning, messages []string
+	tools := 0
+	for _, e := range first.Events {
+		switch {
+		case e.Reasoning != nil:
+			reasoning = append(reasoning, e.Reasoning.Text)
+		case e.Output != nil && e.Output.Type == OutputTypeAgentMessage:
+			messages = append(messages, e.Output.Line)
+		case e.Tool != nil:
+			tools++
+		}
+	}
+	if len(reasoning) != 1 || reasoning[0] != "Thinking about it" || len(messages) != 1 || messages[0] != "I'll add the file" || tools != 1 {
+		t.Errorf("events = reasoning %q, messages %q, %d tools; want the streams folded", reasoning, messages, tools)
+	}
+	if !strings.Contains(first.Diff, "+package feature") || !strings.Contains(first.Diff, "+- [x] First task") {
+		t.Errorf("first diff missing the iteration's changes:\n%s", first.Diff)
+	}
+	second, err := LoadIteration(run.Path, 1)
+	if err != nil {
+		t.Fatalf("LoadIteration(1) error = %v", err)
+	}
+	if second := second.Diff; strings.Contains(second, "feature.go") || !strings.Contains(second, "+- [x] Second task") {
+		t.Errorf("second diff should hold only its own changes:\n%s", second)
+	}
+	if got := gitOutput(t, "status", "--porcelain", "--", ".", ":(exclude).lisa"); !strings.Contains(got, "?? feature.go") {
+		t.Errorf("journal snapshots touched the index:\n%s", got)
+	}
+}
+
+func TestLoadRunHistory(t *testing.T) {
+	if runs, err := LoadRunHistory(filepath.Join(t.TempDir(), "missing")); runs != nil || err != nil {
+		t.Errorf("LoadRunHistory(missing) = %v, %v; want nothing", runs, err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"old.jsonl": `{"kind":"run_started","run_id":"old","time":"2026-01-01T10:00:00Z","backend":"cli"}
+{"kind":"iteration","run_id":"old","time":"2026-01-01T10:01:00Z","iteration":{"number":1,"started":"2026-01-01T10:00:00Z","outcome":{"success":true,"tasks_completed":2},"tokens":500}}
+{"kind":"run_ended","run_id":"old","time":"2026-01-01T10:05:00Z","status":"complete","reason":"Loop complete"}
+`,
+		// Cut off mid-line by a crash: what was written still counts
+		"new.jsonl": `{"kind":"run_started","run_id":"new","time":"2026-01-02T10:00:00Z","backend":"opencode"}
+{"kind":"iteration","run_id":"new","time":"2026-01-02T10:02:00Z","iteration":{"number":1,"started":"2026-01-02T10:00:00Z","outcome":{"success":false,"duration_ms":120000}}}
+{"kind":"iteration","run_id":"new","ti`,
+		"notes.txt": "not a journal",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runs, err := LoadRunHistory(dir)
+	if err != nil || len(runs) != 2 {
+		t.Fatalf("LoadRunHistory() = %d runs, %v; want 2", len(runs), err)
+	}
+	if runs[0].ID != "new" || runs[1].ID != "old" {
+		t.Errorf("runs = %s, %s; want newest first", runs[0].ID, runs[1].ID)
+	}
+	if runs[0].Status != "" || len(runs[0].Iterations) != 1 || runs[0].Duration() != 2*time.Minute {
+		t.Errorf("unfinished run = %q, %d iterations, %s; want no status, 1 iteration, 2m", runs[0].Status, len(runs[0].Iterations), runs[0].Duration())
+	}
+	old := runs[1]
+	if old.Status != "complete" || old.Duration() != 5*time.Minute || old.TasksCompleted() != 2 || old.Tokens() != 500 {
+		t.Errorf("old run = %q, %s, %d tasks, %d tokens; want complete, 5m, 2, 500", old.Status, old.Duration(), old.TasksCompleted(), old.Tokens())
+	}
+}
+
+func TestLoadIteration_Missing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.jsonl")
+	if _, err := LoadIteration(path, 0); err == nil {
+		t.Error("LoadIteration() of a missing journal succeeded")
+	}
+	os.WriteFile(path, []byte(`{"kind":"run_started","run_id":"run","time":"2026-01-01T10:00:00Z"}`+"\n"), 0644)
+	if _, err := LoadIteration(path, 0); err == nil {
+		t.Error("LoadIteration() of a journal without iterations succeeded")
+	}
+}
+
+func TestPruneJournals(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	for i, name := range []string{"a.jsonl", "b.jsonl", "c.jsonl", "d.jsonl", "notes.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("{}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		// a.jsonl is the oldest
+		modified := now.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := pruneJournals(dir, 2); err != nil {
+		t.Fatalf("pruneJournals() error = %v", err)
+	}
+	var left []string
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		left = append(left, e.Name())
+	}
+	if got := strings.Join(left, " "); got != "c.jsonl d.jsonl notes.txt" {
+		t.Errorf("after pruning: %s, want the two newest journals and notes.txt", got)
+	}
+}
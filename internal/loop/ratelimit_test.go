@@ -9,6 +9,21 @@ import (
 	"github.com/brainwhocodes/lisa-loop/internal/state"
 )
 
+// TestMain runs the package tests from a scratch directory so state written by
+// tests that don't chdir themselves never lands in the source tree
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "lisa-loop-test")
+	if err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func TestNewRateLimiter(t *testing.T) {
 	limiter := NewRateLimiter(100, 1)
 
@@ -0,0 +1,371 @@
+package loop
+
+import (
+	"bufio"
+	"bytes"
+	stdcontext "context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ReviewDecision is the reviewer's verdict on an iteration's changes
+type ReviewDecision string
+
+// Review decisions
+const (
+	ReviewApprove ReviewDecision = "approve" // Keep the changes and commit them
+	ReviewReject  ReviewDecision = "reject"  // Revert the changes
+)
+
+// Files used to exchange reviews with a human when no terminal is attached
+const (
+	ReviewFile         = ".lisa/review.md"
+	ReviewDecisionFile = ".lisa/review-decision"
+)
+
+// reviewPathspec limits reviews, commits and reverts to the project's own
+// files, leaving Lisa's state under .lisa alone
+var reviewPathspec = []string{"--", ".", ":(exclude).lisa"}
+
+// Review is an iteration's changes awaiting a decision, or the decision once
+// it is made (EventTypeReview)
+type Review struct {
+	Loop     int            `json:"loop"`
+	Task     string         `json:"task,omitempty"` // Task the iteration worked on
+	Files    []ChangedFile  `json:"files"`
+	Patch    string         `json:"patch,omitempty"` // Diff of tracked files against HEAD
+	Decision ReviewDecision `json:"decision,omitempty"`
+	Feedback string         `json:"feedback,omitempty"` // Reviewer's note to the agent on a rejection
+}
+
+// ChangedFile is one file an iteration changed. Status is git's name-status letter (M, A,
+// D, R...) or "?" for a new untracked file.
+type ChangedFile struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+}
+
+// ParseReviewDecision parses "approve"/"a" or "reject"/"r"
+func ParseReviewDecision(s string) (ReviewDecision, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "approve", "a":
+		return ReviewApprove, nil
+	case "reject", "r":
+		return ReviewReject, nil
+	}
+	return "", fmt.Errorf("unknown review decision %q (want approve or reject)", s)
+}
+
+// gitArgs appends the review pathspec to a git command
+func gitArgs(args ...string) []string {
+	return append(args, reviewPathspec...)
+}
+
+// CheckReviewable reports why the working tree can't be reviewed iteration
+// by iteration: review mode needs a commit to diff against and no
+// uncommitted changes of its own, so each review shows one iteration's work.
+func CheckReviewable() error {
+	if _, err := GitExec("rev-parse", "--verify", "-q", "HEAD"); err != nil {
+		return errors.New("review mode needs a git repository with at least one commit")
+	}
+	out, err := GitExec(gitArgs("status", "--porcelain")...)
+	if err != nil {
+		return fmt.Errorf("git status failed: %w", err)
+	}
+	if strings.TrimSpace(string(out)) != "" {
+		return errors.New("review mode needs a clean working tree; commit or stash your changes first")
+	}
+	return nil
+}
+
+// CollectReview gathers the working tree's changes against HEAD
+func CollectReview(loopNum int, task string) (*Review, error) {
+	review := &Review{Loop: loopNum, Task: task}
+
+	nameStatus, err := GitExec(gitArgs("diff", "--name-status", "HEAD")...)
+	if err != nil {
+		return nil, fmt.Errorf("git diff --name-status failed: %w", err)
+	}
+	for _, line := range strings.Split(string(nameStatus), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 || fields[0] == "" {
+			continue
+		}
+		// Renames and copies list the old path first
+		review.Files = append(review.Files, ChangedFile{Path: fields[len(fields)-1], Status: fields[0][:1]})
+	}
+
+	untracked, err := GitExec(gitArgs("ls-files", "--others", "--exclude-standard")...)
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files failed: %w", err)
+	}
+	for _, line := range strings.Split(string(untracked), "\n") {
+		if path := strings.TrimSpace(line); path != "" {
+			review.Files = append(review.Files, ChangedFile{Path: path, Status: "?"})
+		}
+	}
+
+	patch, err := GitExec(gitArgs("diff", "--no-color", "HEAD")...)
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %w", err)
+	}
+	review.Patch = strings.TrimRight(string(patch), "\n")
+	return review, nil
+}
+
+// CommitMessage is the message an approved review is committed with
+func (r *Review) CommitMessage() string {
+	if r.Task == "" {
+		return fmt.Sprintf("lisa: loop %d", r.Loop)
+	}
+	return fmt.Sprintf("lisa: loop %d: %s", r.Loop, r.Task)
+}
+
+// CommitReview stages and commits the reviewed changes
+func CommitReview(r *Review) error {
+	if _, err := GitExec(gitArgs("add", "-A")...); err != nil {
+		return fmt.Errorf("git add failed: %w", err)
+	}
+	if _, err := GitExec("commit", "-q", "-m", r.CommitMessage()); err != nil {
+		return fmt.Errorf("git commit failed: %w", err)
+	}
+	return nil
+}
+
+// RevertReview puts the reviewed files back as they are in HEAD, deleting
+// the files the iteration created, including the new paths of renames and copies
+func RevertReview(r *Review) error {
+	if _, err := GitExec(gitArgs("reset", "-q", "HEAD")...); err != nil {
+		return fmt.Errorf("git reset failed: %w", err)
+	}
+	if _, err := GitExec(gitArgs("checkout", "HEAD")...); err != nil {
+		return fmt.Errorf("git checkout failed: %w", err)
+	}
+	for _, f := range r.Files {
+		switch f.Status {
+		case "A", "R", "C", "?":
+		default:
+			continue
+		}
+		if err := os.Remove(f.Path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", f.Path, err)
+		}
+	}
+	return nil
+}
+
+// revertKeepingPlanEdits reverts a rejected review like RevertReview, but
+// writes back a plan the reviewer edited while deciding, so their edits
+// survive and the next iteration runs on their plan
+func revertKeepingPlanEdits(r *Review, planFile string, planAtReview []byte) error {
+	var edited []byte
+	if planFile != "" {
+		if data, err := os.ReadFile(planFile); err == nil && !bytes.Equal(data, planAtReview) {
+			edited = data
+		}
+	}
+	if err := RevertReview(r); err != nil {
+		return err
+	}
+	if edited == nil {
+		return nil
+	}
+	reverted, err := os.ReadFile(planFile)
+	if os.IsNotExist(err) {
+		return os.WriteFile(planFile, edited, 0644)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore the edited plan: %w", err)
+	}
+	if err := replacePlanFile(planFile, string(reverted), string(edited)); err != nil {
+		return fmt.Errorf("failed to restore the edited plan: %w", err)
+	}
+	return nil
+}
+
+// Prompt renders the review for a human reading a terminal or ReviewFile
+func (r *Review) Prompt() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Loop %d changed %d file(s)", r.Loop, len(r.Files))
+	if r.Task != "" {
+		fmt.Fprintf(&b, " working on: %s", r.Task)
+	}
+	b.WriteString("\n\n")
+	for _, f := range r.Files {
+		fmt.Fprintf(&b, "  %s %s\n", f.Status, f.Path)
+	}
+	if r.Patch != "" {
+		fmt.Fprintf(&b, "\n%s\n", r.Patch)
+	}
+	return b.String()
+}
+
+// PromptForReview writes the review to out and reads the decision, plus
+// feedback for a rejection, from in
+func PromptForReview(r *Review, in io.Reader, out io.Writer) (ReviewDecision, string, error) {
+	reader := bufio.NewReader(in)
+	fmt.Fprintf(out, "\n%s\n", r.Prompt())
+	fmt.Fprint(out, "Keep these changes? [a]pprove and commit / [r]eject and revert: ")
+	line, err := readLine(reader)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read review decision: %w", err)
+	}
+	decision, err := ParseReviewDecision(line)
+	if err != nil || decision == ReviewApprove {
+		return decision, "", err
+	}
+
+	fmt.Fprint(out, "Feedback for the agent (Enter for none): ")
+	feedback, err := readLine(reader)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read review feedback: %w", err)
+	}
+	return decision, feedback, nil
+}
+
+// readLine reads one trimmed line, accepting a final line without a newline
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// WaitForReviewFile writes the review to ReviewFile and polls until the human
+// writes ReviewDecisionFile: "approve", or "reject" with optional feedback
+// on the following lines. A decision left over from an earlier review is
+// removed first. Both files are removed once the decision is read.
+func WaitForReviewFile(ctx stdcontext.Context, r *Review, pollInterval time.Duration) (ReviewDecision, string, error) {
+	if err := os.MkdirAll(filepath.Dir(ReviewFile), 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create %s: %w", filepath.Dir(ReviewFile), err)
+	}
+	if err := os.Remove(ReviewDecisionFile); err != nil && !os.IsNotExist(err) {
+		return "", "", fmt.Errorf("failed to remove stale %s: %w", ReviewDecisionFile, err)
+	}
+	content := r.Prompt() + fmt.Sprintf("\nWrite approve, or reject followed by feedback for the agent, to %s to resume the loop.\n", ReviewDecisionFile)
+	if err := os.WriteFile(ReviewFile, []byte(content), 0644); err != nil {
+		return "", "", fmt.Errorf("failed to write %s: %w", ReviewFile, err)
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		if data, err := os.ReadFile(ReviewDecisionFile); err == nil {
+			_ = os.Remove(ReviewDecisionFile)
+			first, rest, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+			decision, err := ParseReviewDecision(first)
+			if err != nil {
+				return "", "", err
+			}
+			_ = os.Remove(ReviewFile)
+			return decision, strings.TrimSpace(rest), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", "", ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// reviewIteration pauses the loop until the reviewer decides on the
+// iteration's changes, then commits or reverts them. A review that ends
+// without a decision (the loop was resumed, stopped or aborted) leaves the
+// changes in the working tree.
+func (c *Controller) reviewIteration(ctx stdcontext.Context, task string) error {
+	review, err := CollectReview(c.loopNum+1, task)
+	if err != nil {
+		// Never carry on with changes nobody could review
+		c.emitLog(LogLevelError, fmt.Sprintf("Could not collect changes for review: %v", err))
+		c.markStop()
+		return nil
+	}
+	if len(review.Files) == 0 {
+		c.emitLog(LogLevelInfo, fmt.Sprintf("Loop %d made no changes to review", review.Loop))
+		return nil
+	}
+
+	// The reviewer may edit the plan before deciding
+	planFile := c.cachedPlanFile
+	var planAtReview []byte
+	if planFile != "" {
+		planAtReview, _ = os.ReadFile(planFile)
+	}
+
+	c.mu.Lock()
+	c.pendingReview = review
+	c.reviewDecision = ""
+	c.reviewFeedback = ""
+	c.mu.Unlock()
+	c.emitLog(LogLevelInfo, fmt.Sprintf("Loop %d changed %d file(s); waiting for review", review.Loop, len(review.Files)))
+	c.Pause()
+	c.emitUpdate("awaiting_review")
+	c.emit(LoopEvent{Type: EventTypeReview, Review: review})
+
+	waitErr := c.waitWhilePaused(ctx)
+
+	c.mu.Lock()
+	decided := *review
+	decided.Decision, decided.Feedback = c.reviewDecision, c.reviewFeedback
+	c.pendingReview = nil
+	c.mu.Unlock()
+	if waitErr != nil {
+		return waitErr
+	}
+
+	switch decided.Decision {
+	case ReviewApprove:
+		if err := CommitReview(&decided); err != nil {
+			c.emitLog(LogLevelError, fmt.Sprintf("Approved, but the commit failed: %v", err))
+			c.markStop()
+			return nil
+		}
+		c.emitLog(LogLevelSuccess, fmt.Sprintf("Approved and committed: %s", decided.CommitMessage()))
+	case ReviewReject:
+		if err := revertKeepingPlanEdits(&decided, planFile, planAtReview); err != nil {
+			c.emitLog(LogLevelError, fmt.Sprintf("Rejected, but the revert failed: %v", err))
+			c.markStop()
+			return nil
+		}
+		c.lastRejection = &decided
+		c.cacheValid = false
+		c.emitLog(LogLevelWarn, fmt.Sprintf("Rejected and reverted loop %d", decided.Loop))
+	default:
+		c.emitLog(LogLevelWarn, fmt.Sprintf("Loop %d was not reviewed; its changes stay in the working tree", decided.Loop))
+	}
+	c.emit(LoopEvent{Type: EventTypeReview, Review: &decided})
+	return nil
+}
+
+// PendingReview returns the iteration awaiting a review decision, or nil
+func (c *Controller) PendingReview() *Review {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pendingReview
+}
+
+// Review records the reviewer's decision on the pending review and resumes
+// the loop. Feedback on a rejection is included in the next iteration's context.
+func (c *Controller) Review(decision ReviewDecision, feedback string) {
+	if decision != ReviewApprove && decision != ReviewReject {
+		c.emitLog(LogLevelWarn, fmt.Sprintf("Unknown review decision %q", decision))
+		return
+	}
+	c.mu.Lock()
+	if c.pendingReview == nil {
+		c.mu.Unlock()
+		c.emitLog(LogLevelWarn, "No iteration is awaiting review")
+		return
+	}
+	c.reviewDecision = decision
+	c.reviewFeedback = strings.TrimSpace(feedback)
+	c.mu.Unlock()
+	c.Resume()
+}
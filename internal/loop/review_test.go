@@ -0,0 +1,333 @@
+package loop
+
+import (
+	"bytes"
+	stdcontext "context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/circuit"
+)
+
+// setupReviewProject creates a hook project in a git repository with one commit
+func setupReviewProject(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	setupHookProject(t)
+	for _, env := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(env, "Lisa Test")
+	}
+	for _, env := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(env, "lisa@example.com")
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"commit", "-q", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+}
+
+// waitReview waits until loop's review is pending
+func waitReview(t *testing.T, c *Controller, loop int) *Review {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if review := c.PendingReview(); review != nil && review.Loop == loop {
+			return review
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("loop %d's review never became pending", loop)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func gitOutput(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		t.Fatalf("git %s: %v", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestController_ReviewApproveCommitsRejectReverts(t *testing.T) {
+	setupReviewProject(t)
+
+	r := &scriptedRunner{
+		started: make(chan int, 16),
+		onCall: func(ctx stdcontext.Context, call int) error {
+			switch call {
+			case 1:
+				if err := os.WriteFile("feature.go", []byte("package feature\n"), 0644); err != nil {
+					return err
+				}
+				return checkOff("First task")
+			case 2:
+				if err := os.WriteFile("hack.go", []byte("package hack\n"), 0644); err != nil {
+					return err
+				}
+				return checkOff("Second task")
+			}
+			return checkOff("Second task")
+		},
+	}
+	c := NewController(Config{MaxCalls: 10, Backend: "cli", Review: true, RetryDelay: time.Millisecond}, NewRateLimiter(10, 1), circuit.NewBreaker(3, 5))
+	c.SetRunner(r)
+	sub := c.Subscribe(1024, DropNewest)
+	defer sub.Unsubscribe()
+	done := startRun(c)
+
+	review := waitReview(t, c, 1)
+	waitState(t, c, RunStatePaused)
+	if review.Task != "First task" {
+		t.Errorf("review task = %q, want %q", review.Task, "First task")
+	}
+	paths := map[string]string{}
+	for _, f := range review.Files {
+		paths[f.Path] = f.Status
+	}
+	if paths["feature.go"] != "?" || paths["@fix_plan.md"] != "M" || len(paths) != 2 {
+		t.Errorf("review files = %v, want feature.go (?) and @fix_plan.md (M)", review.Files)
+	}
+	if !strings.Contains(review.Patch, "+- [x] First task") {
+		t.Errorf("review patch missing the plan change:\n%s", review.Patch)
+	}
+
+	c.Review(ReviewApprove, "")
+	waitReview(t, c, 2)
+	if got := gitOutput(t, "log", "-1", "--format=%s"); got != "lisa: loop 1: First task" {
+		t.Errorf("last commit = %q, want %q", got, "lisa: loop 1: First task")
+	}
+
+	c.Review(ReviewReject, "Don't add hack.go")
+	waitReview(t, c, 3)
+	if _, err := os.Stat("hack.go"); !os.IsNotExist(err) {
+		t.Errorf("hack.go still exists after the rejection (err = %v)", err)
+	}
+	if !strings.Contains(r.prompt(3), "Don't add hack.go") || !strings.Contains(r.prompt(3), "hack.go") {
+		t.Errorf("third prompt lacks the rejection feedback:\n%s", r.prompt(3))
+	}
+
+	c.Review(ReviewApprove, "")
+	waitDone(t, done)
+	if got := gitOutput(t, "status", "--porcelain", "--", ".", ":(exclude).lisa"); got != "" {
+		t.Errorf("working tree not clean after approval:\n%s", got)
+	}
+	if got := gitOutput(t, "rev-list", "--count", "HEAD"); got != "3" {
+		t.Errorf("commits = %s, want 3 (initial and two approved loops)", got)
+	}
+
+	var decisions []ReviewDecision
+	for _, e := range drainEvents(sub) {
+		if e.Type == EventTypeReview && e.Review.Decision != "" {
+			decisions = append(decisions, e.Review.Decision)
+		}
+	}
+	want := []ReviewDecision{ReviewApprove, ReviewReject, ReviewApprove}
+	if len(decisions) != len(want) {
+		t.Fatalf("review decisions = %v, want %v", decisions, want)
+	}
+	for i := range want {
+		if decisions[i] != want[i] {
+			t.Errorf("review decisions = %v, want %v", decisions, want)
+			break
+		}
+	}
+}
+
+func TestController_ReviewRejectKeepsPlanEdits(t *testing.T) {
+	setupReviewProject(t)
+
+	r := &scriptedRunner{
+		started: make(chan int, 16),
+		onCall: func(ctx stdcontext.Context, call int) error {
+			if call == 1 {
+				if err := os.WriteFile("hack.go", []byte("package hack\n"), 0644); err != nil {
+					return err
+				}
+			}
+			return checkOff("First task")
+		},
+	}
+	c := NewController(Config{MaxCalls: 10, Backend: "cli", Review: true, RetryDelay: time.Millisecond}, NewRateLimiter(10, 1), circuit.NewBreaker(3, 5))
+	c.SetRunner(r)
+	done := startRun(c)
+
+	waitReview(t, c, 1)
+	// The reviewer edits the plan from the review screen, then rejects
+	plan, err := os.ReadFile("@fix_plan.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := string(plan) + "- [ ] Reviewer task\n"
+	if err := os.WriteFile("@fix_plan.md", []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c.Review(ReviewReject, "")
+
+	waitReview(t, c, 2)
+	if _, err := os.Stat("hack.go"); !os.IsNotExist(err) {
+		t.Errorf("hack.go still exists after the rejection (err = %v)", err)
+	}
+	if !strings.Contains(r.prompt(2), "Reviewer task") {
+		t.Errorf("second prompt does not see the reviewer's plan edit:\n%s", r.prompt(2))
+	}
+	c.Abort()
+	waitDone(t, done)
+	if got, _ := os.ReadFile("@fix_plan.md"); !strings.Contains(string(got), "- [ ] Reviewer task") {
+		t.Errorf("plan lost the reviewer's edit:\n%s", got)
+	}
+}
+
+func TestRevertReview_RemovesRenamedPath(t *testing.T) {
+	setupReviewProject(t)
+	if err := os.WriteFile("old.go", []byte("package old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitOutput(t, "add", "old.go")
+	gitOutput(t, "commit", "-q", "-m", "add old.go")
+	gitOutput(t, "mv", "old.go", "new.go")
+
+	review, err := CollectReview(1, "")
+	if err != nil {
+		t.Fatalf("CollectReview: %v", err)
+	}
+	if len(review.Files) != 1 || review.Files[0].Status != "R" || review.Files[0].Path != "new.go" {
+		t.Fatalf("review files = %v, want new.go (R)", review.Files)
+	}
+
+	if err := RevertReview(review); err != nil {
+		t.Fatalf("RevertReview: %v", err)
+	}
+	if _, err := os.Stat("new.go"); !os.IsNotExist(err) {
+		t.Errorf("new.go survived the revert (err = %v)", err)
+	}
+	if _, err := os.Stat("old.go"); err != nil {
+		t.Errorf("old.go not restored: %v", err)
+	}
+	if status := gitOutput(t, "status", "--porcelain"); status != "" {
+		t.Errorf("tree not clean after revert:\n%s", status)
+	}
+}
+
+func TestController_ReviewNeedsCleanTree(t *testing.T) {
+	setupReviewProject(t)
+	os.WriteFile("scratch.txt", []byte("wip"), 0644)
+
+	c := NewController(Config{MaxCalls: 10, Backend: "cli", Review: true, RetryDelay: time.Millisecond}, NewRateLimiter(10, 1), circuit.NewBreaker(3, 5))
+	c.SetRunner(&checkOffRunner{planFile: "@fix_plan.md"})
+	err := c.Run(stdcontext.Background())
+	if err == nil || !strings.Contains(err.Error(), "clean working tree") {
+		t.Errorf("Run() error = %v, want a clean working tree error", err)
+	}
+}
+
+func TestController_ReviewWithoutPending(t *testing.T) {
+	c := newControlTestController(&checkOffRunner{})
+	sub := c.Subscribe(16, DropNewest)
+	defer sub.Unsubscribe()
+
+	c.Review(ReviewApprove, "")
+	c.Review("maybe", "")
+
+	var logs []string
+	for _, e := range drainEvents(sub) {
+		if e.Type == EventTypeLog {
+			logs = append(logs, e.Log.Message)
+		}
+	}
+	if len(logs) != 2 || !strings.Contains(logs[0], "No iteration is awaiting review") || !strings.Contains(logs[1], "Unknown review decision") {
+		t.Errorf("logs = %v, want a no-review and an unknown-decision warning", logs)
+	}
+}
+
+func TestPromptForReview(t *testing.T) {
+	review := &Review{Loop: 2, Task: "Add login", Files: []ChangedFile{{Path: "login.go", Status: "A"}}}
+	tests := []struct {
+		name         string
+		input        string
+		wantDecision ReviewDecision
+		wantFeedback string
+		wantErr      bool
+	}{
+		{"approve", "a\n", ReviewApprove, "", false},
+		{"reject with feedback", "reject\nUse the existing session store\n", ReviewReject, "Use the existing session store", false},
+		{"reject then end of input", "r\n", "", "", true},
+		{"unknown", "maybe\n", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			decision, feedback, err := PromptForReview(review, strings.NewReader(tt.input), &out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PromptForReview() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if decision != tt.wantDecision || feedback != tt.wantFeedback {
+				t.Errorf("PromptForReview() = %q, %q, want %q, %q", decision, feedback, tt.wantDecision, tt.wantFeedback)
+			}
+			if !strings.Contains(out.String(), "A login.go") {
+				t.Errorf("PromptForReview() output lacks the file list:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestWaitForReviewFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(origDir)
+
+	go func() {
+		for {
+			if _, err := os.Stat(ReviewFile); err == nil {
+				os.WriteFile(ReviewDecisionFile, []byte("reject\nKeep the public API\n"), 0644)
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), 5*time.Second)
+	defer cancel()
+	decision, feedback, err := WaitForReviewFile(ctx, &Review{Loop: 1}, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForReviewFile() error = %v", err)
+	}
+	if decision != ReviewReject || feedback != "Keep the public API" {
+		t.Errorf("WaitForReviewFile() = %q, %q, want reject with feedback", decision, feedback)
+	}
+	if _, err := os.Stat(ReviewFile); !os.IsNotExist(err) {
+		t.Error("WaitForReviewFile() left the review file behind")
+	}
+}
+
+func TestWaitForReviewFile_IgnoresStaleDecision(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(origDir)
+
+	os.MkdirAll(filepath.Dir(ReviewDecisionFile), 0755)
+	os.WriteFile(ReviewDecisionFile, []byte("approve\n"), 0644)
+
+	ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), 50*time.Millisecond)
+	defer cancel()
+	if decision, _, err := WaitForReviewFile(ctx, &Review{Loop: 2}, 5*time.Millisecond); err == nil {
+		t.Errorf("WaitForReviewFile() = %q, want it to wait for a new decision", decision)
+	}
+}
@@ -0,0 +1,88 @@
+package loop
+
+import (
+	"strings"
+
+	"github.com/brainwhocodes/lisa-loop/internal/analysis"
+)
+
+// Status is a point-in-time snapshot of the loop, safe to read from any
+// goroutine while Run is in progress
+type Status struct {
+	RunID      string                 `json:"run_id,omitempty"`
+	State      RunState               `json:"state"`
+	Status     string                 `json:"status"` // Latest loop update status (running, executing, ...)
+	Loop       int                    `json:"loop"`
+	Iteration  int                    `json:"iteration"`
+	Paused     bool                   `json:"paused"`
+	Plan       PlanProgress           `json:"plan"`
+	Breaker    map[string]interface{} `json:"circuit_breaker"`
+	RateLimit  map[string]interface{} `json:"rate_limiter"`
+	Context    *ContextUsage          `json:"context,omitempty"` // Nil until the backend reports usage
+	Escalation *analysis.Escalation   `json:"escalation,omitempty"`
+	Steering   []string               `json:"steering,omitempty"` // Standing operator guidance
+	Review     *Review                `json:"review,omitempty"`   // Iteration awaiting a review decision
+}
+
+// PlanProgress summarizes the plan file
+type PlanProgress struct {
+	File        string   `json:"file,omitempty"`
+	Total       int      `json:"total"`
+	Completed   int      `json:"completed"`
+	Remaining   int      `json:"remaining"`
+	CurrentTask string   `json:"current_task,omitempty"`
+	Skipped     []string `json:"skipped,omitempty"`
+}
+
+// Status returns a snapshot of the loop's progress. The plan, circuit
+// breaker and rate limiter figures are captured by the loop at each update,
+// so they are never read while the loop is changing them.
+func (c *Controller) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status := c.status
+	status.RunID = c.runID
+	status.State = c.state
+	status.Iteration = c.iteration
+	status.Paused = c.paused
+	status.Escalation = c.pendingEscalation
+	status.Steering = append([]string(nil), c.steering...)
+	status.Review = c.pendingReview
+	if c.currentTask != "" {
+		status.Plan.CurrentTask = c.currentTask
+	}
+	for task := range c.skipped {
+		status.Plan.Skipped = append(status.Plan.Skipped, task)
+	}
+	return status
+}
+
+// refreshStatus captures the loop's progress for Status, keeping the previous
+// update status when update is empty. It must only be called from the
+// goroutine running the loop.
+func (c *Controller) refreshStatus(update string) {
+	plan := PlanProgress{File: c.cachedPlanFile, Total: len(c.cachedTasks)}
+	for _, task := range c.cachedTasks {
+		if strings.HasPrefix(task, "[x]") {
+			plan.Completed++
+		}
+	}
+	open := c.openTasks(c.cachedTasks)
+	plan.Remaining = len(open)
+	if len(open) > 0 {
+		plan.CurrentTask = planTaskText(open[0])
+	}
+	breaker := c.breaker.GetStats()
+	rateLimit := c.rateLimiter.GetStats()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if update != "" {
+		c.status.Status = update
+	}
+	c.status.Loop = c.loopNum
+	c.status.Plan = plan
+	c.status.Breaker = breaker
+	c.status.RateLimit = rateLimit
+}
@@ -0,0 +1,99 @@
+package loop
+
+import (
+	stdcontext "context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/testreport"
+)
+
+// DefaultTestTimeout bounds the test command when no test.timeout is configured
+const DefaultTestTimeout = 10 * time.Minute
+
+// TestExec runs the configured test command through the shell and returns its
+// stdout. The command is killed when ctx is done. Tests can replace it.
+var TestExec = func(ctx stdcontext.Context, command string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	// Don't wait forever on children of the shell that keep stdout open
+	cmd.WaitDelay = 5 * time.Second
+	return cmd.Output()
+}
+
+// runVerification runs the configured test command and/or parses the test report.
+// A report file is only trusted if it was written after loopStart, so a stale
+// report from an earlier run cannot masquerade as this loop's result.
+// A test command that outruns its timeout counts as a failed run. Returns nil
+// when verification is not configured, was cancelled, or produced no usable report.
+func (c *Controller) runVerification(ctx stdcontext.Context, loopStart time.Time) *testreport.Summary {
+	if c.cfg.TestCommand == "" && c.cfg.TestReport == "" {
+		return nil
+	}
+
+	var output []byte
+	if c.cfg.TestCommand != "" {
+		c.emitLog(LogLevelInfo, fmt.Sprintf("Running tests: %s", c.cfg.TestCommand))
+		timeout := c.cfg.TestTimeout
+		if timeout <= 0 {
+			timeout = DefaultTestTimeout
+		}
+		testCtx, cancel := stdcontext.WithTimeout(ctx, timeout)
+		var err error
+		output, err = TestExec(testCtx, c.cfg.TestCommand)
+		cancel()
+		if ctx.Err() != nil {
+			c.emitLog(LogLevelWarn, "Test command interrupted")
+			return nil
+		}
+		if errors.Is(testCtx.Err(), stdcontext.DeadlineExceeded) {
+			c.emitLog(LogLevelWarn, fmt.Sprintf("Test command timed out after %s", timeout))
+			return &testreport.Summary{
+				Failed:   1,
+				Failures: []testreport.Failure{{Name: c.cfg.TestCommand, Message: fmt.Sprintf("timed out after %s", timeout)}},
+			}
+		}
+		// A non-zero exit is expected when tests fail; the report says why
+		var exitErr *exec.ExitError
+		if err != nil && !errors.As(err, &exitErr) {
+			c.emitLog(LogLevelWarn, fmt.Sprintf("Test command failed to run: %v", err))
+			return nil
+		}
+	}
+
+	var (
+		summary *testreport.Summary
+		err     error
+	)
+	if c.cfg.TestReport != "" {
+		info, statErr := os.Stat(c.cfg.TestReport)
+		if statErr != nil {
+			c.emitLog(LogLevelWarn, fmt.Sprintf("Test report unavailable: %v", statErr))
+			return nil
+		}
+		if info.ModTime().Before(loopStart.Truncate(time.Second)) {
+			c.emitLog(LogLevelDebug, fmt.Sprintf("Ignoring stale test report %s", c.cfg.TestReport))
+			return nil
+		}
+		summary, err = testreport.ParseFile(c.cfg.TestReport)
+	} else {
+		summary, err = testreport.Parse(output)
+	}
+	if err != nil {
+		c.emitLog(LogLevelWarn, fmt.Sprintf("Could not parse test results: %v", err))
+		return nil
+	}
+
+	level := LogLevelSuccess
+	if summary.Failed > 0 {
+		level = LogLevelWarn
+	}
+	c.emitLog(level, fmt.Sprintf("Tests: %s", summary))
+	for _, failure := range summary.Failures {
+		c.emitLog(LogLevelWarn, fmt.Sprintf("  FAIL %s", failure.Name))
+	}
+
+	return summary
+}
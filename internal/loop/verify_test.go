@@ -0,0 +1,98 @@
+package loop
+
+import (
+	stdcontext "context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/circuit"
+)
+
+const verifyGoTestJSON = `{"Action":"pass","Package":"example.com/pkg","Test":"TestA"}
+{"Action":"fail","Package":"example.com/pkg","Test":"TestB"}
+`
+
+func TestRunVerification(t *testing.T) {
+	origExec := TestExec
+	defer func() { TestExec = origExec }()
+
+	tests := []struct {
+		name       string
+		cfg        Config
+		execOutput string
+		execErr    error
+		report     string
+		reportAge  time.Duration
+		wantNil    bool
+		wantFailed int
+	}{
+		{name: "not configured", cfg: Config{}, wantNil: true},
+		{name: "command stdout", cfg: Config{TestCommand: "go test -json ./..."}, execOutput: verifyGoTestJSON, wantFailed: 1},
+		{name: "command cannot run", cfg: Config{TestCommand: "missing"}, execErr: errors.New("exec: not found"), wantNil: true},
+		{name: "unparseable stdout", cfg: Config{TestCommand: "make test"}, execOutput: "PASS\n", wantNil: true},
+		{name: "fresh report file", cfg: Config{TestReport: "report.json"}, report: verifyGoTestJSON, wantFailed: 1},
+		{name: "stale report file", cfg: Config{TestReport: "report.json"}, report: verifyGoTestJSON, reportAge: time.Hour, wantNil: true},
+		{name: "missing report file", cfg: Config{TestReport: "missing.json"}, wantNil: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.cfg.TestReport != "" {
+				tt.cfg.TestReport = filepath.Join(dir, tt.cfg.TestReport)
+			}
+			if tt.report != "" {
+				os.WriteFile(tt.cfg.TestReport, []byte(tt.report), 0644)
+				modTime := time.Now().Add(-tt.reportAge)
+				os.Chtimes(tt.cfg.TestReport, modTime, modTime)
+			}
+			TestExec = func(ctx stdcontext.Context, command string) ([]byte, error) {
+				return []byte(tt.execOutput), tt.execErr
+			}
+
+			controller := NewController(tt.cfg, NewRateLimiter(10, 1), circuit.NewBreaker(3, 5))
+			summary := controller.runVerification(stdcontext.Background(), time.Now().Add(-time.Minute))
+
+			if tt.wantNil {
+				if summary != nil {
+					t.Errorf("runVerification() = %+v, want nil", summary)
+				}
+				return
+			}
+			if summary == nil {
+				t.Fatal("runVerification() = nil, want summary")
+			}
+			if summary.Failed != tt.wantFailed {
+				t.Errorf("runVerification() Failed = %d, want %d", summary.Failed, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestRunVerification_Timeout(t *testing.T) {
+	cfg := Config{TestCommand: "exec sleep 30", TestTimeout: 50 * time.Millisecond}
+	controller := NewController(cfg, NewRateLimiter(10, 1), circuit.NewBreaker(3, 5))
+
+	start := time.Now()
+	summary := controller.runVerification(stdcontext.Background(), time.Now())
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("runVerification() took %v, want it killed at the timeout", elapsed)
+	}
+	if summary == nil || summary.TestsStatus() != "FAILING" {
+		t.Fatalf("runVerification() = %+v, want a failing summary", summary)
+	}
+}
+
+func TestRunVerification_Cancelled(t *testing.T) {
+	cfg := Config{TestCommand: "exec sleep 30"}
+	controller := NewController(cfg, NewRateLimiter(10, 1), circuit.NewBreaker(3, 5))
+
+	ctx, cancel := stdcontext.WithCancel(stdcontext.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if summary := controller.runVerification(ctx, time.Now()); summary != nil {
+		t.Errorf("runVerification() = %+v, want nil when cancelled", summary)
+	}
+}
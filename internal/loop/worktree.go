@@ -0,0 +1,60 @@
+package loop
+
+import (
+	stdcontext "context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SnapshotWorkTree records the working tree, untracked files included, as a
+// git tree object. Files are staged into a private copy of the index, so the
+// user's index and HEAD are left alone; Lisa's .lisa state is excluded. Each
+// call gets its own copy, so snapshots can run side by side.
+func SnapshotWorkTree(ctx stdcontext.Context) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", "rev-parse", "--git-path", "index").Output()
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %w", err)
+	}
+	index, err := filepath.Abs(strings.TrimSpace(string(out)))
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(index), filepath.Base(index)+".lisa-snapshot-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create snapshot index: %w", err)
+	}
+	snapshot := file.Name()
+	defer os.Remove(snapshot)
+	// Starting from the real index lets git reuse the hashes of unchanged
+	// files. Without one, git must find no file rather than an empty one.
+	data, err := os.ReadFile(index)
+	if err == nil {
+		_, err = file.Write(data)
+	} else {
+		err = os.Remove(snapshot)
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to copy the index: %w", err)
+	}
+
+	git := func(args ...string) ([]byte, error) {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Env = append(os.Environ(), "GIT_INDEX_FILE="+snapshot)
+		return cmd.Output()
+	}
+	if _, err := git(gitArgs("add", "-A")...); err != nil {
+		return "", fmt.Errorf("git add failed: %w", err)
+	}
+	out, err = git("write-tree")
+	if err != nil {
+		return "", fmt.Errorf("git write-tree failed: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,49 @@
+package loop
+
+import (
+	stdcontext "context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotWorkTree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	setupHookProject(t)
+	gitOutput(t, "init", "-q")
+
+	// A fresh repository has no index to start from
+	before, err := SnapshotWorkTree(stdcontext.Background())
+	if err != nil {
+		t.Fatalf("SnapshotWorkTree() error = %v", err)
+	}
+	if got := gitOutput(t, "ls-tree", "--name-only", before); got != "@fix_plan.md\nPROMPT.md" {
+		t.Errorf("snapshot holds %q, want the plan and prompt", got)
+	}
+
+	os.WriteFile("feature.go", []byte("package feature\n"), 0644)
+	os.MkdirAll(".lisa", 0755)
+	os.WriteFile(".lisa/state.json", []byte("{}"), 0644)
+	after, err := SnapshotWorkTree(stdcontext.Background())
+	if err != nil {
+		t.Fatalf("SnapshotWorkTree() error = %v", err)
+	}
+	if got := gitOutput(t, "diff", "--name-only", before, after); got != "feature.go" {
+		t.Errorf("snapshots differ in %q, want feature.go alone", got)
+	}
+	if got := gitOutput(t, "status", "--porcelain"); got != "?? .lisa/\n?? @fix_plan.md\n?? PROMPT.md\n?? feature.go" {
+		t.Errorf("snapshots touched the index:\n%s", got)
+	}
+	if left, _ := filepath.Glob(".git/index.lisa-snapshot-*"); len(left) > 0 {
+		t.Errorf("snapshot indexes left behind: %v", left)
+	}
+
+	ctx, cancel := stdcontext.WithCancel(stdcontext.Background())
+	cancel()
+	if _, err := SnapshotWorkTree(ctx); err == nil {
+		t.Error("SnapshotWorkTree() with a cancelled context succeeded")
+	}
+}
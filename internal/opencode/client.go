@@ -301,15 +301,24 @@ type PartUpdatedProps struct {
 		Text      string `json:"text"`
 		Delta     string `json:"delta,omitempty"` // Incremental update
 		// Tool-specific fields
-		Tool   string `json:"tool,omitempty"`
-		CallID string `json:"callID,omitempty"`
-		State  *struct {
-			Status string                 `json:"status,omitempty"`
-			Input  map[string]interface{} `json:"input,omitempty"`
-		} `json:"state,omitempty"`
+		Tool   string     `json:"tool,omitempty"`
+		CallID string     `json:"callID,omitempty"`
+		State  *ToolState `json:"state,omitempty"`
 	} `json:"part"`
 }
 
+// ToolState is the execution state of a tool part
+type ToolState struct {
+	Status string          `json:"status,omitempty"`
+	Input  json.RawMessage `json:"input,omitempty"`
+	Output string          `json:"output,omitempty"`
+	Error  string          `json:"error,omitempty"` // Set when Status is "error"
+	// Metadata holds tool-specific details; the bash tool reports its exit code
+	Metadata struct {
+		Exit *int `json:"exit,omitempty"`
+	} `json:"metadata,omitempty"`
+}
+
 // SessionDiffProps contains properties for session.diff events
 type SessionDiffProps struct {
 	SessionID string `json:"sessionID"`
@@ -389,10 +398,11 @@ type StreamResult struct {
 	SessionID        string
 	MessageID        string
 	Error            error
-	RetryCount       int  // Number of API retries during streaming
-	WasCompacted     bool // True if session was compacted during this message
-	PromptTokens     int  // Token usage after message
-	CompletionTokens int  // Token usage after message
+	RetryCount       int     // Number of API retries during streaming
+	WasCompacted     bool    // True if session was compacted during this message
+	PromptTokens     int     // Token usage after message
+	CompletionTokens int     // Token usage after message
+	Cost             float64 // Session cost in USD after message
 }
 
 // connectToSSE attempts to connect to the SSE endpoint with fallback
@@ -761,6 +771,7 @@ func (c *Client) sendMessageStreamingInternal(ctx context.Context, sessionID, co
 					if props.Session.ID == sessionID {
 						result.PromptTokens = props.Session.PromptTokens
 						result.CompletionTokens = props.Session.CompletionTokens
+						result.Cost = props.Session.Cost
 					}
 				}
 			}
@@ -2,6 +2,7 @@ package opencode
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -195,13 +196,12 @@ func (sa *SessionArchiver) Save(archive SessionArchive) (string, error) {
 	return filepath, nil
 }
 
-// List returns all archived sessions
+// List returns all archived sessions, none if nothing was ever archived
 func (sa *SessionArchiver) List() ([]SessionArchive, error) {
-	if err := sa.EnsureDir(); err != nil {
-		return nil, err
-	}
-
 	entries, err := os.ReadDir(sa.archiveDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read archive dir: %w", err)
 	}
@@ -0,0 +1,36 @@
+package opencode
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSessionArchiver_List(t *testing.T) {
+	dir := t.TempDir()
+	sa := NewSessionArchiver(dir)
+
+	archives, err := sa.List()
+	if err != nil || archives != nil {
+		t.Fatalf("List() = %v, %v; want nothing before any archive", archives, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".lisa", "sessions")); !os.IsNotExist(err) {
+		t.Errorf("List() created the archive dir (err = %v)", err)
+	}
+
+	saved := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, id := range []string{"ses_aaaaaaaa1", "ses_bbbbbbbb2"} {
+		if _, err := sa.Save(SessionArchive{SessionID: id, LoopNumber: i + 1, SavedAt: saved.Add(time.Duration(i) * time.Hour), Reason: "threshold"}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	archives, err = sa.List()
+	if err != nil || len(archives) != 2 {
+		t.Fatalf("List() = %d archives, %v; want 2", len(archives), err)
+	}
+	latest, err := sa.GetLatest()
+	if err != nil || latest == nil || latest.SessionID != "ses_bbbbbbbb2" {
+		t.Errorf("GetLatest() = %+v, %v; want ses_bbbbbbbb2", latest, err)
+	}
+}
@@ -4,13 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/brainwhocodes/lisa-loop/internal/backend"
 	"github.com/brainwhocodes/lisa-loop/internal/config"
 )
 
 // OutputCallback is called for streaming output events
-type OutputCallback func(event map[string]interface{})
+type OutputCallback func(event backend.Event)
 
 // Runner executes prompts using the OpenCode server API
 type Runner struct {
@@ -32,6 +34,10 @@ type Runner struct {
 	contextTracker *ContextTracker
 	archiver       *SessionArchiver
 	loopNumber     int
+
+	// Session with a message in flight, for Steer (called from other goroutines)
+	liveMu      sync.Mutex
+	liveSession string
 }
 
 // NewRunner creates a new OpenCode runner from config
@@ -72,6 +78,11 @@ func (r *Runner) SetOutputCallback(cb OutputCallback) {
 
 // Run executes a prompt and returns the output, session ID, and any error
 func (r *Runner) Run(prompt string) (output string, sessionID string, err error) {
+	return r.RunContext(context.Background(), prompt)
+}
+
+// RunContext is like Run but aborts the session's in-flight message when ctx is cancelled
+func (r *Runner) RunContext(parent context.Context, prompt string) (output string, sessionID string, err error) {
 	// Start managed server if needed
 	if r.client == nil {
 		if err := r.startManagedServer(); err != nil {
@@ -80,25 +91,19 @@ func (r *Runner) Run(prompt string) (output string, sessionID string, err error)
 	}
 
 	// Each loop/task run uses a fresh OpenCode session to keep iteration context isolated.
-	r.emitEvent("message", map[string]interface{}{
-		"content": "Creating new session for this loop...",
-	})
+	r.emit(backend.Note("Creating new session for this loop..."))
 
 	sessionID, err = r.client.CreateSession()
 	if err != nil {
 		return "", "", fmt.Errorf("failed to create session: %w", err)
 	}
 
-	r.emitEvent("message", map[string]interface{}{
-		"content": fmt.Sprintf("Session created: %s", shortSessionID(sessionID)),
-	})
+	r.emit(backend.Note(fmt.Sprintf("Session created: %s", shortSessionID(sessionID))))
 
 	// Cache only for diagnostics/getters; do not reuse for future runs.
 	r.sessionID = sessionID
 
-	r.emitEvent("message", map[string]interface{}{
-		"content": "Sending prompt to OpenCode...",
-	})
+	r.emit(backend.Note("Sending prompt to OpenCode..."))
 
 	// Reset tracking for new message (SSE sends cumulative updates)
 	r.lastReasoning = ""
@@ -110,113 +115,108 @@ func (r *Runner) Run(prompt string) (output string, sessionID string, err error)
 	r.contextTracker.Reset()
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	ctx, cancel := context.WithTimeout(parent, r.timeout)
 	defer cancel()
 
-	r.emitEvent("message", map[string]interface{}{
-		"content": fmt.Sprintf("Connecting to SSE stream (timeout: %v)...", r.timeout),
-	})
+	r.emit(backend.Note(fmt.Sprintf("Connecting to SSE stream (timeout: %v)...", r.timeout)))
 
 	// Send the message with SSE streaming
+	r.setLiveSession(sessionID)
 	result, err := r.client.SendMessageStreaming(ctx, sessionID, prompt, func(event SSEEvent) {
-		// Parse and forward SSE events in TUI-compatible format
+		// Translate SSE events into backend events
 		r.handleSSEEvent(sessionID, event)
 	})
+	r.setLiveSession("")
 
+	if err != nil && parent.Err() != nil {
+		// Stop the server working on a message nobody is waiting for
+		if abortErr := r.client.AbortSession(sessionID); abortErr != nil {
+			r.emit(backend.Error(fmt.Sprintf("Failed to abort session: %v", abortErr)))
+		}
+		return "", sessionID, fmt.Errorf("message cancelled: %w", context.Cause(parent))
+	}
 	if err != nil {
-		r.emitEvent("message.error", map[string]interface{}{
-			"session_id": sessionID,
-			"error":      err.Error(),
-		})
+		r.emit(backend.Error(err.Error()))
 		return "", sessionID, fmt.Errorf("failed to send message: %w", err)
 	}
 
-	r.emitEvent("message", map[string]interface{}{
-		"content": fmt.Sprintf("Received response (%d chars)", len(result.Content)),
-	})
+	r.emit(backend.Note(fmt.Sprintf("Received response (%d chars)", len(result.Content))))
 
 	if err != nil {
-		r.emitEvent("message.error", map[string]interface{}{
-			"session_id": sessionID,
-			"error":      err.Error(),
-		})
+		r.emit(backend.Error(err.Error()))
 		return "", sessionID, fmt.Errorf("failed to send message: %w", err)
 	}
 
+	// Emit the final response unless streaming already delivered all of it
 	content := result.Content
-	r.emitEvent("message.received", map[string]interface{}{
-		"session_id": sessionID,
-		"content":    content,
-	})
-
-	// Emit the response as a message event (for TUI compatibility)
-	r.emitEvent("message", map[string]interface{}{
-		"type": "message",
-		"text": content,
-	})
+	if content != "" && content != r.lastMessage {
+		r.lastMessage = content
+		r.emit(backend.MessageDelta(content, true))
+	}
 
 	// Update context tracking with token usage from result
 	usage := r.contextTracker.Update(result.PromptTokens, result.CompletionTokens, result.WasCompacted)
 
 	// Emit context usage event for TUI
-	r.emitEvent("context.usage", map[string]interface{}{
-		"prompt_tokens":     usage.PromptTokens,
-		"completion_tokens": usage.CompletionTokens,
-		"total_tokens":      usage.TotalTokens,
-		"context_limit":     usage.ContextLimit,
-		"usage_percent":     usage.UsagePercent,
-		"threshold_reached": usage.ThresholdReached,
-		"was_compacted":     usage.WasCompacted,
-	})
+	r.emit(backend.UsageReport(backend.Usage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+		ContextLimit:     usage.ContextLimit,
+		UsagePercent:     usage.UsagePercent,
+		ThresholdReached: usage.ThresholdReached,
+		WasCompacted:     usage.WasCompacted,
+		Cost:             result.Cost,
+	}))
 
 	// Check if we need to auto-save and start new session
 	if usage.ThresholdReached {
-		r.emitEvent("lifecycle", map[string]interface{}{
-			"type":   "context_threshold",
-			"status": "saving",
-		})
+		r.emit(backend.LifecycleEvent(backend.Lifecycle{Phase: "context_threshold", Status: "saving"}, ""))
 
 		if archivePath, err := r.saveAndRotateSession(sessionID, usage, "threshold"); err != nil {
-			r.emitEvent("message.error", map[string]interface{}{
-				"error": fmt.Sprintf("Failed to save session: %v", err),
-			})
+			r.emit(backend.Error(fmt.Sprintf("Failed to save session: %v", err)))
 		} else {
-			r.emitEvent("lifecycle", map[string]interface{}{
-				"type":         "context_threshold",
-				"status":       "saved",
-				"archive_path": archivePath,
-			})
+			r.emit(backend.LifecycleEvent(backend.Lifecycle{Phase: "context_threshold", Status: "saved"}, "Session archived to "+archivePath))
 		}
 	} else if result.WasCompacted {
 		// OpenCode already compacted - save for our records
-		r.emitEvent("lifecycle", map[string]interface{}{
-			"type":   "session_compacted",
-			"status": "detected",
-		})
+		r.emit(backend.LifecycleEvent(backend.Lifecycle{Phase: "session_compacted", Status: "detected"}, ""))
 		if _, err := r.saveAndRotateSession(sessionID, usage, "compacted"); err != nil {
-			r.emitEvent("message.error", map[string]interface{}{
-				"error": fmt.Sprintf("Failed to save compacted session: %v", err),
-			})
+			r.emit(backend.Error(fmt.Sprintf("Failed to save compacted session: %v", err)))
 		}
 	}
 
 	return content, sessionID, nil
 }
 
-// emitEvent sends an event to the output callback if set
-func (r *Runner) emitEvent(eventType string, data map[string]interface{}) {
-	if r.outputCallback == nil {
-		return
+// emit sends an event to the output callback if set
+func (r *Runner) emit(event backend.Event) {
+	if r.outputCallback != nil {
+		r.outputCallback(event)
 	}
+}
 
-	event := make(map[string]interface{})
-	for k, v := range data {
-		event[k] = v
-	}
-	event["type"] = eventType  // For codex event parser compatibility
-	event["event"] = eventType // Legacy field
+// setLiveSession records the session a message is in flight on, or none
+func (r *Runner) setLiveSession(sessionID string) {
+	r.liveMu.Lock()
+	r.liveSession = sessionID
+	r.liveMu.Unlock()
+}
 
-	r.outputCallback(event)
+// Steer queues operator guidance into the session working on the current
+// message, so the agent sees it without waiting for the next loop. It
+// reports false when no message is in flight.
+func (r *Runner) Steer(message string) (bool, error) {
+	r.liveMu.Lock()
+	sessionID := r.liveSession
+	r.liveMu.Unlock()
+	if sessionID == "" {
+		return false, nil
+	}
+	if err := r.client.SendMessageAsync(sessionID, "Guidance from the human operator (follow it from now on):\n"+message); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // NewSession clears the current session and starts fresh
@@ -256,9 +256,7 @@ func (r *Runner) saveAndRotateSession(sessionID string, usage ContextUsage, reas
 	}
 
 	// Create new session
-	r.emitEvent("message", map[string]interface{}{
-		"content": fmt.Sprintf("Context at %.0f%%, creating new session...", usage.UsagePercent*100),
-	})
+	r.emit(backend.Note(fmt.Sprintf("Context at %.0f%%, creating new session...", usage.UsagePercent*100)))
 
 	newSessionID, err := r.client.CreateSession()
 	if err != nil {
@@ -274,9 +272,7 @@ func (r *Runner) saveAndRotateSession(sessionID string, usage ContextUsage, reas
 	r.sessionID = newSessionID
 	r.contextTracker.Reset()
 
-	r.emitEvent("message", map[string]interface{}{
-		"content": fmt.Sprintf("New session created: %s", shortSessionID(newSessionID)),
-	})
+	r.emit(backend.Note(fmt.Sprintf("New session created: %s", shortSessionID(newSessionID))))
 
 	return archivePath, nil
 }
@@ -294,7 +290,9 @@ func (r *Runner) Stop() error {
 	return nil
 }
 
-// handleSSEEvent parses SSE events and emits them in TUI-compatible format
+// handleSSEEvent translates an SSE event into backend events. Message and
+// reasoning parts arrive as cumulative updates, so they are merged per part and
+// emitted as cumulative text.
 func (r *Runner) handleSSEEvent(sessionID string, event SSEEvent) {
 	switch event.Type {
 	case "message.part.updated":
@@ -312,58 +310,16 @@ func (r *Runner) handleSSEEvent(sessionID string, event SSEEvent) {
 				reasoning := r.mergePartText(part.ID, part.Text, part.Delta, r.reasoningParts, &r.reasoningOrder)
 				if reasoning != "" && reasoning != r.lastReasoning {
 					r.lastReasoning = reasoning
-					r.emitEvent("item.completed", map[string]interface{}{
-						"item": map[string]interface{}{
-							"type": "reasoning",
-							"text": reasoning,
-						},
-					})
+					r.emit(backend.Reasoning(reasoning, true))
 				}
 			case "text":
 				message := r.mergePartText(part.ID, part.Text, part.Delta, r.messageParts, &r.messageOrder)
 				if message != "" && message != r.lastMessage {
 					r.lastMessage = message
-					r.emitEvent("message", map[string]interface{}{
-						"type":    "message",
-						"content": message,
-					})
+					r.emit(backend.MessageDelta(message, true))
 				}
 			case "tool":
-				// Extract tool name and target
-				toolName := part.Tool
-				if toolName == "" {
-					toolName = "tool"
-				}
-				target := ""
-				status := "started"
-				if part.State != nil {
-					if part.State.Status == "completed" || part.State.Status == "done" {
-						status = "completed"
-					}
-					// Extract target from input
-					if input := part.State.Input; input != nil {
-						// Try various field names for file paths
-						if fp, ok := input["filePath"].(string); ok {
-							target = fp
-						} else if fp, ok := input["file_path"].(string); ok {
-							target = fp
-						} else if fp, ok := input["path"].(string); ok {
-							target = fp
-						} else if cmd, ok := input["command"].(string); ok {
-							if len(cmd) > 50 {
-								target = cmd[:50] + "..."
-							} else {
-								target = cmd
-							}
-						}
-					}
-				}
-				// Emit tool event
-				r.emitEvent("tool_use", map[string]interface{}{
-					"name":   toolName,
-					"target": target,
-					"status": status,
-				})
+				r.emit(toolEvent(part.Tool, part.CallID, part.State))
 			}
 		}
 
@@ -373,28 +329,13 @@ func (r *Runner) handleSSEEvent(sessionID string, event SSEEvent) {
 			status := props.Status
 			switch status.Type {
 			case "busy":
-				r.emitEvent("lifecycle", map[string]interface{}{
-					"type":   "status",
-					"status": "processing",
-				})
+				r.emit(backend.LifecycleEvent(backend.Lifecycle{Phase: "status", Status: "processing"}, ""))
 			case "retry":
-				r.emitEvent("lifecycle", map[string]interface{}{
-					"type":    "status",
-					"status":  "retry",
-					"attempt": status.Attempt,
-					"message": status.Message,
-				})
+				r.emit(backend.LifecycleEvent(backend.Lifecycle{Phase: "status", Status: "retry", Attempt: status.Attempt}, status.Message))
 			case "idle":
-				r.emitEvent("lifecycle", map[string]interface{}{
-					"type":   "status",
-					"status": "complete",
-				})
+				r.emit(backend.LifecycleEvent(backend.Lifecycle{Phase: "status", Status: "complete"}, ""))
 			case "error":
-				r.emitEvent("lifecycle", map[string]interface{}{
-					"type":    "status",
-					"status":  "error",
-					"message": status.Message,
-				})
+				r.emit(backend.LifecycleEvent(backend.Lifecycle{Phase: "status", Status: "error"}, status.Message))
 			}
 		}
 
@@ -405,16 +346,67 @@ func (r *Runner) handleSSEEvent(sessionID string, event SSEEvent) {
 				if d.File == "" {
 					continue
 				}
-				r.emitEvent("tool_use", map[string]interface{}{
-					"name":   "apply_patch",
-					"target": d.File,
-					"status": "completed",
-				})
+				r.emit(backend.FileChanged(backend.FileChange{Path: d.File, Additions: d.Additions, Deletions: d.Deletions}))
 			}
 		}
 	}
 }
 
+// toolEvent converts a tool part into a tool_start or tool_end event
+func toolEvent(name, callID string, state *ToolState) backend.Event {
+	tool := backend.Tool{ID: callID, Name: name}
+	if tool.Name == "" {
+		tool.Name = "tool"
+	}
+	if state == nil {
+		return backend.ToolStart(tool)
+	}
+
+	tool.Input = state.Input
+	tool.Target = toolTarget(state.Input)
+	switch state.Status {
+	case "completed", "done":
+		tool.Output = state.Output
+		tool.ExitCode = state.Metadata.Exit
+		return backend.ToolEnd(tool)
+	case "error":
+		tool.Output = state.Output
+		tool.Error = state.Error
+		if tool.Error == "" {
+			tool.Error = "tool failed"
+		}
+		return backend.ToolEnd(tool)
+	}
+	return backend.ToolStart(tool)
+}
+
+// toolTarget picks the file path or command a tool input refers to
+func toolTarget(input json.RawMessage) string {
+	if len(input) == 0 {
+		return ""
+	}
+	var args struct {
+		FilePath      string `json:"filePath"`
+		FilePathSnake string `json:"file_path"`
+		Path          string `json:"path"`
+		Command       string `json:"command"`
+	}
+	if err := json.Unmarshal(input, &args); err != nil {
+		return ""
+	}
+	switch {
+	case args.FilePath != "":
+		return args.FilePath
+	case args.FilePathSnake != "":
+		return args.FilePathSnake
+	case args.Path != "":
+		return args.Path
+	case len(args.Command) > 50:
+		return args.Command[:50] + "..."
+	}
+	return args.Command
+}
+
 func (r *Runner) mergePartText(partID, text, delta string, partMap map[string]string, order *[]string) string {
 	if partID == "" {
 		return ""
@@ -456,9 +448,7 @@ func shortSessionID(id string) string {
 // startManagedServer starts a child OpenCode server in the project directory
 func (r *Runner) startManagedServer() error {
 	// Emit startup message to TUI
-	r.emitEvent("message", map[string]interface{}{
-		"content": "Starting OpenCode server...",
-	})
+	r.emit(backend.Note("Starting OpenCode server..."))
 
 	r.server = NewServer(ServerConfig{
 		ProjectDir: r.cfg.ProjectPath,
@@ -480,9 +470,7 @@ func (r *Runner) startManagedServer() error {
 	})
 
 	// Emit server ready message to TUI
-	r.emitEvent("message", map[string]interface{}{
-		"content": fmt.Sprintf("OpenCode server ready at %s", r.server.URL()),
-	})
+	r.emit(backend.Note(fmt.Sprintf("OpenCode server ready at %s", r.server.URL())))
 
 	return nil
 }
@@ -5,18 +5,26 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync/atomic"
 	"testing"
 
+	"github.com/brainwhocodes/lisa-loop/internal/backend"
 	"github.com/brainwhocodes/lisa-loop/internal/config"
 )
 
-func TestHandleSSEEvent_MessageDeltaNotDuplicatedAfterEmptyStarter(t *testing.T) {
-	r := &Runner{}
-	var got []map[string]interface{}
-	r.SetOutputCallback(func(event map[string]interface{}) {
+// recordEvents captures everything the runner emits
+func recordEvents(r *Runner) *[]backend.Event {
+	var got []backend.Event
+	r.SetOutputCallback(func(event backend.Event) {
 		got = append(got, event)
 	})
+	return &got
+}
+
+func TestHandleSSEEvent_MessageDeltaNotDuplicatedAfterEmptyStarter(t *testing.T) {
+	r := &Runner{}
+	got := recordEvents(r)
 
 	r.handleSSEEvent("session-1", SSEEvent{Type: "message.part.updated", Properties: mustMarshalJSON(map[string]interface{}{
 		"part": map[string]interface{}{"id": "p1", "type": "text"},
@@ -25,23 +33,20 @@ func TestHandleSSEEvent_MessageDeltaNotDuplicatedAfterEmptyStarter(t *testing.T)
 		"part": map[string]interface{}{"id": "p1", "type": "text", "delta": "Hi"},
 	})})
 
-	if len(got) != 1 {
-		t.Fatalf("expected exactly 1 emitted message event, got %d", len(got))
+	if len(*got) != 1 {
+		t.Fatalf("expected exactly 1 emitted message event, got %d", len(*got))
 	}
-	if got[0]["type"] != "message" {
-		t.Fatalf("expected type=message, got %v", got[0]["type"])
+	if (*got)[0].Kind != backend.KindMessageDelta {
+		t.Fatalf("expected kind=message_delta, got %v", (*got)[0].Kind)
 	}
-	if got[0]["content"] != "Hi" {
-		t.Fatalf("expected non-duplicated content 'Hi', got %v", got[0]["content"])
+	if (*got)[0].Text != "Hi" {
+		t.Fatalf("expected non-duplicated content 'Hi', got %v", (*got)[0].Text)
 	}
 }
 
 func TestHandleSSEEvent_MessageDeltaAggregates(t *testing.T) {
 	r := &Runner{}
-	var got []map[string]interface{}
-	r.SetOutputCallback(func(event map[string]interface{}) {
-		got = append(got, event)
-	})
+	got := recordEvents(r)
 
 	first := SSEEvent{Type: "message.part.updated", Properties: mustMarshalJSON(map[string]interface{}{
 		"part": map[string]interface{}{"id": "p1", "type": "text", "text": "Hel"},
@@ -53,23 +58,21 @@ func TestHandleSSEEvent_MessageDeltaAggregates(t *testing.T) {
 	r.handleSSEEvent("session-1", first)
 	r.handleSSEEvent("session-1", second)
 
-	if len(got) != 2 {
-		t.Fatalf("expected 2 emitted events, got %d", len(got))
+	if len(*got) != 2 {
+		t.Fatalf("expected 2 emitted events, got %d", len(*got))
 	}
-	if got[1]["type"] != "message" {
-		t.Fatalf("expected type=message, got %v", got[1]["type"])
+	last := (*got)[1]
+	if last.Kind != backend.KindMessageDelta || !last.Cumulative {
+		t.Fatalf("expected cumulative message_delta, got %+v", last)
 	}
-	if got[1]["content"] != "Hello" {
-		t.Fatalf("expected aggregated message 'Hello', got %v", got[1]["content"])
+	if last.Text != "Hello" {
+		t.Fatalf("expected aggregated message 'Hello', got %v", last.Text)
 	}
 }
 
 func TestHandleSSEEvent_ReasoningDeltaAggregates(t *testing.T) {
 	r := &Runner{}
-	var got []map[string]interface{}
-	r.SetOutputCallback(func(event map[string]interface{}) {
-		got = append(got, event)
-	})
+	got := recordEvents(r)
 
 	r.handleSSEEvent("session-1", SSEEvent{Type: "message.part.updated", Properties: mustMarshalJSON(map[string]interface{}{
 		"part": map[string]interface{}{"id": "r1", "type": "reasoning", "text": "Think"},
@@ -78,24 +81,73 @@ func TestHandleSSEEvent_ReasoningDeltaAggregates(t *testing.T) {
 		"part": map[string]interface{}{"id": "r1", "type": "reasoning", "delta": "ing"},
 	})})
 
-	if len(got) != 2 {
-		t.Fatalf("expected 2 emitted events, got %d", len(got))
+	if len(*got) != 2 {
+		t.Fatalf("expected 2 emitted events, got %d", len(*got))
+	}
+	if (*got)[1].Kind != backend.KindReasoning {
+		t.Fatalf("expected reasoning event, got %v", (*got)[1].Kind)
+	}
+	if (*got)[1].Text != "Thinking" {
+		t.Fatalf("expected aggregated reasoning 'Thinking', got %v", (*got)[1].Text)
+	}
+}
+
+func TestHandleSSEEvent_ToolPart(t *testing.T) {
+	r := &Runner{}
+	got := recordEvents(r)
+
+	r.handleSSEEvent("session-1", SSEEvent{Type: "message.part.updated", Properties: mustMarshalJSON(map[string]interface{}{
+		"part": map[string]interface{}{"id": "t1", "type": "tool", "tool": "edit", "callID": "call-1",
+			"state": map[string]interface{}{"status": "running", "input": map[string]interface{}{"filePath": "main.go"}}},
+	})})
+	r.handleSSEEvent("session-1", SSEEvent{Type: "message.part.updated", Properties: mustMarshalJSON(map[string]interface{}{
+		"part": map[string]interface{}{"id": "t1", "type": "tool", "tool": "edit", "callID": "call-1",
+			"state": map[string]interface{}{"status": "completed", "input": map[string]interface{}{"filePath": "main.go"}, "output": "ok"}},
+	})})
+
+	if len(*got) != 2 {
+		t.Fatalf("expected 2 tool events, got %d", len(*got))
+	}
+	start, end := (*got)[0], (*got)[1]
+	if start.Kind != backend.KindToolStart || start.Tool.Name != "edit" || start.Tool.Target != "main.go" || start.Tool.ID != "call-1" {
+		t.Fatalf("unexpected tool_start %+v", start.Tool)
 	}
-	item, ok := got[1]["item"].(map[string]interface{})
-	if !ok {
-		t.Fatalf("expected item payload, got %T", got[1]["item"])
+	if string(start.Tool.Input) != `{"filePath":"main.go"}` {
+		t.Fatalf("expected raw tool input, got %s", start.Tool.Input)
 	}
-	if item["text"] != "Thinking" {
-		t.Fatalf("expected aggregated reasoning 'Thinking', got %v", item["text"])
+	if end.Kind != backend.KindToolEnd || end.Tool.Output != "ok" {
+		t.Fatalf("unexpected tool_end %+v", end.Tool)
+	}
+}
+
+func TestHandleSSEEvent_ToolPartFailed(t *testing.T) {
+	r := &Runner{}
+	got := recordEvents(r)
+
+	r.handleSSEEvent("session-1", SSEEvent{Type: "message.part.updated", Properties: mustMarshalJSON(map[string]interface{}{
+		"part": map[string]interface{}{"id": "t1", "type": "tool", "tool": "bash", "callID": "call-1",
+			"state": map[string]interface{}{"status": "completed", "input": map[string]interface{}{"command": "go vet ./..."}, "output": "vet: bad", "metadata": map[string]interface{}{"exit": 1}}},
+	})})
+	r.handleSSEEvent("session-1", SSEEvent{Type: "message.part.updated", Properties: mustMarshalJSON(map[string]interface{}{
+		"part": map[string]interface{}{"id": "t2", "type": "tool", "tool": "read", "callID": "call-2",
+			"state": map[string]interface{}{"status": "error", "input": map[string]interface{}{"filePath": "gone.go"}, "error": "file not found"}},
+	})})
+
+	if len(*got) != 2 {
+		t.Fatalf("expected 2 tool events, got %d", len(*got))
+	}
+	bash, read := (*got)[0], (*got)[1]
+	if bash.Kind != backend.KindToolEnd || bash.Tool.ExitCode == nil || *bash.Tool.ExitCode != 1 || bash.Tool.Output != "vet: bad" {
+		t.Fatalf("unexpected bash tool_end %+v", bash.Tool)
+	}
+	if read.Kind != backend.KindToolEnd || read.Tool.Error != "file not found" || read.Tool.Target != "gone.go" {
+		t.Fatalf("unexpected failed tool_end %+v", read.Tool)
 	}
 }
 
 func TestHandleSSEEvent_SessionStatusErrorIsForwarded(t *testing.T) {
 	r := &Runner{}
-	var got map[string]interface{}
-	r.SetOutputCallback(func(event map[string]interface{}) {
-		got = event
-	})
+	got := recordEvents(r)
 
 	props, err := json.Marshal(SessionStatusProps{
 		SessionID: "session-1",
@@ -111,26 +163,24 @@ func TestHandleSSEEvent_SessionStatusErrorIsForwarded(t *testing.T) {
 
 	r.handleSSEEvent("session-1", SSEEvent{Type: "session.status", Properties: props})
 
-	if got == nil {
-		t.Fatal("expected lifecycle event")
+	if len(*got) != 1 {
+		t.Fatalf("expected 1 lifecycle event, got %d", len(*got))
 	}
-	if got["type"] != "lifecycle" {
-		t.Fatalf("expected lifecycle event type, got %v", got["type"])
+	ev := (*got)[0]
+	if ev.Kind != backend.KindLifecycle || ev.Lifecycle == nil {
+		t.Fatalf("expected lifecycle event, got %+v", ev)
 	}
-	if got["status"] != "error" {
-		t.Fatalf("expected status=error, got %v", got["status"])
+	if ev.Lifecycle.Status != "error" {
+		t.Fatalf("expected status=error, got %v", ev.Lifecycle.Status)
 	}
-	if got["message"] != "boom" {
-		t.Fatalf("expected error message forwarded, got %v", got["message"])
+	if ev.Text != "boom" {
+		t.Fatalf("expected error message forwarded, got %v", ev.Text)
 	}
 }
 
-func TestHandleSSEEvent_SessionDiffEmitsToolUse(t *testing.T) {
+func TestHandleSSEEvent_SessionDiffEmitsFileChanges(t *testing.T) {
 	r := &Runner{}
-	var got []map[string]interface{}
-	r.SetOutputCallback(func(event map[string]interface{}) {
-		got = append(got, event)
-	})
+	got := recordEvents(r)
 
 	props := mustMarshalJSON(map[string]interface{}{
 		"sessionID": "session-1",
@@ -142,20 +192,17 @@ func TestHandleSSEEvent_SessionDiffEmitsToolUse(t *testing.T) {
 
 	r.handleSSEEvent("session-1", SSEEvent{Type: "session.diff", Properties: props})
 
-	if len(got) != 2 {
-		t.Fatalf("expected 2 tool_use events, got %d", len(got))
+	if len(*got) != 2 {
+		t.Fatalf("expected 2 file_change events, got %d", len(*got))
 	}
-	for i, ev := range got {
-		if ev["type"] != "tool_use" {
-			t.Fatalf("event[%d] expected tool_use, got %v", i, ev["type"])
-		}
-		if ev["name"] != "apply_patch" {
-			t.Fatalf("event[%d] expected apply_patch name, got %v", i, ev["name"])
-		}
-		if ev["status"] != "completed" {
-			t.Fatalf("event[%d] expected completed status, got %v", i, ev["status"])
+	for i, ev := range *got {
+		if ev.Kind != backend.KindFileChange || ev.File == nil {
+			t.Fatalf("event[%d] expected file_change, got %+v", i, ev)
 		}
 	}
+	if f := (*got)[0].File; f.Path != "internal/opencode/runner.go" || f.Additions != 10 || f.Deletions != 2 {
+		t.Fatalf("unexpected file change %+v", f)
+	}
 }
 
 func TestRun_CreatesNewSessionEachCall(t *testing.T) {
@@ -225,3 +272,33 @@ func TestShortSessionID(t *testing.T) {
 		t.Fatalf("expected truncated session id, got %q", got)
 	}
 }
+
+func TestSteer_SendsIntoLiveSession(t *testing.T) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/session/session-1/prompt_async" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var req SendMessageRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		sent = append(sent, req.Parts[0].Text)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	r := NewRunner(config.Config{OpenCodeServerURL: server.URL, Timeout: 5})
+
+	if delivered, err := r.Steer("Leave the migrations alone"); delivered || err != nil {
+		t.Errorf("Steer() with no message in flight = %v, %v, want false, nil", delivered, err)
+	}
+
+	r.setLiveSession("session-1")
+	delivered, err := r.Steer("Leave the migrations alone")
+	if !delivered || err != nil {
+		t.Fatalf("Steer() = %v, %v, want delivered", delivered, err)
+	}
+	if len(sent) != 1 || !strings.HasSuffix(sent[0], "\nLeave the migrations alone") {
+		t.Errorf("sent = %q, want the guidance in the live session", sent)
+	}
+}
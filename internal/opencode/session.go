@@ -1,43 +1,31 @@
 package opencode
 
 import (
-	"os"
 	"time"
-)
 
-const sessionFile = ".opencode_session_id"
+	"github.com/brainwhocodes/lisa-loop/internal/state"
+)
 
-// LoadSessionID loads the OpenCode session ID from .opencode_session_id
+// LoadSessionID loads the OpenCode session ID from the state file
 func LoadSessionID() (string, error) {
-	data, err := os.ReadFile(sessionFile)
+	sess, err := state.LoadOpenCodeSession()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return "", nil
-		}
 		return "", err
 	}
-	return string(data), nil
+	return sess.ID, nil
 }
 
-// SaveSessionID saves the OpenCode session ID to .opencode_session_id atomically
+// SaveSessionID saves the OpenCode session ID to the state file
 func SaveSessionID(id string) error {
-	tmpPath := sessionFile + ".tmp"
-	if err := os.WriteFile(tmpPath, []byte(id), 0644); err != nil {
-		return err
-	}
-	return os.Rename(tmpPath, sessionFile)
+	return state.SaveOpenCodeSession(id)
 }
 
-// ClearSession removes the session file
+// ClearSession forgets the saved session
 func ClearSession() error {
-	err := os.Remove(sessionFile)
-	if os.IsNotExist(err) {
-		return nil
-	}
-	return err
+	return state.ClearOpenCodeSession()
 }
 
-// SessionExists checks if a session ID file exists
+// SessionExists checks if a session ID has been saved
 func SessionExists() bool {
 	id, err := LoadSessionID()
 	if err != nil {
@@ -48,16 +36,15 @@ func SessionExists() bool {
 
 // SessionAgeHours calculates the age of the session in hours
 func SessionAgeHours() (int, error) {
-	if !SessionExists() {
-		return 0, nil
-	}
-
-	info, err := os.Stat(sessionFile)
+	sess, err := state.LoadOpenCodeSession()
 	if err != nil {
 		return 0, err
 	}
+	if sess.ID == "" {
+		return 0, nil
+	}
 
-	age := time.Since(info.ModTime()).Hours()
+	age := time.Since(sess.UpdatedAt).Hours()
 	return int(age), nil
 }
 
@@ -6,9 +6,7 @@ import (
 )
 
 func TestSessionPersistence(t *testing.T) {
-	// Clean up before and after test
-	os.Remove(sessionFile)
-	defer os.Remove(sessionFile)
+	chdirTemp(t)
 
 	// Initially no session should exist
 	if SessionExists() {
@@ -56,7 +54,7 @@ func TestSessionPersistence(t *testing.T) {
 }
 
 func TestClearSession_NoFile(t *testing.T) {
-	os.Remove(sessionFile)
+	chdirTemp(t)
 
 	// Should not error if file doesn't exist
 	if err := ClearSession(); err != nil {
@@ -65,7 +63,7 @@ func TestClearSession_NoFile(t *testing.T) {
 }
 
 func TestSessionAgeHours_NoSession(t *testing.T) {
-	os.Remove(sessionFile)
+	chdirTemp(t)
 
 	age, err := SessionAgeHours()
 	if err != nil {
@@ -77,8 +75,7 @@ func TestSessionAgeHours_NoSession(t *testing.T) {
 }
 
 func TestIsSessionExpired_NoExpiry(t *testing.T) {
-	os.Remove(sessionFile)
-	defer os.Remove(sessionFile)
+	chdirTemp(t)
 
 	if err := SaveSessionID("test-session"); err != nil {
 		t.Fatalf("SaveSessionID failed: %v", err)
@@ -96,8 +93,7 @@ func TestIsSessionExpired_NoExpiry(t *testing.T) {
 }
 
 func TestIsSessionExpired_NotExpired(t *testing.T) {
-	os.Remove(sessionFile)
-	defer os.Remove(sessionFile)
+	chdirTemp(t)
 
 	if err := SaveSessionID("test-session"); err != nil {
 		t.Fatalf("SaveSessionID failed: %v", err)
@@ -108,3 +104,13 @@ func TestIsSessionExpired_NotExpired(t *testing.T) {
 		t.Error("expected new session not to be expired")
 	}
 }
+
+// chdirTemp runs the test inside an empty project directory
+func chdirTemp(t *testing.T) {
+	t.Helper()
+	origDir, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(origDir) })
+}
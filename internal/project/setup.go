@@ -243,10 +243,9 @@ func initGitRepo(projectPath string) error {
 	// Create .gitignore
 	gitignorePath := filepath.Join(projectPath, ".gitignore")
 	gitignoreContent := `# Lisa Codex
-.lisa_session
-.circuit_breaker_state
-.exit_signals
-.call_count
+.lisa/state.json
+.lisa/lock
+.lisa/*.tmp
 .response_analysis
 
 # Logs
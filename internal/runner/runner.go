@@ -1,21 +1,25 @@
 package runner
 
 import (
+	"context"
+
+	"github.com/brainwhocodes/lisa-loop/internal/backend"
 	"github.com/brainwhocodes/lisa-loop/internal/codex"
 	"github.com/brainwhocodes/lisa-loop/internal/config"
 	"github.com/brainwhocodes/lisa-loop/internal/opencode"
 )
 
-// Event is a generic event type for streaming output
-type Event = map[string]interface{}
+// Event is a typed streaming event in the backend protocol
+type Event = backend.Event
 
 // OutputCallback is called for streaming output events
 type OutputCallback func(event Event)
 
 // Runner is the interface for executing prompts
 type Runner interface {
-	// Run executes a prompt and returns the output, session ID, and any error
-	Run(prompt string) (output string, sessionID string, err error)
+	// Run executes a prompt and returns the output, session ID, and any error.
+	// Cancelling ctx interrupts the backend call.
+	Run(ctx context.Context, prompt string) (output string, sessionID string, err error)
 
 	// SetOutputCallback sets the callback for streaming output events
 	SetOutputCallback(cb OutputCallback)
@@ -24,6 +28,14 @@ type Runner interface {
 	Stop() error
 }
 
+// Steerer is implemented by runners that can deliver operator guidance into
+// the backend call in progress
+type Steerer interface {
+	// Steer sends message into the live session. It reports false, without
+	// an error, when no call is in progress.
+	Steer(message string) (bool, error)
+}
+
 // New creates a new runner based on the config backend setting
 func New(cfg config.Config) Runner {
 	switch cfg.Backend {
@@ -40,14 +52,12 @@ type codexWrapper struct {
 	runner *codex.Runner
 }
 
-func (w *codexWrapper) Run(prompt string) (string, string, error) {
-	return w.runner.Run(prompt)
+func (w *codexWrapper) Run(ctx context.Context, prompt string) (string, string, error) {
+	return w.runner.RunContext(ctx, prompt)
 }
 
 func (w *codexWrapper) SetOutputCallback(cb OutputCallback) {
-	w.runner.SetOutputCallback(func(event codex.Event) {
-		cb(Event(event))
-	})
+	w.runner.SetOutputCallback(codex.OutputCallback(cb))
 }
 
 func (w *codexWrapper) Stop() error {
@@ -59,16 +69,18 @@ type openCodeWrapper struct {
 	runner *opencode.Runner
 }
 
-func (w *openCodeWrapper) Run(prompt string) (string, string, error) {
-	return w.runner.Run(prompt)
+func (w *openCodeWrapper) Run(ctx context.Context, prompt string) (string, string, error) {
+	return w.runner.RunContext(ctx, prompt)
 }
 
 func (w *openCodeWrapper) SetOutputCallback(cb OutputCallback) {
-	w.runner.SetOutputCallback(func(event map[string]interface{}) {
-		cb(Event(event))
-	})
+	w.runner.SetOutputCallback(opencode.OutputCallback(cb))
 }
 
 func (w *openCodeWrapper) Stop() error {
 	return w.runner.Stop()
 }
+
+func (w *openCodeWrapper) Steer(message string) (bool, error) {
+	return w.runner.Steer(message)
+}
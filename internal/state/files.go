@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"time"
 )
 
@@ -68,98 +67,136 @@ func AtomicWrite(path string, data []byte) error {
 	return WriteStateFile(path, data)
 }
 
-// LoadCallCount loads the call count from .call_count
+// LoadCallCount loads the call count for the current rate limit window
 func LoadCallCount() (int, error) {
-	return LoadState(".call_count", 0)
+	s, err := Load()
+	if err != nil {
+		return 0, err
+	}
+	return s.RateLimit.CallCount, nil
 }
 
-// SaveCallCount saves the call count to .call_count atomically
+// SaveCallCount saves the call count
 func SaveCallCount(count int) error {
-	return SaveState(".call_count", count)
+	return Update(func(s *State) { s.RateLimit.CallCount = count })
 }
 
-// LoadLastReset loads last reset time from .last_reset
+// LoadLastReset loads the last rate limit reset time, defaulting to now
 func LoadLastReset() (time.Time, error) {
-	return LoadState(".last_reset", time.Now())
+	s, err := Load()
+	if err != nil {
+		return time.Now(), err
+	}
+	if s.RateLimit.LastReset.IsZero() {
+		return time.Now(), nil
+	}
+	return s.RateLimit.LastReset, nil
 }
 
-// SaveLastReset saves last reset time to .last_reset atomically
+// SaveLastReset saves the last rate limit reset time
 func SaveLastReset(t time.Time) error {
-	return SaveState(".last_reset", t)
+	return Update(func(s *State) { s.RateLimit.LastReset = t })
 }
 
-// LoadCodexSession loads Codex session ID from .codex_session_id
+// LoadCodexSession loads the Codex session ID
 func LoadCodexSession() (string, error) {
-	data, err := os.ReadFile(".codex_session_id")
+	s, err := Load()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return "", nil
-		}
 		return "", err
 	}
-	return string(data), nil
+	return s.Sessions.Codex.ID, nil
 }
 
-// SaveCodexSession saves Codex session ID to .codex_session_id atomically
+// SaveCodexSession saves the Codex session ID
 func SaveCodexSession(id string) error {
-	return AtomicWrite(".codex_session_id", []byte(id))
+	return Update(func(s *State) { s.Sessions.Codex = SessionRef{ID: id, UpdatedAt: time.Now()} })
+}
+
+// LoadOpenCodeSession loads the OpenCode session
+func LoadOpenCodeSession() (SessionRef, error) {
+	s, err := Load()
+	if err != nil {
+		return SessionRef{}, err
+	}
+	return s.Sessions.OpenCode, nil
+}
+
+// SaveOpenCodeSession saves the OpenCode session ID
+func SaveOpenCodeSession(id string) error {
+	return Update(func(s *State) { s.Sessions.OpenCode = SessionRef{ID: id, UpdatedAt: time.Now()} })
 }
 
-// LoadLisaSession loads Lisa session metadata from .lisa_session
-func LoadLisaSession() (map[string]interface{}, error) {
-	return LoadState(".lisa_session", map[string]interface{}{})
+// ClearOpenCodeSession forgets the OpenCode session
+func ClearOpenCodeSession() error {
+	return Update(func(s *State) { s.Sessions.OpenCode = SessionRef{} })
 }
 
-// SaveLisaSession saves Lisa session metadata atomically
-func SaveLisaSession(session map[string]interface{}) error {
-	return SaveState(".lisa_session", session)
+// LoadLisaSession loads Lisa session metadata
+func LoadLisaSession() (LisaSession, error) {
+	s, err := Load()
+	if err != nil {
+		return LisaSession{}, err
+	}
+	return s.Sessions.Lisa, nil
+}
+
+// SaveLisaSession saves Lisa session metadata
+func SaveLisaSession(session LisaSession) error {
+	return Update(func(s *State) { s.Sessions.Lisa = session })
 }
 
-// LoadExitSignals loads recent exit signals from .exit_signals
+// LoadExitSignals loads recent exit signals
 func LoadExitSignals() ([]string, error) {
-	return LoadState(".exit_signals", []string{})
+	s, err := Load()
+	if err != nil {
+		return []string{}, err
+	}
+	if s.ExitSignals == nil {
+		return []string{}, nil
+	}
+	return s.ExitSignals, nil
 }
 
-// SaveExitSignals saves exit signals atomically
+// SaveExitSignals saves exit signals
 func SaveExitSignals(signals []string) error {
-	return SaveState(".exit_signals", signals)
+	return Update(func(s *State) { s.ExitSignals = signals })
 }
 
-// LoadCircuitBreakerState loads circuit breaker state from .circuit_breaker_state
-func LoadCircuitBreakerState() (map[string]interface{}, error) {
-	defaultState := map[string]interface{}{
-		"state":           "CLOSED",
-		"last_check_time": time.Now().Format(time.RFC3339),
+// LoadCircuitBreakerState loads circuit breaker state, defaulting to CLOSED
+func LoadCircuitBreakerState() (CircuitBreakerState, error) {
+	s, err := Load()
+	if err != nil {
+		return CircuitBreakerState{State: "CLOSED", LastCheckTime: time.Now()}, err
+	}
+	cb := s.CircuitBreaker
+	if cb.LastCheckTime.IsZero() {
+		cb.LastCheckTime = time.Now()
 	}
-	return LoadState(".circuit_breaker_state", defaultState)
+	return cb, nil
 }
 
-// SaveCircuitBreakerState saves circuit breaker state atomically
-func SaveCircuitBreakerState(state map[string]interface{}) error {
-	return SaveState(".circuit_breaker_state", state)
+// SaveCircuitBreakerState saves circuit breaker state
+func SaveCircuitBreakerState(cb CircuitBreakerState) error {
+	return Update(func(s *State) { s.CircuitBreaker = cb })
 }
 
 // EnsureStateDir ensures the directory for state files exists
 func EnsureStateDir() error {
-	if err := os.MkdirAll(".", 0755); err != nil {
+	if err := os.MkdirAll(Dir, 0755); err != nil {
 		return fmt.Errorf("failed to create state directory: %w", err)
 	}
 	return nil
 }
 
-// CleanupOldFiles removes old temporary state files
+// CleanupOldFiles removes temporary files left by interrupted atomic writes
+// of Lisa's own state: the state file's and each legacy dotfile's ".tmp"
+// sibling. Other .tmp files in the project are never touched.
 func CleanupOldFiles() error {
-	entries, err := os.ReadDir(".")
-	if err != nil {
-		return err
-	}
-
-	for _, entry := range entries {
-		if filepath.Ext(entry.Name()) == ".tmp" {
-			if err := os.Remove(filepath.Join(".", entry.Name())); err != nil {
-				// Log but continue - best effort cleanup
-				fmt.Printf("Warning: failed to remove temp file %s: %v\n", entry.Name(), err)
-			}
+	for _, path := range append([]string{StatePath}, LegacyFiles...) {
+		tmpPath := path + ".tmp"
+		if err := os.Remove(tmpPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			// Log but continue - best effort cleanup
+			fmt.Printf("Warning: failed to remove temp file %s: %v\n", tmpPath, err)
 		}
 	}
 
@@ -201,7 +201,7 @@ func TestLoadLisaSession(t *testing.T) {
 		t.Errorf("LoadLisaSession() error = %v, want nil", err)
 	}
 
-	if len(sess) != 0 {
+	if sess.ID != "" {
 		t.Errorf("LoadLisaSession() session = %v, want empty", sess)
 	}
 }
@@ -214,10 +214,8 @@ func TestSaveLisaSession(t *testing.T) {
 	os.Chdir(tmpDir)
 
 	// Test: Save Lisa session
-	testSess := map[string]interface{}{
-		"key":    "value",
-		"number": 42,
-	}
+	now := time.Now().Truncate(time.Second)
+	testSess := LisaSession{ID: "lisa-123", CreatedAt: now, LastUsed: now}
 	err := SaveLisaSession(testSess)
 
 	if err != nil {
@@ -226,8 +224,8 @@ func TestSaveLisaSession(t *testing.T) {
 
 	// Verify
 	loaded, _ := LoadLisaSession()
-	if len(loaded) != len(testSess) {
-		t.Errorf("SaveLisaSession() session mismatch")
+	if loaded.ID != testSess.ID || !loaded.CreatedAt.Equal(now) {
+		t.Errorf("SaveLisaSession() session = %v, want %v", loaded, testSess)
 	}
 }
 
@@ -287,8 +285,8 @@ func TestLoadCircuitBreakerState(t *testing.T) {
 	}
 
 	// Should default to CLOSED state
-	if st["state"] != "CLOSED" {
-		t.Errorf("LoadCircuitBreakerState() state = %s, want CLOSED", st["state"])
+	if st.State != "CLOSED" {
+		t.Errorf("LoadCircuitBreakerState() state = %s, want CLOSED", st.State)
 	}
 }
 
@@ -300,9 +298,10 @@ func TestSaveCircuitBreakerState(t *testing.T) {
 	os.Chdir(tmpDir)
 
 	// Test: Save circuit breaker state
-	testState := map[string]interface{}{
-		"state":    "CLOSED",
-		"counters": map[string]int{"errors": 0, "no_progress": 0},
+	testState := CircuitBreakerState{
+		State:           "HALF_OPEN",
+		NoProgressCount: 2,
+		ErrorHistory:    []string{"boom"},
 	}
 	err := SaveCircuitBreakerState(testState)
 
@@ -312,8 +311,8 @@ func TestSaveCircuitBreakerState(t *testing.T) {
 
 	// Verify
 	loaded, _ := LoadCircuitBreakerState()
-	if loaded["state"] != testState["state"] {
-		t.Errorf("SaveCircuitBreakerState() state mismatch")
+	if loaded.State != testState.State || loaded.NoProgressCount != testState.NoProgressCount {
+		t.Errorf("SaveCircuitBreakerState() state = %v, want %v", loaded, testState)
 	}
 }
 
@@ -324,8 +323,10 @@ func TestCleanupOldFiles(t *testing.T) {
 	defer os.Chdir(origDir)
 	os.Chdir(tmpDir)
 
-	// Create some .tmp and .json files
-	os.WriteFile("test1.tmp", []byte("data"), 0644)
+	// Leftovers from interrupted state writes, plus files that aren't Lisa's
+	os.MkdirAll(Dir, 0755)
+	os.WriteFile(StatePath+".tmp", []byte("data"), 0644)
+	os.WriteFile(".call_count.tmp", []byte("data"), 0644)
 	os.WriteFile("test2.tmp", []byte("data"), 0644)
 	os.WriteFile("test3.json", []byte("data"), 0644)
 
@@ -336,13 +337,17 @@ func TestCleanupOldFiles(t *testing.T) {
 		t.Errorf("CleanupOldFiles() error = %v, want nil", err)
 	}
 
-	// Verify .tmp files removed, .json files kept
-	if _, err := os.Stat("test1.tmp"); err == nil {
-		t.Error("test1.tmp should have been removed")
+	// Verify only Lisa's own temp files are removed
+	if _, err := os.Stat(StatePath + ".tmp"); err == nil {
+		t.Error("state.json.tmp should have been removed")
+	}
+
+	if _, err := os.Stat(".call_count.tmp"); err == nil {
+		t.Error(".call_count.tmp should have been removed")
 	}
 
-	if _, err := os.Stat("test2.tmp"); err == nil {
-		t.Error("test2.tmp should have been removed")
+	if _, err := os.Stat("test2.tmp"); err != nil {
+		t.Error("test2.tmp is not a state file and should have been kept")
 	}
 
 	if _, err := os.Stat("test3.json"); err != nil {
@@ -0,0 +1,176 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// LockPath is the advisory lock held by the process driving the project
+var LockPath = filepath.Join(Dir, "lock")
+
+// lockGuardPath is OS-locked while a process decides who holds LockPath, so
+// two processes can't both break the same stale lock. The OS lock goes away
+// with its process, so the guard itself is never stale.
+func lockGuardPath() string {
+	return LockPath + ".guard"
+}
+
+// staleLockFound runs between finding a stale lock and breaking it. Tests
+// can replace it to hold that window open.
+var staleLockFound = func() {}
+
+// unreadableLockGrace is how long an empty or corrupt lock file is trusted,
+// covering the window between creating the file and writing its contents
+const unreadableLockGrace = 5 * time.Second
+
+// Lock is an advisory single-instance lock on a project
+type Lock struct {
+	PID       int       `json:"pid"`
+	Host      string    `json:"host"`
+	StartedAt time.Time `json:"started_at"`
+	Command   string    `json:"command,omitempty"`
+	Profile   string    `json:"profile,omitempty"` // Config profile the holder runs with
+}
+
+// LockedError is returned when another live process holds the project lock
+type LockedError struct {
+	Holder Lock
+}
+
+func (e *LockedError) Error() string {
+	msg := fmt.Sprintf("project is locked by PID %d on %s (started %s", e.Holder.PID, e.Holder.Host, e.Holder.StartedAt.Local().Format("2006-01-02 15:04:05"))
+	if e.Holder.Command != "" {
+		msg += ", running '" + e.Holder.Command + "'"
+	}
+	return msg + fmt.Sprintf("); stop it first, or remove %s if it is no longer running", LockPath)
+}
+
+// AcquireLock takes the project lock for the current process, recording the
+// command and config profile it runs with. A lock left by a
+// process that is no longer running on this host is broken and reported via
+// brokeStale. If a live process holds the lock, a *LockedError is returned.
+// Processes acquiring at the same time take turns, so only one of them can
+// break a stale lock and take it.
+func AcquireLock(command, profile string) (lock *Lock, brokeStale *Lock, err error) {
+	if err := EnsureStateDir(); err != nil {
+		return nil, nil, err
+	}
+
+	host, _ := os.Hostname()
+	lock = &Lock{PID: os.Getpid(), Host: host, StartedAt: time.Now(), Command: command, Profile: profile}
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal lock: %w", err)
+	}
+
+	guard, err := os.OpenFile(lockGuardPath(), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open lock guard %s: %w", lockGuardPath(), err)
+	}
+	defer guard.Close()
+	if err := lockFile(guard); err != nil {
+		return nil, nil, fmt.Errorf("failed to lock %s: %w", lockGuardPath(), err)
+	}
+	defer unlockFile(guard)
+
+	// Two attempts: the second follows breaking a stale lock
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(LockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, werr := f.Write(data)
+			cerr := f.Close()
+			if werr != nil || cerr != nil {
+				os.Remove(LockPath)
+				return nil, nil, fmt.Errorf("failed to write lock file %s: %w", LockPath, errors.Join(werr, cerr))
+			}
+			return lock, brokeStale, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, nil, fmt.Errorf("failed to create lock file %s: %w", LockPath, err)
+		}
+
+		holder, stale := inspectLock()
+		if !stale {
+			return nil, nil, &LockedError{Holder: holder}
+		}
+		staleLockFound()
+		if err := os.Remove(LockPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, nil, fmt.Errorf("failed to remove stale lock %s: %w", LockPath, err)
+		}
+		brokeStale = &holder
+	}
+
+	return nil, nil, fmt.Errorf("failed to acquire %s: lock was re-created by another process", LockPath)
+}
+
+// Release removes the lock file if it still belongs to this lock
+func (l *Lock) Release() error {
+	if l == nil {
+		return nil
+	}
+	holder, err := ReadLock()
+	if err != nil || holder == nil || holder.PID != l.PID || holder.Host != l.Host {
+		return err
+	}
+	if err := os.Remove(LockPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to release lock %s: %w", LockPath, err)
+	}
+	return nil
+}
+
+// ReadLock returns the current lock holder, or nil if the project is not locked
+func ReadLock() (*Lock, error) {
+	data, err := os.ReadFile(LockPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock file %s: %w", LockPath, err)
+	}
+	var lock Lock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lock file %s: %w", LockPath, err)
+	}
+	return &lock, nil
+}
+
+// inspectLock reads the existing lock and decides whether it is stale
+func inspectLock() (Lock, bool) {
+	holder, err := ReadLock()
+	if holder == nil {
+		// Vanished (not stale, just retry) or unreadable
+		if err == nil {
+			return Lock{}, true
+		}
+		return Lock{}, time.Since(modTime(LockPath)) > unreadableLockGrace
+	}
+	return *holder, holder.IsStale()
+}
+
+// IsStale reports whether the lock holder is known to be gone. Locks held from
+// another host can't be checked and are never considered stale.
+func (l *Lock) IsStale() bool {
+	host, _ := os.Hostname()
+	if l.Host != host {
+		return false
+	}
+	return !processAlive(l.PID)
+}
+
+// processAlive reports whether a process with the given PID exists
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
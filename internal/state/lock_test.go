@@ -0,0 +1,155 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func writeLockFile(t *testing.T, lock Lock) {
+	t.Helper()
+	os.MkdirAll(Dir, 0755)
+	data, _ := json.Marshal(lock)
+	if err := os.WriteFile(LockPath, data, 0644); err != nil {
+		t.Fatalf("failed to write lock: %v", err)
+	}
+}
+
+func TestAcquireLock_HeldByLiveProcess(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(tmpDir)
+
+	lock, stale, err := AcquireLock("run", "ci")
+	if err != nil {
+		t.Fatalf("AcquireLock() error = %v", err)
+	}
+	if stale != nil {
+		t.Errorf("AcquireLock() broke stale lock %v on a fresh project", stale)
+	}
+
+	_, _, err = AcquireLock("sync", "")
+	var locked *LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("second AcquireLock() error = %v, want *LockedError", err)
+	}
+	if locked.Holder.PID != os.Getpid() || locked.Holder.Command != "run" || locked.Holder.Profile != "ci" {
+		t.Errorf("LockedError holder = %+v, want this process running 'run' with profile ci", locked.Holder)
+	}
+	if !strings.Contains(err.Error(), "PID") {
+		t.Errorf("LockedError message %q should name the holder PID", err.Error())
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, err := os.Stat(LockPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Release() left %s behind", LockPath)
+	}
+}
+
+func TestAcquireLock_BreaksStaleLock(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(tmpDir)
+
+	host, _ := os.Hostname()
+	writeLockFile(t, Lock{PID: 1 << 30, Host: host, StartedAt: time.Now().Add(-time.Hour)})
+
+	lock, stale, err := AcquireLock("run", "")
+	if err != nil {
+		t.Fatalf("AcquireLock() error = %v", err)
+	}
+	defer lock.Release()
+	if stale == nil || stale.PID != 1<<30 {
+		t.Errorf("AcquireLock() stale = %v, want the dead holder", stale)
+	}
+
+	holder, _ := ReadLock()
+	if holder == nil || holder.PID != os.Getpid() {
+		t.Errorf("ReadLock() = %v, want this process", holder)
+	}
+}
+
+func TestAcquireLock_ConcurrentStaleTakeover(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(tmpDir)
+
+	// Give every acquirer time to find the stale lock before any breaks it
+	orig := staleLockFound
+	defer func() { staleLockFound = orig }()
+	staleLockFound = func() { time.Sleep(5 * time.Millisecond) }
+
+	host, _ := os.Hostname()
+	const acquirers = 8
+	for round := 0; round < 5; round++ {
+		writeLockFile(t, Lock{PID: 1 << 30, Host: host, StartedAt: time.Now().Add(-time.Hour)})
+
+		var wg sync.WaitGroup
+		var won atomic.Int32
+		start := make(chan struct{})
+		for i := 0; i < acquirers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				_, _, err := AcquireLock("run", "")
+				var locked *LockedError
+				switch {
+				case err == nil:
+					won.Add(1)
+				case !errors.As(err, &locked):
+					t.Errorf("AcquireLock() error = %v, want success or *LockedError", err)
+				}
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		if n := won.Load(); n != 1 {
+			t.Fatalf("round %d: %d acquirers took the stale lock, want exactly 1", round, n)
+		}
+		os.Remove(LockPath)
+	}
+}
+
+func TestAcquireLock_OtherHostIsNotStale(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(tmpDir)
+
+	writeLockFile(t, Lock{PID: 1 << 30, Host: "some-other-host", StartedAt: time.Now()})
+
+	_, _, err := AcquireLock("run", "")
+	var locked *LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("AcquireLock() error = %v, want *LockedError", err)
+	}
+}
+
+func TestLockRelease_KeepsOtherHoldersLock(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(tmpDir)
+
+	mine := &Lock{PID: os.Getpid(), Host: "me"}
+	writeLockFile(t, Lock{PID: 42, Host: "someone-else"})
+
+	if err := mine.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, err := os.Stat(LockPath); err != nil {
+		t.Errorf("Release() removed a lock it did not own")
+	}
+}
@@ -0,0 +1,299 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dir is the project-local directory that holds all Lisa state
+const Dir = ".lisa"
+
+// SchemaVersion is the current version of the state file layout
+const SchemaVersion = 1
+
+// StatePath is the consolidated state file
+var StatePath = filepath.Join(Dir, "state.json")
+
+// State is everything Lisa persists between runs
+type State struct {
+	Version        int                 `json:"version"`
+	RateLimit      RateLimitState      `json:"rate_limit"`
+	CircuitBreaker CircuitBreakerState `json:"circuit_breaker"`
+	ExitSignals    []string            `json:"exit_signals"`
+	Sessions       Sessions            `json:"sessions"`
+}
+
+// RateLimitState tracks API calls in the current rate limit window
+type RateLimitState struct {
+	CallCount int       `json:"call_count"`
+	LastReset time.Time `json:"last_reset"`
+}
+
+// CircuitBreakerState is the persisted circuit breaker
+type CircuitBreakerState struct {
+	State           string    `json:"state"` // CLOSED, HALF_OPEN or OPEN
+	NoProgressCount int       `json:"no_progress_count"`
+	ErrorHistory    []string  `json:"error_history"`
+	LastCheckTime   time.Time `json:"last_check_time"`
+}
+
+// Sessions holds backend session identifiers
+type Sessions struct {
+	Codex    SessionRef  `json:"codex"`
+	OpenCode SessionRef  `json:"opencode"`
+	Lisa     LisaSession `json:"lisa"`
+}
+
+// SessionRef is a backend session ID and when it was last written
+type SessionRef struct {
+	ID        string    `json:"id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LisaSession is Lisa's own session metadata
+type LisaSession struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	LastUsed  time.Time `json:"last_used"`
+}
+
+// Legacy dotfiles in the project root, replaced by StatePath
+const (
+	legacyCallCount      = ".call_count"
+	legacyLastReset      = ".last_reset"
+	legacyExitSignals    = ".exit_signals"
+	legacyCircuitBreaker = ".circuit_breaker_state"
+	legacyCodexSession   = ".codex_session_id"
+	legacyOpenCodeSess   = ".opencode_session_id"
+	legacyLisaSession    = ".lisa_session"
+)
+
+// LegacyFiles lists the pre-.lisa/ state files that are migrated automatically
+var LegacyFiles = []string{
+	legacyCallCount,
+	legacyLastReset,
+	legacyExitSignals,
+	legacyCircuitBreaker,
+	legacyCodexSession,
+	legacyOpenCodeSess,
+	legacyLisaSession,
+}
+
+// migration upgrades the raw JSON of a state file from one version to the next
+type migration func(raw map[string]json.RawMessage) error
+
+// migrations maps a schema version to the step that upgrades it to version+1.
+// Version 0 is the legacy dotfile layout, handled by migrateLegacy.
+var migrations = map[int]migration{}
+
+// mu serializes read-modify-write cycles on the state file within a process
+var mu sync.Mutex
+
+// NewState returns an empty state at the current schema version
+func NewState() *State {
+	return &State{
+		Version:        SchemaVersion,
+		CircuitBreaker: CircuitBreakerState{State: "CLOSED"},
+		ExitSignals:    []string{},
+	}
+}
+
+// Load reads the state file, migrating legacy dotfiles or older schema
+// versions on first use. A missing state file yields an empty state.
+func Load() (*State, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	return load()
+}
+
+// Update applies fn to the current state and saves the result atomically
+func Update(fn func(s *State)) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	s, err := load()
+	if err != nil {
+		return err
+	}
+	fn(s)
+	return save(s)
+}
+
+// Clean removes the state file and any legacy dotfiles. Other files under
+// Dir (session archives, questions) are left alone.
+func Clean() ([]string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var removed []string
+	for _, path := range append([]string{StatePath}, LegacyFiles...) {
+		if err := os.Remove(path); err == nil {
+			removed = append(removed, path)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return removed, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	return removed, nil
+}
+
+// load reads or migrates the state; callers must hold mu
+func load() (*State, error) {
+	data, err := os.ReadFile(StatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return migrateLegacy()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file %s: %w", StatePath, err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", StatePath, err)
+	}
+	version := 0
+	if v, ok := raw["version"]; ok {
+		if err := json.Unmarshal(v, &version); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: invalid version: %w", StatePath, err)
+		}
+	}
+	if version > SchemaVersion {
+		return nil, fmt.Errorf("%s has schema version %d, newer than supported version %d", StatePath, version, SchemaVersion)
+	}
+
+	migrated := version < SchemaVersion
+	for ; version < SchemaVersion; version++ {
+		step, ok := migrations[version]
+		if !ok {
+			return nil, fmt.Errorf("no migration for %s from schema version %d", StatePath, version)
+		}
+		if err := step(raw); err != nil {
+			return nil, fmt.Errorf("failed to migrate %s from schema version %d: %w", StatePath, version, err)
+		}
+	}
+
+	if migrated {
+		versionJSON, _ := json.Marshal(SchemaVersion)
+		raw["version"] = versionJSON
+		if data, err = json.Marshal(raw); err != nil {
+			return nil, fmt.Errorf("failed to re-encode migrated state: %w", err)
+		}
+	}
+
+	s := NewState()
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", StatePath, err)
+	}
+	if s.CircuitBreaker.State == "" {
+		s.CircuitBreaker.State = "CLOSED"
+	}
+	if migrated {
+		if err := save(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// save writes the state atomically; callers must hold mu
+func save(s *State) error {
+	if err := EnsureStateDir(); err != nil {
+		return err
+	}
+	s.Version = SchemaVersion
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	return WriteStateFile(StatePath, data)
+}
+
+// migrateLegacy imports the pre-.lisa/ dotfiles into a new state. If any were
+// found, the state file is written and the dotfiles are removed. Unreadable
+// legacy files are skipped rather than blocking startup.
+func migrateLegacy() (*State, error) {
+	s := NewState()
+	found := false
+
+	read := func(name string) (string, bool) {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return "", false
+		}
+		found = true
+		return strings.TrimSpace(string(data)), true
+	}
+
+	if v, ok := read(legacyCallCount); ok {
+		s.RateLimit.CallCount, _ = strconv.Atoi(v)
+	}
+	if v, ok := read(legacyLastReset); ok {
+		_ = json.Unmarshal([]byte(v), &s.RateLimit.LastReset)
+	}
+	if v, ok := read(legacyExitSignals); ok {
+		_ = json.Unmarshal([]byte(v), &s.ExitSignals)
+	}
+	if v, ok := read(legacyCircuitBreaker); ok {
+		var legacy struct {
+			State           string   `json:"state"`
+			NoProgressCount int      `json:"no_progress_count"`
+			ErrorHistory    []string `json:"error_history"`
+			LastCheckTime   string   `json:"last_check_time"`
+		}
+		if json.Unmarshal([]byte(v), &legacy) == nil {
+			if legacy.State != "" {
+				s.CircuitBreaker.State = legacy.State
+			}
+			s.CircuitBreaker.NoProgressCount = legacy.NoProgressCount
+			s.CircuitBreaker.ErrorHistory = legacy.ErrorHistory
+			s.CircuitBreaker.LastCheckTime, _ = time.Parse(time.RFC3339, legacy.LastCheckTime)
+		}
+	}
+	if v, ok := read(legacyCodexSession); ok {
+		s.Sessions.Codex = SessionRef{ID: v, UpdatedAt: modTime(legacyCodexSession)}
+	}
+	if v, ok := read(legacyOpenCodeSess); ok {
+		s.Sessions.OpenCode = SessionRef{ID: v, UpdatedAt: modTime(legacyOpenCodeSess)}
+	}
+	if v, ok := read(legacyLisaSession); ok {
+		var legacy map[string]interface{}
+		if json.Unmarshal([]byte(v), &legacy) == nil {
+			s.Sessions.Lisa.ID, _ = legacy["id"].(string)
+			s.Sessions.Lisa.CreatedAt = parseLegacyTime(legacy["created_at"])
+			s.Sessions.Lisa.LastUsed = parseLegacyTime(legacy["last_used"])
+		}
+	}
+
+	if !found {
+		return s, nil
+	}
+	if err := save(s); err != nil {
+		return nil, fmt.Errorf("failed to migrate legacy state: %w", err)
+	}
+	for _, name := range LegacyFiles {
+		_ = os.Remove(name)
+	}
+	return s, nil
+}
+
+// modTime returns a file's modification time, or the zero time if unavailable
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// parseLegacyTime parses an RFC3339 string from an untyped legacy map
+func parseLegacyTime(v interface{}) time.Time {
+	str, _ := v.(string)
+	t, _ := time.Parse(time.RFC3339, str)
+	return t
+}
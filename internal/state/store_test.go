@@ -0,0 +1,151 @@
+package state
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad_MigratesLegacyDotfiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(tmpDir)
+
+	legacy := map[string]string{
+		".call_count":            "7",
+		".last_reset":            `"2025-01-02T03:04:05Z"`,
+		".exit_signals":          `["done"]`,
+		".circuit_breaker_state": `{"state":"HALF_OPEN","no_progress_count":2,"error_history":["e1"],"last_check_time":"2025-01-02T03:04:05Z"}`,
+		".codex_session_id":      "thread-1",
+		".opencode_session_id":   "oc-1",
+		".lisa_session":          `{"id":"lisa-1","created_at":"2025-01-01T00:00:00Z","last_used":"2025-01-02T00:00:00Z"}`,
+	}
+	for name, content := range legacy {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	s, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if s.Version != SchemaVersion {
+		t.Errorf("Load() version = %d, want %d", s.Version, SchemaVersion)
+	}
+	if s.RateLimit.CallCount != 7 {
+		t.Errorf("Load() call count = %d, want 7", s.RateLimit.CallCount)
+	}
+	if want := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC); !s.RateLimit.LastReset.Equal(want) {
+		t.Errorf("Load() last reset = %v, want %v", s.RateLimit.LastReset, want)
+	}
+	if len(s.ExitSignals) != 1 || s.ExitSignals[0] != "done" {
+		t.Errorf("Load() exit signals = %v, want [done]", s.ExitSignals)
+	}
+	if s.CircuitBreaker.State != "HALF_OPEN" || s.CircuitBreaker.NoProgressCount != 2 || len(s.CircuitBreaker.ErrorHistory) != 1 {
+		t.Errorf("Load() circuit breaker = %+v, want HALF_OPEN/2/[e1]", s.CircuitBreaker)
+	}
+	if s.Sessions.Codex.ID != "thread-1" || s.Sessions.Codex.UpdatedAt.IsZero() {
+		t.Errorf("Load() codex session = %+v, want thread-1 with mtime", s.Sessions.Codex)
+	}
+	if s.Sessions.OpenCode.ID != "oc-1" {
+		t.Errorf("Load() opencode session = %+v, want oc-1", s.Sessions.OpenCode)
+	}
+	if s.Sessions.Lisa.ID != "lisa-1" || s.Sessions.Lisa.CreatedAt.IsZero() {
+		t.Errorf("Load() lisa session = %+v, want lisa-1", s.Sessions.Lisa)
+	}
+
+	if _, err := os.Stat(StatePath); err != nil {
+		t.Errorf("Load() did not write %s: %v", StatePath, err)
+	}
+	for _, name := range LegacyFiles {
+		if _, err := os.Stat(name); err == nil {
+			t.Errorf("Load() left legacy file %s behind", name)
+		}
+	}
+}
+
+func TestLoad_NoStateIsEmpty(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(tmpDir)
+
+	s, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if s.CircuitBreaker.State != "CLOSED" {
+		t.Errorf("Load() circuit state = %s, want CLOSED", s.CircuitBreaker.State)
+	}
+	if _, err := os.Stat(Dir); err == nil {
+		t.Errorf("Load() created %s without any state to write", Dir)
+	}
+}
+
+func TestLoad_RejectsNewerSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(tmpDir)
+
+	os.MkdirAll(Dir, 0755)
+	os.WriteFile(StatePath, []byte(`{"version": 99}`), 0644)
+
+	_, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "newer than supported") {
+		t.Errorf("Load() error = %v, want newer schema error", err)
+	}
+}
+
+func TestUpdate_RoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(tmpDir)
+
+	err := Update(func(s *State) {
+		s.RateLimit.CallCount = 3
+		s.Sessions.OpenCode = SessionRef{ID: "oc-2", UpdatedAt: time.Now()}
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := Update(func(s *State) { s.ExitSignals = []string{"a", "b"} }); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	s, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if s.RateLimit.CallCount != 3 || s.Sessions.OpenCode.ID != "oc-2" || len(s.ExitSignals) != 2 {
+		t.Errorf("Load() after Update() = %+v, want fields from both updates", s)
+	}
+}
+
+func TestClean(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(tmpDir)
+
+	SaveCallCount(5)
+	os.WriteFile(".exit_signals", []byte(`[]`), 0644)
+
+	removed, err := Clean()
+	if err != nil {
+		t.Fatalf("Clean() error = %v", err)
+	}
+	if len(removed) != 2 {
+		t.Errorf("Clean() removed = %v, want state file and .exit_signals", removed)
+	}
+
+	count, _ := LoadCallCount()
+	if count != 0 {
+		t.Errorf("LoadCallCount() after Clean() = %d, want 0", count)
+	}
+}
@@ -0,0 +1,100 @@
+package testreport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// goTestEvent is a single line of go test -json output
+type goTestEvent struct {
+	Action  string `json:"Action"`
+	Package string `json:"Package"`
+	Test    string `json:"Test"`
+	Output  string `json:"Output"`
+}
+
+// ParseGoTest parses a go test -json event stream. Only test-level events are
+// counted; package-level pass/fail lines are ignored unless a package fails
+// without any failing test (e.g. a build error).
+func ParseGoTest(data []byte) (*Summary, error) {
+	summary := &Summary{Format: FormatGoTest}
+	output := map[string]*strings.Builder{}
+	failedPackages := map[string]bool{}
+	packagesWithFailures := map[string]bool{}
+	sawEvent := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var ev goTestEvent
+		if err := json.Unmarshal(line, &ev); err != nil {
+			continue
+		}
+		sawEvent = true
+
+		key := ev.Package + "." + ev.Test
+		switch ev.Action {
+		case "output":
+			if ev.Test == "" {
+				continue
+			}
+			if output[key] == nil {
+				output[key] = &strings.Builder{}
+			}
+			output[key].WriteString(ev.Output)
+		case "pass":
+			if ev.Test != "" {
+				summary.Passed++
+			}
+		case "skip":
+			if ev.Test != "" {
+				summary.Skipped++
+			}
+		case "fail":
+			if ev.Test == "" {
+				failedPackages[ev.Package] = true
+				continue
+			}
+			packagesWithFailures[ev.Package] = true
+			message := ""
+			if out := output[key]; out != nil {
+				message = goTestFailureMessage(out.String())
+			}
+			summary.addFailure(ev.Test, message)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read go test output: %w", err)
+	}
+	if !sawEvent {
+		return nil, fmt.Errorf("no go test -json events found")
+	}
+
+	for pkg := range failedPackages {
+		if !packagesWithFailures[pkg] {
+			summary.addFailure(pkg, "package failed (build error or panic outside a test)")
+		}
+	}
+
+	return summary, nil
+}
+
+// goTestFailureMessage drops the === RUN / --- FAIL framing lines from test output
+func goTestFailureMessage(output string) string {
+	var kept []string
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "=== ") || strings.HasPrefix(trimmed, "--- FAIL") {
+			continue
+		}
+		kept = append(kept, trimmed)
+	}
+	return strings.Join(kept, "\n")
+}
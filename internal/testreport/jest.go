@@ -0,0 +1,73 @@
+package testreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jestReport is the subset of the Jest/Vitest --json reporter output Lisa reads
+type jestReport struct {
+	NumTotalTests   *int `json:"numTotalTests"`
+	NumPassedTests  int  `json:"numPassedTests"`
+	NumFailedTests  int  `json:"numFailedTests"`
+	NumPendingTests int  `json:"numPendingTests"`
+	NumTodoTests    int  `json:"numTodoTests"`
+	TestResults     []struct {
+		Name             string `json:"name"`
+		Message          string `json:"message"`
+		AssertionResults []struct {
+			FullName        string   `json:"fullName"`
+			Title           string   `json:"title"`
+			Status          string   `json:"status"`
+			FailureMessages []string `json:"failureMessages"`
+		} `json:"assertionResults"`
+	} `json:"testResults"`
+}
+
+// ParseJest parses the JSON written by jest --json or vitest --reporter=json.
+// Counts come from the top-level totals; failing test names and messages come
+// from the per-file assertion results.
+func ParseJest(data []byte) (*Summary, error) {
+	var report jestReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("invalid Jest/Vitest JSON: %w", err)
+	}
+	if report.NumTotalTests == nil {
+		return nil, fmt.Errorf("invalid Jest/Vitest JSON: missing numTotalTests")
+	}
+
+	summary := &Summary{
+		Format:  FormatJest,
+		Passed:  report.NumPassedTests,
+		Skipped: report.NumPendingTests + report.NumTodoTests,
+	}
+
+	for _, file := range report.TestResults {
+		fileFailed := false
+		for _, assertion := range file.AssertionResults {
+			if assertion.Status != "failed" {
+				continue
+			}
+			fileFailed = true
+			name := assertion.FullName
+			if name == "" {
+				name = assertion.Title
+			}
+			summary.Failures = append(summary.Failures, Failure{
+				Name:    name,
+				Message: trimMessage(strings.Join(assertion.FailureMessages, "\n")),
+			})
+		}
+		// A suite that fails to load has no assertions but carries a message
+		if !fileFailed && file.Message != "" && len(file.AssertionResults) == 0 {
+			summary.Failures = append(summary.Failures, Failure{Name: file.Name, Message: trimMessage(file.Message)})
+		}
+	}
+
+	summary.Failed = report.NumFailedTests
+	if len(summary.Failures) > summary.Failed {
+		summary.Failed = len(summary.Failures)
+	}
+	return summary, nil
+}
@@ -0,0 +1,88 @@
+package testreport
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+)
+
+// junitTestCase is a <testcase> element
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitProblem `xml:"failure"`
+	Error     *junitProblem `xml:"error"`
+	Skipped   *junitProblem `xml:"skipped"`
+}
+
+// junitProblem is a <failure>, <error> or <skipped> element
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// junitSuite is a <testsuite> element; suites may nest
+type junitSuite struct {
+	TestCases []junitTestCase `xml:"testcase"`
+	Suites    []junitSuite    `xml:"testsuite"`
+}
+
+// ParseJUnit parses JUnit XML with either a <testsuites> or <testsuite> root.
+// <error> elements are counted as failures.
+func ParseJUnit(data []byte) (*Summary, error) {
+	var root struct {
+		XMLName   xml.Name
+		TestCases []junitTestCase `xml:"testcase"`
+		Suites    []junitSuite    `xml:"testsuite"`
+	}
+	if err := xml.NewDecoder(bytes.NewReader(data)).Decode(&root); err != nil {
+		return nil, fmt.Errorf("invalid JUnit XML: %w", err)
+	}
+	if root.XMLName.Local != "testsuites" && root.XMLName.Local != "testsuite" {
+		return nil, fmt.Errorf("invalid JUnit XML: unexpected root element <%s>", root.XMLName.Local)
+	}
+
+	summary := &Summary{Format: FormatJUnit}
+	addJUnitCases(summary, root.TestCases)
+	for _, suite := range root.Suites {
+		addJUnitSuite(summary, suite)
+	}
+	return summary, nil
+}
+
+// addJUnitSuite counts the test cases in a suite and its nested suites
+func addJUnitSuite(summary *Summary, suite junitSuite) {
+	addJUnitCases(summary, suite.TestCases)
+	for _, nested := range suite.Suites {
+		addJUnitSuite(summary, nested)
+	}
+}
+
+// addJUnitCases counts individual test cases
+func addJUnitCases(summary *Summary, cases []junitTestCase) {
+	for _, tc := range cases {
+		name := tc.Name
+		if tc.ClassName != "" {
+			name = tc.ClassName + "." + tc.Name
+		}
+
+		switch {
+		case tc.Failure != nil:
+			summary.addFailure(name, tc.Failure.message())
+		case tc.Error != nil:
+			summary.addFailure(name, tc.Error.message())
+		case tc.Skipped != nil:
+			summary.Skipped++
+		default:
+			summary.Passed++
+		}
+	}
+}
+
+// message prefers the message attribute and falls back to the element body
+func (p *junitProblem) message() string {
+	if p.Message != "" {
+		return p.Message
+	}
+	return p.Body
+}
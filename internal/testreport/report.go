@@ -0,0 +1,139 @@
+// Package testreport parses machine-readable test reports into a common summary.
+// Supported formats are go test -json, JUnit XML, TAP and the Jest/Vitest JSON reporters.
+package testreport
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Format identifies a test report format
+type Format string
+
+const (
+	FormatGoTest Format = "gotest" // go test -json event stream
+	FormatJUnit  Format = "junit"  // JUnit XML
+	FormatTAP    Format = "tap"    // Test Anything Protocol
+	FormatJest   Format = "jest"   // Jest/Vitest --json reporter
+)
+
+// Failure describes a single failing test
+type Failure struct {
+	Name    string
+	Message string
+}
+
+// Summary is the structured result of a test run
+type Summary struct {
+	Format   Format
+	Passed   int
+	Failed   int
+	Skipped  int
+	Failures []Failure
+}
+
+// Total returns the number of tests that ran or were skipped
+func (s *Summary) Total() int {
+	return s.Passed + s.Failed + s.Skipped
+}
+
+// TestsStatus maps the summary onto the PASSING/FAILING/UNKNOWN values used in LISA_STATUS
+func (s *Summary) TestsStatus() string {
+	switch {
+	case s == nil || s.Total() == 0:
+		return "UNKNOWN"
+	case s.Failed > 0:
+		return "FAILING"
+	default:
+		return "PASSING"
+	}
+}
+
+// String returns a one-line summary such as "12 passed, 1 failed, 2 skipped"
+func (s *Summary) String() string {
+	return fmt.Sprintf("%d passed, %d failed, %d skipped", s.Passed, s.Failed, s.Skipped)
+}
+
+// addFailure records a failing test, trimming the message to something loggable
+func (s *Summary) addFailure(name, message string) {
+	s.Failed++
+	s.Failures = append(s.Failures, Failure{Name: name, Message: trimMessage(message)})
+}
+
+// maxMessageLen bounds failure messages so they fit in logs and loop context
+const maxMessageLen = 500
+
+// trimMessage collapses surrounding whitespace and truncates long messages
+func trimMessage(message string) string {
+	message = strings.TrimSpace(message)
+	if len(message) > maxMessageLen {
+		message = message[:maxMessageLen] + "..."
+	}
+	return message
+}
+
+// DetectFormat guesses the report format from its content
+func DetectFormat(data []byte) (Format, bool) {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case len(trimmed) == 0:
+		return "", false
+	case trimmed[0] == '<':
+		return FormatJUnit, true
+	case trimmed[0] == '{' && bytes.Contains(trimmed, []byte(`"numTotalTests"`)):
+		return FormatJest, true
+	case trimmed[0] == '{' && bytes.Contains(trimmed, []byte(`"Action"`)):
+		return FormatGoTest, true
+	case bytes.HasPrefix(trimmed, []byte("TAP version")) || bytes.HasPrefix(trimmed, []byte("1..")) ||
+		bytes.HasPrefix(trimmed, []byte("ok ")) || bytes.HasPrefix(trimmed, []byte("not ok ")):
+		return FormatTAP, true
+	}
+
+	// go test -json output may be preceded by build noise; look for any event line
+	for _, line := range bytes.Split(trimmed, []byte("\n")) {
+		if bytes.HasPrefix(bytes.TrimSpace(line), []byte(`{"Time"`)) || bytes.HasPrefix(bytes.TrimSpace(line), []byte(`{"Action"`)) {
+			return FormatGoTest, true
+		}
+	}
+	return "", false
+}
+
+// Parse parses a report, detecting its format from the content
+func Parse(data []byte) (*Summary, error) {
+	format, ok := DetectFormat(data)
+	if !ok {
+		return nil, fmt.Errorf("unrecognized test report format")
+	}
+	return ParseFormat(format, data)
+}
+
+// ParseFormat parses a report in a known format
+func ParseFormat(format Format, data []byte) (*Summary, error) {
+	switch format {
+	case FormatGoTest:
+		return ParseGoTest(data)
+	case FormatJUnit:
+		return ParseJUnit(data)
+	case FormatTAP:
+		return ParseTAP(data)
+	case FormatJest:
+		return ParseJest(data)
+	default:
+		return nil, fmt.Errorf("unknown test report format %q", format)
+	}
+}
+
+// ParseFile reads and parses a report file, detecting its format from the content
+func ParseFile(path string) (*Summary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test report %s: %w", path, err)
+	}
+	summary, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse test report %s: %w", path, err)
+	}
+	return summary, nil
+}
@@ -0,0 +1,163 @@
+package testreport
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const goTestJSON = `{"Action":"run","Package":"example.com/pkg","Test":"TestAdd"}
+{"Action":"output","Package":"example.com/pkg","Test":"TestAdd","Output":"=== RUN   TestAdd\n"}
+{"Action":"pass","Package":"example.com/pkg","Test":"TestAdd","Elapsed":0}
+{"Action":"run","Package":"example.com/pkg","Test":"TestSub"}
+{"Action":"output","Package":"example.com/pkg","Test":"TestSub","Output":"=== RUN   TestSub\n"}
+{"Action":"output","Package":"example.com/pkg","Test":"TestSub","Output":"    math_test.go:12: Sub(3, 1) = 1, want 2\n"}
+{"Action":"output","Package":"example.com/pkg","Test":"TestSub","Output":"--- FAIL: TestSub (0.00s)\n"}
+{"Action":"fail","Package":"example.com/pkg","Test":"TestSub","Elapsed":0}
+{"Action":"skip","Package":"example.com/pkg","Test":"TestSlow","Elapsed":0}
+{"Action":"fail","Package":"example.com/pkg","Elapsed":0.01}
+{"Action":"fail","Package":"example.com/broken","Elapsed":0}
+`
+
+const junitXML = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="math">
+    <testcase classname="math" name="adds"/>
+    <testcase classname="math" name="subtracts">
+      <failure message="expected 2, got 1">stack trace</failure>
+    </testcase>
+    <testsuite name="nested">
+      <testcase classname="math.nested" name="divides">
+        <error>division by zero</error>
+      </testcase>
+      <testcase classname="math.nested" name="skipped"><skipped/></testcase>
+    </testsuite>
+  </testsuite>
+</testsuites>`
+
+const tapOutput = `TAP version 13
+1..5
+ok 1 - adds
+not ok 2 - subtracts
+  ---
+  message: "expected 2, got 1"
+  severity: fail
+  ...
+ok 3 - divides # SKIP not on this platform
+not ok 4 - multiplies # TODO not implemented
+not ok 5
+    not ok 1 - nested subtest is not counted
+`
+
+const jestJSON = `{
+  "numTotalTests": 4,
+  "numPassedTests": 2,
+  "numFailedTests": 1,
+  "numPendingTests": 1,
+  "numTodoTests": 0,
+  "testResults": [
+    {
+      "name": "/app/math.test.ts",
+      "message": "",
+      "assertionResults": [
+        {"fullName": "math adds", "title": "adds", "status": "passed", "failureMessages": []},
+        {"fullName": "math subtracts", "title": "subtracts", "status": "failed", "failureMessages": ["Expected: 2\nReceived: 1"]}
+      ]
+    }
+  ]
+}`
+
+func TestParse_Formats(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         string
+		format       Format
+		passed       int
+		failed       int
+		skipped      int
+		firstFailure Failure
+	}{
+		{"go test -json", goTestJSON, FormatGoTest, 1, 2, 1, Failure{Name: "TestSub", Message: "math_test.go:12: Sub(3, 1) = 1, want 2"}},
+		{"junit", junitXML, FormatJUnit, 1, 2, 1, Failure{Name: "math.subtracts", Message: "expected 2, got 1"}},
+		{"tap", tapOutput, FormatTAP, 2, 2, 1, Failure{Name: "subtracts", Message: "expected 2, got 1"}},
+		{"jest", jestJSON, FormatJest, 2, 1, 1, Failure{Name: "math subtracts", Message: "Expected: 2\nReceived: 1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, err := Parse([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if summary.Format != tt.format {
+				t.Errorf("Parse() Format = %v, want %v", summary.Format, tt.format)
+			}
+			if summary.Passed != tt.passed || summary.Failed != tt.failed || summary.Skipped != tt.skipped {
+				t.Errorf("Parse() = %s, want %d passed, %d failed, %d skipped", summary, tt.passed, tt.failed, tt.skipped)
+			}
+			if len(summary.Failures) == 0 {
+				t.Fatal("Parse() Failures empty")
+			}
+			if summary.Failures[0] != tt.firstFailure {
+				t.Errorf("Parse() Failures[0] = %+v, want %+v", summary.Failures[0], tt.firstFailure)
+			}
+			if summary.TestsStatus() != "FAILING" {
+				t.Errorf("TestsStatus() = %v, want FAILING", summary.TestsStatus())
+			}
+		})
+	}
+}
+
+func TestParseGoTest_BuildFailure(t *testing.T) {
+	summary, err := ParseGoTest([]byte(`{"Action":"fail","Package":"example.com/broken","Elapsed":0}`))
+	if err != nil {
+		t.Fatalf("ParseGoTest() error = %v", err)
+	}
+	if summary.Failed != 1 || summary.Failures[0].Name != "example.com/broken" {
+		t.Errorf("ParseGoTest() = %+v, want one package failure", summary)
+	}
+}
+
+func TestParse_Unrecognized(t *testing.T) {
+	for _, data := range []string{"", "PASS\nok  example.com/pkg 0.01s", `{"foo": 1}`} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) error = nil, want error", data)
+		}
+	}
+}
+
+func TestSummary_TestsStatus(t *testing.T) {
+	tests := []struct {
+		summary *Summary
+		want    string
+	}{
+		{nil, "UNKNOWN"},
+		{&Summary{}, "UNKNOWN"},
+		{&Summary{Passed: 3, Skipped: 1}, "PASSING"},
+		{&Summary{Passed: 3, Failed: 1}, "FAILING"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.summary.TestsStatus(); got != tt.want {
+			t.Errorf("TestsStatus(%+v) = %v, want %v", tt.summary, got, tt.want)
+		}
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.xml")
+	os.WriteFile(path, []byte(junitXML), 0644)
+
+	summary, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if summary.Format != FormatJUnit {
+		t.Errorf("ParseFile() Format = %v, want %v", summary.Format, FormatJUnit)
+	}
+
+	if _, err := ParseFile(filepath.Join(t.TempDir(), "missing.xml")); err == nil || !strings.Contains(err.Error(), "missing.xml") {
+		t.Errorf("ParseFile() error = %v, want error naming the file", err)
+	}
+}
@@ -0,0 +1,96 @@
+package testreport
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var tapResultRegex = regexp.MustCompile(`^(not ok|ok)\b\s*(\d+)?\s*(?:-\s*)?([^#]*?)\s*(?:#\s*(\w+)\b\s*(.*))?$`)
+
+// ParseTAP parses Test Anything Protocol output. Tests marked # SKIP are counted
+// as skipped, and # TODO failures are not counted as failures. The YAML
+// diagnostic block following a failure supplies its message.
+func ParseTAP(data []byte) (*Summary, error) {
+	summary := &Summary{Format: FormatTAP}
+	sawResult := false
+
+	var (
+		inDiagnostics bool
+		diagnostics   []string
+		lastFailure   = -1
+	)
+	flushDiagnostics := func() {
+		if lastFailure >= 0 && len(diagnostics) > 0 && summary.Failures[lastFailure].Message == "" {
+			summary.Failures[lastFailure].Message = trimMessage(tapDiagnosticMessage(diagnostics))
+		}
+		inDiagnostics = false
+		diagnostics = nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+
+		if inDiagnostics {
+			if line == "..." {
+				flushDiagnostics()
+			} else {
+				diagnostics = append(diagnostics, line)
+			}
+			continue
+		}
+		if line == "---" && lastFailure >= 0 {
+			inDiagnostics = true
+			continue
+		}
+
+		// Only top-level results count; indented lines are subtests
+		if raw != strings.TrimLeft(raw, " \t") {
+			continue
+		}
+		m := tapResultRegex.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		sawResult = true
+		lastFailure = -1
+
+		name := m[3]
+		if name == "" {
+			name = "test " + m[2]
+		}
+		directive := strings.ToUpper(m[4])
+
+		switch {
+		case directive == "SKIP":
+			summary.Skipped++
+		case m[1] == "ok" || directive == "TODO":
+			summary.Passed++
+		default:
+			summary.addFailure(name, "")
+			lastFailure = len(summary.Failures) - 1
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read TAP output: %w", err)
+	}
+	if !sawResult {
+		return nil, fmt.Errorf("no TAP test results found")
+	}
+	return summary, nil
+}
+
+// tapDiagnosticMessage extracts the message: key from a YAML diagnostic block,
+// falling back to the whole block
+func tapDiagnosticMessage(lines []string) string {
+	for _, line := range lines {
+		if strings.HasPrefix(line, "message:") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "message:")), `"'`)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
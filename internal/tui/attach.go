@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/effects"
+)
+
+// applySnapshot brings the model up to date with a loop it attached to
+// mid-run. It returns the commands that reload the plan and the diff.
+func (m *Model) applySnapshot(s *loop.Status) []tea.Cmd {
+	switch s.State {
+	case loop.RunStateRunning, loop.RunStatePausing, loop.RunStateStopping:
+		m.state = StateRunning
+	case loop.RunStatePaused:
+		m.state = StatePaused
+	case loop.RunStateStopped:
+		if m.state != StateError {
+			m.state = StateComplete
+			m.activeTaskIdx = -1
+		}
+	}
+
+	m.loopNumber = s.Loop
+	if s.Status != "" {
+		m.status = s.Status
+	}
+	if state, ok := s.Breaker["state"].(string); ok {
+		m.circuitState = state
+	}
+	m.callsUsed = statInt(s.RateLimit["current_calls"])
+
+	if u := s.Context; u != nil {
+		m.contextUsagePercent = u.UsagePercent
+		m.contextTotalTokens = u.TotalTokens
+		m.contextLimit = u.Limit
+		m.contextThreshold = u.ThresholdReached
+		m.contextWasCompacted = u.WasCompacted
+	}
+
+	// The replayed tail may include questions that were answered since
+	m.escalation = s.Escalation
+	m.answerInput = nil
+	if s.Escalation != nil {
+		m.state = StatePaused
+		m.screen = ScreenQuestion
+	} else if m.screen == ScreenQuestion {
+		m.screen = ScreenSplit
+	}
+
+	m.steering = s.Steering
+	if s.Review != nil {
+		m.applyReview(s.Review)
+	} else {
+		// The replayed tail may include reviews that were decided since
+		m.review = nil
+		if m.screen == ScreenReview {
+			m.screen = ScreenSplit
+		}
+	}
+
+	m.addLog(string(loop.LogLevelInfo), fmt.Sprintf("Attached at loop %d: %d/%d tasks remaining, circuit %s",
+		s.Loop, s.Plan.Remaining, s.Plan.Total, m.circuitState))
+
+	var cmds []tea.Cmd
+	planFile := s.Plan.File
+	if planFile == "" {
+		planFile = m.planFile
+	}
+	if planFile != "" {
+		cmds = append(cmds, effects.LoadPlan(planFile, m.readFile))
+	}
+	// Diff history starts from the tree as it is on attach
+	if m.diffs.last == "" && len(m.diffs.snapshots) == 0 {
+		if cmd := m.startDiffHistory(); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	return append(cmds, m.triggerDiffRefresh())
+}
+
+// statInt reads a numeric GetStats value, which is a float64 once it has
+// been through JSON
+func statInt(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case float64:
+		return int(n)
+	}
+	return 0
+}
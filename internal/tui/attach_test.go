@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/brainwhocodes/lisa-loop/internal/analysis"
+	"github.com/brainwhocodes/lisa-loop/internal/codex"
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	tuimsg "github.com/brainwhocodes/lisa-loop/internal/tui/msg"
+)
+
+func snapshotEvent(status loop.Status) tuimsg.ControllerEventMsg {
+	return tuimsg.ControllerEventMsg{Event: loop.LoopEvent{Type: loop.EventTypeSnapshot, Snapshot: &status}}
+}
+
+func TestSnapshotRestoresAttachedState(t *testing.T) {
+	model := Model{state: StateInitializing, screen: ScreenSplit}
+
+	updated, cmd := model.Update(snapshotEvent(loop.Status{
+		State:     loop.RunStatePaused,
+		Status:    "executing",
+		Loop:      4,
+		Plan:      loop.PlanProgress{File: "@fix_plan.md", Total: 3, Remaining: 1},
+		Breaker:   map[string]interface{}{"state": "HALF_OPEN"},
+		RateLimit: map[string]interface{}{"current_calls": float64(7)},
+		Context:   &loop.ContextUsage{UsagePercent: 0.4, TotalTokens: 400, Limit: 1000},
+	}))
+	m := updated.(Model)
+
+	if m.state != StatePaused || m.loopNumber != 4 || m.status != "executing" {
+		t.Errorf("state = %v loop = %d status = %q, want paused loop 4 executing", m.state, m.loopNumber, m.status)
+	}
+	if m.circuitState != "HALF_OPEN" || m.callsUsed != 7 {
+		t.Errorf("circuit = %s calls = %d, want HALF_OPEN and 7", m.circuitState, m.callsUsed)
+	}
+	if m.contextTotalTokens != 400 || m.contextLimit != 1000 {
+		t.Errorf("context = %d/%d, want 400/1000", m.contextTotalTokens, m.contextLimit)
+	}
+	if cmd == nil || !m.diffPending {
+		t.Error("snapshot did not reload the plan and diff")
+	}
+}
+
+func TestSnapshotClearsAnsweredEscalation(t *testing.T) {
+	model := Model{
+		state:      StatePaused,
+		screen:     ScreenQuestion,
+		escalation: &analysis.Escalation{Questions: []string{"Which port?"}},
+	}
+
+	updated, _ := model.Update(snapshotEvent(loop.Status{State: loop.RunStateRunning}))
+	m := updated.(Model)
+
+	if m.escalation != nil || m.screen != ScreenSplit || m.state != StateRunning {
+		t.Errorf("escalation = %v screen = %v state = %v, want the replayed question cleared", m.escalation, m.screen, m.state)
+	}
+}
+
+func TestNewAttachedProgramFollowsRun(t *testing.T) {
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(t.TempDir())
+	os.WriteFile("PROMPT.md", []byte("prompt"), 0644)
+	os.WriteFile("@fix_plan.md", []byte("- [ ] Task\n"), 0644)
+
+	fc := &fakeController{}
+	program := NewAttachedProgram(codex.Config{MaxCalls: 3}, fc, "127.0.0.1:7777")
+	m := program.model
+
+	if m.state != StateRunning || m.ctx == nil {
+		t.Fatalf("state = %v ctx = %v, want running with a run context", m.state, m.ctx)
+	}
+	if !strings.Contains(m.logs[len(m.logs)-1].Text, "127.0.0.1:7777") {
+		t.Errorf("last log = %q, want the attach address", m.logs[len(m.logs)-1].Text)
+	}
+	if m.Init() == nil {
+		t.Fatal("Init() returned no command")
+	}
+	if !strings.Contains(m.renderHeader(120), "attached") {
+		t.Error("header does not show that the UI is attached")
+	}
+}
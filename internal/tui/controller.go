@@ -11,6 +11,14 @@ import (
 type Controller interface {
 	Run(ctx context.Context) error
 	Pause()
+	PauseNow()
 	Resume()
-	SetEventCallback(cb loop.EventCallback)
+	StopAfterIteration()
+	Abort()
+	SkipTask()
+	Answer(answer string)
+	Steer(message string)
+	ClearSteering()
+	Review(decision loop.ReviewDecision, feedback string)
+	Subscribe(buffer int, policy loop.DropPolicy) *loop.Subscription
 }
@@ -19,9 +19,31 @@ func (f *fakeController) Run(ctx context.Context) error {
 	return f.runErr
 }
 
-func (f *fakeController) Pause()                              {}
-func (f *fakeController) Resume()                             {}
-func (f *fakeController) SetEventCallback(loop.EventCallback) {}
+func (f *fakeController) Pause()                             {}
+func (f *fakeController) PauseNow()                          {}
+func (f *fakeController) Resume()                            {}
+func (f *fakeController) StopAfterIteration()                {}
+func (f *fakeController) Abort()                             {}
+func (f *fakeController) SkipTask()                          {}
+func (f *fakeController) Answer(string)                      {}
+func (f *fakeController) Steer(string)                       {}
+func (f *fakeController) ClearSteering()                     {}
+func (f *fakeController) Review(loop.ReviewDecision, string) {}
+func (f *fakeController) Subscribe(int, loop.DropPolicy) *loop.Subscription {
+	return loop.NewBus().Subscribe(1, loop.DropNewest)
+}
+
+// runControl runs the command a control returned, as Bubble Tea would, and
+// checks that it reports the control as sent
+func runControl(t *testing.T, cmd tea.Cmd) {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("expected a command sending the control")
+	}
+	if got, ok := cmd().(tuimsg.ControlSentMsg); !ok {
+		t.Fatalf("control command returned %T, want ControlSentMsg", got)
+	}
+}
 
 func TestRunStartsControllerViaCmdAndCompletesOnDoneMsg(t *testing.T) {
 	fc := &fakeController{}
@@ -0,0 +1,297 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/effects"
+	tuimsg "github.com/brainwhocodes/lisa-loop/internal/tui/msg"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// diffView selects what the Diffs tab shows
+type diffView int
+
+const (
+	diffViewLive       diffView = iota // Working tree against the index (git diff)
+	diffViewIteration                  // One iteration's changes
+	diffViewCumulative                 // Everything changed since the run started
+)
+
+func (v diffView) String() string {
+	switch v {
+	case diffViewIteration:
+		return "iteration"
+	case diffViewCumulative:
+		return "since run start"
+	default:
+		return "live"
+	}
+}
+
+// fileStat is one file's line counts in a diff
+type fileStat struct {
+	Path    string
+	Added   int
+	Deleted int
+	Binary  bool
+}
+
+// treeDiff is the difference between two working tree snapshots
+type treeDiff struct {
+	Iteration int
+	From, To  string              // Snapshot tree hashes
+	Files     []fileStat          // Filled in once the diff has loaded
+	Patch     string              // Filled in once the diff has loaded
+	Tools     map[string][]string // Tools that touched each file, keyed by tool target
+	Loaded    bool
+	Err       error
+}
+
+type snapshotKind int
+
+const (
+	snapshotBaseline  snapshotKind = iota // Run start
+	snapshotIteration                     // Iteration boundary
+	snapshotResync                        // The tree changed between iterations (a rejected review was reverted)
+)
+
+// snapshotPurpose is what a requested working tree snapshot is for
+type snapshotPurpose struct {
+	kind      snapshotKind
+	iteration int
+	tools     map[string][]string
+}
+
+// cumulativeDiff marks a diff load for the cumulative view
+const cumulativeDiff = -1
+
+// diffHistory tracks the working tree across a run by snapshotting it at
+// run start and at every iteration boundary
+type diffHistory struct {
+	seq       int
+	snapshots map[int]snapshotPurpose // Requested snapshots by ID
+	loads     map[int]int             // Requested diffs by ID: iteration index or cumulativeDiff
+	disabled  bool                    // A snapshot failed (no git repository); stop trying
+	baseline  string                  // Snapshot at run start
+	last      string                  // Latest snapshot; the next iteration is diffed against it
+
+	iterations []treeDiff
+	cumulative treeDiff
+	touched    map[string][]string // Tool touches in the iteration in progress
+
+	view     diffView
+	selected int // Iteration shown in diffViewIteration
+}
+
+// startDiffHistory forgets the previous run's history and snapshots the
+// working tree the new run starts from
+func (m *Model) startDiffHistory() tea.Cmd {
+	// IDs keep counting so replies to the old run's requests are recognised as stale
+	m.diffs = diffHistory{seq: m.diffs.seq, view: m.diffs.view}
+	return m.snapshotWorkTree(snapshotPurpose{kind: snapshotBaseline})
+}
+
+func (m *Model) snapshotWorkTree(p snapshotPurpose) tea.Cmd {
+	if m.diffs.disabled {
+		return nil
+	}
+	if m.diffs.snapshots == nil {
+		m.diffs.snapshots = make(map[int]snapshotPurpose)
+	}
+	m.diffs.seq++
+	m.diffs.snapshots[m.diffs.seq] = p
+	return effects.SnapshotWorkTree(m.diffs.seq, m.snapshot)
+}
+
+// snapshotIteration closes the iteration, attributing its changes to the
+// tool calls recorded while it ran
+func (m *Model) snapshotIteration(iteration int) tea.Cmd {
+	tools := m.diffs.touched
+	m.diffs.touched = nil
+	return m.snapshotWorkTree(snapshotPurpose{kind: snapshotIteration, iteration: iteration, tools: tools})
+}
+
+// recordToolTouch remembers that a tool call touched a file in the current
+// iteration
+func (m *Model) recordToolTouch(target, tool string) {
+	if m.diffs.touched == nil {
+		m.diffs.touched = make(map[string][]string)
+	}
+	if !containsString(m.diffs.touched[target], tool) {
+		m.diffs.touched[target] = append(m.diffs.touched[target], tool)
+	}
+}
+
+func (m *Model) loadTreeDiff(target int, from, to string) tea.Cmd {
+	if m.diffs.loads == nil {
+		m.diffs.loads = make(map[int]int)
+	}
+	m.diffs.seq++
+	m.diffs.loads[m.diffs.seq] = target
+	return effects.LoadTreeDiff(m.diffs.seq, from, to, m.exec)
+}
+
+// applyWorkTreeSnapshot files a finished snapshot and loads the diffs it
+// completes
+func (m *Model) applyWorkTreeSnapshot(msg tuimsg.WorkTreeSnapshotMsg) tea.Cmd {
+	p, ok := m.diffs.snapshots[msg.ID]
+	if !ok {
+		return nil // Requested before the current run started
+	}
+	delete(m.diffs.snapshots, msg.ID)
+	if msg.Err != nil {
+		if !m.diffs.disabled {
+			m.addLog(string(loop.LogLevelWarn), fmt.Sprintf("Diff history unavailable: %v", msg.Err))
+		}
+		m.diffs.disabled = true
+		return nil
+	}
+
+	// Without a baseline (attached mid-run) the first snapshot becomes one
+	if p.kind == snapshotBaseline || m.diffs.last == "" {
+		m.diffs.baseline = msg.Tree
+		m.diffs.last = msg.Tree
+		return nil
+	}
+	if p.kind == snapshotResync {
+		m.diffs.last = msg.Tree
+		return nil
+	}
+
+	from := m.diffs.last
+	m.diffs.last = msg.Tree
+	if len(m.diffs.iterations) == 0 || m.diffs.selected == len(m.diffs.iterations)-1 {
+		// Follow the latest iteration unless another one is being browsed
+		m.diffs.selected = len(m.diffs.iterations)
+	}
+	m.diffs.iterations = append(m.diffs.iterations, treeDiff{Iteration: p.iteration, From: from, To: msg.Tree, Tools: p.tools})
+	m.diffs.cumulative.From, m.diffs.cumulative.To = m.diffs.baseline, msg.Tree
+	return tea.Batch(
+		m.loadTreeDiff(len(m.diffs.iterations)-1, from, msg.Tree),
+		m.loadTreeDiff(cumulativeDiff, m.diffs.baseline, msg.Tree),
+	)
+}
+
+// applyTreeDiff fills in a loaded iteration or cumulative diff
+func (m *Model) applyTreeDiff(msg tuimsg.TreeDiffLoadedMsg) {
+	target, ok := m.diffs.loads[msg.ID]
+	if !ok {
+		return
+	}
+	delete(m.diffs.loads, msg.ID)
+
+	var d *treeDiff
+	if target == cumulativeDiff {
+		// A newer cumulative load supersedes this one
+		for _, t := range m.diffs.loads {
+			if t == cumulativeDiff {
+				return
+			}
+		}
+		d = &m.diffs.cumulative
+		d.Tools = m.cumulativeTools()
+	} else if target < len(m.diffs.iterations) {
+		d = &m.diffs.iterations[target]
+	} else {
+		return
+	}
+	d.Loaded = true
+	d.Err = msg.Err
+	d.Files = parseNumStat(msg.NumStat)
+	d.Patch = msg.Patch
+}
+
+// cumulativeTools merges every iteration's tool touches
+func (m Model) cumulativeTools() map[string][]string {
+	merged := make(map[string][]string)
+	for _, it := range m.diffs.iterations {
+		for target, tools := range it.Tools {
+			for _, tool := range tools {
+				if !containsString(merged[target], tool) {
+					merged[target] = append(merged[target], tool)
+				}
+			}
+		}
+	}
+	return merged
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNumStat parses `git diff --numstat`; binary files count "-" lines
+func parseNumStat(out string) []fileStat {
+	var files []fileStat
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		f := fileStat{Path: fields[2]}
+		if fields[0] == "-" && fields[1] == "-" {
+			f.Binary = true
+		} else {
+			f.Added, _ = strconv.Atoi(fields[0])
+			f.Deleted, _ = strconv.Atoi(fields[1])
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// toolsFor returns the tools that touched path; tool targets may be
+// absolute or relative to the project
+func (d treeDiff) toolsFor(path string) []string {
+	var tools []string
+	targets := make([]string, 0, len(d.Tools))
+	for target := range d.Tools {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	for _, target := range targets {
+		t := strings.TrimPrefix(target, "./")
+		if t != path && !strings.HasSuffix(t, "/"+path) {
+			continue
+		}
+		for _, tool := range d.Tools[target] {
+			if !containsString(tools, tool) {
+				tools = append(tools, tool)
+			}
+		}
+	}
+	return tools
+}
+
+// totals sums a diff's line counts
+func (d treeDiff) totals() (added, deleted int) {
+	for _, f := range d.Files {
+		added += f.Added
+		deleted += f.Deleted
+	}
+	return added, deleted
+}
+
+// shownDiff is the history diff the Diffs tab is showing, or nil
+func (m Model) shownDiff() *treeDiff {
+	switch m.diffs.view {
+	case diffViewIteration:
+		if m.diffs.selected < len(m.diffs.iterations) {
+			return &m.diffs.iterations[m.diffs.selected]
+		}
+	case diffViewCumulative:
+		if m.diffs.cumulative.To != "" {
+			return &m.diffs.cumulative
+		}
+	}
+	return nil
+}
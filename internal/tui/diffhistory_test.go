@@ -0,0 +1,160 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	tuimsg "github.com/brainwhocodes/lisa-loop/internal/tui/msg"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const binaryPatch = "diff --git a/logo.png b/logo.png\nBinary files a/logo.png and b/logo.png differ\n"
+
+// fakeSnapshots serves snapshots tree0, tree1... in turn
+func fakeSnapshots() func(ctx context.Context) (string, error) {
+	trees := 0
+	return func(ctx context.Context) (string, error) {
+		tree := "tree" + string(rune('0'+trees))
+		trees++
+		return tree, nil
+	}
+}
+
+// fakeDiffGit serves canned diffs between the snapshots fakeSnapshots takes
+func fakeDiffGit(t *testing.T, diffs map[string][2]string) func(name string, args ...string) ([]byte, error) {
+	return func(name string, args ...string) ([]byte, error) {
+		a := strings.Join(args, " ")
+		switch {
+		case name == "git" && strings.HasPrefix(a, "diff --numstat "):
+			return []byte(diffs[strings.TrimPrefix(a, "diff --numstat ")][0]), nil
+		case name == "git" && strings.HasPrefix(a, "diff --patch --no-color "):
+			return []byte(diffs[strings.TrimPrefix(a, "diff --patch --no-color ")][1]), nil
+		}
+		t.Errorf("unexpected exec: %s %s", name, a)
+		return nil, nil
+	}
+}
+
+// runCmds feeds a command's messages, batches included, back into the model
+func runCmds(model tea.Model, cmd tea.Cmd) tea.Model {
+	if cmd == nil {
+		return model
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, c := range msg {
+			model = runCmds(model, c)
+		}
+	case tuimsg.WorkTreeSnapshotMsg, tuimsg.TreeDiffLoadedMsg:
+		var next tea.Cmd
+		model, next = model.Update(msg)
+		model = runCmds(model, next)
+	}
+	return model
+}
+
+func TestDiffHistory_SnapshotsEachIteration(t *testing.T) {
+	model := tea.Model(Model{
+		screen:    ScreenOutput,
+		outputTab: OutputTabDiffs,
+		snapshot:  fakeSnapshots(),
+		exec: fakeDiffGit(t, map[string][2]string{
+			"tree0 tree1": {"3\t1\tmain.go\n", "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1,3 @@\n-a\n+b\n+c\n+d\n"},
+			"tree1 tree2": {"0\t2\tmain.go\n-\t-\tlogo.png\n", "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,3 +1 @@\n-b\n-c\n d\n" + binaryPatch},
+			"tree0 tree2": {"1\t1\tmain.go\n-\t-\tlogo.png\n", "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-a\n+d\n" + binaryPatch},
+		}),
+	})
+	event := func(e loop.LoopEvent) {
+		var cmd tea.Cmd
+		model, cmd = model.Update(tuimsg.ControllerEventMsg{Event: e})
+		if e.Type != loop.EventTypeCodexTool {
+			model = runCmds(model, cmd)
+		}
+	}
+
+	event(loop.LoopEvent{Type: loop.EventTypeStateChange, State: &loop.StateChange{From: loop.RunStateIdle, To: loop.RunStateRunning}})
+	event(loop.LoopEvent{Type: loop.EventTypeCodexTool, Iteration: 1, Tool: &loop.ToolCall{Name: "edit", Target: "/work/project/main.go", Status: loop.ToolStatusCompleted}})
+	event(loop.LoopEvent{Type: loop.EventTypeOutcome, Iteration: 1, Outcome: &loop.LoopOutcome{Success: true}})
+	event(loop.LoopEvent{Type: loop.EventTypeOutcome, Iteration: 2, Outcome: &loop.LoopOutcome{Success: true}})
+
+	m := model.(Model)
+	if len(m.diffs.iterations) != 2 {
+		t.Fatalf("iterations = %d, want 2", len(m.diffs.iterations))
+	}
+	first := m.diffs.iterations[0]
+	if first.Iteration != 1 || first.From != "tree0" || first.To != "tree1" || !first.Loaded {
+		t.Errorf("first iteration = %+v", first)
+	}
+	if got := first.toolsFor("main.go"); len(got) != 1 || got[0] != "edit" {
+		t.Errorf("toolsFor(main.go) = %v, want [edit]", got)
+	}
+	if second := m.diffs.iterations[1]; len(second.Files) != 2 || !second.Files[1].Binary || len(second.toolsFor("main.go")) != 0 {
+		t.Errorf("second iteration = %+v", second)
+	}
+	if c := m.diffs.cumulative; c.From != "tree0" || c.To != "tree2" || !c.Loaded || len(c.toolsFor("main.go")) != 1 {
+		t.Errorf("cumulative = %+v", c)
+	}
+
+	tab := func() string {
+		return model.(Model).renderDiffTab(120, 30)
+	}
+	keys := func(keys ...string) {
+		for _, k := range keys {
+			model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		}
+	}
+
+	keys("d")
+	if got := tab(); !strings.Contains(got, "Diffs: iteration • Loop 2 • 2 files +0 -2") || !strings.Contains(got, "logo.png  (binary)") {
+		t.Errorf("iteration view does not show the latest loop:\n%s", got)
+	}
+	keys("<")
+	got := tab()
+	for _, want := range []string{"Loop 1 • 1 files +3 -1", "main.go +3 -1 edit", "+ d"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("loop 1 view missing %q:\n%s", want, got)
+		}
+	}
+	keys("d")
+	if got := tab(); !strings.Contains(got, "Diffs: since run start • 2 files +1 -1") {
+		t.Errorf("cumulative view:\n%s", got)
+	}
+	keys("d")
+	if model.(Model).diffs.view != diffViewLive {
+		t.Errorf("d did not cycle back to the live view")
+	}
+}
+
+func TestDiffHistory_NewRunResets(t *testing.T) {
+	m := Model{diffs: diffHistory{
+		seq:        1,
+		view:       diffViewCumulative,
+		baseline:   "old",
+		iterations: []treeDiff{{Iteration: 1}},
+		snapshots:  map[int]snapshotPurpose{1: {kind: snapshotIteration}},
+	}}
+	m.startDiffHistory()
+	if len(m.diffs.iterations) != 0 || m.diffs.baseline != "" || m.diffs.view != diffViewCumulative {
+		t.Errorf("startDiffHistory() left %+v", m.diffs)
+	}
+
+	// A snapshot requested during the previous run is ignored
+	if cmd := m.applyWorkTreeSnapshot(tuimsg.WorkTreeSnapshotMsg{ID: 1, Tree: "stale"}); cmd != nil || m.diffs.last != "" {
+		t.Errorf("stale snapshot applied: last = %q", m.diffs.last)
+	}
+}
+
+func TestParseNumStat(t *testing.T) {
+	got := parseNumStat("12\t3\tinternal/a.go\n-\t-\tlogo.png\n\n")
+	want := []fileStat{{Path: "internal/a.go", Added: 12, Deleted: 3}, {Path: "logo.png", Binary: true}}
+	if len(got) != len(want) {
+		t.Fatalf("parseNumStat() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("parseNumStat()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/diffview"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/keymap"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// diffViewerStyles colours the Diffs tab's viewer, set by applyTheme
+var diffViewerStyles diffview.Styles
+
+// newDiffViewerStyles colours the diff viewer for theme t
+func newDiffViewerStyles(t *Theme) diffview.Styles {
+	s := diffview.Styles{
+		File:             lipgloss.NewStyle().Foreground(t.FgSelected).Background(t.BgBorder).Bold(true),
+		Hunk:             styleDiffHunk,
+		LineNumber:       lipgloss.NewStyle().Foreground(t.FgSubtle),
+		Context:          StyleTextMuted,
+		Add:              styleDiffAdd,
+		Delete:           styleDiffDelete,
+		Divider:          StyleDividerSubtle,
+		AddBackground:    t.DiffAddBg,
+		DeleteBackground: t.DiffDeleteBg,
+	}
+	if t.Syntax != "" {
+		s.Chroma = styles.Get(t.Syntax)
+	}
+	return s
+}
+
+// Diffs tab layout
+const (
+	diffFileListWidth    = 32  // File list column
+	diffFileListMinWidth = 100 // Narrower tabs show the current file on one line instead
+	diffWheelStep        = 3   // Rows per mouse wheel notch
+)
+
+// diffTabLayout is where the Diffs tab puts things within its content area
+type diffTabLayout struct {
+	header int // Lines above the diff
+	list   int // File list width, 0 when collapsed to one line
+	diff   int // Viewer width
+	height int // Viewer height
+}
+
+func (m Model) diffTabLayout(width, height int) diffTabLayout {
+	l := diffTabLayout{header: 1}
+	if m.diffs.view != diffViewLive {
+		l.header++ // The loops line
+	}
+	inner := width - 2 // Horizontal padding
+	if width >= diffFileListMinWidth {
+		l.list = diffFileListWidth
+		l.diff = inner - l.list - 1
+	} else {
+		l.header++ // The current file line
+		l.diff = inner
+	}
+	l.height = max(1, height-l.header)
+	return l
+}
+
+// shownPatch is the patch the Diffs tab's view shows
+func (m Model) shownPatch() string {
+	if m.diffs.view == diffViewLive {
+		return m.gitDiffPatch
+	}
+	if d := m.shownDiff(); d != nil && d.Loaded {
+		return d.Patch
+	}
+	return ""
+}
+
+// shownTools attributes the shown files to tool calls: the current
+// iteration's touches for the live view, the iteration's for history
+func (m Model) shownTools() treeDiff {
+	if m.diffs.view == diffViewLive {
+		return treeDiff{Tools: m.diffs.touched}
+	}
+	if d := m.shownDiff(); d != nil {
+		return *d
+	}
+	return treeDiff{}
+}
+
+// sizedDiffViewer returns the viewer showing the current patch at the layout's
+// size. Models built without one (tests) get a throwaway viewer.
+func (m Model) sizedDiffViewer(l diffTabLayout) *diffview.Viewer {
+	v := m.diffViewer
+	if v == nil {
+		v = diffview.New(diffViewerStyles)
+	}
+	v.SetPatch(m.shownPatch())
+	v.SetSize(l.diff, l.height)
+	return v
+}
+
+// syncDiffViewer sizes the model's viewer for the output screen
+func (m *Model) syncDiffViewer() (*diffview.Viewer, diffTabLayout) {
+	if m.diffViewer == nil {
+		m.diffViewer = diffview.New(diffViewerStyles)
+	}
+	l := m.diffTabLayout(m.outputContentSize())
+	return m.sizedDiffViewer(l), l
+}
+
+// handleDiffKey drives the Diffs tab. It reports whether the key was used.
+func (m *Model) handleDiffKey(key string) bool {
+	action := m.keymap().Lookup(keymap.ScopeDiffs, key)
+	switch action {
+	case "":
+		return false
+	case keymap.DiffView:
+		m.diffs.view = (m.diffs.view + 1) % 3
+		return true
+	case keymap.DiffPrevLoop:
+		if m.diffs.selected > 0 {
+			m.diffs.selected--
+		}
+		return true
+	case keymap.DiffNextLoop:
+		if m.diffs.selected < len(m.diffs.iterations)-1 {
+			m.diffs.selected++
+		}
+		return true
+	}
+
+	v, _ := m.syncDiffViewer()
+	switch action {
+	case keymap.DiffDown:
+		v.ScrollBy(1)
+	case keymap.DiffUp:
+		v.ScrollBy(-1)
+	case keymap.DiffPageDown:
+		v.PageDown()
+	case keymap.DiffPageUp:
+		v.PageUp()
+	case keymap.DiffTop:
+		v.Top()
+	case keymap.DiffBottom:
+		v.Bottom()
+	case keymap.DiffNextFile:
+		v.NextFile()
+	case keymap.DiffPrevFile:
+		v.PrevFile()
+	case keymap.DiffNextHunk:
+		v.NextHunk()
+	case keymap.DiffPrevHunk:
+		v.PrevHunk()
+	case keymap.DiffFold:
+		v.ToggleFold()
+	case keymap.DiffFoldAll:
+		v.ToggleFoldAll()
+	case keymap.DiffSplit:
+		v.ToggleSideBySide()
+	default:
+		return false
+	}
+	return true
+}
+
+// handleDiffMouse scrolls the diff with the wheel and jumps to a file
+// clicked in the file list
+func (m *Model) handleDiffMouse(msg tea.MouseMsg) {
+	v, l := m.syncDiffViewer()
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		v.ScrollBy(-diffWheelStep)
+	case tea.MouseButtonWheelDown:
+		v.ScrollBy(diffWheelStep)
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress || l.list == 0 {
+			return
+		}
+		x := msg.X - 1 // Padding
+		y := msg.Y - outputContentTop - l.header
+		if x < 0 || x >= l.list || y < 0 || y >= l.height {
+			return
+		}
+		files := v.Files()
+		if i := fileListStart(len(files), v.CurrentFile(), l.height) + y; i < len(files) {
+			v.JumpToFile(i)
+		}
+	}
+}
+
+// fileListStart is the first file the list shows, keeping the current file
+// in view
+func fileListStart(files, current, height int) int {
+	if files <= height || current < height/2 {
+		return 0
+	}
+	return min(current-height/2, files-height)
+}
+
+func (m Model) renderDiffTab(width, height int) string {
+	l := m.diffTabLayout(width, height)
+	v := m.sizedDiffViewer(l)
+	files := v.Files()
+
+	var lines []string
+	title := StyleTextSelected.Render("Diffs: " + m.diffs.view.String())
+	if summary := m.diffSummary(files); summary != "" {
+		title += StyleTextSubtle.Render(MetaDotSeparator) + StyleTextMuted.Render(summary)
+	}
+	lines = append(lines, title)
+	if m.diffs.view != diffViewLive {
+		lines = append(lines, m.renderDiffLoops(width-2))
+	}
+
+	var body string
+	if len(files) == 0 {
+		body = lipgloss.NewStyle().Height(l.height).Render(strings.Join(m.diffPlaceholder(l.diff), "\n"))
+	} else {
+		diff := v.Render(l.diff, l.height)
+		if l.list > 0 {
+			list := m.renderDiffFileList(v, l.list, l.height)
+			divider := strings.TrimRight(strings.Repeat(StyleDividerSubtle.Render("│")+"\n", l.height), "\n")
+			body = lipgloss.JoinHorizontal(lipgloss.Top, list, divider, diff)
+		} else {
+			current := max(v.CurrentFile(), 0)
+			f := files[current]
+			lines = append(lines, StyleTextMuted.Render(ansi.Truncate(
+				fmt.Sprintf("File %d/%d: %s  +%d -%d", current+1, len(files), f.Path, f.Added, f.Deleted), width-2, "…")))
+			body = diff
+		}
+	}
+
+	content := strings.Join(append(lines, body), "\n")
+	return lipgloss.NewStyle().Width(width).Height(height).MaxHeight(height).Padding(0, 1).Render(content)
+}
+
+// diffSummary totals the shown patch, or reports why there is none yet
+func (m Model) diffSummary(files []diffview.File) string {
+	if m.diffs.view == diffViewLive && m.diffPending {
+		return "updating"
+	}
+	if len(files) == 0 {
+		return ""
+	}
+	added, deleted := 0, 0
+	for _, f := range files {
+		added += f.Added
+		deleted += f.Deleted
+	}
+	summary := fmt.Sprintf("%d files +%d -%d", len(files), added, deleted)
+	if d := m.shownDiff(); d != nil && m.diffs.view == diffViewIteration {
+		summary = fmt.Sprintf("Loop %d%s%s", d.Iteration, MetaDotSeparator, summary)
+	}
+	return summary
+}
+
+// renderDiffLoops lists the recorded iterations with their line counts,
+// marking the one the iteration view shows
+func (m Model) renderDiffLoops(width int) string {
+	if len(m.diffs.iterations) == 0 {
+		return StyleTextSubtle.Render("Loops: none yet")
+	}
+	parts := []string{StyleTextSubtle.Render("Loops:")}
+	for i, it := range m.diffs.iterations {
+		label := fmt.Sprintf("%d", it.Iteration)
+		if it.Loaded {
+			added, deleted := it.totals()
+			label = fmt.Sprintf("%d (+%d -%d)", it.Iteration, added, deleted)
+		}
+		if m.diffs.view == diffViewIteration && i == m.diffs.selected {
+			parts = append(parts, StyleTextSelected.Render("["+label+"]"))
+		} else {
+			parts = append(parts, StyleTextMuted.Render(label))
+		}
+	}
+	return ansi.Truncate(strings.Join(parts, " "), width, "…")
+}
+
+// renderDiffFileList lists the patch's files, highlighting the one at the
+// top of the viewer
+func (m Model) renderDiffFileList(v *diffview.Viewer, width, height int) string {
+	files := v.Files()
+	current := v.CurrentFile()
+	attribution := m.shownTools()
+
+	var lines []string
+	start := fileListStart(len(files), current, height)
+	for i := start; i < len(files) && len(lines) < height; i++ {
+		f := files[i]
+		counts := fmt.Sprintf("+%d -%d", f.Added, f.Deleted)
+		if f.Binary {
+			counts = "bin"
+		}
+		name := clipPathLeft(f.Path, width-len(counts)-3)
+		marker, nameStyle := " ", StyleTextMuted
+		if i == current {
+			marker, nameStyle = StyleSpinnerActive.Render(IconBorderThick), StyleTextSelected
+		}
+		line := marker + " " + nameStyle.Render(name) + " " + styleDiffAdd.Render(counts)
+		if tools := attribution.toolsFor(f.Path); len(tools) > 0 {
+			line += " " + StyleTextSubtle.Render(strings.Join(tools, ","))
+		}
+		lines = append(lines, ansi.Truncate(line, width, "…"))
+	}
+	return lipgloss.NewStyle().Width(width).Height(height).Render(strings.Join(lines, "\n"))
+}
+
+// clipPathLeft shortens a path from the left, where it matters least
+func clipPathLeft(path string, n int) string {
+	runes := []rune(path)
+	if n < 2 || len(runes) <= n {
+		return path
+	}
+	return "…" + string(runes[len(runes)-n+1:])
+}
+
+// diffPlaceholder explains an empty Diffs tab
+func (m Model) diffPlaceholder(width int) []string {
+	var lines []string
+	switch m.diffs.view {
+	case diffViewLive:
+		switch {
+		case m.diffErr != nil:
+			lines = append(lines, StyleErrorTitle.Render("git diff failed: ")+StyleTextMuted.Render(m.diffErr.Error()))
+		case len(m.pendingChanges) > 0:
+			// Tool hints until git confirms the change
+			lines = append(lines, StyleTextMuted.Render("Touched files (unverified):"))
+			for _, pc := range m.pendingChanges {
+				lines = append(lines, StyleTextMuted.Render(fmt.Sprintf("  %s (%s %s)", pc.Path, pc.Tool, pc.Status)))
+			}
+		default:
+			lines = append(lines, StyleTextSubtle.Render("No changes detected."))
+		}
+	default:
+		d := m.shownDiff()
+		switch {
+		case m.diffs.disabled:
+			lines = append(lines, StyleTextSubtle.Render("Diff history needs a git repository."))
+		case d == nil:
+			lines = append(lines, StyleTextSubtle.Render("No iterations recorded yet."))
+		case !d.Loaded:
+			lines = append(lines, StyleTextSubtle.Render("Collecting git diff..."))
+		case d.Err != nil:
+			lines = append(lines, StyleErrorTitle.Render("git diff failed: ")+StyleTextMuted.Render(d.Err.Error()))
+		default:
+			lines = append(lines, StyleTextSubtle.Render("No changes."))
+		}
+	}
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, "…")
+	}
+	return lines
+}
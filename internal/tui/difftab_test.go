@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brainwhocodes/lisa-loop/internal/tui/diffview"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const twoFilePatch = "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1,2 +1,2 @@\n-one\n+uno\n two\n" +
+	"diff --git a/b.go b/b.go\n--- a/b.go\n+++ b/b.go\n@@ -1 +1 @@\n-three\n+tres\n"
+
+func TestDiffTab_KeysAndMouse(t *testing.T) {
+	var model tea.Model = Model{
+		screen:       ScreenOutput,
+		outputTab:    OutputTabDiffs,
+		width:        120,
+		height:       30,
+		gitDiffPatch: twoFilePatch,
+		diffViewer:   diffview.New(diffViewerStyles),
+	}
+	current := func() int { return model.(Model).diffViewer.CurrentFile() }
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	if current() != 1 {
+		t.Errorf("f: current file = %d, want 1", current())
+	}
+	model, _ = model.Update(tea.MouseMsg{Button: tea.MouseButtonWheelUp, Action: tea.MouseActionPress})
+	if current() != 0 {
+		t.Errorf("wheel up: current file = %d, want 0", current())
+	}
+
+	// Click b.go, the second entry of the file list
+	model, _ = model.Update(tea.MouseMsg{X: 3, Y: outputContentTop + 2, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	if current() != 1 {
+		t.Errorf("click: current file = %d, want 1", current())
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	view := model.(Model).View()
+	for _, want := range []string{"Diffs: live • 2 files +2 -2", "▸ @@ -1 +1 @@", "d view"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestDiffTab_NarrowShowsCurrentFileLine(t *testing.T) {
+	m := Model{gitDiffPatch: twoFilePatch}
+	got := m.renderDiffTab(80, 20)
+	if !strings.Contains(got, "File 1/2: a.go  +1 -1") || !strings.Contains(got, "+ uno") {
+		t.Errorf("renderDiffTab() narrow:\n%s", got)
+	}
+}
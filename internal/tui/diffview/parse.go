@@ -0,0 +1,106 @@
+package diffview
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LineKind is what a diff line does
+type LineKind int
+
+const (
+	LineContext LineKind = iota // Unchanged
+	LineAdd
+	LineDelete
+	LineNote // "\ No newline at end of file"
+)
+
+// Line is one line of a hunk without its +/-/space prefix. Old and New are
+// its line numbers on either side, 0 where it doesn't exist.
+type Line struct {
+	Kind LineKind
+	Text string
+	Old  int
+	New  int
+}
+
+// Hunk is one @@ section of a file's diff
+type Hunk struct {
+	Header string
+	Lines  []Line
+}
+
+// File is one file's part of a patch
+type File struct {
+	Path    string
+	OldPath string // Set when the file was renamed
+	Binary  bool
+	Added   int
+	Deleted int
+	Hunks   []Hunk
+}
+
+// Parse splits a git patch into files and hunks. Anything it doesn't
+// recognise is skipped, so a partial patch still parses.
+func Parse(patch string) []File {
+	var files []File
+	var f *File
+	var h *Hunk
+	oldNo, newNo := 0, 0
+	for _, line := range strings.Split(patch, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			files = append(files, File{Path: diffGitPath(line)})
+			f, h = &files[len(files)-1], nil
+			continue
+		}
+		if f == nil {
+			continue
+		}
+		switch {
+		case h == nil && strings.HasPrefix(line, "rename from "):
+			f.OldPath = strings.TrimPrefix(line, "rename from ")
+		case h == nil && strings.HasPrefix(line, "rename to "):
+			f.Path = strings.TrimPrefix(line, "rename to ")
+		case h == nil && strings.HasPrefix(line, "Binary files "):
+			f.Binary = true
+		case strings.HasPrefix(line, "@@"):
+			oldNo, newNo = parseHunkHeader(line)
+			f.Hunks = append(f.Hunks, Hunk{Header: line})
+			h = &f.Hunks[len(f.Hunks)-1]
+		case h == nil || line == "":
+			// File headers (index, ---, +++), or the patch's trailing newline
+		case line[0] == '+':
+			h.Lines = append(h.Lines, Line{Kind: LineAdd, Text: line[1:], New: newNo})
+			newNo++
+			f.Added++
+		case line[0] == '-':
+			h.Lines = append(h.Lines, Line{Kind: LineDelete, Text: line[1:], Old: oldNo})
+			oldNo++
+			f.Deleted++
+		case line[0] == '\\':
+			h.Lines = append(h.Lines, Line{Kind: LineNote, Text: line})
+		default:
+			h.Lines = append(h.Lines, Line{Kind: LineContext, Text: line[1:], Old: oldNo, New: newNo})
+			oldNo++
+			newNo++
+		}
+	}
+	return files
+}
+
+// diffGitPath takes the new path from a "diff --git a/x b/x" line
+func diffGitPath(line string) string {
+	if i := strings.LastIndex(line, " b/"); i >= 0 {
+		return line[i+3:]
+	}
+	return strings.TrimPrefix(line, "diff --git ")
+}
+
+// parseHunkHeader reads the starting line numbers of "@@ -a,b +c,d @@"
+func parseHunkHeader(header string) (oldStart, newStart int) {
+	fmt.Sscanf(header, "@@ -%d", &oldStart)
+	if i := strings.Index(header, " +"); i >= 0 {
+		fmt.Sscanf(header[i:], " +%d", &newStart)
+	}
+	return oldStart, newStart
+}
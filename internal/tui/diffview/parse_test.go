@@ -0,0 +1,72 @@
+package diffview
+
+import "testing"
+
+const samplePatch = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,4 +1,4 @@ package main
+ package main
+-func old() {}
++func new() {}
++// added
+ 
+@@ -10,2 +10,1 @@ func other() {
+-	gone()
+ }
+\ No newline at end of file
+diff --git a/old.txt b/new.txt
+similarity index 90%
+rename from old.txt
+rename to new.txt
+diff --git a/logo.png b/logo.png
+Binary files a/logo.png and b/logo.png differ
+`
+
+func TestParse(t *testing.T) {
+	files := Parse(samplePatch)
+	if len(files) != 3 {
+		t.Fatalf("Parse() files = %d, want 3", len(files))
+	}
+
+	main := files[0]
+	if main.Path != "main.go" || main.Added != 2 || main.Deleted != 2 || len(main.Hunks) != 2 {
+		t.Errorf("Parse() main.go = %+v", main)
+	}
+	lines := main.Hunks[0].Lines
+	tests := []struct {
+		line     Line
+		wantKind LineKind
+		wantOld  int
+		wantNew  int
+		wantText string
+	}{
+		{lines[0], LineContext, 1, 1, "package main"},
+		{lines[1], LineDelete, 2, 0, "func old() {}"},
+		{lines[2], LineAdd, 0, 2, "func new() {}"},
+		{lines[3], LineAdd, 0, 3, "// added"},
+		{lines[4], LineContext, 3, 4, ""},
+	}
+	for i, tt := range tests {
+		if tt.line.Kind != tt.wantKind || tt.line.Old != tt.wantOld || tt.line.New != tt.wantNew || tt.line.Text != tt.wantText {
+			t.Errorf("line %d = %+v, want kind %d old %d new %d text %q", i, tt.line, tt.wantKind, tt.wantOld, tt.wantNew, tt.wantText)
+		}
+	}
+	if last := main.Hunks[1].Lines; len(last) != 3 || last[1].Old != 11 || last[2].Kind != LineNote {
+		t.Errorf("second hunk lines = %+v", last)
+	}
+
+	if files[1].Path != "new.txt" || files[1].OldPath != "old.txt" {
+		t.Errorf("Parse() rename = %+v", files[1])
+	}
+	if !files[2].Binary || files[2].Path != "logo.png" {
+		t.Errorf("Parse() binary = %+v", files[2])
+	}
+}
+
+func TestParse_Empty(t *testing.T) {
+	if files := Parse(""); len(files) != 0 {
+		t.Errorf("Parse(\"\") = %+v, want none", files)
+	}
+}
@@ -0,0 +1,508 @@
+package diffview
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// SideBySideWidth is the narrowest viewer that shows changes side by side
+const SideBySideWidth = 140
+
+// maxHighlight caps how much of a line is highlighted; the rest is
+// off screen anyway
+const maxHighlight = 512
+
+// Styles colours the viewer
+type Styles struct {
+	File       lipgloss.Style // File header rows
+	Hunk       lipgloss.Style // Hunk header rows
+	LineNumber lipgloss.Style
+	Context    lipgloss.Style // Unchanged code when Chroma is nil
+	Add        lipgloss.Style // + signs, and added code when Chroma is nil
+	Delete     lipgloss.Style // - signs, and deleted code when Chroma is nil
+	Divider    lipgloss.Style // Between the sides of a side-by-side row
+
+	// Backgrounds behind changed lines; nil for none
+	AddBackground    lipgloss.TerminalColor
+	DeleteBackground lipgloss.TerminalColor
+
+	Chroma *chroma.Style // Syntax colours; nil disables highlighting
+}
+
+type rowKind int
+
+const (
+	rowFile rowKind = iota
+	rowHunk
+	rowLine
+)
+
+// row is one screen line. Rows are indexes into the parsed files, so the
+// layout of a large patch is cheap; only rows on screen are rendered.
+type row struct {
+	kind        rowKind
+	file, hunk  int
+	left, right int // Line indexes in the hunk, -1 for none; unified rows only use left
+}
+
+type hunkKey struct{ path, header string }
+
+type lineKey struct{ file, hunk, line int }
+
+// Viewer renders a patch as a scrollable diff with syntax highlighting,
+// foldable hunks and a side-by-side mode on wide terminals. It keeps its
+// scroll position and folds when the patch is refreshed.
+type Viewer struct {
+	styles Styles
+
+	patch  string
+	files  []File
+	digits int // Width of the line number columns
+
+	rows       []row
+	offset     int
+	width      int
+	height     int
+	sideBySide bool // Preferred; only used at SideBySideWidth and wider
+	split      bool // Side by side as laid out
+	folded     map[hunkKey]bool
+
+	lexers      map[int]chroma.Lexer
+	highlighted map[lineKey]string
+}
+
+// New creates an empty viewer that prefers side-by-side on wide terminals
+func New(styles Styles) *Viewer {
+	return &Viewer{
+		styles:      styles,
+		sideBySide:  true,
+		folded:      make(map[hunkKey]bool),
+		lexers:      make(map[int]chroma.Lexer),
+		highlighted: make(map[lineKey]string),
+	}
+}
+
+// SetPatch shows a new patch, staying on the same file where possible
+func (v *Viewer) SetPatch(patch string) {
+	if patch == v.patch && v.files != nil {
+		return
+	}
+	path, hunk, skip := v.anchor()
+	v.patch = patch
+	v.files = Parse(patch)
+	v.lexers = make(map[int]chroma.Lexer)
+	v.highlighted = make(map[lineKey]string)
+
+	maxLine := 0
+	for _, f := range v.files {
+		for _, h := range f.Hunks {
+			for _, l := range h.Lines {
+				maxLine = max(maxLine, l.Old, l.New)
+			}
+		}
+	}
+	v.digits = max(3, len(strconv.Itoa(maxLine)))
+	v.layout(path, hunk, skip)
+}
+
+// SetSize sets the viewport, switching between unified and side by side
+func (v *Viewer) SetSize(width, height int) {
+	v.width, v.height = width, height
+	if split := v.sideBySide && width >= SideBySideWidth; split != v.split {
+		v.split = split
+		path, hunk, skip := v.anchor()
+		v.layout(path, hunk, skip)
+	}
+	v.ScrollBy(0)
+}
+
+// Files returns the parsed patch
+func (v *Viewer) Files() []File {
+	return v.files
+}
+
+// CurrentFile is the index of the file at the top of the viewport, or -1
+func (v *Viewer) CurrentFile() int {
+	if v.offset >= len(v.rows) {
+		return -1
+	}
+	return v.rows[v.offset].file
+}
+
+// SideBySide reports whether changes are laid out side by side
+func (v *Viewer) SideBySide() bool {
+	return v.split
+}
+
+// ToggleSideBySide switches between unified and side-by-side layouts
+func (v *Viewer) ToggleSideBySide() {
+	v.sideBySide = !v.sideBySide
+	v.SetSize(v.width, v.height)
+}
+
+// ScrollBy moves the viewport by n rows. Scrolling down stops once the last
+// row is on screen, though a jump may have put a late file further down.
+func (v *Viewer) ScrollBy(n int) {
+	target := v.offset + n
+	if n > 0 {
+		target = min(target, max(len(v.rows)-v.height, v.offset))
+	}
+	v.offset = max(0, min(target, len(v.rows)-1))
+}
+
+// PageDown scrolls a screen down
+func (v *Viewer) PageDown() { v.ScrollBy(max(1, v.height-1)) }
+
+// PageUp scrolls a screen up
+func (v *Viewer) PageUp() { v.ScrollBy(-max(1, v.height-1)) }
+
+// Top scrolls to the start of the patch
+func (v *Viewer) Top() { v.ScrollBy(-v.offset) }
+
+// Bottom scrolls to the end of the patch
+func (v *Viewer) Bottom() { v.ScrollBy(len(v.rows)) }
+
+// JumpToFile scrolls to the i'th file's header
+func (v *Viewer) JumpToFile(i int) {
+	for r, rw := range v.rows {
+		if rw.kind == rowFile && rw.file == i {
+			v.scrollTo(r)
+			return
+		}
+	}
+}
+
+// NextFile scrolls to the next file's header
+func (v *Viewer) NextFile() { v.seek(rowFile, 1) }
+
+// PrevFile scrolls to the previous file's header
+func (v *Viewer) PrevFile() { v.seek(rowFile, -1) }
+
+// NextHunk scrolls to the next hunk's header
+func (v *Viewer) NextHunk() { v.seek(rowHunk, 1) }
+
+// PrevHunk scrolls to the previous hunk's header
+func (v *Viewer) PrevHunk() { v.seek(rowHunk, -1) }
+
+func (v *Viewer) seek(kind rowKind, dir int) {
+	for r := v.offset + dir; r >= 0 && r < len(v.rows); r += dir {
+		if v.rows[r].kind == kind {
+			v.scrollTo(r)
+			return
+		}
+	}
+}
+
+// scrollTo puts row r at the top of the viewport
+func (v *Viewer) scrollTo(r int) {
+	v.offset = r
+	v.ScrollBy(0)
+}
+
+// ToggleFold folds or unfolds the hunk at the top of the viewport
+func (v *Viewer) ToggleFold() {
+	if v.offset >= len(v.rows) {
+		return
+	}
+	r := v.rows[v.offset]
+	f := v.files[r.file]
+	if len(f.Hunks) == 0 {
+		return
+	}
+	hunk := max(r.hunk, 0)
+	key := hunkKey{f.Path, f.Hunks[hunk].Header}
+	v.folded[key] = !v.folded[key]
+	v.layout(f.Path, hunk, 0)
+}
+
+// ToggleFoldAll folds every hunk, or unfolds them all when all are folded
+func (v *Viewer) ToggleFoldAll() {
+	fold := false
+	for _, f := range v.files {
+		for _, h := range f.Hunks {
+			if !v.folded[hunkKey{f.Path, h.Header}] {
+				fold = true
+			}
+		}
+	}
+	path, _, _ := v.anchor()
+	for _, f := range v.files {
+		for _, h := range f.Hunks {
+			v.folded[hunkKey{f.Path, h.Header}] = fold
+		}
+	}
+	v.layout(path, -1, 0)
+}
+
+// anchor is the file and hunk at the top of the viewport, and how many
+// rows into the hunk the viewport starts
+func (v *Viewer) anchor() (path string, hunk, skip int) {
+	if v.offset >= len(v.rows) {
+		return "", -1, 0
+	}
+	r := v.rows[v.offset]
+	start := v.offset
+	for start > 0 && v.rows[start-1].file == r.file && v.rows[start-1].hunk == r.hunk {
+		start--
+	}
+	return v.files[r.file].Path, r.hunk, v.offset - start
+}
+
+// layout rebuilds the rows and scrolls back to the anchor, staying within
+// the anchor's hunk when it got shorter
+func (v *Viewer) layout(path string, hunk, skip int) {
+	v.rows = v.rows[:0]
+	v.offset = 0
+	anchorEnd := -1
+	for fi, f := range v.files {
+		if f.Path == path {
+			v.offset = len(v.rows)
+			anchorEnd = v.offset
+		}
+		v.rows = append(v.rows, row{kind: rowFile, file: fi, hunk: -1, left: -1, right: -1})
+		for hi, h := range f.Hunks {
+			if f.Path == path && hi == hunk {
+				v.offset = len(v.rows)
+				anchorEnd = v.offset
+			}
+			v.rows = append(v.rows, row{kind: rowHunk, file: fi, hunk: hi, left: -1, right: -1})
+			if v.folded[hunkKey{f.Path, h.Header}] {
+				continue
+			}
+			if v.split {
+				v.rows = appendSplitRows(v.rows, fi, hi, h.Lines)
+				continue
+			}
+			for li := range h.Lines {
+				v.rows = append(v.rows, row{kind: rowLine, file: fi, hunk: hi, left: li, right: -1})
+			}
+			if f.Path == path && hi == hunk {
+				anchorEnd = len(v.rows) - 1
+			}
+		}
+	}
+	if anchorEnd >= 0 {
+		v.offset = min(v.offset+skip, anchorEnd)
+	}
+	v.ScrollBy(0)
+}
+
+// appendSplitRows pairs each run of deleted lines with the added lines
+// that replace it
+func appendSplitRows(rows []row, file, hunk int, lines []Line) []row {
+	for i := 0; i < len(lines); {
+		switch lines[i].Kind {
+		case LineDelete, LineAdd:
+			var dels, adds []int
+			for ; i < len(lines) && lines[i].Kind == LineDelete; i++ {
+				dels = append(dels, i)
+			}
+			for ; i < len(lines) && lines[i].Kind == LineAdd; i++ {
+				adds = append(adds, i)
+			}
+			for j := 0; j < len(dels) || j < len(adds); j++ {
+				r := row{kind: rowLine, file: file, hunk: hunk, left: -1, right: -1}
+				if j < len(dels) {
+					r.left = dels[j]
+				}
+				if j < len(adds) {
+					r.right = adds[j]
+				}
+				rows = append(rows, r)
+			}
+		case LineNote:
+			rows = append(rows, row{kind: rowLine, file: file, hunk: hunk, left: i, right: -1})
+			i++
+		default:
+			rows = append(rows, row{kind: rowLine, file: file, hunk: hunk, left: i, right: i})
+			i++
+		}
+	}
+	return rows
+}
+
+// Render draws the rows in the viewport
+func (v *Viewer) Render(width, height int) string {
+	v.SetSize(width, height)
+	end := min(v.offset+height, len(v.rows))
+	lines := make([]string, 0, height)
+	for _, r := range v.rows[v.offset:end] {
+		lines = append(lines, v.renderRow(r, width))
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (v *Viewer) renderRow(r row, width int) string {
+	f := v.files[r.file]
+	switch r.kind {
+	case rowFile:
+		title := f.Path
+		if f.OldPath != "" {
+			title = f.OldPath + " → " + f.Path
+		}
+		if f.Binary {
+			title += "  (binary)"
+		} else {
+			title += fmt.Sprintf("  +%d -%d", f.Added, f.Deleted)
+		}
+		return v.styles.File.Width(width).Render(ansi.Truncate(" "+title, width, "…"))
+
+	case rowHunk:
+		h := f.Hunks[r.hunk]
+		text := "▾ " + h.Header
+		if v.folded[hunkKey{f.Path, h.Header}] {
+			text = fmt.Sprintf("▸ %s  (%d lines folded)", h.Header, len(h.Lines))
+		}
+		return v.styles.Hunk.Render(ansi.Truncate(text, width, "…"))
+	}
+
+	lines := f.Hunks[r.hunk].Lines
+	if !v.split {
+		l := lines[r.left]
+		gutter := fmt.Sprintf("%s %s ", v.number(l.Old), v.number(l.New))
+		return v.renderCell(r.file, r.hunk, r.left, &l, gutter, width)
+	}
+
+	half := (width - 1) / 2
+	left := strings.Repeat(" ", half)
+	if r.left >= 0 {
+		l := lines[r.left]
+		left = v.renderCell(r.file, r.hunk, r.left, &l, v.number(l.Old)+" ", half)
+	}
+	right := ""
+	if r.right >= 0 {
+		l := lines[r.right]
+		right = v.renderCell(r.file, r.hunk, r.right, &l, v.number(l.New)+" ", width-half-1)
+	}
+	return left + v.styles.Divider.Render("│") + right
+}
+
+// renderCell draws a line with its gutter, padded to width so changed
+// lines get a full-width background
+func (v *Viewer) renderCell(file, hunk, line int, l *Line, gutter string, width int) string {
+	if l.Kind == LineNote {
+		return v.styles.LineNumber.Render(ansi.Truncate(gutter+l.Text, width, ""))
+	}
+
+	sign, signStyle, bg := " ", v.styles.Context, lipgloss.TerminalColor(nil)
+	switch l.Kind {
+	case LineAdd:
+		sign, signStyle, bg = "+", v.styles.Add, v.styles.AddBackground
+	case LineDelete:
+		sign, signStyle, bg = "-", v.styles.Delete, v.styles.DeleteBackground
+	}
+	if bg != nil {
+		signStyle = signStyle.Background(bg)
+	}
+
+	prefix := v.styles.LineNumber.Render(gutter) + signStyle.Render(sign+" ")
+	room := width - ansi.StringWidth(gutter) - 2
+	if room <= 0 {
+		return ansi.Truncate(prefix, width, "")
+	}
+	code := ansi.Truncate(v.highlight(file, hunk, line, l), room, "")
+	if pad := room - ansi.StringWidth(code); pad > 0 {
+		fill := lipgloss.NewStyle()
+		if bg != nil {
+			fill = fill.Background(bg)
+		}
+		code += fill.Render(strings.Repeat(" ", pad))
+	}
+	return prefix + code
+}
+
+func (v *Viewer) number(n int) string {
+	if n == 0 {
+		return strings.Repeat(" ", v.digits)
+	}
+	return fmt.Sprintf("%*d", v.digits, n)
+}
+
+// highlight colours a line's code, caching the result. Lines are tokenised
+// on their own, so constructs spanning lines (block comments, raw strings)
+// may be coloured as plain code.
+func (v *Viewer) highlight(file, hunk, line int, l *Line) string {
+	key := lineKey{file, hunk, line}
+	if s, ok := v.highlighted[key]; ok {
+		return s
+	}
+
+	text := strings.ReplaceAll(l.Text, "\t", "    ")
+	if runes := []rune(text); len(runes) > maxHighlight {
+		text = string(runes[:maxHighlight])
+	}
+	var bg lipgloss.TerminalColor
+	plain := v.styles.Context
+	switch l.Kind {
+	case LineAdd:
+		bg, plain = v.styles.AddBackground, v.styles.Add
+	case LineDelete:
+		bg, plain = v.styles.DeleteBackground, v.styles.Delete
+	}
+
+	var out string
+	if tokens := v.tokenise(file, text); tokens != nil {
+		var b strings.Builder
+		for _, tok := range tokens {
+			value := strings.TrimRight(tok.Value, "\n")
+			if value == "" {
+				continue
+			}
+			style := lipgloss.NewStyle()
+			entry := v.styles.Chroma.Get(tok.Type)
+			if entry.Colour.IsSet() {
+				style = style.Foreground(lipgloss.Color(entry.Colour.String()))
+			}
+			if entry.Bold == chroma.Yes {
+				style = style.Bold(true)
+			}
+			if entry.Italic == chroma.Yes {
+				style = style.Italic(true)
+			}
+			if bg != nil {
+				style = style.Background(bg)
+			}
+			b.WriteString(style.Render(value))
+		}
+		out = b.String()
+	} else {
+		if bg != nil {
+			plain = plain.Background(bg)
+		}
+		out = plain.Render(text)
+	}
+	v.highlighted[key] = out
+	return out
+}
+
+// tokenise lexes text as the file's language, or returns nil when
+// highlighting is off or the language is unknown
+func (v *Viewer) tokenise(file int, text string) []chroma.Token {
+	if v.styles.Chroma == nil {
+		return nil
+	}
+	lexer, ok := v.lexers[file]
+	if !ok {
+		if lexer = lexers.Match(v.files[file].Path); lexer != nil {
+			lexer = chroma.Coalesce(lexer)
+		}
+		v.lexers[file] = lexer
+	}
+	if lexer == nil {
+		return nil
+	}
+	it, err := lexer.Tokenise(nil, text)
+	if err != nil {
+		return nil
+	}
+	return it.Tokens()
+}
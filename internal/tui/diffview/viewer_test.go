@@ -0,0 +1,137 @@
+package diffview
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+func TestViewer_Navigation(t *testing.T) {
+	v := New(Styles{})
+	v.SetPatch(samplePatch)
+	v.SetSize(80, 5)
+
+	if v.CurrentFile() != 0 {
+		t.Fatalf("CurrentFile() = %d, want 0", v.CurrentFile())
+	}
+	v.NextHunk()
+	v.NextHunk()
+	if got := strings.Split(v.Render(80, 5), "\n")[0]; !strings.Contains(got, "@@ -10,2 +10,1 @@") {
+		t.Errorf("after two NextHunk the top row = %q, want the second hunk", got)
+	}
+	v.NextFile()
+	if v.CurrentFile() != 1 {
+		t.Errorf("NextFile() CurrentFile = %d, want 1", v.CurrentFile())
+	}
+	v.PrevFile()
+	if v.CurrentFile() != 0 {
+		t.Errorf("PrevFile() CurrentFile = %d, want 0", v.CurrentFile())
+	}
+
+	// Scrolling stops with the last row on screen
+	v.Bottom()
+	rows := strings.Split(v.Render(80, 5), "\n")
+	if !strings.Contains(rows[4], "logo.png") {
+		t.Errorf("Bottom() last row = %q, want the last file", rows[4])
+	}
+	v.ScrollBy(-100)
+	if v.offset != 0 {
+		t.Errorf("ScrollBy(-100) offset = %d, want 0", v.offset)
+	}
+}
+
+func TestViewer_Folding(t *testing.T) {
+	v := New(Styles{})
+	v.SetPatch(samplePatch)
+	v.SetSize(80, 40)
+	all := len(v.rows)
+
+	v.NextHunk()
+	v.ToggleFold()
+	if len(v.rows) != all-5 {
+		t.Errorf("folded rows = %d, want %d", len(v.rows), all-5)
+	}
+	if top := strings.Split(v.Render(80, 40), "\n")[0]; !strings.Contains(top, "▸ @@ -1,4 +1,4 @@") || !strings.Contains(top, "(5 lines folded)") {
+		t.Errorf("folded hunk row = %q", top)
+	}
+
+	// Folds survive a refresh of the same patch
+	v.SetPatch(samplePatch + "\n")
+	if len(v.rows) != all-5 {
+		t.Errorf("rows after refresh = %d, want the hunk still folded", len(v.rows))
+	}
+
+	v.ToggleFoldAll()
+	if len(v.rows) != 5 { // Three file headers and two hunk headers
+		t.Errorf("ToggleFoldAll() rows = %d, want 5", len(v.rows))
+	}
+	v.ToggleFoldAll()
+	if len(v.rows) != all {
+		t.Errorf("second ToggleFoldAll() rows = %d, want %d", len(v.rows), all)
+	}
+}
+
+func TestViewer_SideBySide(t *testing.T) {
+	v := New(Styles{})
+	v.SetPatch(samplePatch)
+
+	unified := v.Render(100, 40)
+	if v.SideBySide() || !strings.Contains(unified, "- func old() {}") {
+		t.Fatalf("narrow viewer is not unified:\n%s", unified)
+	}
+
+	split := v.Render(SideBySideWidth, 40)
+	if !v.SideBySide() {
+		t.Fatal("wide viewer is not side by side")
+	}
+	var paired string
+	for _, line := range strings.Split(split, "\n") {
+		if strings.Contains(line, "func old") {
+			paired = line
+		}
+	}
+	if !strings.Contains(paired, "+ func new() {}") || !strings.Contains(paired, "│") {
+		t.Errorf("deleted and added lines are not paired: %q", paired)
+	}
+	if n := len(strings.Split(split, "\n")[5]); n < SideBySideWidth-10 {
+		t.Errorf("side-by-side row is %d bytes wide", n)
+	}
+
+	v.ToggleSideBySide()
+	if v.SideBySide() {
+		t.Error("ToggleSideBySide() did not switch back to unified")
+	}
+}
+
+func TestViewer_KeepsPositionOnRefresh(t *testing.T) {
+	v := New(Styles{})
+	v.SetPatch(samplePatch)
+	v.SetSize(80, 3)
+	v.NextFile()
+
+	// A new file sorting first shifts every row down
+	v.SetPatch("diff --git a/aaa.go b/aaa.go\n--- a/aaa.go\n+++ b/aaa.go\n@@ -1 +1 @@\n-x\n+y\n" + samplePatch)
+	if path := v.Files()[v.CurrentFile()].Path; path != "new.txt" {
+		t.Errorf("CurrentFile() after refresh = %s, want new.txt", path)
+	}
+}
+
+func TestViewer_HighlightsOnlyVisibleRows(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("diff --git a/big.go b/big.go\n--- a/big.go\n+++ b/big.go\n@@ -0,0 +1,20000 @@\n")
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(&b, "+var x%d = %d\n", i, i)
+	}
+	v := New(Styles{Chroma: styles.Get("monokai")})
+	v.SetPatch(b.String())
+
+	out := v.Render(80, 30)
+	if len(v.highlighted) > 30 {
+		t.Errorf("highlighted %d lines for a 30 row viewport", len(v.highlighted))
+	}
+	if !strings.Contains(out, "var x0 = 0") {
+		t.Errorf("Render() = %q, want the first added line", out)
+	}
+}
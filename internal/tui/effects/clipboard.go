@@ -0,0 +1,35 @@
+package effects
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/brainwhocodes/lisa-loop/internal/tui/msg"
+)
+
+// CopyToClipboard puts text on the terminal's clipboard in a Bubble Tea
+// command. It writes an OSC 52 escape sequence to out rather than calling a
+// clipboard program, so it also works over SSH; inside tmux or screen the
+// sequence is wrapped to pass through to the outer terminal. Terminals that
+// do not support OSC 52 ignore it. A nil out writes to standard error, which
+// leaves standard output to the renderer.
+func CopyToClipboard(what, text string, out io.Writer) tea.Cmd {
+	return func() tea.Msg {
+		if out == nil {
+			out = os.Stderr
+		}
+		seq := osc52.New(text)
+		switch {
+		case os.Getenv("TMUX") != "":
+			seq = seq.Tmux()
+		case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+			seq = seq.Screen()
+		}
+		_, err := seq.WriteTo(out)
+		return msg.ClipboardCopiedMsg{What: what, Bytes: len(text), Err: err}
+	}
+}
@@ -0,0 +1,31 @@
+package effects
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/brainwhocodes/lisa-loop/internal/tui/msg"
+)
+
+func TestCopyToClipboard(t *testing.T) {
+	t.Setenv("TMUX", "")
+	t.Setenv("TERM", "xterm-256color")
+
+	var out bytes.Buffer
+	got := CopyToClipboard("input", "go test ./...", &out)().(msg.ClipboardCopiedMsg)
+	if got.Err != nil || got.What != "input" || got.Bytes != 13 {
+		t.Errorf("CopyToClipboard() = %+v", got)
+	}
+	want := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte("go test ./...")) + "\x07"
+	if out.String() != want {
+		t.Errorf("CopyToClipboard() wrote %q, want %q", out.String(), want)
+	}
+
+	t.Setenv("TMUX", "/tmp/tmux-0/default,1,0")
+	out.Reset()
+	CopyToClipboard("input", "x", &out)()
+	if !bytes.HasPrefix(out.Bytes(), []byte("\x1bPtmux;")) {
+		t.Errorf("CopyToClipboard() in tmux wrote %q, want a tmux passthrough", out.String())
+	}
+}
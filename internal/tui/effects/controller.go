@@ -2,6 +2,7 @@ package effects
 
 import (
 	"context"
+	"sync"
 
 	tea "github.com/charmbracelet/bubbletea"
 
@@ -27,3 +28,62 @@ func RunController(ctx context.Context, runner Runner) tea.Cmd {
 		return msg.ControllerDoneMsg{Err: runner.Run(ctx)}
 	}
 }
+
+// ControlQueue runs controller controls off the Bubble Tea goroutine, one at
+// a time and in the order they were sent. When the TUI is attached to another
+// process each control is an HTTP round trip, which must never stall Update.
+type ControlQueue struct {
+	mu      sync.Mutex
+	pending []func()
+	running bool
+}
+
+// NewControlQueue creates an empty control queue
+func NewControlQueue() *ControlQueue {
+	return &ControlQueue{}
+}
+
+// Send queues a control immediately, so controls keep the order of the keys
+// that sent them, and returns a command that reports msg.ControlSentMsg once
+// the control has run. A nil queue runs the control inside the command.
+func (q *ControlQueue) Send(action string, control func()) tea.Cmd {
+	if q == nil {
+		return func() tea.Msg {
+			control()
+			return msg.ControlSentMsg{Action: action}
+		}
+	}
+
+	done := make(chan struct{})
+	q.mu.Lock()
+	q.pending = append(q.pending, func() {
+		defer close(done)
+		control()
+	})
+	if !q.running {
+		q.running = true
+		go q.drain()
+	}
+	q.mu.Unlock()
+
+	return func() tea.Msg {
+		<-done
+		return msg.ControlSentMsg{Action: action}
+	}
+}
+
+// drain runs queued controls until the queue is empty
+func (q *ControlQueue) drain() {
+	for {
+		q.mu.Lock()
+		if len(q.pending) == 0 {
+			q.running = false
+			q.mu.Unlock()
+			return
+		}
+		next := q.pending[0]
+		q.pending = q.pending[1:]
+		q.mu.Unlock()
+		next()
+	}
+}
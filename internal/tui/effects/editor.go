@@ -0,0 +1,31 @@
+package effects
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/brainwhocodes/lisa-loop/internal/tui/msg"
+)
+
+// Editor returns the user's editor: $VISUAL, then $EDITOR, then vi.
+func Editor() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := os.Getenv(env); editor != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
+
+// EditPlan suspends the TUI and opens the plan file in the user's editor.
+// The editor may carry arguments, e.g. EDITOR="code --wait".
+func EditPlan(filename string) tea.Cmd {
+	args := strings.Fields(Editor())
+	cmd := exec.Command(args[0], append(args[1:], filename)...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return msg.PlanEditedMsg{Filename: filename, Err: err}
+	})
+}
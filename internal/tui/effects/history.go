@@ -0,0 +1,28 @@
+package effects
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	"github.com/brainwhocodes/lisa-loop/internal/opencode"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/msg"
+)
+
+// LoadHistory reads the run journals in journalDir and the sessions archived
+// under projectDir for the History screen.
+func LoadHistory(journalDir, projectDir string) tea.Cmd {
+	return func() tea.Msg {
+		runs, rErr := loop.LoadRunHistory(journalDir)
+		sessions, sErr := opencode.NewSessionArchiver(projectDir).List()
+		return msg.HistoryLoadedMsg{Runs: runs, Sessions: sessions, Err: firstErr(rErr, sErr)}
+	}
+}
+
+// LoadHistoryIteration reads the index'th iteration of the run journal at
+// path in full, for browsing on the History screen.
+func LoadHistoryIteration(path string, index int) tea.Cmd {
+	return func() tea.Msg {
+		it, err := loop.LoadIteration(path, index)
+		return msg.HistoryIterationLoadedMsg{Path: path, Index: index, Iteration: it, Err: err}
+	}
+}
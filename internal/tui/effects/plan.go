@@ -28,6 +28,7 @@ func LoadPlan(filename string, readFile ReadFile) tea.Cmd {
 		content := string(b)
 		return msg.PlanLoadedMsg{
 			Filename: filename,
+			Content:  content,
 			Phases:   plan.ParsePhases(content),
 			Tasks:    plan.ParseTasks(content),
 			Err:      nil,
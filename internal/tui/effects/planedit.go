@@ -0,0 +1,93 @@
+package effects
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/brainwhocodes/lisa-loop/internal/tui/msg"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/plan"
+)
+
+// ErrPlanChanged is returned when the plan file changes while an edit is
+// being written
+var ErrPlanChanged = errors.New("plan file changed while saving")
+
+// EditPlanTasks applies a task edit to the plan file in a Bubble Tea command.
+// base is the content the edit was made against. If the file no longer
+// matches it, the plan was changed elsewhere (usually by the agent) and the
+// edit is applied to the file's current content instead, provided its task
+// is still there. Either way the reply carries the plan as it now is on disk.
+func EditPlanTasks(filename, base string, edit plan.Edit, readFile ReadFile) tea.Cmd {
+	return func() tea.Msg {
+		if readFile == nil {
+			readFile = OSReadFile
+		}
+		reply := func(content string, task int, conflict bool, err error) tea.Msg {
+			return msg.PlanTaskEditedMsg{
+				Filename: filename,
+				Content:  content,
+				Phases:   plan.ParsePhases(content),
+				Tasks:    plan.ParseTasks(content),
+				Task:     task,
+				Conflict: conflict,
+				Err:      err,
+			}
+		}
+
+		b, err := readFile(filename)
+		if err != nil {
+			return msg.PlanTaskEditedMsg{Filename: filename, Err: err}
+		}
+		current := string(b)
+		conflict := current != base
+
+		updated, task, err := plan.Apply(current, edit)
+		if err != nil {
+			return reply(current, -1, conflict, err)
+		}
+		if err := writePlanFile(filename, current, updated, readFile); err != nil {
+			if b, rerr := readFile(filename); rerr == nil {
+				current = string(b)
+			}
+			return reply(current, -1, true, err)
+		}
+		return reply(updated, task, conflict, nil)
+	}
+}
+
+// writePlanFile replaces the plan through a temporary file and a rename, so
+// the loop never reads a half-written plan. The file is checked against the
+// content the edit was made from just before the rename.
+func writePlanFile(filename, expected, content string, readFile ReadFile) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(filename); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+
+	b, err := readFile(filename)
+	if err != nil {
+		return err
+	}
+	if string(b) != expected {
+		return ErrPlanChanged
+	}
+	return os.Rename(tmp.Name(), filename)
+}
@@ -0,0 +1,76 @@
+package effects
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brainwhocodes/lisa-loop/internal/tui/msg"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/plan"
+)
+
+func TestEditPlanTasks(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "@fix_plan.md")
+	base := "# Plan\n- [ ] First task\n- [ ] Second task\n"
+	edit := func(base string, e plan.Edit) msg.PlanTaskEditedMsg {
+		t.Helper()
+		return EditPlanTasks(planFile, base, e, nil)().(msg.PlanTaskEditedMsg)
+	}
+	read := func() string {
+		t.Helper()
+		b, err := os.ReadFile(planFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	os.WriteFile(planFile, []byte(base), 0600)
+	got := edit(base, plan.Edit{Kind: plan.EditToggle, Task: "Second task"})
+	want := "# Plan\n- [ ] First task\n- [x] Second task\n"
+	if got.Err != nil || got.Conflict || got.Task != 1 || got.Content != want || read() != want {
+		t.Fatalf("EditPlanTasks() = %+v, file %q", got, read())
+	}
+	if len(got.Tasks) != 2 || !got.Tasks[1].Completed {
+		t.Errorf("EditPlanTasks() tasks = %+v", got.Tasks)
+	}
+	if info, _ := os.Stat(planFile); info.Mode().Perm() != 0600 {
+		t.Errorf("plan mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// The agent adds a task: the edit lands on the new content
+	agent := want + "- [ ] Agent task\n"
+	os.WriteFile(planFile, []byte(agent), 0600)
+	got = edit(want, plan.Edit{Kind: plan.EditPin, Task: "Agent task"})
+	want = "# Plan\n- [ ] Agent task\n- [ ] First task\n- [x] Second task\n"
+	if got.Err != nil || !got.Conflict || got.Task != 0 || read() != want {
+		t.Fatalf("EditPlanTasks() after agent edit = %+v, file %q", got, read())
+	}
+
+	// The agent rewrote the task being edited: nothing is written
+	agent = "# Plan\n- [x] Agent task, done\n"
+	os.WriteFile(planFile, []byte(agent), 0600)
+	got = edit(want, plan.Edit{Kind: plan.EditDelete, Task: "Agent task"})
+	if !errors.Is(got.Err, plan.ErrTaskNotFound) || !got.Conflict || got.Content != agent || read() != agent {
+		t.Fatalf("EditPlanTasks() on a missing task = %+v, file %q", got, read())
+	}
+
+	// The file changes between reading and replacing it
+	calls := 0
+	racing := func(path string) ([]byte, error) {
+		calls++
+		if calls == 2 {
+			os.WriteFile(planFile, []byte(agent+"- [ ] Raced\n"), 0600)
+		}
+		return os.ReadFile(path)
+	}
+	got = EditPlanTasks(planFile, agent, plan.Edit{Kind: plan.EditToggle, Task: "Agent task, done"}, racing)().(msg.PlanTaskEditedMsg)
+	if !errors.Is(got.Err, ErrPlanChanged) || !got.Conflict || read() != agent+"- [ ] Raced\n" || got.Content != read() {
+		t.Fatalf("EditPlanTasks() during a concurrent write = %+v, file %q", got, read())
+	}
+
+	if matches, _ := filepath.Glob(filepath.Join(filepath.Dir(planFile), ".*.tmp")); len(matches) != 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
@@ -0,0 +1,52 @@
+package effects
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/msg"
+)
+
+// snapshotTimeout bounds a snapshot of a very large or wedged working tree
+const snapshotTimeout = time.Minute
+
+// Snapshot records the working tree as a git tree object
+type Snapshot func(ctx context.Context) (string, error)
+
+// SnapshotWorkTree records the working tree with snapshot, or with
+// loop.SnapshotWorkTree when snapshot is nil.
+func SnapshotWorkTree(id int, snapshot Snapshot) tea.Cmd {
+	return func() tea.Msg {
+		if snapshot == nil {
+			snapshot = loop.SnapshotWorkTree
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+		defer cancel()
+		tree, err := snapshot(ctx)
+		return msg.WorkTreeSnapshotMsg{ID: id, Tree: tree, Err: err}
+	}
+}
+
+// LoadTreeDiff collects per-file add/remove counts and a unified patch
+// between two snapshots taken by SnapshotWorkTree.
+func LoadTreeDiff(id int, from, to string, execFn Exec) tea.Cmd {
+	return func() tea.Msg {
+		if execFn == nil {
+			execFn = OSExec
+		}
+
+		numStat, nErr := execFn("git", "diff", "--numstat", from, to)
+		patch, pErr := execFn("git", "diff", "--patch", "--no-color", from, to)
+
+		return msg.TreeDiffLoadedMsg{
+			ID:      id,
+			NumStat: strings.TrimSpace(string(numStat)),
+			Patch:   strings.TrimRight(string(patch), "\n"),
+			Err:     firstErr(nErr, pErr),
+		}
+	}
+}
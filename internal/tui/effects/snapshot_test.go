@@ -0,0 +1,63 @@
+package effects
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/brainwhocodes/lisa-loop/internal/tui/msg"
+)
+
+func TestSnapshotWorkTree_LeavesIndexAlone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(origDir)
+
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	snapshot := func(id int) string {
+		t.Helper()
+		m := SnapshotWorkTree(id, nil)().(msg.WorkTreeSnapshotMsg)
+		if m.Err != nil {
+			t.Fatalf("SnapshotWorkTree() error = %v", m.Err)
+		}
+		return m.Tree
+	}
+
+	git("init", "-q")
+	os.WriteFile("main.go", []byte("package main\n"), 0644)
+	git("add", "main.go")
+	before := snapshot(1)
+
+	os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644)
+	os.WriteFile("new.go", []byte("package main\n"), 0644)
+	os.MkdirAll(".lisa", 0755)
+	os.WriteFile(".lisa/state.json", []byte("{}"), 0644)
+	after := snapshot(2)
+
+	if got := git("status", "--porcelain"); got != "AM main.go\n?? .lisa/\n?? new.go" {
+		t.Errorf("snapshots changed the index:\n%s", got)
+	}
+
+	d := LoadTreeDiff(3, before, after, nil)().(msg.TreeDiffLoadedMsg)
+	if d.Err != nil {
+		t.Fatalf("LoadTreeDiff() error = %v", d.Err)
+	}
+	if d.NumStat != "2\t0\tmain.go\n1\t0\tnew.go" {
+		t.Errorf("LoadTreeDiff() numstat = %q", d.NumStat)
+	}
+	if !strings.Contains(d.Patch, "+func main() {}") {
+		t.Errorf("LoadTreeDiff() patch = %q", d.Patch)
+	}
+}
@@ -0,0 +1,537 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	"github.com/brainwhocodes/lisa-loop/internal/opencode"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/diffview"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/effects"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/keymap"
+	tuimsg "github.com/brainwhocodes/lisa-loop/internal/tui/msg"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/transcript"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// maxHistoryTranscript caps the transcript items replayed for a past iteration
+const maxHistoryTranscript = 2000
+
+// historyTab is a part of a past iteration shown on the History screen
+type historyTab int
+
+const (
+	historyTranscript historyTab = iota
+	historyReasoning
+	historyDiff
+	historyTabCount
+)
+
+func (t historyTab) String() string {
+	switch t {
+	case historyReasoning:
+		return "Reasoning"
+	case historyDiff:
+		return "Diff"
+	default:
+		return "Transcript"
+	}
+}
+
+// historyBrowser is the History screen: past runs read back from the run
+// journal, each opening to its iterations and the sessions archived during it
+type historyBrowser struct {
+	loading  bool
+	loaded   bool
+	err      error
+	runs     []loop.RunHistory
+	sessions []opencode.SessionArchive
+	owners   []int           // Run each session was archived during, -1 for none
+	expanded map[string]bool // Runs opened to their iterations, by ID
+	cursor   int             // Selected row
+	open     *historyView    // Iteration being browsed, nil on the list
+}
+
+// historyRow is a line of the History list: a run, one of its iterations, or
+// an archived session
+type historyRow struct {
+	run       int // Index in runs, -1 for a session archived outside any run
+	iteration int // Index in the run's iterations, -1 unless an iteration
+	session   int // Index in sessions, -1 unless a session
+}
+
+// historyView is a past iteration opened read-only. It opens on the
+// iteration's summary; its events and diff follow from the journal.
+type historyView struct {
+	run        loop.RunHistory
+	index      int // Iteration's place in the run
+	iteration  loop.JournalIteration
+	loading    bool
+	err        error
+	tab        historyTab
+	scroll     int // First line of the transcript or reasoning shown
+	transcript []transcript.Item
+	reasoning  string
+	diff       *diffview.Viewer
+}
+
+// loadHistory reads the History screen's runs and sessions afresh
+func (m *Model) loadHistory() tea.Cmd {
+	if m.history.loading {
+		return nil
+	}
+	m.history.loading = true
+	return effects.LoadHistory(loop.JournalDir, ".")
+}
+
+// applyHistory takes freshly read runs and sessions
+func (m *Model) applyHistory(msg tuimsg.HistoryLoadedMsg) {
+	h := &m.history
+	h.loading, h.loaded = false, true
+	h.err = msg.Err
+	h.runs, h.sessions = msg.Runs, msg.Sessions
+	if h.expanded == nil {
+		h.expanded = make(map[string]bool)
+	}
+
+	// A session belongs to the newest run started before it was archived,
+	// unless that run had already ended
+	h.owners = make([]int, len(h.sessions))
+	for s, session := range h.sessions {
+		h.owners[s] = -1
+		for r, run := range h.runs {
+			if run.Started.After(session.SavedAt) {
+				continue
+			}
+			if run.Ended.IsZero() || !run.Ended.Before(session.SavedAt) {
+				h.owners[s] = r
+			}
+			break
+		}
+	}
+	if rows := m.historyRows(); h.cursor >= len(rows) {
+		h.cursor = max(0, len(rows)-1)
+	}
+}
+
+// historyRows lists the History screen's rows: runs newest first, the
+// expanded ones followed by their iterations and sessions, then the sessions
+// archived outside any recorded run
+func (m Model) historyRows() []historyRow {
+	h := m.history
+	var rows []historyRow
+	for r, run := range h.runs {
+		rows = append(rows, historyRow{run: r, iteration: -1, session: -1})
+		if !h.expanded[run.ID] {
+			continue
+		}
+		for i := range run.Iterations {
+			rows = append(rows, historyRow{run: r, iteration: i, session: -1})
+		}
+		for s, owner := range h.owners {
+			if owner == r {
+				rows = append(rows, historyRow{run: r, iteration: -1, session: s})
+			}
+		}
+	}
+	for s, owner := range h.owners {
+		if owner < 0 {
+			rows = append(rows, historyRow{run: -1, iteration: -1, session: s})
+		}
+	}
+	return rows
+}
+
+// openHistoryIteration browses one of a run's iterations, reading its events
+// and diff from the journal
+func (m *Model) openHistoryIteration(run loop.RunHistory, index int) tea.Cmd {
+	m.history.open = &historyView{
+		run:       run,
+		index:     index,
+		iteration: run.Iterations[index],
+		loading:   true,
+		diff:      diffview.New(diffViewerStyles),
+	}
+	return effects.LoadHistoryIteration(run.Path, index)
+}
+
+// applyHistoryIteration fills in the open iteration once it has been read
+func (m *Model) applyHistoryIteration(msg tuimsg.HistoryIterationLoadedMsg) {
+	v := m.history.open
+	if v == nil || v.run.Path != msg.Path || v.index != msg.Index {
+		return
+	}
+	v.loading, v.err = false, msg.Err
+	if msg.Err != nil {
+		return
+	}
+	it := msg.Iteration
+	v.iteration = it
+	v.transcript = replayTranscript(it.Events)
+	var reasoning []string
+	for _, e := range it.Events {
+		if e.Reasoning != nil && strings.TrimSpace(e.Reasoning.Text) != "" {
+			reasoning = append(reasoning, decodeEscapes(e.Reasoning.Text))
+		}
+	}
+	v.reasoning = strings.Join(reasoning, "\n\n")
+	v.diff.SetPatch(it.Diff)
+}
+
+// replayTranscript rebuilds a past iteration's transcript by feeding its
+// events through the live transcript's handling, keeping their times
+func replayTranscript(events []loop.LoopEvent) []transcript.Item {
+	replay := Model{transcript: transcript.New(maxHistoryTranscript)}
+	for _, e := range events {
+		before := time.Now()
+		next, _ := replay.Update(tuimsg.ControllerEventMsg{Event: e})
+		replay = next.(Model)
+		if e.Time.IsZero() {
+			continue
+		}
+		// Items the event added or updated were stamped just now
+		items := replay.transcript.Items()
+		for i := range items {
+			if !items[i].At.Before(before) {
+				items[i].At = e.Time
+			}
+		}
+	}
+	return replay.transcript.Items()
+}
+
+// historyContentSize is the size of the History screen between its header
+// and footer
+func (m Model) historyContentSize() (width, height int) {
+	width, height = max(m.width, 60), max(m.height, 20)
+	return width, max(8, height-4)
+}
+
+// lines are the open iteration's transcript or reasoning lines at width
+func (v *historyView) lines(m Model, width int) []string {
+	var lines []string
+	switch v.tab {
+	case historyTranscript:
+		for _, it := range v.transcript {
+			lines = append(lines, ansi.Truncate(m.transcriptRow(it), width, "…"))
+		}
+	case historyReasoning:
+		if v.reasoning != "" {
+			lines = strings.Split(ansi.Wrap(v.reasoning, width, ""), "\n")
+		}
+	}
+	return lines
+}
+
+// handleHistoryKey drives the History screen. It reports whether the key was
+// used.
+func (m *Model) handleHistoryKey(key string) (tea.Cmd, bool) {
+	action := m.keymap().Lookup(keymap.ScopeHistory, key)
+	width, height := m.historyContentSize()
+
+	if v := m.history.open; v != nil {
+		page := max(1, height-4)
+		scroll := 0
+		switch action {
+		case keymap.HistoryClose:
+			m.history.open = nil
+			return nil, true
+		case keymap.HistoryPrevTab:
+			v.tab = (v.tab + historyTabCount - 1) % historyTabCount
+			v.scroll = 0
+			return nil, true
+		case keymap.HistoryNextTab:
+			v.tab = (v.tab + 1) % historyTabCount
+			v.scroll = 0
+			return nil, true
+		case keymap.HistoryDown:
+			scroll = 1
+		case keymap.HistoryUp:
+			scroll = -1
+		case keymap.HistoryPageDown:
+			scroll = page
+		case keymap.HistoryPageUp:
+			scroll = -page
+		default:
+			return nil, false
+		}
+		if v.tab == historyDiff {
+			v.diff.SetSize(width-2, height-3)
+			v.diff.ScrollBy(scroll)
+		} else {
+			last := len(v.lines(*m, width-2)) - (height - 3)
+			v.scroll = max(0, min(v.scroll+scroll, last))
+		}
+		return nil, true
+	}
+
+	rows := m.historyRows()
+	h := &m.history
+	switch action {
+	case keymap.HistoryDown:
+		h.cursor = min(h.cursor+1, len(rows)-1)
+	case keymap.HistoryUp:
+		h.cursor--
+	case keymap.HistoryPageDown:
+		h.cursor = min(h.cursor+height-1, len(rows)-1)
+	case keymap.HistoryPageUp:
+		h.cursor -= height - 1
+	case keymap.HistoryOpen:
+		if h.cursor >= len(rows) {
+			return nil, true
+		}
+		row := rows[h.cursor]
+		switch {
+		case row.iteration >= 0:
+			return m.openHistoryIteration(h.runs[row.run], row.iteration), true
+		case row.session < 0:
+			id := h.runs[row.run].ID
+			h.expanded[id] = !h.expanded[id]
+		}
+	case keymap.HistoryClose:
+		m.screen = ScreenSplit
+	default:
+		return nil, false
+	}
+	h.cursor = max(0, h.cursor)
+	return nil, true
+}
+
+// historyStatus is how a run ended, styled
+func historyStatus(run loop.RunHistory) string {
+	switch run.Status {
+	case "":
+		return StyleWarningMsg.Render("unfinished")
+	case "complete":
+		return StyleSuccessMsg.Render(run.Status)
+	case "error", "aborted", "vetoed":
+		return StyleErrorMsg.Render(run.Status)
+	}
+	return StyleTextMuted.Render(run.Status)
+}
+
+// historyOutcome is an iteration's status icon
+func historyOutcome(it loop.JournalIteration) string {
+	if it.Outcome.Success {
+		return StyleSuccessMsg.Render(IconCheck)
+	}
+	return StyleErrorMsg.Render(IconError)
+}
+
+// historyIterationSummary is an iteration's duration, tasks and tokens
+func historyIterationSummary(it loop.JournalIteration) string {
+	var parts []string
+	if it.Outcome.DurationMS > 0 {
+		parts = append(parts, formatMetricsDuration(time.Duration(it.Outcome.DurationMS)*time.Millisecond))
+	}
+	parts = append(parts, fmt.Sprintf("%d tasks", it.Outcome.TasksCompleted))
+	if it.Tokens > 0 {
+		parts = append(parts, formatCount(it.Tokens)+" tokens")
+	}
+	return strings.Join(parts, MetaDotSeparator)
+}
+
+// historyRowLine renders a row of the History list
+func (m Model) historyRowLine(row historyRow, selected bool, width int) string {
+	h := m.history
+	prefix := " "
+	textStyle := StyleTextBase
+	if selected {
+		prefix = StyleSpinnerActive.Render(IconBorderThick)
+		textStyle = StyleTextSelected
+	}
+
+	var left, right string
+	switch {
+	case row.session >= 0:
+		s := h.sessions[row.session]
+		id := s.SessionID
+		if len(id) > 12 {
+			id = id[:12]
+		}
+		indent := "   "
+		if row.run < 0 {
+			indent = ""
+		}
+		var about []string
+		if s.LoopNumber > 0 {
+			about = append(about, fmt.Sprintf("loop %d", s.LoopNumber))
+		}
+		if s.Reason != "" {
+			about = append(about, s.Reason)
+		}
+		left = indent + IconPending + " " + textStyle.Render("session "+id)
+		if len(about) > 0 {
+			left += StyleTextMuted.Render(MetaDotSeparator + strings.Join(about, MetaDotSeparator))
+		}
+		right = s.SavedAt.Local().Format("01-02 15:04")
+		if tokens := s.PromptTokens + s.CompletionTokens; tokens > 0 {
+			right = formatCount(tokens) + " tokens" + MetaDotSeparator + right
+		}
+	case row.iteration >= 0:
+		it := h.runs[row.run].Iterations[row.iteration]
+		task := it.Task
+		if task == "" {
+			task = it.Outcome.Error
+		}
+		left = fmt.Sprintf("   %s %s ", historyOutcome(it), textStyle.Render(fmt.Sprintf("Loop %d", it.Number))) + task
+		right = historyIterationSummary(it)
+	default:
+		run := h.runs[row.run]
+		marker := "▸"
+		if h.expanded[run.ID] {
+			marker = "▾"
+		}
+		left = fmt.Sprintf("%s %s %s", marker, textStyle.Render(run.Started.Local().Format("2006-01-02 15:04")), historyStatus(run))
+		parts := []string{
+			fmt.Sprintf("%d iterations", len(run.Iterations)),
+			formatMetricsDuration(run.Duration()),
+			fmt.Sprintf("%d tasks", run.TasksCompleted()),
+		}
+		if tokens := run.Tokens(); tokens > 0 {
+			parts = append(parts, formatCount(tokens)+" tokens")
+		}
+		if run.Backend != "" {
+			parts = append(parts, run.Backend)
+		}
+		right = strings.Join(parts, MetaDotSeparator)
+	}
+
+	right = " " + StyleTextMuted.Render(right)
+	avail := max(0, width-1-ansi.StringWidth(right))
+	left = ansi.Truncate(strings.ReplaceAll(left, "\n", " "), avail, "…")
+	return prefix + left + strings.Repeat(" ", avail-ansi.StringWidth(left)) + right
+}
+
+// historyListLines is the list of runs, scrolled to keep the cursor in view
+func (m Model) historyListLines(width, height int) []string {
+	h := m.history
+	rows := m.historyRows()
+	header := fmt.Sprintf("History%s%d runs", MetaDotSeparator, len(h.runs))
+	if len(h.sessions) > 0 {
+		header += fmt.Sprintf("%s%d archived sessions", MetaDotSeparator, len(h.sessions))
+	}
+	lines := []string{StyleTextMuted.Render(header)}
+	if h.err != nil {
+		lines = append(lines, StyleErrorMsg.Render(fmt.Sprintf("Some history could not be read: %v", h.err)))
+	}
+	if len(rows) == 0 {
+		if h.loading {
+			return append(lines, StyleTextSubtle.Render("Loading history…"))
+		}
+		return append(lines, StyleTextSubtle.Render("No past runs yet. Each run is recorded in "+loop.JournalDir+" as it happens."))
+	}
+
+	visible := max(1, height-len(lines))
+	start := max(0, min(h.cursor-visible/2, len(rows)-visible))
+	for i := start; i < min(len(rows), start+visible); i++ {
+		lines = append(lines, m.historyRowLine(rows[i], i == h.cursor, width))
+	}
+	return lines
+}
+
+// historyDetailLines is the open iteration: a summary, its tabs and the
+// shown tab's content
+func (m Model) historyDetailLines(v *historyView, width, height int) []string {
+	it := v.iteration
+	title := fmt.Sprintf("%s %s", historyOutcome(it), StyleTextSelected.Render(fmt.Sprintf("Loop %d", it.Number)))
+	if it.Task != "" {
+		title += " " + StyleTextBase.Render(it.Task)
+	}
+	title += StyleTextSubtle.Render(MetaDotSeparator + "run " + v.run.ID)
+
+	parts := []string{historyIterationSummary(it), fmt.Sprintf("%d files", it.Outcome.FilesModified)}
+	if status := it.Outcome.TestsStatus; status != "" {
+		parts = append(parts, "tests "+strings.ToLower(status))
+	}
+	if it.Cost > 0 {
+		parts = append(parts, fmt.Sprintf("$%.4f", it.Cost))
+	}
+	summary := StyleTextMuted.Render(strings.Join(parts, MetaDotSeparator))
+	if it.Outcome.Error != "" {
+		summary += " " + StyleErrorMsg.Render(it.Outcome.Error)
+	}
+
+	var tabs []string
+	for t := historyTab(0); t < historyTabCount; t++ {
+		if t == v.tab {
+			tabs = append(tabs, styleTabActive.Render(t.String()))
+		} else {
+			tabs = append(tabs, styleTabInactive.Render(t.String()))
+		}
+	}
+	lines := []string{
+		ansi.Truncate(title, width, "…"),
+		ansi.Truncate(summary, width, "…"),
+		lipgloss.JoinHorizontal(lipgloss.Top, tabs...),
+	}
+
+	switch {
+	case v.loading:
+		return append(lines, StyleTextSubtle.Render("Loading iteration…"))
+	case v.err != nil:
+		return append(lines, StyleErrorMsg.Render(fmt.Sprintf("Iteration could not be read: %v", v.err)))
+	}
+
+	rows := max(1, height-len(lines))
+	if v.tab == historyDiff {
+		switch {
+		case it.Diff == "":
+			return append(lines, StyleTextSubtle.Render("No changes recorded for this iteration."))
+		case it.DiffTruncated:
+			lines = append(lines, StyleWarningMsg.Render(fmt.Sprintf("Diff cut at %s; later changes were not recorded.", formatCount(loop.MaxJournalDiff)+"B")))
+			rows = max(1, rows-1)
+		}
+		v.diff.SetSize(width, rows)
+		return append(lines, strings.Split(v.diff.Render(width, rows), "\n")...)
+	}
+
+	body := v.lines(m, width)
+	if len(body) == 0 {
+		return append(lines, StyleTextSubtle.Render("No "+strings.ToLower(v.tab.String())+" recorded for this iteration."))
+	}
+	scroll := max(0, min(v.scroll, len(body)-rows))
+	return append(lines, body[scroll:min(len(body), scroll+rows)]...)
+}
+
+// renderHistoryView renders the History screen
+func (m Model) renderHistoryView() string {
+	width, height := m.historyContentSize()
+	header := m.renderHeader(width)
+
+	var lines []string
+	var hints []string
+	if v := m.history.open; v != nil {
+		lines = m.historyDetailLines(v, width-2, height)
+		hints = []string{
+			m.hint("tabs", keymap.HistoryPrevTab, keymap.HistoryNextTab),
+			m.hint("scroll", keymap.HistoryDown, keymap.HistoryUp),
+			m.hint("back", keymap.HistoryClose),
+		}
+	} else {
+		lines = m.historyListLines(width-2, height)
+		hints = []string{
+			m.hint("select", keymap.HistoryDown, keymap.HistoryUp),
+			m.hint("open", keymap.HistoryOpen),
+			m.hint("return", keymap.History),
+		}
+	}
+	hints = append(hints, m.hint("quit", keymap.Quit))
+
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	content := lipgloss.NewStyle().Width(width).Height(height).Padding(0, 1).Render(strings.Join(lines[:height], "\n"))
+	footer := StyleFooter.Width(width).Render(ansi.Truncate(footerHints(hints...), width, "…"))
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		header,
+		"",
+		content,
+		"",
+		footer,
+	)
+}
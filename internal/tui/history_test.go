@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	"github.com/brainwhocodes/lisa-loop/internal/opencode"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// writeJournal writes a run journal to JournalDir in the working directory
+func writeJournal(t *testing.T, entries ...loop.JournalEntry) {
+	t.Helper()
+	if err := os.MkdirAll(loop.JournalDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	for _, e := range entries {
+		data, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.Write(append(data, '\n'))
+	}
+	if err := os.WriteFile(filepath.Join(loop.JournalDir, entries[0].RunID+".jsonl"), []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHistoryScreen(t *testing.T) {
+	t.Chdir(t.TempDir())
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local)
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+	writeJournal(t,
+		loop.JournalEntry{Kind: loop.JournalRunStarted, RunID: "run-1", Time: start, Backend: "opencode"},
+		loop.JournalEntry{Kind: loop.JournalIterated, RunID: "run-1", Time: at(90), Iteration: &loop.JournalIteration{
+			Number: 1, Task: "Add the parser", Started: at(1), Tokens: 12300,
+			Outcome: loop.LoopOutcome{Success: true, TasksCompleted: 1, FilesModified: 1, DurationMS: 88000},
+			Events: []loop.LoopEvent{
+				{Type: loop.EventTypeCodexReasoning, Time: at(2), Reasoning: &loop.Reasoning{Text: "The parser needs a lexer first"}},
+				{Type: loop.EventTypeCodexOutput, Time: at(3), Output: &loop.OutputLine{Line: "I'll write the lexer", Type: loop.OutputTypeAgentMessage}},
+				{Type: loop.EventTypeCodexTool, Time: at(4), Tool: &loop.ToolCall{Name: "write", Target: "lexer.go", Status: loop.ToolStatusStarted}},
+			},
+			Diff: "diff --git a/lexer.go b/lexer.go\nnew file mode 100644\n--- /dev/null\n+++ b/lexer.go\n@@ -0,0 +1 @@\n+package lexer",
+		}},
+		loop.JournalEntry{Kind: loop.JournalRunEnded, RunID: "run-1", Time: at(120), Status: "complete"},
+	)
+	sa := opencode.NewSessionArchiver(".")
+	if _, err := sa.Save(opencode.SessionArchive{SessionID: "ses_inside123", LoopNumber: 1, SavedAt: at(60), Reason: "threshold", PromptTokens: 90000}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sa.Save(opencode.SessionArchive{SessionID: "ses_before99", SavedAt: start.Add(-time.Hour), Reason: "manual"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var m tea.Model = Model{screen: ScreenSplit, width: 120, height: 30}
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'h'}})
+	if m.(Model).screen != ScreenHistory || cmd == nil {
+		t.Fatalf("h opened %v (cmd %v), want the History screen loading", m.(Model).screen, cmd)
+	}
+	m, _ = m.Update(cmd())
+
+	view := ansi.Strip(m.View())
+	for _, want := range []string{"1 runs", "2 archived sessions", "2026-03-01 09:00 complete", "1 iterations", "2m0s", "1 tasks", "12.3k tokens", "opencode", "session ses_before99" + MetaDotSeparator + "manual"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("history list does not show %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "Add the parser") || strings.Contains(view, "ses_inside123") {
+		t.Errorf("collapsed run shows its iterations or sessions:\n%s", view)
+	}
+
+	m = typeKeys(m, "enter")
+	view = ansi.Strip(m.View())
+	for _, want := range []string{"Loop 1 Add the parser", "1m28s", "session ses_inside12", "loop 1" + MetaDotSeparator + "threshold", "90.0k tokens"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expanded run does not show %q:\n%s", want, view)
+		}
+	}
+
+	// Open the iteration: its summary at once, then its transcript,
+	// reasoning and diff once read from the journal, read-only
+	m, cmd = typeKeys(m, "j").Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if view = ansi.Strip(m.View()); !strings.Contains(view, "Loop 1 Add the parser") || !strings.Contains(view, "Loading iteration") {
+		t.Errorf("opening iteration does not show its summary while loading:\n%s", view)
+	}
+	if cmd == nil {
+		t.Fatal("opening an iteration did not read it from the journal")
+	}
+	m, _ = m.Update(cmd())
+	view = ansi.Strip(m.View())
+	for _, want := range []string{"Transcript", "09:00:03 ASSISTANT I'll write the lexer", "09:00:04 TOOL > write lexer.go...", "run run-1"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("iteration transcript does not show %q:\n%s", want, view)
+		}
+	}
+	m = typeKeys(m, "]")
+	if view = ansi.Strip(m.View()); !strings.Contains(view, "The parser needs a lexer first") {
+		t.Errorf("reasoning tab does not show the reasoning:\n%s", view)
+	}
+	m = typeKeys(m, "]")
+	if view = ansi.Strip(m.View()); !strings.Contains(view, "lexer.go") || !strings.Contains(view, "package lexer") {
+		t.Errorf("diff tab does not show the iteration's diff:\n%s", view)
+	}
+
+	m = typeKeys(m, "esc")
+	if got := m.(Model); got.history.open != nil || got.screen != ScreenHistory {
+		t.Fatalf("esc left the iteration open or closed the screen")
+	}
+	m = typeKeys(m, "esc")
+	if got := m.(Model).screen; got != ScreenSplit {
+		t.Errorf("esc on the list left screen %v, want the split view", got)
+	}
+}
+
+func TestHistoryScreen_Empty(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	var m tea.Model = Model{screen: ScreenSplit, width: 100, height: 24}
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'h'}})
+	m, _ = m.Update(cmd())
+	m = typeKeys(m, "j", "enter", "k")
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "No past runs yet") {
+		t.Errorf("empty history view:\n%s", view)
+	}
+	if _, err := os.Stat(filepath.Join(".lisa", "sessions")); !os.IsNotExist(err) {
+		t.Errorf("browsing history created the sessions dir (err = %v)", err)
+	}
+}
@@ -56,6 +56,12 @@ func TestRenderStableSections_HeaderFooterStatus(t *testing.T) {
 		t.Fatalf("header should contain loop metadata, got: %q", header)
 	}
 
+	model.profile = "overnight"
+	header = stripANSI(model.renderHeader(model.width))
+	if !strings.Contains(header, "profile overnight") {
+		t.Fatalf("header should show the active profile, got: %q", header)
+	}
+
 	footer := stripANSI(model.renderFooter(model.width))
 	for _, want := range []string{"r", "run", "p", "pause", "q", "quit"} {
 		if !strings.Contains(footer, want) {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/brainwhocodes/lisa-loop/internal/tui/keymap"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -19,25 +20,152 @@ type KeybindingSection struct {
 	Keys  []Keybinding
 }
 
+// helpLine is a line of the help screen: an action, or a pair of actions
+// such as previous / next, and what it does. Its keys come from the keymap.
+type helpLine struct {
+	actions     []keymap.Action
+	description string
+}
+
+func bind(description string, actions ...keymap.Action) helpLine {
+	return helpLine{actions: actions, description: description}
+}
+
 var (
-	navigationBindings  = []Keybinding{{"q / Ctrl+C", "Quit Lisa Codex"}, {"?", "Toggle help screen"}}
-	loopControlBindings = []Keybinding{{"r", "Run / Restart loop"}, {"p", "Pause / Resume loop"}}
-	viewBindings        = []Keybinding{
-		{"l", "Toggle logs view"},
-		{"t", "Toggle tasks view"},
-		{"o", "Toggle output view"},
-		{"c", "Show circuit breaker status"},
-		{"[ / ]", "Cycle output tabs (Transcript/Diffs/Reasoning)"},
-		{"y", "Toggle reasoning expansion (output view)"},
-		{"R", "Reset circuit breaker"},
+	navigationBindings = []helpLine{
+		bind("Quit Lisa Codex (Ctrl+C always quits)", keymap.Quit),
+		bind("Toggle help screen", keymap.Help),
+	}
+	loopControlBindings = []helpLine{
+		bind("Run / Restart loop", keymap.Run),
+		bind("Pause after this iteration / Resume loop", keymap.Pause),
+		bind("Pause now (interrupts the running iteration)", keymap.PauseNow),
+		bind("Stop after this iteration", keymap.Stop),
+		bind("Abort now (cancels the running iteration)", keymap.Abort),
+		bind("Skip the current task", keymap.SkipTask),
+		bind("Answer the agent's pending question", keymap.Answer),
+		bind("Review the iteration awaiting approval (review mode)", keymap.Review),
+		bind("Give the agent standing guidance", keymap.Steer),
+		bind("Clear standing guidance", keymap.ClearSteering),
+	}
+	viewBindings = []helpLine{
+		bind("Toggle logs view", keymap.Logs),
+		bind("Toggle tasks view", keymap.Tasks),
+		bind("Toggle output view", keymap.Output),
+		bind("Show circuit breaker status", keymap.Circuit),
+		bind("Browse past runs and their iterations", keymap.History),
+		bind("Cycle output tabs (Transcript/Diffs/Reasoning/Tools/Metrics)", keymap.PrevTab, keymap.NextTab),
+		bind("Toggle reasoning expansion (Reasoning tab)", keymap.ToggleReasoning),
+		bind("Cycle diffs: live / iteration / since run start (Diffs tab)", keymap.DiffView),
+		bind("Previous / next iteration (Diffs tab)", keymap.DiffPrevLoop, keymap.DiffNextLoop),
+		bind("Scroll down / up (Diffs tab)", keymap.DiffDown, keymap.DiffUp),
+		bind("Page down / up (Diffs tab)", keymap.DiffPageDown, keymap.DiffPageUp),
+		bind("Top / bottom (Diffs tab)", keymap.DiffTop, keymap.DiffBottom),
+		bind("Next / previous file (Diffs tab)", keymap.DiffNextFile, keymap.DiffPrevFile),
+		bind("Previous / next hunk (Diffs tab)", keymap.DiffPrevHunk, keymap.DiffNextHunk),
+		bind("Fold hunk / all hunks (Diffs tab)", keymap.DiffFold, keymap.DiffFoldAll),
+		bind("Toggle side-by-side diff (Diffs tab)", keymap.DiffSplit),
+		bind("Reset circuit breaker", keymap.ResetCircuit),
+	}
+	searchBindings = []helpLine{
+		bind("Search logs / transcript / reasoning", keymap.SearchStart),
+		bind("Next / previous match", keymap.SearchNext, keymap.SearchPrev),
+		bind("Clear the search", keymap.SearchClear),
+		bind("Filter logs by level, transcript by kind", keymap.SearchFilter),
+	}
+	taskBindings = []helpLine{
+		bind("Move the cursor", keymap.TaskDown, keymap.TaskUp),
+		bind("Check / uncheck the task", keymap.TaskToggle),
+		bind("Move the task down / up", keymap.TaskMoveDown, keymap.TaskMoveUp),
+		bind("Do the task next", keymap.TaskPin),
+		bind("Mark skipped / reopen", keymap.TaskSkip),
+		bind("Mark blocked / reopen", keymap.TaskBlock),
+		bind("Add a task below", keymap.TaskAdd),
+		bind("Delete the task", keymap.TaskDelete),
+	}
+	toolBindings = []helpLine{
+		bind("Select a call / scroll its details", keymap.ToolDown, keymap.ToolUp),
+		bind("Show / hide the call's input and output", keymap.ToolDetail),
+		bind("Hide the call's details", keymap.ToolClose),
+		bind("Previous / next iteration", keymap.ToolPrevLoop, keymap.ToolNextLoop),
+		bind("Copy the call's input / output to the clipboard", keymap.ToolCopyInput, keymap.ToolCopyOutput),
+	}
+	reviewBindings = []helpLine{
+		bind("Approve the iteration (commit its changes)", keymap.ReviewApprove),
+		bind("Reject it with feedback (revert its changes)", keymap.ReviewReject),
+		bind("Edit the plan", keymap.ReviewEdit),
+		bind("Hide the review, leaving the loop paused", keymap.ReviewClose),
+		bind("Scroll down / up", keymap.ReviewDown, keymap.ReviewUp),
+		bind("Page down / up", keymap.ReviewPageDown, keymap.ReviewPageUp),
+	}
+	historyBindings = []helpLine{
+		bind("Select a run or iteration / scroll the open one", keymap.HistoryDown, keymap.HistoryUp),
+		bind("Page down / up", keymap.HistoryPageDown, keymap.HistoryPageUp),
+		bind("Expand a run / open an iteration", keymap.HistoryOpen),
+		bind("Previous / next tab (Transcript/Reasoning/Diff)", keymap.HistoryPrevTab, keymap.HistoryNextTab),
+		bind("Back to the list / close the screen", keymap.HistoryClose),
 	}
 )
 
-func keybindingSections() []KeybindingSection {
+// helpKeys names a help line's keys: every key of a single action, or the
+// first key of each action in a pair. It is "" when nothing is bound.
+func helpKeys(keys *keymap.Keymap, line helpLine) string {
+	if len(line.actions) == 1 {
+		return strings.Join(keys.Keys(line.actions[0]), " / ")
+	}
+	var names []string
+	bound := false
+	for _, a := range line.actions {
+		key := keys.Key(a)
+		if key == "" {
+			key = "(none)"
+		} else {
+			bound = true
+		}
+		names = append(names, key)
+	}
+	if !bound {
+		return ""
+	}
+	return strings.Join(names, " / ")
+}
+
+// keybindings turns help lines into the keymap's bindings, leaving out the
+// unbound ones
+func keybindings(keys *keymap.Keymap, lines []helpLine) []Keybinding {
+	var bindings []Keybinding
+	for _, line := range lines {
+		if key := helpKeys(keys, line); key != "" {
+			bindings = append(bindings, Keybinding{key, line.description})
+		}
+	}
+	return bindings
+}
+
+func keybindingSections(keys *keymap.Keymap) []KeybindingSection {
+	var troubleshooting []Keybinding
+	for _, tip := range []struct {
+		action       keymap.Action
+		format, desc string
+	}{
+		{keymap.Run, "Press '%s' after error", "Retry failed operation"},
+		{keymap.ResetCircuit, "Press '%s' after loop", "Reset circuit if stuck"},
+		{keymap.Logs, "Check logs with '%s'", "View detailed execution logs"},
+	} {
+		if key := keys.Key(tip.action); key != "" {
+			troubleshooting = append(troubleshooting, Keybinding{fmt.Sprintf(tip.format, key), tip.desc})
+		}
+	}
+
 	return []KeybindingSection{
-		{Title: "Navigation", Keys: navigationBindings},
-		{Title: "Loop Control", Keys: loopControlBindings},
-		{Title: "Views", Keys: viewBindings},
+		{Title: "Navigation", Keys: keybindings(keys, navigationBindings)},
+		{Title: "Loop Control", Keys: keybindings(keys, loopControlBindings)},
+		{Title: "Views", Keys: keybindings(keys, viewBindings)},
+		{Title: "Search", Keys: keybindings(keys, searchBindings)},
+		{Title: "Tasks View", Keys: keybindings(keys, taskBindings)},
+		{Title: "Tools Tab", Keys: keybindings(keys, toolBindings)},
+		{Title: "Review Screen", Keys: keybindings(keys, reviewBindings)},
+		{Title: "History Screen", Keys: keybindings(keys, historyBindings)},
 		{
 			Title: "CLI Options",
 			Keys: []Keybinding{
@@ -45,6 +173,7 @@ func keybindingSections() []KeybindingSection {
 				{"--verbose", "Verbose output"},
 				{"--backend cli", "Use CLI backend"},
 				{"--backend opencode", "Use OpenCode backend"},
+				{"--keymap <preset>", "Key preset: default, vim or emacs"},
 			},
 		},
 		{
@@ -70,36 +199,72 @@ func keybindingSections() []KeybindingSection {
 				{"reset-circuit", "Reset circuit breaker"},
 			},
 		},
-		{
-			Title: "Troubleshooting",
-			Keys: []Keybinding{
-				{"Press 'r' after error", "Retry failed operation"},
-				{"Press 'R' after loop", "Reset circuit if stuck"},
-				{"Check logs with 'l'", "View detailed execution logs"},
-			},
-		},
+		{Title: "Troubleshooting", Keys: troubleshooting},
 	}
 }
 
-func footerBindings() []Keybinding {
-	return []Keybinding{
-		{"r", "run"},
-		{"p", "pause"},
-		{"l", "logs"},
-		{"t", "tasks"},
-		{"o", "output"},
-		{"c", "circuit"},
-		{"?", "help"},
-		{"q", "quit"},
+// footerBindings are the split view's footer hints, in the keymap's keys
+func footerBindings(keys *keymap.Keymap) []Keybinding {
+	return keybindings(keys, []helpLine{
+		bind("run", keymap.Run),
+		bind("pause", keymap.Pause),
+		bind("logs", keymap.Logs),
+		bind("tasks", keymap.Tasks),
+		bind("output", keymap.Output),
+		bind("circuit", keymap.Circuit),
+		bind("help", keymap.Help),
+		bind("quit", keymap.Quit),
+	})
+}
+
+// hint is a footer hint: the first key of each action, then what they do. It
+// is "" when none of the actions is bound.
+func (m Model) hint(description string, actions ...keymap.Action) string {
+	var keys []string
+	for _, a := range actions {
+		if key := m.keymap().Key(a); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
 	}
+	return StyleHelpKey.Render(strings.Join(keys, " ")) + " " + description
 }
 
-// GetKeybindingHelp returns formatted help text for all keybindings
+// footerHints joins footer hints, dropping those for unbound actions
+func footerHints(hints ...string) string {
+	var parts []string
+	for _, h := range hints {
+		if h != "" {
+			parts = append(parts, h)
+		}
+	}
+	return " " + strings.Join(parts, StyleTextSubtle.Render(MetaDotSeparator))
+}
+
+// quitKey names the key that quits, which is always at least Ctrl+C
+func quitKey(keys *keymap.Keymap) string {
+	if key := keys.Key(keymap.Quit); key != "" {
+		return key
+	}
+	return "ctrl+c"
+}
+
+// GetKeybindingHelp returns formatted help text for the default keybindings
 func GetKeybindingHelp() string {
-	sections := keybindingSections()
+	return keybindingHelp(keymap.Default())
+}
+
+// keybindingHelp returns formatted help text for a keymap's keybindings
+func keybindingHelp(keys *keymap.Keymap) string {
+	sections := keybindingSections(keys)
 	var builder strings.Builder
 
 	for _, section := range sections {
+		if len(section.Keys) == 0 {
+			continue
+		}
 		// Section title with Crush-style subtle formatting
 		builder.WriteString(StylePaneHeader.Render(" " + section.Title))
 		builder.WriteString("\n")
@@ -132,11 +297,11 @@ func (m Model) renderHelpView() string {
 
 	header := m.renderHeader(width)
 
-	version := StyleTextMuted.Render(" Version 1.0.0")
+	version := StyleTextMuted.Render(" Version 1.0.0" + MetaDotSeparator + m.keymap().Preset + " keys")
 
 	divider := StyleDivider.Render(strings.Repeat(DividerChar, width-4))
 
-	helpContent := GetKeybindingHelp()
+	helpContent := keybindingHelp(m.keymap())
 
 	middleContent := "\n" + version + "\n\n" + divider + "\n\n" + helpContent
 	middleHeight := height - headerHeight - footerHeight - 2
@@ -151,10 +316,7 @@ func (m Model) renderHelpView() string {
 
 	// Footer with Crush-style
 	footer := StyleFooter.Width(width).Render(
-		fmt.Sprintf(" %s return%s%s",
-			StyleHelpKey.Render("?"),
-			StyleTextSubtle.Render(MetaDotSeparator),
-			StyleTextMuted.Render("Use --monitor flag for TUI mode")),
+		footerHints(m.hint("return", keymap.Help), StyleTextMuted.Render("Use --monitor flag for TUI mode")),
 	)
 
 	return lipgloss.JoinVertical(lipgloss.Left,
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/brainwhocodes/lisa-loop/internal/tui/keymap"
 	"github.com/charmbracelet/bubbletea"
 )
 
@@ -16,6 +17,8 @@ func TestGetKeybindingHelp(t *testing.T) {
 		"Navigation",
 		"Loop Control",
 		"Views",
+		"Tasks View",
+		"Tools Tab",
 		"CLI Options",
 		"Project Options",
 		"Rate Limiting",
@@ -55,13 +58,45 @@ func TestGetKeybindingHelp(t *testing.T) {
 func TestFooterBindingsAreDocumentedInHelp(t *testing.T) {
 	help := GetKeybindingHelp()
 
-	for _, binding := range footerBindings() {
+	for _, binding := range footerBindings(keymap.Default()) {
 		if !strings.Contains(help, binding.Key) {
 			t.Errorf("Help should include footer key %q", binding.Key)
 		}
 	}
 }
 
+func TestRemappedKeys(t *testing.T) {
+	keys, err := keymap.Load("vim", map[string][]string{"circuit": {"C"}, "pause": {}})
+	if err != nil {
+		t.Fatalf("keymap.Load() error = %v", err)
+	}
+	model := Model{screen: ScreenSplit, keys: keys, width: 100, height: 30}
+
+	next, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	if next.(Model).screen != ScreenSplit {
+		t.Errorf("c opened %v after circuit was rebound to C", next.(Model).screen)
+	}
+	next, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'C'}})
+	if next.(Model).screen != ScreenCircuit {
+		t.Errorf("C opened %v, want the circuit screen", next.(Model).screen)
+	}
+
+	footer := model.renderFooter(100)
+	if !strings.Contains(footer, "C circuit") || strings.Contains(footer, "pause") {
+		t.Errorf("renderFooter() = %q, want the rebound circuit key and no unbound pause", footer)
+	}
+
+	help := keybindingHelp(keys)
+	for _, want := range []string{"C", "Show circuit breaker status", "i", "Give the agent standing guidance"} {
+		if !strings.Contains(help, want) {
+			t.Errorf("keybindingHelp() should contain %q", want)
+		}
+	}
+	if strings.Contains(help, "Pause after this iteration") {
+		t.Errorf("keybindingHelp() should leave out the unbound pause action")
+	}
+}
+
 // TestModelToggleCircuitView tests circuit view toggle
 func TestModelToggleCircuitView(t *testing.T) {
 	model := Model{
@@ -88,7 +123,7 @@ func TestModelToggleCircuitView(t *testing.T) {
 func TestModelResetCircuit(t *testing.T) {
 	model := Model{
 		circuitState: "OPEN",
-		logs:         []string{},
+		logs:         []logEntry{},
 	}
 
 	// Reset circuit breaker
@@ -104,8 +139,8 @@ func TestModelResetCircuit(t *testing.T) {
 		t.Errorf("Expected 1 log entry, got %d", len(logs))
 	}
 
-	if !contains(logs[0], "Circuit breaker reset") {
-		t.Errorf("Log should contain reset message, got: %s", logs[0])
+	if !contains(logs[0].Text, "Circuit breaker reset") {
+		t.Errorf("Log should contain reset message, got: %s", logs[0].Text)
 	}
 }
 
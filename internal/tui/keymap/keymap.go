@@ -0,0 +1,404 @@
+// Package keymap binds the TUI's keys to actions. A keymap starts from a
+// preset (default, vim or emacs) and takes per-action overrides from the
+// config file's keys section; bindings that would shadow one another are
+// rejected when it is loaded.
+package keymap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Scope is where in the TUI a binding applies
+type Scope int
+
+const (
+	ScopeGlobal    Scope = iota // Every screen but the review screen
+	ScopeSearch                 // Views with a search: logs, transcript, reasoning
+	ScopeMatch                  // Those views while a search is active; may shadow global keys
+	ScopeOutput                 // Every output tab
+	ScopeReasoning              // The Reasoning tab
+	ScopeDiffs                  // The Diffs tab
+	ScopeTools                  // The Tools tab
+	ScopeTasks                  // The Tasks view
+	ScopeReview                 // The review screen, which takes every key
+	ScopeHistory                // The History screen
+)
+
+// Action is something a key does, named as in the config file's keys section
+type Action string
+
+// Global actions
+const (
+	Quit          Action = "quit"
+	Help          Action = "help"
+	Run           Action = "run"
+	Pause         Action = "pause"
+	PauseNow      Action = "pause_now"
+	Stop          Action = "stop"
+	Abort         Action = "abort"
+	SkipTask      Action = "skip_task"
+	Answer        Action = "answer"
+	Review        Action = "review"
+	Steer         Action = "steer"
+	ClearSteering Action = "clear_steering"
+	ResetCircuit  Action = "reset_circuit"
+	Logs          Action = "logs"
+	Tasks         Action = "tasks"
+	Output        Action = "output"
+	Circuit       Action = "circuit"
+	History       Action = "history"
+)
+
+// Output view actions
+const (
+	PrevTab         Action = "output.prev_tab"
+	NextTab         Action = "output.next_tab"
+	ToggleReasoning Action = "reasoning.toggle"
+)
+
+// Search actions
+const (
+	SearchStart  Action = "search.start"
+	SearchFilter Action = "search.filter"
+	SearchNext   Action = "search.next"
+	SearchPrev   Action = "search.prev"
+	SearchClear  Action = "search.clear"
+)
+
+// Diffs tab actions
+const (
+	DiffView     Action = "diffs.view"
+	DiffPrevLoop Action = "diffs.prev_loop"
+	DiffNextLoop Action = "diffs.next_loop"
+	DiffDown     Action = "diffs.down"
+	DiffUp       Action = "diffs.up"
+	DiffPageDown Action = "diffs.page_down"
+	DiffPageUp   Action = "diffs.page_up"
+	DiffTop      Action = "diffs.top"
+	DiffBottom   Action = "diffs.bottom"
+	DiffNextFile Action = "diffs.next_file"
+	DiffPrevFile Action = "diffs.prev_file"
+	DiffNextHunk Action = "diffs.next_hunk"
+	DiffPrevHunk Action = "diffs.prev_hunk"
+	DiffFold     Action = "diffs.fold"
+	DiffFoldAll  Action = "diffs.fold_all"
+	DiffSplit    Action = "diffs.split"
+)
+
+// Tools tab actions
+const (
+	ToolDown       Action = "tools.down"
+	ToolUp         Action = "tools.up"
+	ToolDetail     Action = "tools.detail"
+	ToolClose      Action = "tools.close"
+	ToolPrevLoop   Action = "tools.prev_loop"
+	ToolNextLoop   Action = "tools.next_loop"
+	ToolCopyInput  Action = "tools.copy_input"
+	ToolCopyOutput Action = "tools.copy_output"
+)
+
+// Tasks view actions
+const (
+	TaskDown     Action = "tasks.down"
+	TaskUp       Action = "tasks.up"
+	TaskToggle   Action = "tasks.toggle"
+	TaskMoveDown Action = "tasks.move_down"
+	TaskMoveUp   Action = "tasks.move_up"
+	TaskPin      Action = "tasks.pin"
+	TaskSkip     Action = "tasks.skip"
+	TaskBlock    Action = "tasks.block"
+	TaskAdd      Action = "tasks.add"
+	TaskDelete   Action = "tasks.delete"
+)
+
+// Review screen actions
+const (
+	ReviewApprove  Action = "review.approve"
+	ReviewReject   Action = "review.reject"
+	ReviewEdit     Action = "review.edit"
+	ReviewClose    Action = "review.close"
+	ReviewUp       Action = "review.up"
+	ReviewDown     Action = "review.down"
+	ReviewPageUp   Action = "review.page_up"
+	ReviewPageDown Action = "review.page_down"
+)
+
+// History screen actions
+const (
+	HistoryDown     Action = "history.down"
+	HistoryUp       Action = "history.up"
+	HistoryOpen     Action = "history.open"
+	HistoryClose    Action = "history.close"
+	HistoryPrevTab  Action = "history.prev_tab"
+	HistoryNextTab  Action = "history.next_tab"
+	HistoryPageDown Action = "history.page_down"
+	HistoryPageUp   Action = "history.page_up"
+)
+
+// scopes maps every action to its scope
+var scopes = map[Action]Scope{
+	Quit: ScopeGlobal, Help: ScopeGlobal, Run: ScopeGlobal, Pause: ScopeGlobal, PauseNow: ScopeGlobal,
+	Stop: ScopeGlobal, Abort: ScopeGlobal, SkipTask: ScopeGlobal, Answer: ScopeGlobal, Review: ScopeGlobal,
+	Steer: ScopeGlobal, ClearSteering: ScopeGlobal, ResetCircuit: ScopeGlobal, Logs: ScopeGlobal,
+	Tasks: ScopeGlobal, Output: ScopeGlobal, Circuit: ScopeGlobal, History: ScopeGlobal,
+
+	PrevTab: ScopeOutput, NextTab: ScopeOutput, ToggleReasoning: ScopeReasoning,
+
+	SearchStart: ScopeSearch, SearchFilter: ScopeSearch,
+	SearchNext: ScopeMatch, SearchPrev: ScopeMatch, SearchClear: ScopeMatch,
+
+	DiffView: ScopeDiffs, DiffPrevLoop: ScopeDiffs, DiffNextLoop: ScopeDiffs, DiffDown: ScopeDiffs,
+	DiffUp: ScopeDiffs, DiffPageDown: ScopeDiffs, DiffPageUp: ScopeDiffs, DiffTop: ScopeDiffs,
+	DiffBottom: ScopeDiffs, DiffNextFile: ScopeDiffs, DiffPrevFile: ScopeDiffs, DiffNextHunk: ScopeDiffs,
+	DiffPrevHunk: ScopeDiffs, DiffFold: ScopeDiffs, DiffFoldAll: ScopeDiffs, DiffSplit: ScopeDiffs,
+
+	ToolDown: ScopeTools, ToolUp: ScopeTools, ToolDetail: ScopeTools, ToolClose: ScopeTools,
+	ToolPrevLoop: ScopeTools, ToolNextLoop: ScopeTools, ToolCopyInput: ScopeTools, ToolCopyOutput: ScopeTools,
+
+	TaskDown: ScopeTasks, TaskUp: ScopeTasks, TaskToggle: ScopeTasks, TaskMoveDown: ScopeTasks,
+	TaskMoveUp: ScopeTasks, TaskPin: ScopeTasks, TaskSkip: ScopeTasks, TaskBlock: ScopeTasks,
+	TaskAdd: ScopeTasks, TaskDelete: ScopeTasks,
+
+	ReviewApprove: ScopeReview, ReviewReject: ScopeReview, ReviewEdit: ScopeReview, ReviewClose: ScopeReview,
+	ReviewUp: ScopeReview, ReviewDown: ScopeReview, ReviewPageUp: ScopeReview, ReviewPageDown: ScopeReview,
+
+	HistoryDown: ScopeHistory, HistoryUp: ScopeHistory, HistoryOpen: ScopeHistory, HistoryClose: ScopeHistory,
+	HistoryPrevTab: ScopeHistory, HistoryNextTab: ScopeHistory, HistoryPageDown: ScopeHistory, HistoryPageUp: ScopeHistory,
+}
+
+// contexts are the sets of scopes active at the same time. A key may do only
+// one thing in each, except that search match keys shadow global keys while
+// a search is active.
+var contexts = [][]Scope{
+	{ScopeGlobal},
+	{ScopeMatch, ScopeSearch, ScopeGlobal},                              // Logs
+	{ScopeMatch, ScopeSearch, ScopeGlobal, ScopeOutput},                 // Transcript
+	{ScopeMatch, ScopeSearch, ScopeGlobal, ScopeReasoning, ScopeOutput}, // Reasoning
+	{ScopeGlobal, ScopeDiffs, ScopeOutput},
+	{ScopeGlobal, ScopeTools, ScopeOutput},
+	{ScopeGlobal, ScopeTasks},
+	{ScopeGlobal, ScopeHistory},
+	{ScopeReview},
+}
+
+// reserved keys always quit, so a keymap can't lock the user in
+var reserved = map[string]bool{"ctrl+c": true, "ctrl+q": true}
+
+// Keymap binds keys to actions
+type Keymap struct {
+	Preset string
+	keys   map[Action][]string         // Keys by action, in the order given
+	byKey  map[Scope]map[string]Action // Actions by scope and key
+}
+
+// Presets lists the preset names, sorted
+func Presets() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Actions lists every action, sorted
+func Actions() []Action {
+	actions := make([]Action, 0, len(scopes))
+	for a := range scopes {
+		actions = append(actions, a)
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+	return actions
+}
+
+var defaultKeymap, _ = Load("default", nil)
+
+// Default returns the default keymap
+func Default() *Keymap {
+	return defaultKeymap
+}
+
+// Load builds the keymap for preset ("" for default) with overrides, which
+// replace all the keys of the actions they name; an empty list unbinds one.
+// Unknown actions, unknown keys and keys that would do two things on one
+// screen are errors.
+func Load(preset string, overrides map[string][]string) (*Keymap, error) {
+	if preset == "" {
+		preset = "default"
+	}
+	base, ok := presets[preset]
+	if !ok {
+		return nil, fmt.Errorf("unknown keymap preset %q (available: %s)", preset, strings.Join(Presets(), ", "))
+	}
+
+	k := &Keymap{Preset: preset, keys: make(map[Action][]string, len(scopes))}
+	for a, keys := range defaults {
+		k.keys[a] = keys
+	}
+	for a, keys := range base {
+		k.keys[a] = keys
+	}
+
+	var problems []string
+	for name, keys := range overrides {
+		a := Action(name)
+		if _, ok := scopes[a]; !ok {
+			problems = append(problems, fmt.Sprintf("unknown action %q", name))
+			continue
+		}
+		parsed := make([]string, 0, len(keys))
+		for _, key := range keys {
+			key, err := parseKey(key)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+				continue
+			}
+			parsed = append(parsed, key)
+		}
+		k.keys[a] = parsed
+	}
+	if len(problems) == 0 {
+		problems = k.index()
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("invalid keymap: %s", strings.Join(problems, "; "))
+	}
+	return k, nil
+}
+
+// index builds the lookup tables, returning the conflicts it finds
+func (k *Keymap) index() []string {
+	k.byKey = make(map[Scope]map[string]Action)
+	for a, keys := range k.keys {
+		scope := scopes[a]
+		if k.byKey[scope] == nil {
+			k.byKey[scope] = make(map[string]Action)
+		}
+		for _, key := range keys {
+			k.byKey[scope][key] = a
+		}
+	}
+
+	seen := make(map[string]bool)
+	var problems []string
+	for _, context := range contexts {
+		for i, s1 := range context {
+			for _, s2 := range context[i:] {
+				if s1 == ScopeMatch && s2 == ScopeGlobal {
+					continue
+				}
+				for key, a1 := range k.byKey[s1] {
+					a2, ok := k.byKey[s2][key]
+					if !ok || a1 == a2 {
+						continue
+					}
+					if a1 > a2 {
+						a1, a2 = a2, a1
+					}
+					problem := fmt.Sprintf("%q is bound to both %s and %s", DisplayKey(key), a1, a2)
+					if !seen[problem] {
+						seen[problem] = true
+						problems = append(problems, problem)
+					}
+				}
+			}
+		}
+	}
+	// A key listed twice within a scope lands on one action in byKey, so
+	// compare against the bindings directly
+	for a1, keys := range k.keys {
+		for _, key := range keys {
+			if a2 := k.byKey[scopes[a1]][key]; a2 != a1 {
+				x, y := a1, a2
+				if x > y {
+					x, y = y, x
+				}
+				problem := fmt.Sprintf("%q is bound to both %s and %s", DisplayKey(key), x, y)
+				if !seen[problem] {
+					seen[problem] = true
+					problems = append(problems, problem)
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// Lookup returns the action key is bound to in scope, or "" for none
+func (k *Keymap) Lookup(scope Scope, key string) Action {
+	return k.byKey[scope][key]
+}
+
+// Keys returns the keys bound to a, as they are displayed
+func (k *Keymap) Keys(a Action) []string {
+	keys := make([]string, len(k.keys[a]))
+	for i, key := range k.keys[a] {
+		keys[i] = DisplayKey(key)
+	}
+	return keys
+}
+
+// Key returns the first key bound to a, as it is displayed, or "" when a is
+// unbound
+func (k *Keymap) Key(a Action) string {
+	if len(k.keys[a]) == 0 {
+		return ""
+	}
+	return DisplayKey(k.keys[a][0])
+}
+
+// DisplayKey names a key the way the help screen and config file write it
+func DisplayKey(key string) string {
+	if key == " " {
+		return "space"
+	}
+	return key
+}
+
+// namedKeys are the keys, besides single characters, that can be bound
+var namedKeys = map[string]bool{
+	"enter": true, "esc": true, "tab": true, "shift+tab": true, "backspace": true, "delete": true,
+	"insert": true, "up": true, "down": true, "left": true, "right": true, "home": true, "end": true,
+	"pgup": true, "pgdown": true,
+}
+
+// parseKey validates a key as written in the config file and returns it as
+// the TUI receives it
+func parseKey(key string) (string, error) {
+	name := strings.ToLower(key)
+	switch {
+	case key == "space" || key == " ":
+		return " ", nil
+	case reserved[name]:
+		return "", fmt.Errorf("%q always quits and can't be rebound", key)
+	case utf8.RuneCountInString(key) == 1:
+		return key, nil
+	case namedKeys[name]:
+		return name, nil
+	case strings.HasPrefix(name, "ctrl+") && len(name) == len("ctrl+")+1 && name[5] >= 'a' && name[5] <= 'z' &&
+		name != "ctrl+i" && name != "ctrl+m": // The terminal sends tab and enter for these
+		return name, nil
+	case strings.HasPrefix(name, "f") && isFunctionKey(name[1:]):
+		return name, nil
+	case strings.HasPrefix(key, "alt+"):
+		rest, err := parseKey(key[len("alt+"):])
+		if err != nil || rest == " " || strings.HasPrefix(rest, "alt+") {
+			break
+		}
+		return "alt+" + rest, nil
+	}
+	return "", fmt.Errorf("unknown key %q", key)
+}
+
+// isFunctionKey reports whether n numbers a function key, 1 to 20
+func isFunctionKey(n string) bool {
+	var i int
+	if _, err := fmt.Sscanf(n, "%d", &i); err != nil || fmt.Sprint(i) != n {
+		return false
+	}
+	return i >= 1 && i <= 20
+}
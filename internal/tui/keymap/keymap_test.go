@@ -0,0 +1,133 @@
+package keymap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPresetsLoad(t *testing.T) {
+	for _, preset := range Presets() {
+		k, err := Load(preset, nil)
+		if err != nil {
+			t.Errorf("Load(%q) error = %v", preset, err)
+			continue
+		}
+		for _, a := range Actions() {
+			if k.Key(a) == "" {
+				t.Errorf("Load(%q) leaves %s unbound", preset, a)
+			}
+		}
+	}
+}
+
+func TestDefaultBindings(t *testing.T) {
+	k := Default()
+	tests := []struct {
+		scope Scope
+		key   string
+		want  Action
+	}{
+		{ScopeGlobal, "q", Quit},
+		{ScopeGlobal, "g", Steer},
+		{ScopeSearch, "/", SearchStart},
+		{ScopeMatch, "n", SearchNext},
+		{ScopeDiffs, " ", DiffPageDown},
+		{ScopeTasks, "enter", TaskToggle},
+		{ScopeReview, "esc", ReviewClose},
+		{ScopeGlobal, "h", History},
+		{ScopeHistory, "enter", HistoryOpen},
+		{ScopeTasks, "q", ""},
+	}
+	for _, tt := range tests {
+		if got := k.Lookup(tt.scope, tt.key); got != tt.want {
+			t.Errorf("Lookup(%d, %q) = %q, want %q", tt.scope, tt.key, got, tt.want)
+		}
+	}
+	if got := k.Keys(TaskToggle); !reflect.DeepEqual(got, []string{"space", "enter"}) {
+		t.Errorf("Keys(TaskToggle) = %v, want [space enter]", got)
+	}
+}
+
+func TestVimPreset(t *testing.T) {
+	k, err := Load("vim", nil)
+	if err != nil {
+		t.Fatalf("Load(vim) error = %v", err)
+	}
+	if got := k.Lookup(ScopeDiffs, "g"); got != DiffTop {
+		t.Errorf("vim Lookup(diffs, g) = %q, want %s", got, DiffTop)
+	}
+	if got := k.Lookup(ScopeGlobal, "i"); got != Steer {
+		t.Errorf("vim Lookup(global, i) = %q, want %s", got, Steer)
+	}
+}
+
+func TestLoadOverrides(t *testing.T) {
+	k, err := Load("", map[string][]string{
+		"quit":         {"Q"},
+		"tasks.add":    {"+", "alt+a"},
+		"tasks.delete": {},
+		"steer":        {"ctrl+t"},
+		"logs":         {"F2"},
+	})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	tests := []struct {
+		scope Scope
+		key   string
+		want  Action
+	}{
+		{ScopeGlobal, "Q", Quit},
+		{ScopeGlobal, "q", ""},
+		{ScopeTasks, "+", TaskAdd},
+		{ScopeTasks, "alt+a", TaskAdd},
+		{ScopeTasks, "a", ""},
+		{ScopeTasks, "D", ""},
+		{ScopeGlobal, "ctrl+t", Steer},
+		{ScopeGlobal, "f2", Logs},
+	}
+	for _, tt := range tests {
+		if got := k.Lookup(tt.scope, tt.key); got != tt.want {
+			t.Errorf("Lookup(%d, %q) = %q, want %q", tt.scope, tt.key, got, tt.want)
+		}
+	}
+	if k.Key(TaskDelete) != "" {
+		t.Errorf("Key(TaskDelete) = %q, want unbound", k.Key(TaskDelete))
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		preset    string
+		overrides map[string][]string
+		want      string
+	}{
+		{"unknown preset", "nano", nil, `unknown keymap preset "nano"`},
+		{"unknown action", "", map[string][]string{"launch": {"L"}}, `unknown action "launch"`},
+		{"unknown key", "", map[string][]string{"quit": {"hyper+q"}}, `quit: unknown key "hyper+q"`},
+		{"reserved key", "", map[string][]string{"help": {"ctrl+c"}}, `"ctrl+c" always quits`},
+		{"global conflict", "", map[string][]string{"quit": {"r"}}, `"r" is bound to both quit and run`},
+		{"view shadows global", "", map[string][]string{"diffs.view": {"t"}}, `"t" is bound to both diffs.view and tasks`},
+		{"same scope", "", map[string][]string{"tasks.add": {"b"}}, `"b" is bound to both tasks.add and tasks.block`},
+		{"space in tasks", "", map[string][]string{"tasks.add": {"space"}}, `"space" is bound to both tasks.add and tasks.toggle`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.preset, tt.overrides)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadAllowsUnrelatedScopes(t *testing.T) {
+	// The Tasks view and the Diffs tab are never open together, and search
+	// keys may shadow global ones while a search is active
+	_, err := Load("", map[string][]string{"tasks.add": {"d"}, "search.next": {"r"}})
+	if err != nil {
+		t.Errorf("Load() error = %v, want none", err)
+	}
+}
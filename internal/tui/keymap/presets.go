@@ -0,0 +1,139 @@
+package keymap
+
+// defaults are the default preset's bindings, which the others start from
+var defaults = map[Action][]string{
+	Quit:          {"q"},
+	Help:          {"?"},
+	Run:           {"r"},
+	Pause:         {"p"},
+	PauseNow:      {"P"},
+	Stop:          {"s"},
+	Abort:         {"x"},
+	SkipTask:      {"n"},
+	Answer:        {"!"},
+	Review:        {"v"},
+	Steer:         {"g"},
+	ClearSteering: {"G"},
+	ResetCircuit:  {"R"},
+	Logs:          {"l"},
+	Tasks:         {"t"},
+	Output:        {"o"},
+	Circuit:       {"c"},
+	History:       {"h"},
+
+	PrevTab:         {"["},
+	NextTab:         {"]"},
+	ToggleReasoning: {"y"},
+
+	SearchStart:  {"/"},
+	SearchFilter: {"f"},
+	SearchNext:   {"n"},
+	SearchPrev:   {"N"},
+	SearchClear:  {"esc"},
+
+	DiffView:     {"d"},
+	DiffPrevLoop: {"<"},
+	DiffNextLoop: {">"},
+	DiffDown:     {"j", "down"},
+	DiffUp:       {"k", "up"},
+	DiffPageDown: {"pgdown", " "},
+	DiffPageUp:   {"pgup"},
+	DiffTop:      {"home"},
+	DiffBottom:   {"end"},
+	DiffNextFile: {"f"},
+	DiffPrevFile: {"F"},
+	DiffNextHunk: {"}"},
+	DiffPrevHunk: {"{"},
+	DiffFold:     {"z"},
+	DiffFoldAll:  {"Z"},
+	DiffSplit:    {"|"},
+
+	ToolDown:       {"j", "down"},
+	ToolUp:         {"k", "up"},
+	ToolDetail:     {"enter"},
+	ToolClose:      {"esc"},
+	ToolPrevLoop:   {"<"},
+	ToolNextLoop:   {">"},
+	ToolCopyInput:  {"y"},
+	ToolCopyOutput: {"Y"},
+
+	TaskDown:     {"j", "down"},
+	TaskUp:       {"k", "up"},
+	TaskToggle:   {" ", "enter"},
+	TaskMoveDown: {"J"},
+	TaskMoveUp:   {"K"},
+	TaskPin:      {"N"},
+	TaskSkip:     {"-"},
+	TaskBlock:    {"b"},
+	TaskAdd:      {"a"},
+	TaskDelete:   {"D"},
+
+	ReviewApprove:  {"a"},
+	ReviewReject:   {"r"},
+	ReviewEdit:     {"e"},
+	ReviewClose:    {"esc"},
+	ReviewUp:       {"k", "up"},
+	ReviewDown:     {"j", "down"},
+	ReviewPageUp:   {"pgup"},
+	ReviewPageDown: {"pgdown", " "},
+
+	HistoryDown:     {"j", "down"},
+	HistoryUp:       {"k", "up"},
+	HistoryOpen:     {"enter"},
+	HistoryClose:    {"esc"},
+	HistoryPrevTab:  {"["},
+	HistoryNextTab:  {"]"},
+	HistoryPageDown: {"pgdown", " "},
+	HistoryPageUp:   {"pgup"},
+}
+
+// presets change some of the default bindings
+var presets = map[string]map[Action][]string{
+	"default": {},
+
+	// vim: g and G go to the top and bottom, so steering moves to i and I;
+	// ctrl+f/b and ctrl+d/u page
+	"vim": {
+		Steer:           {"i"},
+		ClearSteering:   {"I"},
+		DiffTop:         {"g", "home"},
+		DiffBottom:      {"G", "end"},
+		DiffPageDown:    {"ctrl+f", "ctrl+d", "pgdown", " "},
+		DiffPageUp:      {"ctrl+b", "ctrl+u", "pgup"},
+		ReviewPageDown:  {"ctrl+f", "ctrl+d", "pgdown", " "},
+		ReviewPageUp:    {"ctrl+b", "ctrl+u", "pgup"},
+		HistoryPageDown: {"ctrl+f", "ctrl+d", "pgdown", " "},
+		HistoryPageUp:   {"ctrl+b", "ctrl+u", "pgup"},
+	},
+
+	// emacs: ctrl+n/p move, ctrl+v and alt+v page, alt+< and alt+> go to the
+	// ends, ctrl+s and ctrl+r search and ctrl+g backs out
+	"emacs": {
+		SearchStart:     {"ctrl+s", "/"},
+		SearchPrev:      {"ctrl+r", "N"},
+		SearchClear:     {"ctrl+g", "esc"},
+		DiffDown:        {"ctrl+n", "down"},
+		DiffUp:          {"ctrl+p", "up"},
+		DiffPageDown:    {"ctrl+v", "pgdown", " "},
+		DiffPageUp:      {"alt+v", "pgup"},
+		DiffTop:         {"alt+<", "home"},
+		DiffBottom:      {"alt+>", "end"},
+		ToolDown:        {"ctrl+n", "down"},
+		ToolUp:          {"ctrl+p", "up"},
+		ToolClose:       {"ctrl+g", "esc"},
+		TaskDown:        {"ctrl+n", "down"},
+		TaskUp:          {"ctrl+p", "up"},
+		TaskMoveDown:    {"alt+n", "J"},
+		TaskMoveUp:      {"alt+p", "K"},
+		ReviewUp:        {"ctrl+p", "up"},
+		ReviewDown:      {"ctrl+n", "down"},
+		ReviewPageUp:    {"alt+v", "pgup"},
+		ReviewPageDown:  {"ctrl+v", "pgdown", " "},
+		ReviewClose:     {"ctrl+g", "esc"},
+		HistoryDown:     {"ctrl+n", "down"},
+		HistoryUp:       {"ctrl+p", "up"},
+		HistoryPageDown: {"ctrl+v", "pgdown", " "},
+		HistoryPageUp:   {"alt+v", "pgup"},
+		HistoryClose:    {"ctrl+g", "esc"},
+	},
+}
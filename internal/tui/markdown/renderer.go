@@ -22,12 +22,22 @@ type Renderer struct {
 
 	// cache stores rendered ANSI output keyed by width+content hash.
 	cache map[cacheKey]string
+
+	// style is the Glamour style name; empty picks one to suit the terminal.
+	style string
 }
 
 func New() *Renderer {
+	return NewWithStyle("")
+}
+
+// NewWithStyle returns a renderer using a standard Glamour style (dark,
+// light, notty, ...); an empty name picks one to suit the terminal.
+func NewWithStyle(style string) *Renderer {
 	return &Renderer{
 		rendererByWidth: make(map[int]*glamour.TermRenderer),
 		cache:           make(map[cacheKey]string),
+		style:           style,
 	}
 }
 
@@ -47,8 +57,12 @@ func (r *Renderer) Render(width int, md string) (string, error) {
 	tr, ok := r.rendererByWidth[width]
 	if !ok {
 		var err error
+		styleOpt := glamour.WithAutoStyle()
+		if r.style != "" {
+			styleOpt = glamour.WithStandardStyle(r.style)
+		}
 		tr, err = glamour.NewTermRenderer(
-			glamour.WithAutoStyle(),
+			styleOpt,
 			glamour.WithWordWrap(width),
 		)
 		if err != nil {
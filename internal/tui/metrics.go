@@ -0,0 +1,329 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/view"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Metrics tab layout
+const (
+	metricsLabelWidth = 14 // Row label column
+	metricsMinChart   = 8  // Narrowest chart worth drawing
+)
+
+// iterationMetrics is what the Metrics tab knows about one iteration
+type iterationMetrics struct {
+	Iteration      int
+	Done           bool // The outcome has arrived
+	Success        bool
+	Duration       time.Duration // 0 when not reported
+	Tokens         int
+	Cost           float64
+	FilesChanged   int
+	TasksCompleted int
+	TestsStatus    string // PASSING, FAILING, UNKNOWN, or "" when not reported
+	TestsPassed    int
+	TestsFailed    int
+	Circuit        string // Breaker state when the iteration ended
+}
+
+// runMetrics collects per-iteration metrics from the loop's events
+type runMetrics struct {
+	iterations []iterationMetrics
+	tokens     int     // Last reported session totals, which usage events
+	cost       float64 // carry, turned into amounts per iteration
+}
+
+// iteration returns the metrics for iteration n, adding them if needed
+func (r *runMetrics) iteration(n int) *iterationMetrics {
+	for i := len(r.iterations) - 1; i >= 0; i-- {
+		if r.iterations[i].Iteration == n {
+			return &r.iterations[i]
+		}
+	}
+	r.iterations = append(r.iterations, iterationMetrics{Iteration: n})
+	return &r.iterations[len(r.iterations)-1]
+}
+
+// recordUsage charges the tokens and cost used since the last report to
+// iteration n. A total lower than the last one means a new session started,
+// and all of it is new.
+func (r *runMetrics) recordUsage(n int, u *loop.ContextUsage) {
+	tokens, cost := u.TotalTokens, u.Cost
+	if tokens >= r.tokens {
+		tokens -= r.tokens
+	}
+	if cost >= r.cost {
+		cost -= r.cost
+	}
+	r.tokens, r.cost = u.TotalTokens, u.Cost
+
+	it := r.iteration(n)
+	it.Tokens += tokens
+	it.Cost += cost
+}
+
+// recordOutcome closes iteration n
+func (r *runMetrics) recordOutcome(n int, o *loop.LoopOutcome, circuit string) {
+	it := r.iteration(n)
+	it.Done = true
+	it.Success = o.Success
+	it.Duration = time.Duration(o.DurationMS) * time.Millisecond
+	it.FilesChanged = o.FilesModified
+	it.TasksCompleted = o.TasksCompleted
+	it.TestsStatus = o.TestsStatus
+	if o.Tests != nil {
+		it.TestsStatus = o.Tests.TestsStatus()
+		it.TestsPassed, it.TestsFailed = o.Tests.Passed, o.Tests.Failed
+	}
+	it.Circuit = circuit
+}
+
+// done lists the finished iterations
+func (r runMetrics) done() []iterationMetrics {
+	var done []iterationMetrics
+	for _, it := range r.iterations {
+		if it.Done {
+			done = append(done, it)
+		}
+	}
+	return done
+}
+
+// metricsETA estimates the iterations, and the time when durations are
+// known, left until every open task is done, at the rate tasks have been
+// completed so far. ok is false until some task has been completed.
+func metricsETA(done []iterationMetrics, remaining int) (iterations int, eta time.Duration, ok bool) {
+	var tasks int
+	var total time.Duration
+	timed := 0
+	for _, it := range done {
+		tasks += it.TasksCompleted
+		if it.Duration > 0 {
+			total += it.Duration
+			timed++
+		}
+	}
+	if tasks == 0 {
+		return 0, 0, false
+	}
+	rate := float64(tasks) / float64(len(done))
+	iterations = int(math.Ceil(float64(remaining) / rate))
+	if timed > 0 {
+		eta = time.Duration(iterations) * (total / time.Duration(timed))
+	}
+	return iterations, eta, true
+}
+
+// remainingTaskCount is how many plan tasks are still open
+func (m Model) remainingTaskCount() int {
+	remaining := 0
+	for _, task := range m.tasks {
+		if !task.Settled() {
+			remaining++
+		}
+	}
+	return remaining
+}
+
+// renderMetricsTab charts the finished iterations, one column each, newest
+// on the right
+func (m Model) renderMetricsTab(width, height int) string {
+	inner := width - 2
+	done := m.metrics.done()
+
+	var lines []string
+	if len(done) == 0 {
+		lines = append(lines, StyleTextSubtle.Render("No finished iterations yet."))
+	} else {
+		lines = append(lines, m.metricsHeadline(done), "")
+
+		// Each row is a label, a chart and a summary; the chart gets what is
+		// left, one column per iteration
+		summaryWidth := min(34, max(0, inner-metricsLabelWidth-metricsMinChart-2))
+		chartWidth := max(1, inner-metricsLabelWidth-summaryWidth-2)
+		shown := done[max(0, len(done)-chartWidth):]
+
+		row := func(label, chart, summary string) {
+			line := StyleTextMuted.Render(fmt.Sprintf("%-*s", metricsLabelWidth, label)) + chart +
+				strings.Repeat(" ", len(shown)-ansi.StringWidth(chart)+2) + StyleTextBase.Render(summary)
+			lines = append(lines, ansi.Truncate(line, inner, "…"))
+		}
+		values := func(f func(iterationMetrics) float64) []float64 {
+			v := make([]float64, len(shown))
+			for i, it := range shown {
+				v[i] = f(it)
+			}
+			return v
+		}
+		spark := func(f func(iterationMetrics) float64) string {
+			return StyleInfoMsg.Render(view.Sparkline(values(f)))
+		}
+		sum := func(f func(iterationMetrics) float64) float64 {
+			total := 0.0
+			for _, it := range done {
+				total += f(it)
+			}
+			return total
+		}
+		last := done[len(done)-1]
+
+		duration := func(it iterationMetrics) float64 { return it.Duration.Seconds() }
+		if total := sum(duration); total > 0 {
+			avg := time.Duration(total / float64(len(done)) * float64(time.Second))
+			row("Duration", spark(duration), fmt.Sprintf("last %s%savg %s", formatMetricsDuration(last.Duration), MetaDotSeparator, formatMetricsDuration(avg)))
+		} else {
+			row("Duration", "", StyleTextSubtle.Render("not reported"))
+		}
+
+		tokens := func(it iterationMetrics) float64 { return float64(it.Tokens) }
+		if total := sum(tokens); total > 0 {
+			row("Tokens", spark(tokens), fmt.Sprintf("last %s%stotal %s", formatCount(last.Tokens), MetaDotSeparator, formatCount(int(total))))
+		} else {
+			row("Tokens", "", StyleTextSubtle.Render("not reported by the backend"))
+		}
+
+		cost := func(it iterationMetrics) float64 { return it.Cost }
+		if total := sum(cost); total > 0 {
+			row("Cost", spark(cost), fmt.Sprintf("last $%.2f%stotal $%.2f", last.Cost, MetaDotSeparator, total))
+		} else {
+			row("Cost", "", StyleTextSubtle.Render("not reported by the backend"))
+		}
+
+		files := func(it iterationMetrics) float64 { return float64(it.FilesChanged) }
+		row("Files changed", spark(files), fmt.Sprintf("last %d%stotal %d", last.FilesChanged, MetaDotSeparator, int(sum(files))))
+
+		tasks := func(it iterationMetrics) float64 { return float64(it.TasksCompleted) }
+		row("Tasks done", spark(tasks), fmt.Sprintf("last %d%stotal %d", last.TasksCompleted, MetaDotSeparator, int(sum(tasks))))
+
+		var tests strings.Builder
+		for _, it := range shown {
+			switch it.TestsStatus {
+			case "PASSING":
+				tests.WriteString(StyleSuccessMsg.Render(IconCheck))
+			case "FAILING":
+				tests.WriteString(StyleErrorMsg.Render(IconError))
+			default:
+				tests.WriteString(StyleTextSubtle.Render(IconPending))
+			}
+		}
+		row("Tests", tests.String(), metricsTestsSummary(last))
+
+		var outcomes strings.Builder
+		succeeded := 0
+		for _, it := range done {
+			if it.Success {
+				succeeded++
+			}
+		}
+		for _, it := range shown {
+			if it.Success {
+				outcomes.WriteString(StyleSuccessMsg.Render(IconCheck))
+			} else {
+				outcomes.WriteString(StyleErrorMsg.Render(IconError))
+			}
+		}
+		row("Outcome", outcomes.String(), fmt.Sprintf("%d/%d succeeded", succeeded, len(done)))
+
+		var breaker strings.Builder
+		for _, it := range shown {
+			breaker.WriteString(circuitStateStyle(it.Circuit).Render("█"))
+		}
+		state := m.circuitState
+		if state == "" {
+			state = "CLOSED"
+		}
+		row("Breaker", breaker.String(), circuitStateStyle(state).Render(strings.ReplaceAll(strings.ToLower(state), "_", "-")))
+
+		lines = append(lines, StyleTextSubtle.Render(fmt.Sprintf("%*s%s", metricsLabelWidth, "", metricsAxis(shown))))
+	}
+
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return lipgloss.NewStyle().Width(width).Height(height).Padding(0, 1).Render(strings.Join(lines[:height], "\n"))
+}
+
+// metricsHeadline sums the run up and estimates when the plan will be done
+func (m Model) metricsHeadline(done []iterationMetrics) string {
+	parts := []string{fmt.Sprintf("%d iterations", len(done))}
+	remaining := m.remainingTaskCount()
+	switch iterations, eta, ok := metricsETA(done, remaining); {
+	case len(m.tasks) == 0:
+	case remaining == 0:
+		parts = append(parts, StyleSuccessMsg.Render("plan complete"))
+	case !ok:
+		parts = append(parts, fmt.Sprintf("%d tasks left", remaining)+StyleTextMuted.Render(" (no ETA until a task is completed)"))
+	case eta > 0:
+		parts = append(parts, fmt.Sprintf("%d tasks left", remaining), StyleTextSelected.Render(fmt.Sprintf("ETA ~%d iterations, %s", iterations, formatMetricsDuration(eta))))
+	default:
+		parts = append(parts, fmt.Sprintf("%d tasks left", remaining), StyleTextSelected.Render(fmt.Sprintf("ETA ~%d iterations", iterations)))
+	}
+	return strings.Join(parts, StyleTextSubtle.Render(MetaDotSeparator))
+}
+
+// metricsTestsSummary describes the last iteration's tests
+func metricsTestsSummary(it iterationMetrics) string {
+	switch {
+	case it.TestsPassed+it.TestsFailed > 0:
+		return fmt.Sprintf("last %d passed, %d failed", it.TestsPassed, it.TestsFailed)
+	case it.TestsStatus != "":
+		return "last " + strings.ToLower(it.TestsStatus)
+	}
+	return StyleTextSubtle.Render("not reported")
+}
+
+// metricsAxis numbers the first and last charted iterations under their
+// columns
+func metricsAxis(shown []iterationMetrics) string {
+	first := fmt.Sprintf("%d", shown[0].Iteration)
+	if len(shown) == 1 {
+		return first
+	}
+	last := fmt.Sprintf("%d", shown[len(shown)-1].Iteration)
+	gap := len(shown) - len(first) - len(last)
+	if gap < 1 {
+		return fmt.Sprintf("%*s", len(shown), last)
+	}
+	return first + strings.Repeat(" ", gap) + last
+}
+
+// circuitStateStyle colours a breaker state
+func circuitStateStyle(state string) lipgloss.Style {
+	switch strings.ToLower(state) {
+	case "closed":
+		return StyleCircuitClosed
+	case "half_open":
+		return StyleCircuitHalfOpen
+	case "open":
+		return StyleCircuitOpen
+	}
+	return StyleTextSubtle
+}
+
+// formatMetricsDuration rounds a duration to the second, or the millisecond
+// below one
+func formatMetricsDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
+
+// formatCount abbreviates large counts: 950, 12.3k, 1.2M
+func formatCount(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	}
+	return fmt.Sprintf("%d", n)
+}
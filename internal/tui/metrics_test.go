@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	"github.com/brainwhocodes/lisa-loop/internal/testreport"
+	tuimsg "github.com/brainwhocodes/lisa-loop/internal/tui/msg"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/plan"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestMetricsTab_ChartsIterations(t *testing.T) {
+	var model tea.Model = Model{
+		screen:    ScreenOutput,
+		outputTab: OutputTabMetrics,
+		tasks:     plan.ParseTasks("- [x] One\n- [x] Two\n- [ ] Three\n- [ ] Four\n- [ ] Five\n- [-] Parked\n"),
+	}
+	send := func(e loop.LoopEvent) {
+		model, _ = model.Update(tuimsg.ControllerEventMsg{Event: e})
+	}
+
+	// Iteration 1: 1000 tokens, then a second iteration in a new session
+	send(loop.LoopEvent{Type: loop.EventTypeContextUsage, Iteration: 1, Context: &loop.ContextUsage{TotalTokens: 400, Cost: 0.01}})
+	send(loop.LoopEvent{Type: loop.EventTypeContextUsage, Iteration: 1, Context: &loop.ContextUsage{TotalTokens: 1000, Cost: 0.05}})
+	send(loop.LoopEvent{Type: loop.EventTypeOutcome, Iteration: 1, Outcome: &loop.LoopOutcome{Success: true, TasksCompleted: 1, FilesModified: 3, DurationMS: 60000,
+		Tests: &testreport.Summary{Passed: 10}}})
+	send(loop.LoopEvent{Type: loop.EventTypeLoopUpdate, Update: &loop.LoopUpdate{LoopNumber: 2, CircuitState: "HALF_OPEN"}})
+	send(loop.LoopEvent{Type: loop.EventTypeContextUsage, Iteration: 2, Context: &loop.ContextUsage{TotalTokens: 300, Cost: 0.02}})
+	send(loop.LoopEvent{Type: loop.EventTypeOutcome, Iteration: 2, Outcome: &loop.LoopOutcome{Success: false, Error: "boom", DurationMS: 120000}})
+	send(loop.LoopEvent{Type: loop.EventTypeOutcome, Iteration: 3, Outcome: &loop.LoopOutcome{Success: true, TasksCompleted: 1, DurationMS: 30000,
+		Tests: &testreport.Summary{Passed: 9, Failed: 1}}})
+
+	m := model.(Model)
+	its := m.metrics.iterations
+	if len(its) != 3 || its[0].Tokens != 1000 || its[1].Tokens != 300 || its[1].Circuit != "HALF_OPEN" || its[0].Duration != time.Minute {
+		t.Fatalf("metrics = %+v", its)
+	}
+	if diff := its[0].Cost - 0.05; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("iteration 1 cost = %v, want 0.05", its[0].Cost)
+	}
+
+	tab := ansi.Strip(m.renderMetricsTab(100, 14))
+	for _, want := range []string{
+		"3 iterations", "3 tasks left", "ETA ~5 iterations, 5m50s",
+		"Duration", "last 30s • avg 1m10s",
+		"Tokens", "last 0 • total 1.3k",
+		"Cost", "total $0.07",
+		"Files changed", "total 3",
+		"Tests", IconCheck + IconPending + IconError, "last 9 passed, 1 failed",
+		"Outcome", IconCheck + IconError + IconCheck, "2/3 succeeded",
+		"Breaker", "half-open",
+	} {
+		if !strings.Contains(tab, want) {
+			t.Errorf("metrics tab does not show %q:\n%s", want, tab)
+		}
+	}
+}
+
+func TestMetricsETA(t *testing.T) {
+	done := []iterationMetrics{{TasksCompleted: 2, Duration: time.Minute}, {TasksCompleted: 0}, {TasksCompleted: 1, Duration: 3 * time.Minute}}
+	if iterations, eta, ok := metricsETA(done, 4); !ok || iterations != 4 || eta != 8*time.Minute {
+		t.Errorf("metricsETA() = %d, %v, %v, want 4 iterations, 8m", iterations, eta, ok)
+	}
+	if _, _, ok := metricsETA([]iterationMetrics{{}}, 4); ok {
+		t.Errorf("metricsETA() without completed tasks should have no estimate")
+	}
+}
@@ -4,13 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
+	"github.com/brainwhocodes/lisa-loop/internal/analysis"
 	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	"github.com/brainwhocodes/lisa-loop/internal/testreport"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/diffview"
 	"github.com/brainwhocodes/lisa-loop/internal/tui/effects"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/keymap"
 	"github.com/brainwhocodes/lisa-loop/internal/tui/markdown"
 	tuimsg "github.com/brainwhocodes/lisa-loop/internal/tui/msg"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/plan"
 	"github.com/brainwhocodes/lisa-loop/internal/tui/transcript"
 	"github.com/brainwhocodes/lisa-loop/internal/tui/view"
 	tea "github.com/charmbracelet/bubbletea"
@@ -25,7 +31,7 @@ type Model struct {
 	maxCalls      int
 	callsUsed     int
 	circuitState  string
-	logs          []string
+	logs          []logEntry
 	screen        Screen
 	quitting      bool
 	err           error
@@ -40,12 +46,19 @@ type Model struct {
 	projectMode   loop.ProjectMode // Current project mode (implementation, refactor, fix)
 	activity      string           // Current activity description
 	controller    Controller
-	readFile      effects.ReadFile // Injected for testability; defaults to effects.OSReadFile
-	exec          effects.Exec     // Injected for testability; defaults to effects.OSExec
+	controls      *effects.ControlQueue // Runs controls off the Update goroutine, in order
+	attached      string                // Control API address when following a loop in another process
+	readFile      effects.ReadFile      // Injected for testability; defaults to effects.OSReadFile
+	exec          effects.Exec          // Injected for testability; defaults to effects.OSExec
+	snapshot      effects.Snapshot      // Injected for testability; defaults to loop.SnapshotWorkTree
+	clipboard     io.Writer             // Where OSC 52 copies are written; nil for standard error
 	ctx           context.Context
 	cancel        context.CancelFunc
 	activeTaskIdx int // Index of currently active task (-1 if none)
 
+	// Key bindings; nil means the default keymap
+	keys *keymap.Keymap
+
 	// Presentation helpers (cached renderers / structured output)
 	md         *markdown.Renderer
 	transcript *transcript.Buffer
@@ -55,6 +68,7 @@ type Model struct {
 
 	// Backend and output streaming
 	backend        string   // Backend name (cli or opencode)
+	profile        string   // Active config profile, if any
 	outputLines    []string // Live output lines from backend
 	reasoningLines []string // Reasoning/thinking output
 	currentTool    string   // Current tool being executed
@@ -75,14 +89,20 @@ type Model struct {
 	gitDiffNameStatus string
 	gitDiffPatch      string
 	pendingChanges    map[string]pendingChange
+	diffs             diffHistory // Per-iteration snapshots for the Diffs tab
+	diffViewer        *diffview.Viewer
+	tools             toolInspector  // Every tool call, for the Tools tab
+	metrics           runMetrics     // Per-iteration history for the Metrics tab
+	history           historyBrowser // Past runs, for the History screen
 
 	// Analysis results (from RALPH_STATUS block)
-	analysisStatus  string  // WORKING, COMPLETE, BLOCKED
-	tasksCompleted  int     // Tasks completed this loop
-	filesModified   int     // Files modified this loop
-	testsStatus     string  // PASSING, FAILING, UNKNOWN
-	exitSignal      bool    // Whether exit was signaled
-	confidenceScore float64 // Confidence in completion (0-1)
+	analysisStatus  string              // WORKING, COMPLETE, BLOCKED
+	tasksCompleted  int                 // Tasks completed this loop
+	filesModified   int                 // Files modified this loop
+	testsStatus     string              // PASSING, FAILING, UNKNOWN
+	testSummary     *testreport.Summary // Parsed test report from the last loop
+	exitSignal      bool                // Whether exit was signaled
+	confidenceScore float64             // Confidence in completion (0-1)
 
 	// Context window tracking
 	contextUsagePercent float64 // Current usage (0-1)
@@ -106,14 +126,76 @@ type Model struct {
 	// Loop outcome (from last iteration)
 	lastOutcome         *loop.LoopOutcome
 	totalTasksCompleted int // Cumulative tasks completed
+
+	// Agent escalation awaiting a human answer
+	escalation  *analysis.Escalation
+	answerInput []rune
+
+	// Standing operator guidance and the input for adding more
+	steering   []string
+	steerInput []rune
+
+	// Plan editing from the Tasks view: the plan as last read, the cursor
+	// (an index into tasks), the new task being typed, and edits queued
+	// behind the one being written
+	planContent string
+	taskCursor  int
+	addingTask  bool
+	taskInput   []rune
+	planEditing bool
+	planEdits   []plan.Edit
+
+	// "/" search and the logs and transcript filters
+	search           textSearch
+	logFilter        int // Index into logLevelFilters
+	transcriptFilter transcriptFilter
+
+	// Iteration awaiting review (review mode)
+	review          *loop.Review
+	reviewScroll    int
+	reviewRejecting bool // Typing rejection feedback
+	reviewFeedback  []rune
 }
 
 // Init initializes model
 func (m Model) Init() tea.Cmd {
 	// Start the tick timer for animations
-	return tea.Tick(100*time.Millisecond, func(t time.Time) tea.Msg {
+	tick := tea.Tick(100*time.Millisecond, func(t time.Time) tea.Msg {
 		return tuimsg.TickMsg(t)
 	})
+	if m.attached != "" && m.ctx != nil {
+		// Follow the attached run until it ends or we detach
+		return tea.Batch(tick, effects.RunController(m.ctx, m.controller))
+	}
+	return tick
+}
+
+// keymap returns the model's key bindings
+func (m Model) keymap() *keymap.Keymap {
+	if m.keys == nil {
+		return keymap.Default()
+	}
+	return m.keys
+}
+
+// quit cancels the run, if any, and ends the program
+func (m Model) quit() (tea.Model, tea.Cmd) {
+	m.quitting = true
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+		m.ctx = nil
+	}
+	return m, tea.Quit
+}
+
+// toggleScreen opens a full screen, or goes back to the split view from it
+func (m *Model) toggleScreen(screen Screen) {
+	if m.screen == screen {
+		m.screen = ScreenSplit
+	} else {
+		m.screen = screen
+	}
 }
 
 // Update handles messages
@@ -122,128 +204,209 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyCtrlC, tea.KeyCtrlQ:
-			m.quitting = true
-			if m.cancel != nil {
-				m.cancel()
-				m.cancel = nil
-				m.ctx = nil
+		if m.screen == ScreenQuestion && msg.Type != tea.KeyCtrlC && msg.Type != tea.KeyCtrlQ {
+			return m.handleQuestionKey(msg)
+		}
+		if m.screen == ScreenSteer && msg.Type != tea.KeyCtrlC && msg.Type != tea.KeyCtrlQ {
+			return m.handleSteerKey(msg)
+		}
+		if m.screen == ScreenReview && m.review != nil && msg.Type != tea.KeyCtrlC && msg.Type != tea.KeyCtrlQ {
+			return m.handleReviewKey(msg)
+		}
+		if m.screen == ScreenTasks && m.addingTask && msg.Type != tea.KeyCtrlC && msg.Type != tea.KeyCtrlQ {
+			return m.handleTaskInputKey(msg)
+		}
+		key := msg.String()
+		keys := m.keymap()
+
+		// Search keys come first so n and N step through matches
+		if view := m.currentSearchView(); view != searchNone && msg.Type != tea.KeyCtrlC && msg.Type != tea.KeyCtrlQ {
+			if m.search.typing && m.search.view == view {
+				return m.handleSearchInputKey(msg)
+			}
+			if m.handleSearchKey(view, key) {
+				return m, nil
 			}
-			return m, tea.Quit
+		}
+		if msg.Type == tea.KeyCtrlC || msg.Type == tea.KeyCtrlQ {
+			return m.quit()
+		}
 
-		case tea.KeyRunes:
-			switch msg.String() {
-			case "q":
-				m.quitting = true
+		switch keys.Lookup(keymap.ScopeGlobal, key) {
+		case keymap.Quit:
+			return m.quit()
+
+		case keymap.Run:
+			if m.state != StateRunning && m.controller != nil {
+				// "Run / Restart" - ensure we don't leave a previous run behind.
 				if m.cancel != nil {
 					m.cancel()
 					m.cancel = nil
 					m.ctx = nil
 				}
-				return m, tea.Quit
-
-			case "r":
-				if m.state != StateRunning && m.controller != nil {
-					// "Run / Restart" - ensure we don't leave a previous run behind.
-					if m.cancel != nil {
-						m.cancel()
-						m.cancel = nil
-						m.ctx = nil
-					}
-					m.state = StateRunning
-					m.activeTaskIdx = 0 // Start with first task
-					m.ctx, m.cancel = context.WithCancel(context.Background())
-					return m, effects.RunController(m.ctx, m.controller)
-				}
+				m.state = StateRunning
+				m.activeTaskIdx = 0 // Start with first task
+				m.ctx, m.cancel = context.WithCancel(context.Background())
+				return m, effects.RunController(m.ctx, m.controller)
+			}
 
-			case "p":
-				switch m.state {
-				case StateRunning:
-					m.state = StatePaused
-					if m.controller != nil {
-						m.controller.Pause()
-					}
-				case StatePaused:
-					m.state = StateRunning
+		case keymap.Pause:
+			switch m.state {
+			case StateRunning:
+				m.state = StatePaused
+				if m.controller != nil {
+					return m, m.controls.Send("pause", m.controller.Pause)
+				}
+			case StatePaused:
+				m.state = StateRunning
+				if m.escalation != nil {
+					// Resuming past a question answers it with nothing, so
+					// the loop stops reporting it as pending
+					m.escalation = nil
 					if m.controller != nil {
-						m.controller.Resume()
+						ctrl := m.controller
+						return m, m.controls.Send("answer", func() { ctrl.Answer("") })
 					}
+					return m, nil
 				}
-				return m, nil
-
-			case "l":
-				// Toggle logs full view
-				if m.screen == ScreenLogs {
-					m.screen = ScreenSplit
-				} else {
-					m.screen = ScreenLogs
+				if m.controller != nil {
+					return m, m.controls.Send("resume", m.controller.Resume)
 				}
-				return m, nil
+			}
+			return m, nil
 
-			case "t":
-				// Toggle tasks full view
-				if m.screen == ScreenTasks {
-					m.screen = ScreenSplit
-				} else {
-					m.screen = ScreenTasks
-				}
-				return m, nil
+		case keymap.PauseNow:
+			if m.state == StateRunning && m.controller != nil {
+				m.state = StatePaused
+				return m, m.controls.Send("pause-now", m.controller.PauseNow)
+			}
+			return m, nil
 
-			case "o":
-				// Toggle output full view
-				if m.screen == ScreenOutput {
-					m.screen = ScreenSplit
-				} else {
-					m.screen = ScreenOutput
+		case keymap.Stop:
+			if m.state == StateRunning || m.state == StatePaused {
+				if m.controller != nil {
+					return m, m.controls.Send("stop", m.controller.StopAfterIteration)
 				}
-				return m, nil
+			}
+			return m, nil
 
-			case "?":
-				if m.screen == ScreenHelp {
-					m.screen = ScreenSplit
-				} else {
-					m.screen = ScreenHelp
+		case keymap.Abort:
+			if m.state == StateRunning || m.state == StatePaused {
+				if m.controller != nil {
+					return m, m.controls.Send("abort", m.controller.Abort)
 				}
-				return m, nil
+			}
+			return m, nil
 
-			case "c":
-				if m.screen == ScreenCircuit {
-					m.screen = ScreenSplit
-				} else {
-					m.screen = ScreenCircuit
-				}
-				return m, nil
+		case keymap.SkipTask:
+			if m.state == StateRunning && m.controller != nil {
+				return m, m.controls.Send("skip", m.controller.SkipTask)
+			}
+			return m, nil
 
-			case "R":
-				// Reset circuit breaker - send message to controller
-				m.circuitState = "CLOSED"
-				m.addLog(string(loop.LogLevelInfo), "Circuit breaker reset")
-				return m, nil
+		case keymap.Logs:
+			// Toggle logs full view
+			m.toggleScreen(ScreenLogs)
+			return m, nil
+
+		case keymap.Tasks:
+			// Toggle tasks full view
+			m.toggleScreen(ScreenTasks)
+			return m, nil
+
+		case keymap.Output:
+			// Toggle output full view
+			m.toggleScreen(ScreenOutput)
+			return m, nil
+
+		case keymap.Help:
+			m.toggleScreen(ScreenHelp)
+			return m, nil
 
+		case keymap.Circuit:
+			m.toggleScreen(ScreenCircuit)
+			return m, nil
+
+		case keymap.History:
+			m.toggleScreen(ScreenHistory)
+			if m.screen == ScreenHistory {
+				return m, m.loadHistory()
+			}
+			return m, nil
+
+		case keymap.Answer:
+			if m.escalation != nil {
+				m.screen = ScreenQuestion
+			}
+			return m, nil
+
+		case keymap.Review:
+			if m.review != nil {
+				m.screen = ScreenReview
+			}
+			return m, nil
+
+		case keymap.Steer:
+			if m.controller != nil {
+				m.steerInput = nil
+				m.screen = ScreenSteer
+			}
+			return m, nil
+
+		case keymap.ClearSteering:
+			if len(m.steering) > 0 && m.controller != nil {
+				return m, m.controls.Send("clear-steering", m.controller.ClearSteering)
+			}
+			return m, nil
+
+		case keymap.ResetCircuit:
+			// Reset circuit breaker - send message to controller
+			m.circuitState = "CLOSED"
+			m.addLog(string(loop.LogLevelInfo), "Circuit breaker reset")
+			return m, nil
+		}
+
+		if m.screen == ScreenOutput && m.outputTab == OutputTabDiffs && m.handleDiffKey(key) {
+			return m, nil
+		}
+		if m.screen == ScreenOutput && m.outputTab == OutputTabTools {
+			if cmd, ok := m.handleToolKey(key); ok {
+				return m, cmd
+			}
+		}
+		if m.screen == ScreenTasks {
+			if cmd, ok := m.handleTaskKey(key); ok {
+				return m, cmd
+			}
+		}
+		if m.screen == ScreenHistory {
+			if cmd, ok := m.handleHistoryKey(key); ok {
+				return m, cmd
 			}
 		}
 
 		// Output view-only keys (when the output screen is open).
-		if msg.Type == tea.KeyRunes && m.screen == ScreenOutput {
-			switch msg.String() {
-			case "[":
+		if m.screen == ScreenOutput && m.outputTab == OutputTabReasoning &&
+			keys.Lookup(keymap.ScopeReasoning, key) == keymap.ToggleReasoning {
+			m.reasoningExpanded = !m.reasoningExpanded
+			return m, nil
+		}
+		if m.screen == ScreenOutput {
+			switch keys.Lookup(keymap.ScopeOutput, key) {
+			case keymap.PrevTab:
 				if m.outputTab == 0 {
-					m.outputTab = OutputTabReasoning
+					m.outputTab = OutputTabMetrics
 				} else {
 					m.outputTab--
 				}
 				return m, nil
-			case "]":
-				if m.outputTab == OutputTabReasoning {
+			case keymap.NextTab:
+				if m.outputTab == OutputTabMetrics {
 					m.outputTab = OutputTabTranscript
 				} else {
 					m.outputTab++
 				}
 				return m, nil
-			case "y":
-				m.reasoningExpanded = !m.reasoningExpanded
-				return m, nil
 			}
 		}
 
@@ -269,6 +432,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.status = msg.Status
 		return m, nil
 
+	case tuimsg.ControlSentMsg:
+		// The controller reports the control's effect (or failure) as events
+		return m, nil
+
+	case tea.MouseMsg:
+		if m.screen == ScreenOutput && m.outputTab == OutputTabDiffs {
+			m.handleDiffMouse(msg)
+		}
+		return m, nil
+
 	case tea.WindowSizeMsg:
 		// Invalidate width-specific markdown renderer cache on resize.
 		if m.md != nil && msg.Width != m.width {
@@ -289,64 +462,92 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		event := msg.Event
 		switch event.Type {
 		case loop.EventTypeLoopUpdate:
-			m.loopNumber = event.LoopNumber
-			m.callsUsed = event.CallsUsed
-			m.status = event.Status
-			m.circuitState = event.CircuitState
-			m.updateActiveTask()
+			if u := event.Update; u != nil {
+				m.loopNumber = u.LoopNumber
+				m.callsUsed = u.CallsUsed
+				m.status = u.Status
+				m.circuitState = u.CircuitState
+				m.updateActiveTask()
+			}
 		case loop.EventTypeLog:
-			m.addLog(string(event.LogLevel), event.LogMessage)
+			if event.Log != nil {
+				m.addLog(string(event.Log.Level), event.Log.Message)
+			}
 		case loop.EventTypeStateChange:
-			// Handle state changes if needed
+			if sc := event.State; sc != nil {
+				switch {
+				case sc.To == loop.RunStatePaused && m.state == StateRunning:
+					m.state = StatePaused
+				case sc.To == loop.RunStateRunning && m.state == StatePaused:
+					m.state = StateRunning
+				}
+				if sc.To == loop.RunStateRunning && (sc.From == loop.RunStateIdle || sc.From == loop.RunStateStopped) {
+					cmds = append(cmds, m.startDiffHistory())
+				}
+			}
 		case loop.EventTypeCodexOutput:
-			m.addOutputLine(event.OutputLine, string(event.OutputType))
+			if event.Output != nil {
+				m.addOutputLine(event.Output.Line, string(event.Output.Type))
+			}
 		case loop.EventTypeCodexReasoning:
-			m.addReasoningLine(event.ReasoningText)
+			if event.Reasoning != nil {
+				m.addReasoningLine(event.Reasoning.Text)
+			}
 		case loop.EventTypeCodexTool:
-			// Deduplicate tool calls
-			toolID := fmt.Sprintf("%s:%s:%s", event.ToolName, event.ToolTarget, event.ToolStatus)
+			tool := event.Tool
+			if tool == nil {
+				break
+			}
+			// Deduplicate tool calls; calls with their own IDs are distinct
+			toolID := fmt.Sprintf("%s:%s:%s:%s", tool.ID, tool.Name, tool.Target, tool.Status)
 			if toolID == m.lastToolCall {
 				return m, nil
 			}
 			m.lastToolCall = toolID
-			m.currentTool = event.ToolName
+			m.currentTool = tool.Name
+			m.recordToolCall(tool, event.Iteration, event.Time)
 
 			// Record file touches as "pending changes" for immediate diff UX.
-			if looksLikeFileTarget(event.ToolTarget) {
+			if looksLikeFileTarget(tool.Target) {
 				if m.pendingChanges == nil {
 					m.pendingChanges = make(map[string]pendingChange)
 				}
 				pc := pendingChange{
-					Path:      event.ToolTarget,
-					Tool:      event.ToolName,
-					Status:    string(event.ToolStatus),
+					Path:      tool.Target,
+					Tool:      tool.Name,
+					Status:    string(tool.Status),
 					UpdatedAt: time.Now(),
 				}
-				m.pendingChanges[event.ToolTarget] = pc
+				m.pendingChanges[tool.Target] = pc
+				m.recordToolTouch(tool.Target, tool.Name)
 
 				// Debounce git diff refresh on write-like tools finishing.
-				if event.ToolStatus != loop.ToolStatusStarted && isDiffRelevantTool(event.ToolName) {
+				if tool.Status != loop.ToolStatusStarted && isDiffRelevantTool(tool.Name) {
 					cmds = append(cmds, m.triggerDiffRefresh())
 				}
 			}
 
-			if event.ToolStatus == loop.ToolStatusStarted {
-				m.addOutputLine(fmt.Sprintf("> %s %s...", event.ToolName, event.ToolTarget), "tool_call")
+			if tool.Status == loop.ToolStatusStarted {
+				m.addOutputLine(fmt.Sprintf("> %s %s...", tool.Name, tool.Target), "tool_call")
 			} else {
-				m.addOutputLine(fmt.Sprintf("  Done: %s", event.ToolTarget), "tool_call")
+				m.addOutputLine(fmt.Sprintf("  Done: %s", tool.Target), "tool_call")
 			}
 		case loop.EventTypeAnalysis:
+			a := event.Analysis
+			if a == nil {
+				break
+			}
 			// Update analysis results from RALPH_STATUS block
-			m.analysisStatus = event.AnalysisStatus
-			m.tasksCompleted = event.TasksCompleted
-			m.filesModified = event.FilesModified
-			m.testsStatus = event.TestsStatus
-			m.exitSignal = event.ExitSignal
-			m.confidenceScore = event.ConfidenceScore
+			m.analysisStatus = a.Status
+			m.tasksCompleted = a.TasksCompleted
+			m.filesModified = a.FilesModified
+			m.testsStatus = a.TestsStatus
+			m.exitSignal = a.ExitSignal
+			m.confidenceScore = a.ConfidenceScore
 
 			// Find and update task by CurrentTask text
-			if event.CurrentTask != "" {
-				m.updateTaskByText(event.CurrentTask, event.AnalysisStatus == "COMPLETE" || event.TasksCompleted > 0)
+			if a.CurrentTask != "" {
+				m.updateTaskByText(a.CurrentTask, a.Status == "COMPLETE" || a.TasksCompleted > 0)
 			}
 
 			// Update state if complete
@@ -355,11 +556,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case loop.EventTypeContextUsage:
 			// Update context window usage
-			m.contextUsagePercent = event.ContextUsagePercent
-			m.contextTotalTokens = event.ContextTotalTokens
-			m.contextLimit = event.ContextLimit
-			m.contextThreshold = event.ContextThreshold
-			m.contextWasCompacted = event.ContextWasCompacted
+			if u := event.Context; u != nil {
+				m.contextUsagePercent = u.UsagePercent
+				m.contextTotalTokens = u.TotalTokens
+				m.contextLimit = u.Limit
+				m.contextThreshold = u.ThresholdReached
+				m.contextWasCompacted = u.WasCompacted
+				iteration := event.Iteration
+				if iteration == 0 {
+					iteration = m.loopNumber
+				}
+				m.metrics.recordUsage(iteration, u)
+			}
 
 		case loop.EventTypePreflight:
 			// Update preflight summary
@@ -396,10 +604,44 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
+		case loop.EventTypeEscalation:
+			if event.Escalation != nil {
+				m.escalation = event.Escalation
+				m.answerInput = nil
+				m.state = StatePaused
+				m.screen = ScreenQuestion
+				m.addLog(string(loop.LogLevelWarn), "Agent needs input - answer the question to resume")
+			}
+
+		case loop.EventTypeReview:
+			if event.Review != nil {
+				if cmd := m.applyReview(event.Review); cmd != nil {
+					cmds = append(cmds, cmd)
+				}
+			}
+
+		case loop.EventTypeSteering:
+			if event.Steering != nil {
+				m.applySteering(event.Steering)
+			}
+
+		case loop.EventTypeSnapshot:
+			if event.Snapshot != nil {
+				cmds = append(cmds, m.applySnapshot(event.Snapshot)...)
+			}
+
 		case loop.EventTypeOutcome:
 			// Update loop outcome
 			if event.Outcome != nil {
 				m.lastOutcome = event.Outcome
+				if event.Iteration > 0 {
+					m.metrics.recordOutcome(event.Iteration, event.Outcome, m.circuitState)
+				}
+				cmds = append(cmds, m.snapshotIteration(event.Iteration))
+				if event.Outcome.Tests != nil {
+					m.testSummary = event.Outcome.Tests
+					m.testsStatus = event.Outcome.Tests.TestsStatus()
+				}
 				if event.Outcome.Success {
 					m.totalTasksCompleted += event.Outcome.TasksCompleted
 					m.addLog(string(loop.LogLevelInfo), fmt.Sprintf("Loop outcome: %d tasks completed, %d files modified",
@@ -445,14 +687,48 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case tuimsg.WorkTreeSnapshotMsg:
+		return m, m.applyWorkTreeSnapshot(msg)
+
+	case tuimsg.TreeDiffLoadedMsg:
+		m.applyTreeDiff(msg)
+		return m, nil
+
+	case tuimsg.PlanEditedMsg:
+		if msg.Err != nil {
+			m.addLog(string(loop.LogLevelWarn), fmt.Sprintf("Editor exited with an error: %v", msg.Err))
+		}
+		// Edits to the plan are part of the change under review
+		return m, tea.Batch(effects.LoadPlan(msg.Filename, m.readFile), m.triggerDiffRefresh())
+
 	case tuimsg.PlanLoadedMsg:
 		if msg.Err != nil {
 			m.addLog(string(loop.LogLevelWarn), fmt.Sprintf("Could not reload tasks from %s: %v", msg.Filename, msg.Err))
 			return m, nil
 		}
+		m.planContent = msg.Content
 		m.applyLoadedPlan(msg.Filename, msg.Tasks, msg.Phases)
 		return m, nil
 
+	case tuimsg.PlanTaskEditedMsg:
+		return m, m.applyPlanTaskEdit(msg)
+
+	case tuimsg.HistoryLoadedMsg:
+		m.applyHistory(msg)
+		return m, nil
+
+	case tuimsg.HistoryIterationLoadedMsg:
+		m.applyHistoryIteration(msg)
+		return m, nil
+
+	case tuimsg.ClipboardCopiedMsg:
+		if msg.Err != nil {
+			m.addLog(string(loop.LogLevelWarn), fmt.Sprintf("Could not copy the tool %s: %v", msg.What, msg.Err))
+		} else {
+			m.addLog(string(loop.LogLevelInfo), fmt.Sprintf("Copied the tool %s to the clipboard (%d bytes)", msg.What, msg.Bytes))
+		}
+		return m, nil
+
 	case tuimsg.ControllerDoneMsg:
 		// The controller is finished; treat nil error as a clean exit. Cancellations happen on quit/restart.
 		if msg.Err != nil && !errors.Is(msg.Err, context.Canceled) {
@@ -521,9 +797,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 // addLog adds a log entry
+// logEntry is one line of the logs view
+type logEntry struct {
+	Level string
+	Text  string
+}
+
 func (m *Model) addLog(level, message string) {
-	formattedLog := StyledLogEntry(level, message)
-	m.logs = append(m.logs, formattedLog)
+	m.logs = append(m.logs, logEntry{Level: level, Text: StyledLogEntry(level, message)})
 	if len(m.logs) > 500 {
 		m.logs = m.logs[len(m.logs)-500:]
 	}
@@ -693,7 +974,7 @@ func (m *Model) updateActiveTask() {
 	}
 	// Simple heuristic: advance to next incomplete task when loop progresses
 	for i := range m.tasks {
-		if !m.tasks[i].Completed {
+		if !m.tasks[i].Settled() {
 			m.activeTaskIdx = i
 			m.tasks[i].Active = true
 			// Deactivate previous tasks
@@ -827,6 +1108,8 @@ func (m *Model) applyLoadedPlan(filename string, tasks []Task, phases []Phase) {
 		m.tasks[i] = Task{
 			Text:      task.Text,
 			Completed: task.Completed || completedInMemory[task.Text],
+			Skipped:   task.Skipped,
+			Blocked:   task.Blocked,
 			Active:    false,
 		}
 	}
@@ -840,13 +1123,15 @@ func (m *Model) applyLoadedPlan(filename string, tasks []Task, phases []Phase) {
 			m.phases[i].Tasks[j] = Task{
 				Text:      task.Text,
 				Completed: task.Completed || completedInMemory[task.Text],
+				Skipped:   task.Skipped,
+				Blocked:   task.Blocked,
 				Active:    false,
 			}
 		}
 		// Update phase completion status.
 		allComplete := true
 		for _, task := range m.phases[i].Tasks {
-			if !task.Completed {
+			if !task.Settled() {
 				allComplete = false
 				break
 			}
@@ -855,6 +1140,7 @@ func (m *Model) applyLoadedPlan(filename string, tasks []Task, phases []Phase) {
 	}
 
 	m.currentPhase = findFirstIncompletePhase(m.phases)
+	m.clampTaskCursor()
 	if filename != "" {
 		m.planFile = filename
 	}
@@ -868,7 +1154,7 @@ func (m *Model) updatePhaseCompletion() {
 	for phaseIdx := range m.phases {
 		allComplete := true
 		for _, task := range m.phases[phaseIdx].Tasks {
-			if !task.Completed {
+			if !task.Settled() {
 				allComplete = false
 				break
 			}
@@ -917,7 +1203,7 @@ func (m Model) View() string {
 	// Show error view if there's an error
 	if m.err != nil && m.screen != ScreenHelp {
 		content := m.renderErrorView()
-		return view.PadToFullScreen(content, width, height, Pepper)
+		return view.PadToFullScreen(content, width, height, CurrentTheme().BgBase)
 	}
 
 	// Get content based on screen
@@ -933,13 +1219,21 @@ func (m Model) View() string {
 		content = m.renderOutputFullView()
 	case ScreenLogs:
 		content = m.renderLogsFullView()
+	case ScreenQuestion:
+		content = m.renderQuestionView()
+	case ScreenSteer:
+		content = m.renderSteerView()
+	case ScreenReview:
+		content = m.renderReviewView()
+	case ScreenHistory:
+		content = m.renderHistoryView()
 	default:
 		// Default to split view
 		content = m.renderSplitView()
 	}
 
 	// Pad content to fill entire screen
-	return view.PadToFullScreen(content, width, height, Pepper)
+	return view.PadToFullScreen(content, width, height, CurrentTheme().BgBase)
 }
 
 func (m Model) renderRateLimitProgress() string {
@@ -986,16 +1280,16 @@ func (m Model) renderErrorView() string {
 	errorIcon := StyleErrorMsg.Render(IconError)
 	errorMsg := fmt.Sprintf("\n %s Error: %v\n", errorIcon, m.err)
 
-	helpText := StyleTextMuted.Render(`
- Press 'r' to retry
- Press 'q' to quit
-`)
+	var help strings.Builder
+	help.WriteString("\n")
+	if key := m.keymap().Key(keymap.Run); key != "" {
+		fmt.Fprintf(&help, " Press '%s' to retry\n", key)
+	}
+	fmt.Fprintf(&help, " Press '%s' to quit\n", quitKey(m.keymap()))
+	helpText := StyleTextMuted.Render(help.String())
 
 	footer := StyleFooter.Width(width).Render(
-		fmt.Sprintf(" %s retry%s%s quit",
-			StyleHelpKey.Render("r"),
-			StyleTextSubtle.Render(MetaDotSeparator),
-			StyleHelpKey.Render("q")),
+		footerHints(m.hint("retry", keymap.Run), StyleHelpKey.Render(quitKey(m.keymap()))+" quit"),
 	)
 
 	return lipgloss.JoinVertical(lipgloss.Left,
@@ -1084,10 +1378,7 @@ func (m Model) renderCircuitView() string {
 
 	// Footer with Crush-style
 	footer := StyleFooter.Width(width).Render(
-		fmt.Sprintf(" %s return%s%s reset",
-			StyleHelpKey.Render("c"),
-			StyleTextSubtle.Render(MetaDotSeparator),
-			StyleHelpKey.Render("R")),
+		footerHints(m.hint("return", keymap.Circuit), m.hint("reset", keymap.ResetCircuit)),
 	)
 
 	return lipgloss.JoinVertical(lipgloss.Left,
@@ -156,7 +156,7 @@ func TestModelToggleHelp(t *testing.T) {
 // TestModelLogMsg tests log message handling
 func TestModelLogMsg(t *testing.T) {
 	model := Model{
-		logs: []string{},
+		logs: []logEntry{},
 	}
 
 	logMsg := msg.LogMsg{
@@ -171,8 +171,8 @@ func TestModelLogMsg(t *testing.T) {
 		t.Errorf("Expected 1 log, got %d", len(logs))
 	}
 
-	if !contains(logs[0], "Test message") {
-		t.Errorf("Log should contain message, got: %s", logs[0])
+	if !contains(logs[0].Text, "Test message") {
+		t.Errorf("Log should contain message, got: %s", logs[0].Text)
 	}
 }
 
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	"github.com/brainwhocodes/lisa-loop/internal/opencode"
 	"github.com/brainwhocodes/lisa-loop/internal/tui/plan"
 )
 
@@ -44,9 +45,17 @@ type ControllerDoneMsg struct {
 	Err error
 }
 
+// ControlSentMsg is sent when a control (pause, answer, steer, review, ...)
+// has been handed to the controller. Failures reach the TUI as error log
+// events from the controller, not through this message.
+type ControlSentMsg struct {
+	Action string
+}
+
 // PlanLoadedMsg is sent when a plan file is loaded (or fails to load).
 type PlanLoadedMsg struct {
 	Filename string
+	Content  string
 	Tasks    []plan.Task
 	Phases   []plan.Phase
 	Err      error
@@ -131,3 +140,65 @@ type LoopOutcomeMsg struct {
 	ExitSignal     bool
 	Error          string
 }
+
+// PlanEditedMsg is sent when the external editor opened on the plan exits.
+type PlanEditedMsg struct {
+	Filename string
+	Err      error
+}
+
+// PlanTaskEditedMsg is sent when a task edit from the Tasks view has been
+// written to the plan, or refused. Content and the parsed tasks are the
+// plan as it is on disk afterwards; Task is the edited task's index in it.
+// Conflict is set when the file had changed since the TUI last loaded it.
+type PlanTaskEditedMsg struct {
+	Filename string
+	Content  string
+	Tasks    []plan.Task
+	Phases   []plan.Phase
+	Task     int
+	Conflict bool
+	Err      error
+}
+
+// WorkTreeSnapshotMsg is emitted when the working tree has been recorded as a
+// git tree object.
+type WorkTreeSnapshotMsg struct {
+	ID   int
+	Tree string
+	Err  error
+}
+
+// TreeDiffLoadedMsg is emitted when the diff between two working tree
+// snapshots has been collected.
+type TreeDiffLoadedMsg struct {
+	ID      int
+	NumStat string
+	Patch   string
+	Err     error
+}
+
+// ClipboardCopiedMsg is emitted when text has been sent to the terminal's
+// clipboard. What names what was copied, for the log.
+type ClipboardCopiedMsg struct {
+	What  string
+	Bytes int
+	Err   error
+}
+
+// HistoryLoadedMsg is emitted when the run journals and archived sessions
+// have been read for the History screen.
+type HistoryLoadedMsg struct {
+	Runs     []loop.RunHistory
+	Sessions []opencode.SessionArchive
+	Err      error
+}
+
+// HistoryIterationLoadedMsg is emitted when a past iteration opened on the
+// History screen has been read in full from its run journal.
+type HistoryIterationLoadedMsg struct {
+	Path      string // Run journal the iteration was read from
+	Index     int    // Iteration's place in the journal
+	Iteration loop.JournalIteration
+	Err       error
+}
@@ -6,6 +6,8 @@ const (
 	OutputTabTranscript OutputTab = iota
 	OutputTabDiffs
 	OutputTabReasoning
+	OutputTabTools
+	OutputTabMetrics
 )
 
 func (t OutputTab) String() string {
@@ -16,6 +18,10 @@ func (t OutputTab) String() string {
 		return "Diffs"
 	case OutputTabReasoning:
 		return "Reasoning"
+	case OutputTabTools:
+		return "Tools"
+	case OutputTabMetrics:
+		return "Metrics"
 	default:
 		return "Transcript"
 	}
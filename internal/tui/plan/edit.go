@@ -0,0 +1,206 @@
+package plan
+
+import (
+	"errors"
+	"strings"
+)
+
+// EditKind is a change to one task of a plan
+type EditKind int
+
+const (
+	EditToggle   EditKind = iota // Check or uncheck
+	EditSkip                     // Mark skipped, or reopen a skipped task
+	EditBlock                    // Mark blocked, or reopen a blocked task
+	EditMoveUp                   // Swap with the task above
+	EditMoveDown                 // Swap with the task below
+	EditPin                      // Reopen and move before the first open task, so the loop does it next
+	EditDelete                   // Remove the task's line
+	EditAdd                      // Add Text as an open task below the task, or at the end of a plan without tasks
+)
+
+// Edit is one change to a plan's tasks. The task is named by its text and
+// which occurrence of that text it is rather than by line, so an edit still
+// finds its task after the file changes underneath it.
+type Edit struct {
+	Kind EditKind
+	Task string // Text of the task to change, empty to add to a plan without tasks
+	Nth  int    // Which task with that text, from 0
+	Text string // The new task for EditAdd
+}
+
+// ErrTaskNotFound is returned when the plan no longer has the edited task
+var ErrTaskNotFound = errors.New("task not found in plan")
+
+// EditFor names the task at index (in ParseTasks order) for an edit
+func EditFor(tasks []Task, index int, kind EditKind) Edit {
+	e := Edit{Kind: kind}
+	if index < 0 || index >= len(tasks) {
+		return e
+	}
+	e.Task = tasks[index].Text
+	for _, t := range tasks[:index] {
+		if t.Text == e.Task {
+			e.Nth++
+		}
+	}
+	return e
+}
+
+// document is plan content split into lines, with the line of each task in
+// the order ParseTasks returns them
+type document struct {
+	lines []string
+	tasks []int
+}
+
+func parseDocument(content string) document {
+	d := document{lines: strings.Split(content, "\n")}
+	for i, line := range d.lines {
+		if taskText(line) != "" {
+			d.tasks = append(d.tasks, i)
+		}
+	}
+	return d
+}
+
+// taskText is the text of a task line as ParsePhases reads it, or "" for
+// any other line
+func taskText(line string) string {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "- [") || len(trimmed) <= 6 {
+		return ""
+	}
+	return strings.TrimSpace(trimmed[6:])
+}
+
+// marker is the character between a task line's brackets
+func marker(line string) byte {
+	return line[strings.Index(line, "- [")+3]
+}
+
+// setMarker rewrites a task line's checkbox, leaving the rest alone
+func setMarker(line string, m byte) string {
+	i := strings.Index(line, "- [") + 3
+	return line[:i] + string(m) + line[i+1:]
+}
+
+func (d document) find(text string, nth int) int {
+	for i, line := range d.tasks {
+		if taskText(d.lines[line]) != text {
+			continue
+		}
+		if nth == 0 {
+			return i
+		}
+		nth--
+	}
+	return -1
+}
+
+// firstOpen is the index of the first "- [ ]" task, or -1
+func (d document) firstOpen() int {
+	for i, line := range d.tasks {
+		if marker(d.lines[line]) == ' ' {
+			return i
+		}
+	}
+	return -1
+}
+
+// moveLine moves a line to sit before line to, returning where it ended up
+func (d *document) moveLine(from, to int) int {
+	line := d.lines[from]
+	d.lines = append(d.lines[:from], d.lines[from+1:]...)
+	if to > from {
+		to--
+	}
+	d.lines = append(d.lines[:to], append([]string{line}, d.lines[to:]...)...)
+	return to
+}
+
+// Apply makes an edit to plan content. It returns the new content and the
+// index of the edited task in it, which for a deleted task is the one that
+// took its place.
+func Apply(content string, e Edit) (string, int, error) {
+	d := parseDocument(content)
+
+	if e.Kind == EditAdd {
+		text := strings.Join(strings.Fields(e.Text), " ")
+		if text == "" {
+			return "", 0, errors.New("task text is empty")
+		}
+		at := len(d.lines)
+		line := "- [ ] " + text
+		if e.Task == "" {
+			// End of the plan, before the final newline
+			if at > 0 && d.lines[at-1] == "" {
+				at--
+			}
+		} else {
+			i := d.find(e.Task, e.Nth)
+			if i < 0 {
+				return "", 0, ErrTaskNotFound
+			}
+			after := d.lines[d.tasks[i]]
+			line = after[:strings.Index(after, "- [")] + line
+			at = d.tasks[i] + 1
+		}
+		d.lines = append(d.lines[:at], append([]string{line}, d.lines[at:]...)...)
+		return d.result(at)
+	}
+
+	i := d.find(e.Task, e.Nth)
+	if i < 0 {
+		return "", 0, ErrTaskNotFound
+	}
+	line := d.tasks[i]
+	switch e.Kind {
+	case EditToggle:
+		if m := marker(d.lines[line]); m == 'x' || m == 'X' {
+			d.lines[line] = setMarker(d.lines[line], ' ')
+		} else {
+			d.lines[line] = setMarker(d.lines[line], 'x')
+		}
+	case EditSkip, EditBlock:
+		m := byte('-')
+		if e.Kind == EditBlock {
+			m = '!'
+		}
+		if marker(d.lines[line]) == m {
+			m = ' '
+		}
+		d.lines[line] = setMarker(d.lines[line], m)
+	case EditMoveUp, EditMoveDown:
+		j := i - 1
+		if e.Kind == EditMoveDown {
+			j = i + 1
+		}
+		if j >= 0 && j < len(d.tasks) {
+			other := d.tasks[j]
+			d.lines[line], d.lines[other] = d.lines[other], d.lines[line]
+			line = other
+		}
+	case EditPin:
+		d.lines[line] = setMarker(d.lines[line], ' ')
+		if first := d.firstOpen(); first >= 0 && first < i {
+			line = d.moveLine(line, d.tasks[first])
+		}
+	case EditDelete:
+		d.lines = append(d.lines[:line], d.lines[line+1:]...)
+		content := strings.Join(d.lines, "\n")
+		return content, min(i, max(len(d.tasks)-2, 0)), nil
+	}
+	return d.result(line)
+}
+
+// result joins the document back up, locating the task on line
+func (d document) result(line int) (string, int, error) {
+	index := 0
+	for _, l := range d.lines[:line] {
+		if taskText(l) != "" {
+			index++
+		}
+	}
+	return strings.Join(d.lines, "\n"), index, nil
+}
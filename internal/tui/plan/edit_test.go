@@ -0,0 +1,142 @@
+package plan
+
+import (
+	"errors"
+	"testing"
+)
+
+const editPlan = `# Fix Plan
+
+## High Priority
+- [x] Done task
+- [ ] First open
+  - [ ] Nested task
+
+## Low Priority
+- [ ] Later task
+- [ ] Later task
+`
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name string
+		edit Edit
+		want string
+		task int
+	}{
+		{
+			name: "toggle open task",
+			edit: Edit{Kind: EditToggle, Task: "First open"},
+			want: "## High Priority\n- [x] Done task\n- [x] First open\n  - [ ] Nested task\n\n## Low Priority\n- [ ] Later task\n- [ ] Later task\n",
+			task: 1,
+		},
+		{
+			name: "toggle done task",
+			edit: Edit{Kind: EditToggle, Task: "Done task"},
+			want: "## High Priority\n- [ ] Done task\n- [ ] First open\n  - [ ] Nested task\n\n## Low Priority\n- [ ] Later task\n- [ ] Later task\n",
+			task: 0,
+		},
+		{
+			name: "skip second of two identical tasks",
+			edit: Edit{Kind: EditSkip, Task: "Later task", Nth: 1},
+			want: "## High Priority\n- [x] Done task\n- [ ] First open\n  - [ ] Nested task\n\n## Low Priority\n- [ ] Later task\n- [-] Later task\n",
+			task: 4,
+		},
+		{
+			name: "block nested task",
+			edit: Edit{Kind: EditBlock, Task: "Nested task"},
+			want: "## High Priority\n- [x] Done task\n- [ ] First open\n  - [!] Nested task\n\n## Low Priority\n- [ ] Later task\n- [ ] Later task\n",
+			task: 2,
+		},
+		{
+			name: "move up",
+			edit: Edit{Kind: EditMoveUp, Task: "First open"},
+			want: "## High Priority\n- [ ] First open\n- [x] Done task\n  - [ ] Nested task\n\n## Low Priority\n- [ ] Later task\n- [ ] Later task\n",
+			task: 0,
+		},
+		{
+			name: "move down across sections",
+			edit: Edit{Kind: EditMoveDown, Task: "Nested task"},
+			want: "## High Priority\n- [x] Done task\n- [ ] First open\n- [ ] Later task\n\n## Low Priority\n  - [ ] Nested task\n- [ ] Later task\n",
+			task: 3,
+		},
+		{
+			name: "move first task up does nothing",
+			edit: Edit{Kind: EditMoveUp, Task: "Done task"},
+			want: editPlan[len("# Fix Plan\n\n"):],
+			task: 0,
+		},
+		{
+			name: "pin moves before the first open task",
+			edit: Edit{Kind: EditPin, Task: "Later task", Nth: 1},
+			want: "## High Priority\n- [x] Done task\n- [ ] Later task\n- [ ] First open\n  - [ ] Nested task\n\n## Low Priority\n- [ ] Later task\n",
+			task: 1,
+		},
+		{
+			name: "pin reopens a done task in place",
+			edit: Edit{Kind: EditPin, Task: "Done task"},
+			want: "## High Priority\n- [ ] Done task\n- [ ] First open\n  - [ ] Nested task\n\n## Low Priority\n- [ ] Later task\n- [ ] Later task\n",
+			task: 0,
+		},
+		{
+			name: "delete",
+			edit: Edit{Kind: EditDelete, Task: "First open"},
+			want: "## High Priority\n- [x] Done task\n  - [ ] Nested task\n\n## Low Priority\n- [ ] Later task\n- [ ] Later task\n",
+			task: 1,
+		},
+		{
+			name: "delete last task",
+			edit: Edit{Kind: EditDelete, Task: "Later task", Nth: 1},
+			want: "## High Priority\n- [x] Done task\n- [ ] First open\n  - [ ] Nested task\n\n## Low Priority\n- [ ] Later task\n",
+			task: 3,
+		},
+		{
+			name: "add below keeps indent",
+			edit: Edit{Kind: EditAdd, Task: "Nested task", Text: "  Another   nested "},
+			want: "## High Priority\n- [x] Done task\n- [ ] First open\n  - [ ] Nested task\n  - [ ] Another nested\n\n## Low Priority\n- [ ] Later task\n- [ ] Later task\n",
+			task: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, task, err := Apply(editPlan, tt.edit)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if want := "# Fix Plan\n\n" + tt.want; got != want {
+				t.Errorf("Apply() content =\n%s\nwant\n%s", got, want)
+			}
+			if task != tt.task {
+				t.Errorf("Apply() task = %d, want %d", task, tt.task)
+			}
+		})
+	}
+}
+
+func TestApply_AddToPlanWithoutTasks(t *testing.T) {
+	got, task, err := Apply("# Plan\n", Edit{Kind: EditAdd, Text: "First"})
+	if err != nil || got != "# Plan\n- [ ] First\n" || task != 0 {
+		t.Errorf("Apply() = %q, %d, %v", got, task, err)
+	}
+}
+
+func TestApply_Errors(t *testing.T) {
+	if _, _, err := Apply(editPlan, Edit{Kind: EditToggle, Task: "Later task", Nth: 2}); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Apply() missing task error = %v, want ErrTaskNotFound", err)
+	}
+	if _, _, err := Apply(editPlan, Edit{Kind: EditAdd, Task: "Done task", Text: "  "}); err == nil {
+		t.Error("Apply() with empty task text should fail")
+	}
+}
+
+func TestEditFor(t *testing.T) {
+	tasks := ParseTasks(editPlan)
+	e := EditFor(tasks, 4, EditDelete)
+	if e.Kind != EditDelete || e.Task != "Later task" || e.Nth != 1 {
+		t.Errorf("EditFor() = %+v", e)
+	}
+	if e := EditFor(nil, 0, EditAdd); e.Task != "" {
+		t.Errorf("EditFor() without tasks = %+v", e)
+	}
+}
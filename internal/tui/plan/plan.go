@@ -10,9 +10,22 @@ import (
 type Task struct {
 	Text      string
 	Completed bool
+	Skipped   bool // "- [-]": set aside by the operator
+	Blocked   bool // "- [!]": waiting on something outside the loop
 	Active    bool
 }
 
+// SetAside reports whether the task is skipped or blocked. The loop only
+// works on "- [ ]" tasks, so set-aside tasks don't hold it up.
+func (t Task) SetAside() bool {
+	return t.Skipped || t.Blocked
+}
+
+// Settled reports whether the task needs no more work from the loop
+func (t Task) Settled() bool {
+	return t.Completed || t.SetAside()
+}
+
 // Phase groups tasks under a section header (e.g. "## Phase 1: ...").
 type Phase struct {
 	Name      string
@@ -57,9 +70,11 @@ func ParsePhases(data string) []Phase {
 			continue
 		}
 
-		// Parse checkbox items: - [ ] or - [x]
+		// Parse checkbox items: - [ ], - [x], - [-] or - [!]
 		if strings.HasPrefix(trimmed, "- [") {
 			completed := strings.HasPrefix(trimmed, "- [x]") || strings.HasPrefix(trimmed, "- [X]")
+			skipped := strings.HasPrefix(trimmed, "- [-]")
+			blocked := strings.HasPrefix(trimmed, "- [!]")
 
 			// Extract task text (skip "- [ ] " or "- [x] ")
 			text := ""
@@ -70,7 +85,7 @@ func ParsePhases(data string) []Phase {
 				continue
 			}
 
-			task := Task{Text: text, Completed: completed}
+			task := Task{Text: text, Completed: completed, Skipped: skipped, Blocked: blocked}
 			if currentPhase != nil {
 				currentPhase.Tasks = append(currentPhase.Tasks, task)
 			} else {
@@ -89,7 +104,7 @@ func ParsePhases(data string) []Phase {
 	for i := range phases {
 		allComplete := true
 		for _, task := range phases[i].Tasks {
-			if !task.Completed {
+			if !task.Settled() {
 				allComplete = false
 				break
 			}
@@ -118,3 +118,24 @@ func TestParseTasks_FlattensPhases(t *testing.T) {
 		t.Fatalf("unexpected task order: %#v", tasks)
 	}
 }
+
+func TestParsePhases_SetAsideTasks(t *testing.T) {
+	data := `
+## Phase 1: Setup
+- [x] Done
+- [-] Skipped
+- [!] Blocked
+`
+
+	phases := ParsePhases(data)
+	if len(phases) != 1 || len(phases[0].Tasks) != 3 {
+		t.Fatalf("unexpected phases: %#v", phases)
+	}
+	tasks := phases[0].Tasks
+	if !tasks[1].Skipped || tasks[1].Completed || !tasks[2].Blocked || tasks[2].Completed {
+		t.Fatalf("set-aside markers not parsed: %#v", tasks)
+	}
+	if !phases[0].Completed {
+		t.Fatalf("expected a phase with only done and set-aside tasks to be completed")
+	}
+}
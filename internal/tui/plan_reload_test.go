@@ -110,7 +110,7 @@ func TestPlanReloadErrorDoesNotWipeTasks(t *testing.T) {
 	// Warning should be logged.
 	foundWarn := false
 	for _, l := range m.logs {
-		if contains(l, "Could not reload tasks") {
+		if contains(l.Text, "Could not reload tasks") {
 			foundWarn = true
 			break
 		}
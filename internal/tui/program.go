@@ -1,21 +1,30 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 
 	"github.com/brainwhocodes/lisa-loop/internal/codex"
 	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/diffview"
 	"github.com/brainwhocodes/lisa-loop/internal/tui/effects"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/keymap"
 	"github.com/brainwhocodes/lisa-loop/internal/tui/markdown"
 	"github.com/brainwhocodes/lisa-loop/internal/tui/msg"
 	"github.com/brainwhocodes/lisa-loop/internal/tui/plan"
 	"github.com/brainwhocodes/lisa-loop/internal/tui/transcript"
 )
 
+// eventBufferSize bounds the controller events queued for the TUI. Streaming
+// output arrives in bursts, so it is larger than the bus default.
+const eventBufferSize = 4096
+
 // Program wraps the Bubble Tea program
 type Program struct {
 	model      Model
@@ -24,7 +33,7 @@ type Program struct {
 
 // NewProgram creates a new TUI program
 // If explicitMode is provided (non-empty), use it instead of auto-detecting
-func NewProgram(config codex.Config, controller *loop.Controller, explicitMode ...loop.ProjectMode) *Program {
+func NewProgram(config codex.Config, controller Controller, explicitMode ...loop.ProjectMode) *Program {
 	var projectMode loop.ProjectMode
 	if len(explicitMode) > 0 && explicitMode[0] != "" {
 		projectMode = explicitMode[0]
@@ -33,11 +42,26 @@ func NewProgram(config codex.Config, controller *loop.Controller, explicitMode .
 	}
 	planInfo := loadTasksForMode(projectMode)
 
+	// Under NO_COLOR nothing but plain text reaches the terminal
+	if termenv.EnvNoColor() {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+	theme, themeErr := selectTheme(config.Theme)
+	applyTheme(theme)
+	keys, keysErr := keymap.Load(config.Keymap, config.Keys)
+
 	// Determine initial state and status based on loaded files
 	initialState := StateInitializing
 	initialStatus := "Ready to start"
 	var initialErr error
-	var logs []string
+	var logs []logEntry
+	if themeErr != nil {
+		logs = append(logs, formatLog("WARN", fmt.Sprintf("%v; using the %s theme", themeErr, theme.Name)))
+	}
+	if keysErr != nil {
+		keys = keymap.Default()
+		logs = append(logs, formatLog("WARN", fmt.Sprintf("%v; using the default keys", keysErr)))
+	}
 
 	// Validate project mode
 	if projectMode == loop.ModeUnknown {
@@ -84,16 +108,22 @@ func NewProgram(config codex.Config, controller *loop.Controller, explicitMode .
 		phases:         planInfo.Phases,
 		currentPhase:   findFirstIncompletePhase(planInfo.Phases),
 		planFile:       planInfo.Filename,
+		planContent:    planInfo.Content,
 		projectMode:    projectMode,
 		activity:       "",
 		controller:     controller,
+		controls:       effects.NewControlQueue(),
 		readFile:       effects.OSReadFile,
+		clipboard:      os.Stderr,
 		exec:           effects.OSExec,
-		md:             markdown.New(),
+		md:             markdown.NewWithStyle(theme.Markdown),
+		keys:           keys,
+		diffViewer:     diffview.New(diffViewerStyles),
 		transcript:     transcript.New(500),
 		outputTab:      OutputTabTranscript,
 		activeTaskIdx:  -1,
 		backend:        config.Backend,
+		profile:        config.Profile,
 		outputLines:    []string{},
 		reasoningLines: []string{},
 	}
@@ -104,9 +134,22 @@ func NewProgram(config codex.Config, controller *loop.Controller, explicitMode .
 	}
 }
 
+// NewAttachedProgram creates a TUI program for a loop running in another
+// process, reached through controller at addr. The UI follows the run from
+// the start; quitting detaches and leaves the run going.
+func NewAttachedProgram(config codex.Config, controller Controller, addr string) *Program {
+	p := NewProgram(config, controller)
+	p.model.attached = addr
+	p.model.state = StateRunning
+	p.model.status = "Attached"
+	p.model.ctx, p.model.cancel = context.WithCancel(context.Background())
+	p.model.logs = append(p.model.logs, formatLog("INFO", fmt.Sprintf("Attached to the loop at %s (%s detaches)", addr, quitKey(p.model.keymap()))))
+	return p
+}
+
 // formatLog formats a log entry with timestamp
-func formatLog(level, message string) string {
-	return fmt.Sprintf("[%s] %s: %s", time.Now().Format("15:04:05"), level, message)
+func formatLog(level, message string) logEntry {
+	return logEntry{Level: level, Text: fmt.Sprintf("[%s] %s: %s", time.Now().Format("15:04:05"), level, message)}
 }
 
 // findFirstIncompletePhase returns the index of the first incomplete phase
@@ -126,6 +169,7 @@ func findFirstIncompletePhase(phases []Phase) int {
 // PlanFileInfo holds info about loaded plan file
 type PlanFileInfo struct {
 	Filename string
+	Content  string
 	Tasks    []Task
 	Phases   []Phase
 }
@@ -154,6 +198,7 @@ func loadTasksForMode(mode loop.ProjectMode) PlanFileInfo {
 	tasks := plan.ParseTasks(string(data))
 	return PlanFileInfo{
 		Filename: planFile,
+		Content:  string(data),
 		Tasks:    tasks,
 		Phases:   phases,
 	}
@@ -167,11 +212,16 @@ func (p *Program) Run() error {
 		tea.WithMouseCellMotion(), // Enable mouse support
 	)
 
-	// Set up controller event callback to send messages to the TUI
+	// Forward controller events to the TUI. The subscription buffers them so a
+	// busy render never stalls the loop; under pressure the oldest are dropped.
 	if p.controller != nil {
-		p.controller.SetEventCallback(func(event loop.LoopEvent) {
-			program.Send(msg.ControllerEventMsg{Event: event})
-		})
+		sub := p.controller.Subscribe(eventBufferSize, loop.DropOldest)
+		defer sub.Unsubscribe()
+		go func() {
+			for event := range sub.Events() {
+				program.Send(msg.ControllerEventMsg{Event: event})
+			}
+		}()
 	}
 
 	_, err := program.Run()
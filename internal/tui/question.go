@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// handleQuestionKey edits the answer input while the question screen is open.
+// Enter sends the answer and resumes the loop; Esc hides the prompt and leaves
+// the loop paused (press p to resume without answering, or ! to reopen).
+func (m Model) handleQuestionKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		answer := strings.TrimSpace(string(m.answerInput))
+		m.escalation = nil
+		m.answerInput = nil
+		m.screen = ScreenSplit
+		m.state = StateRunning
+		if answer != "" {
+			m.addLog(string(loop.LogLevelInfo), fmt.Sprintf("Answer sent: %s", answer))
+		}
+		if m.controller != nil {
+			ctrl := m.controller
+			return m, m.controls.Send("answer", func() { ctrl.Answer(answer) })
+		}
+	case tea.KeyEsc:
+		m.screen = ScreenSplit
+	case tea.KeyBackspace:
+		if len(m.answerInput) > 0 {
+			m.answerInput = m.answerInput[:len(m.answerInput)-1]
+		}
+	case tea.KeySpace:
+		m.answerInput = append(m.answerInput, ' ')
+	case tea.KeyRunes:
+		m.answerInput = append(m.answerInput, msg.Runes...)
+	}
+	return m, nil
+}
+
+// renderQuestionView renders the agent's blockers/questions with an answer input
+func (m Model) renderQuestionView() string {
+	width := m.width
+	if width < 60 {
+		width = 60
+	}
+	height := m.height
+	if height < 20 {
+		height = 20
+	}
+
+	const headerHeight = 1
+	const footerHeight = 1
+
+	header := m.renderHeader(width)
+
+	var lines []string
+	lines = append(lines, "")
+	lines = append(lines, StyleWarningMsg.Render(" "+IconWarning+" Agent needs input"))
+	lines = append(lines, "")
+	lines = append(lines, StyleDivider.Render(strings.Repeat(DividerChar, width-4)))
+	lines = append(lines, "")
+	if m.escalation != nil {
+		for _, line := range strings.Split(strings.TrimRight(m.escalation.Prompt(), "\n"), "\n") {
+			lines = append(lines, StyleTextBase.Render(" "+line))
+		}
+	}
+	lines = append(lines, "")
+	lines = append(lines, StyleDividerSubtle.Render(strings.Repeat(DividerCharSubtle, width-4)))
+	lines = append(lines, "")
+	lines = append(lines, StyleHelpKey.Render(" > ")+StyleTextSelected.Render(string(m.answerInput))+StyleSpinnerActive.Render("█"))
+
+	middleHeight := height - headerHeight - footerHeight - 2
+	if middleHeight < 10 {
+		middleHeight = 10
+	}
+
+	middleContainer := lipgloss.NewStyle().
+		Width(width).
+		Height(middleHeight).
+		Render(strings.Join(lines, "\n"))
+
+	footer := StyleFooter.Width(width).Render(
+		fmt.Sprintf(" %s send answer%s%s hide",
+			StyleHelpKey.Render("enter"),
+			StyleTextSubtle.Render(MetaDotSeparator),
+			StyleHelpKey.Render("esc")),
+	)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		header,
+		middleContainer,
+		footer,
+	)
+}
@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/brainwhocodes/lisa-loop/internal/analysis"
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	tuimsg "github.com/brainwhocodes/lisa-loop/internal/tui/msg"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type answerRecorder struct {
+	fakeController
+	answers []string
+}
+
+func (a *answerRecorder) Answer(answer string) { a.answers = append(a.answers, answer) }
+
+func TestQuestionScreen_AnswerResumesLoop(t *testing.T) {
+	ctrl := &answerRecorder{}
+	var model tea.Model = Model{state: StateRunning, controller: ctrl}
+
+	model, _ = model.Update(tuimsg.ControllerEventMsg{Event: loop.LoopEvent{
+		Type:       loop.EventTypeEscalation,
+		Escalation: &analysis.Escalation{Questions: []string{"Which port?"}},
+	}})
+	m := model.(Model)
+	if m.screen != ScreenQuestion || m.state != StatePaused {
+		t.Fatalf("escalation event: screen = %v, state = %v, want question screen and paused", m.screen, m.state)
+	}
+
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("Use")},
+		{Type: tea.KeySpace},
+		{Type: tea.KeyRunes, Runes: []rune("80800")},
+		{Type: tea.KeyBackspace},
+		// "q" is typed into the answer rather than quitting
+		{Type: tea.KeyRunes, Runes: []rune("q")},
+		{Type: tea.KeyBackspace},
+	} {
+		model, _ = model.Update(key)
+	}
+	if got := string(model.(Model).answerInput); got != "Use 8080" {
+		t.Fatalf("answerInput = %q, want %q", got, "Use 8080")
+	}
+
+	model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(Model)
+	if len(ctrl.answers) != 0 {
+		t.Fatalf("Answer() called inside Update: %v", ctrl.answers)
+	}
+	runControl(t, cmd)
+	if len(ctrl.answers) != 1 || ctrl.answers[0] != "Use 8080" {
+		t.Errorf("controller answers = %v, want [Use 8080]", ctrl.answers)
+	}
+	if m.screen != ScreenSplit || m.state != StateRunning || m.escalation != nil {
+		t.Errorf("after enter: screen = %v, state = %v, escalation = %v", m.screen, m.state, m.escalation)
+	}
+}
+
+func TestQuestionScreen_EscHidesAndBangReopens(t *testing.T) {
+	var model tea.Model = Model{
+		state:      StatePaused,
+		screen:     ScreenQuestion,
+		escalation: &analysis.Escalation{Blockers: []string{"No credentials"}},
+		controller: &answerRecorder{},
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if model.(Model).screen != ScreenSplit {
+		t.Fatalf("esc: screen = %v, want split", model.(Model).screen)
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("!")})
+	if model.(Model).screen != ScreenQuestion {
+		t.Errorf("!: screen = %v, want question", model.(Model).screen)
+	}
+
+	if view := model.(Model).View(); view == "" {
+		t.Error("View() is empty on question screen")
+	}
+}
+
+func TestQuestionScreen_ResumeAnswersPendingEscalation(t *testing.T) {
+	ctrl := &answerRecorder{}
+	var model tea.Model = Model{
+		state:      StatePaused,
+		screen:     ScreenQuestion,
+		escalation: &analysis.Escalation{Questions: []string{"Which port?"}},
+		controller: ctrl,
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	runControl(t, cmd)
+	m := model.(Model)
+	if len(ctrl.answers) != 1 || ctrl.answers[0] != "" {
+		t.Errorf("controller answers = %q, want one empty answer", ctrl.answers)
+	}
+	if m.state != StateRunning || m.escalation != nil {
+		t.Errorf("after p: state = %v, escalation = %v, want running with none", m.state, m.escalation)
+	}
+}
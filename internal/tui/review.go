@@ -0,0 +1,245 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/effects"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/keymap"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Diff line styles for the review screen, set by applyTheme
+var styleDiffAdd, styleDiffDelete, styleDiffHunk lipgloss.Style
+
+// applyReview opens the review screen for a pending review and closes it
+// once the review is decided
+func (m *Model) applyReview(r *loop.Review) tea.Cmd {
+	if r.Decision == "" {
+		m.review = r
+		m.reviewScroll = 0
+		m.reviewFeedback = nil
+		m.reviewRejecting = false
+		m.state = StatePaused
+		m.screen = ScreenReview
+		return nil
+	}
+
+	m.review = nil
+	if m.screen == ScreenReview {
+		m.screen = ScreenSplit
+	}
+	// The working tree was committed or reverted
+	if r.Decision == loop.ReviewReject {
+		return tea.Batch(m.triggerDiffRefresh(), m.snapshotWorkTree(snapshotPurpose{kind: snapshotResync}))
+	}
+	return m.triggerDiffRefresh()
+}
+
+// handleReviewKey drives the review screen: a approves, r asks for feedback
+// and rejects, e opens the plan in an editor and Esc hides the screen with
+// the loop still paused (v reopens it), with the keys as the keymap binds
+// them. Typing feedback takes every key.
+func (m Model) handleReviewKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.reviewRejecting {
+		switch msg.Type {
+		case tea.KeyEnter:
+			return m.decideReview(loop.ReviewReject, strings.TrimSpace(string(m.reviewFeedback)))
+		case tea.KeyEsc:
+			m.reviewRejecting = false
+			m.reviewFeedback = nil
+		case tea.KeyBackspace:
+			if len(m.reviewFeedback) > 0 {
+				m.reviewFeedback = m.reviewFeedback[:len(m.reviewFeedback)-1]
+			}
+		case tea.KeySpace:
+			m.reviewFeedback = append(m.reviewFeedback, ' ')
+		case tea.KeyRunes:
+			m.reviewFeedback = append(m.reviewFeedback, msg.Runes...)
+		}
+		return m, nil
+	}
+
+	switch m.keymap().Lookup(keymap.ScopeReview, msg.String()) {
+	case keymap.ReviewApprove:
+		return m.decideReview(loop.ReviewApprove, "")
+	case keymap.ReviewReject:
+		m.reviewRejecting = true
+	case keymap.ReviewEdit:
+		if m.planFile != "" {
+			return m, effects.EditPlan(m.planFile)
+		}
+		m.addLog(string(loop.LogLevelWarn), "No plan file to edit")
+	case keymap.ReviewClose:
+		m.screen = ScreenSplit
+	case keymap.ReviewUp:
+		if m.reviewScroll > 0 {
+			m.reviewScroll--
+		}
+	case keymap.ReviewDown:
+		m.reviewScroll = min(m.reviewScroll+1, m.maxReviewScroll())
+	case keymap.ReviewPageUp:
+		m.reviewScroll -= 10
+		if m.reviewScroll < 0 {
+			m.reviewScroll = 0
+		}
+	case keymap.ReviewPageDown:
+		m.reviewScroll = min(m.reviewScroll+10, m.maxReviewScroll())
+	}
+	return m, nil
+}
+
+// reviewViewSize is the review screen's width and the height of the part
+// showing the review
+func (m Model) reviewViewSize() (width, middleHeight int) {
+	width = max(m.width, 60)
+	height := max(m.height, 20)
+	// Header, footer and the blank lines around them
+	return width, max(height-4, 10)
+}
+
+// maxReviewScroll is the furthest the review can scroll with its last line
+// still at the bottom
+func (m Model) maxReviewScroll() int {
+	if m.review == nil {
+		return 0
+	}
+	width, middleHeight := m.reviewViewSize()
+	return max(0, len(m.reviewLines(width))-middleHeight)
+}
+
+// decideReview sends the decision in a command and returns to the split
+// view; the controller's review event confirms it
+func (m Model) decideReview(decision loop.ReviewDecision, feedback string) (tea.Model, tea.Cmd) {
+	m.reviewRejecting = false
+	m.reviewFeedback = nil
+	m.screen = ScreenSplit
+	m.state = StateRunning
+	if m.controller != nil {
+		ctrl := m.controller
+		return m, m.controls.Send("review", func() { ctrl.Review(decision, feedback) })
+	}
+	return m, nil
+}
+
+// reviewLines renders the review's file list and its patch, grouped per file
+func (m Model) reviewLines(width int) []string {
+	r := m.review
+	var lines []string
+	title := fmt.Sprintf(" %s Review loop %d", IconInfo, r.Loop)
+	if r.Task != "" {
+		title += ": " + r.Task
+	}
+	lines = append(lines, "", StyleInfoMsg.Render(clipRunes(title, width-2)), "")
+	lines = append(lines, StyleDivider.Render(strings.Repeat(DividerChar, width-4)), "")
+	for _, f := range r.Files {
+		lines = append(lines, StyleTextBase.Render(clipRunes(fmt.Sprintf("  %s %s", f.Status, f.Path), width-2)))
+	}
+
+	sections := splitPatch(r.Patch)
+	for _, f := range r.Files {
+		lines = append(lines, "", StyleDividerSubtle.Render(strings.Repeat(DividerCharSubtle, width-4)))
+		lines = append(lines, StyleTextSelected.Render(clipRunes(fmt.Sprintf(" %s (%s)", f.Path, f.Status), width-2)))
+		body, ok := sections[f.Path]
+		if !ok {
+			lines = append(lines, StyleTextSubtle.Render("  new untracked file"))
+			continue
+		}
+		for _, line := range body {
+			lines = append(lines, styleDiffLine(clipRunes(line, width-2)))
+		}
+	}
+	return lines
+}
+
+// splitPatch splits a unified diff into hunks keyed by file path, dropping
+// the per-file headers
+func splitPatch(patch string) map[string][]string {
+	sections := map[string][]string{}
+	path := ""
+	inHunks := false
+	for _, line := range strings.Split(patch, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			if i := strings.LastIndex(line, " b/"); i >= 0 {
+				path = line[i+3:]
+			}
+			sections[path] = nil
+			inHunks = false
+			continue
+		}
+		if strings.HasPrefix(line, "@@") {
+			inHunks = true
+		}
+		if inHunks && path != "" {
+			sections[path] = append(sections[path], line)
+		}
+	}
+	return sections
+}
+
+func styleDiffLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "+"):
+		return styleDiffAdd.Render(line)
+	case strings.HasPrefix(line, "-"):
+		return styleDiffDelete.Render(line)
+	case strings.HasPrefix(line, "@@"):
+		return styleDiffHunk.Render(line)
+	}
+	return StyleTextMuted.Render(line)
+}
+
+// clipRunes shortens s to at most n runes
+func clipRunes(s string, n int) string {
+	s = strings.ReplaceAll(s, "\t", "    ")
+	runes := []rune(s)
+	if n < 1 || len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// renderReviewView renders the iteration awaiting review
+func (m Model) renderReviewView() string {
+	width, middleHeight := m.reviewViewSize()
+	header := m.renderHeader(width)
+
+	var lines []string
+	if m.review != nil {
+		lines = m.reviewLines(width)
+	}
+	if scroll := min(m.reviewScroll, len(lines)-middleHeight); scroll > 0 {
+		lines = lines[scroll:]
+	}
+	if len(lines) > middleHeight {
+		lines = lines[:middleHeight]
+	}
+
+	middleContainer := lipgloss.NewStyle().
+		Width(width).
+		Height(middleHeight).
+		Render(strings.Join(lines, "\n"))
+
+	var footer string
+	if m.reviewRejecting {
+		footer = StyleFooter.Width(width).Render(
+			StyleHelpKey.Render(" feedback > ") + StyleTextSelected.Render(string(m.reviewFeedback)) + StyleSpinnerActive.Render("█") +
+				StyleTextSubtle.Render("  enter reject · esc back"))
+	} else {
+		footer = StyleFooter.Width(width).Render(footerHints(
+			m.hint("approve", keymap.ReviewApprove),
+			m.hint("reject", keymap.ReviewReject),
+			m.hint("edit plan", keymap.ReviewEdit),
+			m.hint("scroll", keymap.ReviewDown, keymap.ReviewUp),
+			m.hint("hide", keymap.ReviewClose),
+		))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		header,
+		middleContainer,
+		footer,
+	)
+}
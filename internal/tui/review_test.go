@@ -0,0 +1,149 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	tuimsg "github.com/brainwhocodes/lisa-loop/internal/tui/msg"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type reviewRecorder struct {
+	fakeController
+	decisions []string
+}
+
+func (r *reviewRecorder) Review(decision loop.ReviewDecision, feedback string) {
+	r.decisions = append(r.decisions, string(decision)+": "+feedback)
+}
+
+func pendingReview() *loop.Review {
+	return &loop.Review{
+		Loop: 3,
+		Task: "Add login",
+		Files: []loop.ChangedFile{
+			{Path: "login.go", Status: "M"},
+			{Path: "login_test.go", Status: "?"},
+		},
+		Patch: "diff --git a/login.go b/login.go\nindex 1..2 100644\n--- a/login.go\n+++ b/login.go\n@@ -1 +1 @@\n-old\n+new",
+	}
+}
+
+func reviewEvent(r *loop.Review) tea.Msg {
+	return tuimsg.ControllerEventMsg{Event: loop.LoopEvent{Type: loop.EventTypeReview, Review: r}}
+}
+
+func TestReviewScreen_Approve(t *testing.T) {
+	ctrl := &reviewRecorder{}
+	var model tea.Model = Model{state: StateRunning, controller: ctrl, width: 100, height: 40}
+
+	model, _ = model.Update(reviewEvent(pendingReview()))
+	m := model.(Model)
+	if m.screen != ScreenReview || m.state != StatePaused {
+		t.Fatalf("review event: screen = %v, state = %v, want review screen and paused", m.screen, m.state)
+	}
+
+	view := m.View()
+	for _, want := range []string{"Review loop 3: Add login", "login.go (M)", "+new", "login_test.go (?)", "new untracked file"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() missing %q", want)
+		}
+	}
+	if strings.Contains(view, "+++ b/login.go") {
+		t.Error("View() shows the per-file patch header")
+	}
+
+	// Esc hides the review; v brings it back
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+	if model.(Model).screen != ScreenReview {
+		t.Fatalf("v: screen = %v, want review", model.(Model).screen)
+	}
+
+	model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	runControl(t, cmd)
+	if len(ctrl.decisions) != 1 || ctrl.decisions[0] != "approve: " {
+		t.Errorf("controller decisions = %v, want [approve: ]", ctrl.decisions)
+	}
+	if m := model.(Model); m.screen != ScreenSplit || m.state != StateRunning {
+		t.Errorf("after a: screen = %v, state = %v", m.screen, m.state)
+	}
+
+	decided := pendingReview()
+	decided.Decision = loop.ReviewApprove
+	model, _ = model.Update(reviewEvent(decided))
+	if model.(Model).review != nil {
+		t.Error("review still pending after the decision event")
+	}
+}
+
+func TestReviewScreen_RejectWithFeedback(t *testing.T) {
+	ctrl := &reviewRecorder{}
+	var model tea.Model = Model{state: StateRunning, controller: ctrl}
+	model, _ = model.Update(reviewEvent(pendingReview()))
+
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("r")},
+		// "a" is typed into the feedback rather than approving
+		{Type: tea.KeyRunes, Runes: []rune("Keep")},
+		{Type: tea.KeySpace},
+		{Type: tea.KeyRunes, Runes: []rune("a")},
+		{Type: tea.KeySpace},
+		{Type: tea.KeyRunes, Runes: []rune("session")},
+	} {
+		model, _ = model.Update(key)
+	}
+	if got := string(model.(Model).reviewFeedback); got != "Keep a session" {
+		t.Fatalf("reviewFeedback = %q, want %q", got, "Keep a session")
+	}
+	if len(ctrl.decisions) != 0 {
+		t.Fatalf("decision sent before enter: %v", ctrl.decisions)
+	}
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	runControl(t, cmd)
+	if len(ctrl.decisions) != 1 || ctrl.decisions[0] != "reject: Keep a session" {
+		t.Errorf("controller decisions = %v, want the rejection with feedback", ctrl.decisions)
+	}
+}
+
+func TestReviewScreen_ScrollStopsAtEnd(t *testing.T) {
+	review := pendingReview()
+	for i := 0; i < 40; i++ {
+		review.Patch += "\n+line"
+	}
+	var model tea.Model = Model{state: StateRunning, controller: &reviewRecorder{}, width: 100, height: 30}
+	model, _ = model.Update(reviewEvent(review))
+	end := model.(Model).maxReviewScroll()
+	if end <= 0 {
+		t.Fatalf("maxReviewScroll() = %d, want a review taller than the screen", end)
+	}
+
+	for i := 0; i < 10; i++ {
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	}
+	if got := model.(Model).reviewScroll; got != end {
+		t.Fatalf("scrolled past the end: reviewScroll = %d, want %d", got, end)
+	}
+
+	// Up moves at once rather than working off the overshoot
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")})
+	if got := model.(Model).reviewScroll; got != end-1 {
+		t.Errorf("after k: reviewScroll = %d, want %d", got, end-1)
+	}
+}
+
+func TestSplitPatch(t *testing.T) {
+	patch := "diff --git a/a.go b/a.go\nindex 1..2\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-x\n+y\n" +
+		"diff --git a/b.go b/b.go\nnew file mode 100644\n--- /dev/null\n+++ b/b.go\n@@ -0,0 +1 @@\n+z"
+
+	sections := splitPatch(patch)
+	if got := strings.Join(sections["a.go"], "|"); got != "@@ -1 +1 @@|-x|+y" {
+		t.Errorf("splitPatch() a.go = %q", got)
+	}
+	if got := strings.Join(sections["b.go"], "|"); got != "@@ -0,0 +1 @@|+z" {
+		t.Errorf("splitPatch() b.go = %q", got)
+	}
+}
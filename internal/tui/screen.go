@@ -11,4 +11,8 @@ const (
 	ScreenLogs
 	ScreenHelp
 	ScreenCircuit
+	ScreenQuestion // Agent blocker/question awaiting an answer
+	ScreenSteer    // Operator guidance input
+	ScreenReview   // Iteration changes awaiting approval
+	ScreenHistory  // Past runs from the run journal
 )
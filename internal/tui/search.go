@@ -0,0 +1,316 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/keymap"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/transcript"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Search match styles, set by applyTheme
+var styleSearchMatch, styleSearchFocused lipgloss.Style
+
+// searchView is a view "/" search works in
+type searchView int
+
+const (
+	searchNone searchView = iota
+	searchLogs
+	searchTranscript
+	searchReasoning
+)
+
+// currentSearchView is the searchable view on screen, if any
+func (m Model) currentSearchView() searchView {
+	switch {
+	case m.screen == ScreenLogs:
+		return searchLogs
+	case m.screen == ScreenOutput && m.outputTab == OutputTabTranscript:
+		return searchTranscript
+	case m.screen == ScreenOutput && m.outputTab == OutputTabReasoning:
+		return searchReasoning
+	}
+	return searchNone
+}
+
+// textSearch is an incremental search through one view's rows. It stays
+// with the view it was started in.
+type textSearch struct {
+	view    searchView
+	query   string
+	typing  bool
+	focused int // Index of the focused match
+}
+
+// queryFor is the search's query if it belongs to view
+func (s textSearch) queryFor(view searchView) string {
+	if s.view != view {
+		return ""
+	}
+	return s.query
+}
+
+// logLevelFilters are the levels f cycles the logs view through, "" for all
+var logLevelFilters = []string{
+	"",
+	string(loop.LogLevelError),
+	string(loop.LogLevelWarn),
+	string(loop.LogLevelInfo),
+	string(loop.LogLevelSuccess),
+	string(loop.LogLevelDebug),
+}
+
+// transcriptFilter narrows the transcript to one sort of item
+type transcriptFilter int
+
+const (
+	transcriptAll transcriptFilter = iota
+	transcriptTools
+	transcriptErrors
+	transcriptMessages
+)
+
+func (f transcriptFilter) String() string {
+	switch f {
+	case transcriptTools:
+		return "tool calls"
+	case transcriptErrors:
+		return "errors"
+	case transcriptMessages:
+		return "messages"
+	default:
+		return "all"
+	}
+}
+
+func (f transcriptFilter) match(it transcript.Item) bool {
+	switch f {
+	case transcriptTools:
+		return it.Kind == transcript.KindToolCall || it.Role == transcript.RoleTool
+	case transcriptErrors:
+		return it.Kind == transcript.KindError
+	case transcriptMessages:
+		return it.Kind == transcript.KindMessage
+	default:
+		return true
+	}
+}
+
+// handleSearchInputKey edits the query while it is being typed. Matches
+// update with every key; Enter keeps the search, Esc drops it.
+func (m Model) handleSearchInputKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.search.typing = false
+		if m.search.query == "" {
+			m.search = textSearch{}
+		}
+		return m, nil
+	case tea.KeyEsc:
+		m.search = textSearch{}
+		return m, nil
+	case tea.KeyBackspace:
+		if r := []rune(m.search.query); len(r) > 0 {
+			m.search.query = string(r[:len(r)-1])
+		}
+	case tea.KeySpace:
+		m.search.query += " "
+	case tea.KeyRunes:
+		m.search.query += string(msg.Runes)
+	default:
+		return m, nil
+	}
+	// Start from the latest match, where the view was looking
+	m.search.focused = len(searchMatches(m.searchRows(m.search.view), m.search.query)) - 1
+	return m, nil
+}
+
+// handleSearchKey starts a search, steps through its matches and cycles the
+// view's filter. It reports whether the key was used.
+func (m *Model) handleSearchKey(view searchView, key string) bool {
+	query := m.search.queryFor(view)
+	action := m.keymap().Lookup(keymap.ScopeSearch, key)
+	if query != "" {
+		if match := m.keymap().Lookup(keymap.ScopeMatch, key); match != "" {
+			action = match
+		}
+	}
+	switch {
+	case action == keymap.SearchStart:
+		m.search = textSearch{view: view, typing: true}
+	case action == keymap.SearchFilter && view == searchLogs:
+		m.logFilter = (m.logFilter + 1) % len(logLevelFilters)
+	case action == keymap.SearchFilter && view == searchTranscript:
+		m.transcriptFilter = (m.transcriptFilter + 1) % (transcriptMessages + 1)
+	case action == keymap.SearchNext || action == keymap.SearchPrev:
+		matches := len(searchMatches(m.searchRows(view), query))
+		if matches == 0 {
+			return true
+		}
+		if action == keymap.SearchNext {
+			m.search.focused = (m.search.focused + 1) % matches
+		} else {
+			m.search.focused = (m.search.focused - 1 + matches) % matches
+		}
+	case action == keymap.SearchClear:
+		m.search = textSearch{}
+	default:
+		return false
+	}
+	return true
+}
+
+// searchRows are the rows a view shows, filtered, before scrolling
+func (m Model) searchRows(view searchView) []string {
+	var rows []string
+	switch view {
+	case searchLogs:
+		level := logLevelFilters[m.logFilter]
+		for _, entry := range m.logs {
+			if level == "" || entry.Level == level {
+				rows = append(rows, " "+entry.Text)
+			}
+		}
+	case searchTranscript:
+		if m.transcript == nil {
+			return nil
+		}
+		for _, it := range m.transcript.Items() {
+			if m.transcriptFilter.match(it) {
+				rows = append(rows, " "+m.transcriptRow(it))
+			}
+		}
+	case searchReasoning:
+		reasoning := m.currentReasoning
+		if reasoning == "" && len(m.reasoningLines) > 0 {
+			reasoning = m.reasoningLines[len(m.reasoningLines)-1]
+		}
+		if reasoning == "" {
+			return nil
+		}
+		width, _ := m.outputContentSize()
+		rows = strings.Split(ansi.Wrap(reasoning, width-2, ""), "\n")
+	}
+	return rows
+}
+
+// searchMatches lists the rows containing query, ignoring case
+func searchMatches(rows []string, query string) []int {
+	if query == "" {
+		return nil
+	}
+	query = strings.ToLower(query)
+	var matches []int
+	for i, row := range rows {
+		if strings.Contains(strings.ToLower(ansi.Strip(row)), query) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// renderSearchRows fits a view's rows into height lines. Without a search
+// that is the tail; with one it is the rows around the focused match, with
+// every match highlighted.
+func (m Model) renderSearchRows(view searchView, rows []string, width, height int) []string {
+	query := m.search.queryFor(view)
+	matches := searchMatches(rows, query)
+	focusRow := -1
+	if len(matches) > 0 {
+		focusRow = matches[max(0, min(m.search.focused, len(matches)-1))]
+	}
+
+	start := max(0, len(rows)-height)
+	if focusRow >= 0 {
+		start = max(0, min(focusRow-height/2, len(rows)-height))
+	}
+	end := min(len(rows), start+height)
+
+	lines := make([]string, 0, end-start)
+	matched := make(map[int]bool, len(matches))
+	for _, i := range matches {
+		matched[i] = true
+	}
+	for i := start; i < end; i++ {
+		row := rows[i]
+		if matched[i] {
+			row = highlightMatches(ansi.Strip(row), query, i == focusRow)
+		}
+		lines = append(lines, ansi.Truncate(row, width, "…"))
+	}
+	return lines
+}
+
+// highlightMatches marks each occurrence of query in plain text
+func highlightMatches(text, query string, focused bool) string {
+	matchStyle := styleSearchMatch
+	if focused {
+		matchStyle = styleSearchFocused
+	}
+	lower := strings.ToLower(text)
+	q := strings.ToLower(query)
+	if len(lower) != len(text) {
+		// Lowercasing changed byte offsets; fall back to an exact match
+		lower, q = text, query
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, q)
+		if i < 0 {
+			b.WriteString(StyleTextBase.Render(text))
+			return b.String()
+		}
+		b.WriteString(StyleTextBase.Render(text[:i]))
+		b.WriteString(matchStyle.Render(text[i : i+len(q)]))
+		text, lower = text[i+len(q):], lower[i+len(q):]
+	}
+}
+
+// searchFooter describes the view's search and filter for its footer, or
+// offers them
+func (m Model) searchFooter(view searchView) []string {
+	var parts []string
+	if query := m.search.queryFor(view); query != "" || (m.search.typing && m.search.view == view) {
+		text := StyleHelpKey.Render("/") + StyleTextSelected.Render(query)
+		if m.search.typing {
+			text += StyleSpinnerActive.Render("█")
+		}
+		matches := len(searchMatches(m.searchRows(view), query))
+		switch {
+		case query == "":
+		case matches == 0:
+			text += " " + StyleErrorMsg.Render("no matches")
+		default:
+			text += " " + StyleTextMuted.Render(fmt.Sprintf("%d/%d", max(0, min(m.search.focused, matches-1))+1, matches))
+		}
+		parts = append(parts, text)
+		switch {
+		case m.search.typing:
+			parts = append(parts, StyleHelpKey.Render("esc")+" clear")
+		case matches > 0:
+			parts = append(parts, m.hint("next/prev", keymap.SearchNext, keymap.SearchPrev), m.hint("clear", keymap.SearchClear))
+		default:
+			parts = append(parts, m.hint("clear", keymap.SearchClear))
+		}
+	} else {
+		parts = append(parts, m.hint("search", keymap.SearchStart))
+	}
+
+	switch view {
+	case searchLogs:
+		filter := "all"
+		if level := logLevelFilters[m.logFilter]; level != "" {
+			filter = strings.ToLower(level)
+		}
+		parts = append(parts, m.hint("level: "+filter, keymap.SearchFilter))
+	case searchTranscript:
+		parts = append(parts, m.hint("show: "+m.transcriptFilter.String(), keymap.SearchFilter))
+	}
+	return parts
+}
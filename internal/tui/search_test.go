@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/transcript"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// typeKeys sends each key in turn; single characters are typed as runes
+func typeKeys(model tea.Model, keys ...string) tea.Model {
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "backspace":
+			msg = tea.KeyMsg{Type: tea.KeyBackspace}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		model, _ = model.Update(msg)
+	}
+	return model
+}
+
+func TestSearch_Logs(t *testing.T) {
+	m := Model{screen: ScreenLogs, width: 100, height: 30}
+	m.addLog(string(loop.LogLevelInfo), "Loop 1 started")
+	m.addLog(string(loop.LogLevelError), "Disk full writing plan")
+	m.addLog(string(loop.LogLevelWarn), "disk nearly full")
+	m.addLog(string(loop.LogLevelError), "Backend timed out")
+
+	model := typeKeys(m, "/", "d", "i", "s", "k")
+	got := model.(Model)
+	if !got.search.typing || got.search.query != "disk" || got.search.focused != 1 {
+		t.Fatalf("search after typing = %+v", got.search)
+	}
+	view := ansi.Strip(got.renderLogsFullView())
+	if !strings.Contains(view, "/disk█ 2/2") {
+		t.Errorf("footer does not show the query and match count:\n%s", view)
+	}
+
+	// n wraps from the latest match to the first; the search outranks skip-task
+	model = typeKeys(model, "enter", "n")
+	if got := model.(Model); got.search.typing || got.search.focused != 0 {
+		t.Errorf("search after enter, n = %+v", got.search)
+	}
+	if view := ansi.Strip(model.(Model).renderLogsFullView()); !strings.Contains(view, "1/2") || !strings.Contains(view, "n N next/prev") {
+		t.Errorf("footer after n:\n%s", view)
+	}
+
+	// Errors only: one match left
+	model = typeKeys(model, "f")
+	view = ansi.Strip(model.(Model).renderLogsFullView())
+	if strings.Contains(view, "Loop 1 started") || strings.Contains(view, "nearly full") || !strings.Contains(view, "Backend timed out") {
+		t.Errorf("error filter shows other levels:\n%s", view)
+	}
+	if !strings.Contains(view, "level: error") || !strings.Contains(view, "1/1") || !strings.Contains(view, "2/4 entries") {
+		t.Errorf("footer does not describe the filter:\n%s", view)
+	}
+
+	model = typeKeys(model, "esc")
+	if got := model.(Model); got.search.query != "" || got.logFilter != 1 {
+		t.Errorf("esc should clear the search but keep the filter: %+v, filter %d", got.search, got.logFilter)
+	}
+}
+
+func TestSearch_TranscriptScrollsToFocusedMatch(t *testing.T) {
+	buf := transcript.New(500)
+	for i := 0; i < 40; i++ {
+		buf.Append(transcript.Item{Role: transcript.RoleAssistant, Kind: transcript.KindMessage, Body: fmt.Sprintf("message %d", i)})
+		if i == 3 {
+			buf.Append(transcript.Item{Role: transcript.RoleTool, Kind: transcript.KindToolCall, Body: "go test ./... needle"})
+		}
+	}
+	m := Model{screen: ScreenOutput, outputTab: OutputTabTranscript, transcript: buf, width: 100, height: 24}
+
+	if tab := m.renderTranscriptTab(100, 10); strings.Contains(tab, "needle") {
+		t.Fatalf("early item visible without a search:\n%s", tab)
+	}
+	model := typeKeys(m, "/", "N", "E", "E", "D", "L", "E", "enter")
+	tab := model.(Model).renderTranscriptTab(100, 10)
+	if !strings.Contains(tab, "go test ./... needle") {
+		t.Errorf("transcript did not scroll to the match:\n%s", tab)
+	}
+
+	// The search belongs to the transcript, not the reasoning tab
+	model = typeKeys(model, "]", "]")
+	if got := model.(Model); got.search.queryFor(got.currentSearchView()) != "" {
+		t.Errorf("search followed the user to another tab")
+	}
+	model = typeKeys(model, "[", "[")
+
+	model = typeKeys(model, "f")
+	tab = ansi.Strip(model.(Model).renderTranscriptTab(100, 10))
+	if strings.Contains(tab, "message") || !strings.Contains(tab, "go test ./... needle") {
+		t.Errorf("tool call filter:\n%s", tab)
+	}
+	model = typeKeys(model, "f")
+	if tab := ansi.Strip(model.(Model).renderTranscriptTab(100, 10)); !strings.Contains(tab, "No errors yet.") {
+		t.Errorf("error filter on a transcript without errors:\n%s", tab)
+	}
+}
+
+func TestSearch_ReasoningShowsWholeText(t *testing.T) {
+	reasoning := strings.Repeat("thinking about it. ", 100) + "The answer is in plan.go"
+	m := Model{screen: ScreenOutput, outputTab: OutputTabReasoning, currentReasoning: reasoning, width: 80, height: 24}
+
+	model := typeKeys(m, "/", "p", "l", "a", "n", ".", "g", "o")
+	tab := model.(Model).renderReasoningTab(80, 10)
+	if !strings.Contains(tab, "The answer is in plan.go") {
+		t.Errorf("reasoning search did not find text past the collapsed length:\n%s", tab)
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	got := highlightMatches("Disk full, disk", "DISK", false)
+	want := StyleTextBase.Render("") + styleSearchMatch.Render("Disk") + StyleTextBase.Render(" full, ") +
+		styleSearchMatch.Render("disk") + StyleTextBase.Render("")
+	if got != want {
+		t.Errorf("highlightMatches() = %q, want %q", got, want)
+	}
+	if got := searchMatches([]string{"a", StyleErrorMsg.Render("× Disk"), "disk"}, "disk"); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("searchMatches() = %v, want [1 2]", got)
+	}
+}
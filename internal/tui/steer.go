@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/transcript"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// handleSteerKey edits the guidance input while the steer screen is open.
+// Enter sends the message to the controller in a command (steering the
+// OpenCode backend is an HTTP call), Esc closes the box unsent.
+func (m Model) handleSteerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		message := strings.TrimSpace(string(m.steerInput))
+		m.steerInput = nil
+		m.screen = ScreenSplit
+		// The controller echoes the message back as a steering event,
+		// which is what records it in the transcript
+		if message != "" && m.controller != nil {
+			ctrl := m.controller
+			return m, m.controls.Send("steer", func() { ctrl.Steer(message) })
+		}
+	case tea.KeyEsc:
+		m.steerInput = nil
+		m.screen = ScreenSplit
+	case tea.KeyBackspace:
+		if len(m.steerInput) > 0 {
+			m.steerInput = m.steerInput[:len(m.steerInput)-1]
+		}
+	case tea.KeySpace:
+		m.steerInput = append(m.steerInput, ' ')
+	case tea.KeyRunes:
+		m.steerInput = append(m.steerInput, msg.Runes...)
+	}
+	return m, nil
+}
+
+// applySteering tracks the standing guidance and records each message in
+// the transcript as a user turn
+func (m *Model) applySteering(s *loop.Steering) {
+	if s.Cleared {
+		m.steering = nil
+		if m.transcript != nil {
+			m.transcript.Append(transcript.Item{
+				At:   time.Now(),
+				Role: transcript.RoleSystem,
+				Kind: transcript.KindNotice,
+				Body: "Standing guidance cleared",
+			})
+		}
+		return
+	}
+
+	m.steering = append(m.steering, s.Message)
+	if m.transcript != nil {
+		title := "guidance"
+		if s.Live {
+			title = "guidance (live)"
+		}
+		m.transcript.Append(transcript.Item{
+			At:    time.Now(),
+			Role:  transcript.RoleUser,
+			Kind:  transcript.KindMessage,
+			Title: title,
+			Body:  s.Message,
+		})
+	}
+}
+
+// renderSteerView renders the standing guidance with an input for adding more
+func (m Model) renderSteerView() string {
+	width := m.width
+	if width < 60 {
+		width = 60
+	}
+	height := m.height
+	if height < 20 {
+		height = 20
+	}
+
+	const headerHeight = 1
+	const footerHeight = 1
+
+	header := m.renderHeader(width)
+
+	var lines []string
+	lines = append(lines, "")
+	lines = append(lines, StyleInfoMsg.Render(" "+IconInfo+" Guide the agent"))
+	lines = append(lines, "")
+	lines = append(lines, StyleDivider.Render(strings.Repeat(DividerChar, width-4)))
+	lines = append(lines, "")
+	if len(m.steering) == 0 {
+		lines = append(lines, StyleTextSubtle.Render(" No standing guidance yet"))
+	} else {
+		lines = append(lines, StyleTextSubtle.Render(" Standing guidance (G clears it):"))
+		for _, message := range m.steering {
+			lines = append(lines, StyleTextBase.Render("  - "+message))
+		}
+	}
+	lines = append(lines, "")
+	lines = append(lines, StyleDividerSubtle.Render(strings.Repeat(DividerCharSubtle, width-4)))
+	lines = append(lines, "")
+	lines = append(lines, StyleHelpKey.Render(" > ")+StyleTextSelected.Render(string(m.steerInput))+StyleSpinnerActive.Render("█"))
+
+	middleHeight := height - headerHeight - footerHeight - 2
+	if middleHeight < 10 {
+		middleHeight = 10
+	}
+
+	middleContainer := lipgloss.NewStyle().
+		Width(width).
+		Height(middleHeight).
+		Render(strings.Join(lines, "\n"))
+
+	footer := StyleFooter.Width(width).Render(
+		fmt.Sprintf(" %s send guidance%s%s cancel",
+			StyleHelpKey.Render("enter"),
+			StyleTextSubtle.Render(MetaDotSeparator),
+			StyleHelpKey.Render("esc")),
+	)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		header,
+		middleContainer,
+		footer,
+	)
+}
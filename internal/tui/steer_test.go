@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	tuimsg "github.com/brainwhocodes/lisa-loop/internal/tui/msg"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/transcript"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type steerRecorder struct {
+	fakeController
+	messages []string
+	cleared  int
+}
+
+func (s *steerRecorder) Steer(message string) { s.messages = append(s.messages, message) }
+func (s *steerRecorder) ClearSteering()       { s.cleared++ }
+
+func TestSteerScreen_SendsGuidance(t *testing.T) {
+	ctrl := &steerRecorder{}
+	var model tea.Model = Model{state: StateRunning, controller: ctrl}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	if model.(Model).screen != ScreenSteer {
+		t.Fatalf("g: screen = %v, want steer", model.(Model).screen)
+	}
+	if view := model.(Model).View(); view == "" {
+		t.Error("View() is empty on steer screen")
+	}
+
+	for _, key := range []tea.KeyMsg{
+		// "q" and "p" are typed into the message rather than acting as keys
+		{Type: tea.KeyRunes, Runes: []rune("Skip")},
+		{Type: tea.KeySpace},
+		{Type: tea.KeyRunes, Runes: []rune("qa")},
+		{Type: tea.KeyBackspace},
+		{Type: tea.KeyRunes, Runes: []rune("p")},
+	} {
+		model, _ = model.Update(key)
+	}
+	if got := string(model.(Model).steerInput); got != "Skip qp" {
+		t.Fatalf("steerInput = %q, want %q", got, "Skip qp")
+	}
+
+	model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	runControl(t, cmd)
+	if len(ctrl.messages) != 1 || ctrl.messages[0] != "Skip qp" {
+		t.Errorf("controller messages = %v, want [Skip qp]", ctrl.messages)
+	}
+	if m := model.(Model); m.screen != ScreenSplit || m.state != StateRunning {
+		t.Errorf("after enter: screen = %v, state = %v", m.screen, m.state)
+	}
+
+	// Esc closes without sending
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("never mind")})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if model.(Model).screen != ScreenSplit || len(ctrl.messages) != 1 {
+		t.Errorf("esc: screen = %v, messages = %v", model.(Model).screen, ctrl.messages)
+	}
+}
+
+func TestSteeringEvents_RecordedAsUserItems(t *testing.T) {
+	ctrl := &steerRecorder{}
+	var model tea.Model = Model{state: StateRunning, controller: ctrl, transcript: transcript.New(10)}
+
+	// Nothing to clear yet
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	if ctrl.cleared != 0 {
+		t.Errorf("G without guidance: cleared = %d, want 0", ctrl.cleared)
+	}
+
+	for _, s := range []loop.Steering{
+		{Message: "Use tabs"},
+		{Message: "No new dependencies", Live: true},
+	} {
+		s := s
+		model, _ = model.Update(tuimsg.ControllerEventMsg{Event: loop.LoopEvent{Type: loop.EventTypeSteering, Steering: &s}})
+	}
+
+	m := model.(Model)
+	if len(m.steering) != 2 {
+		t.Fatalf("steering = %v, want 2 messages", m.steering)
+	}
+	items := m.transcript.Items()
+	if len(items) != 2 {
+		t.Fatalf("expected 2 transcript items, got %d: %#v", len(items), items)
+	}
+	for i, want := range []string{"Use tabs", "No new dependencies"} {
+		if items[i].Role != transcript.RoleUser || items[i].Body != want {
+			t.Errorf("item %d = %#v, want user message %q", i, items[i], want)
+		}
+	}
+
+	model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	runControl(t, cmd)
+	if ctrl.cleared != 1 {
+		t.Errorf("G: cleared = %d, want 1", ctrl.cleared)
+	}
+	model, _ = model.Update(tuimsg.ControllerEventMsg{Event: loop.LoopEvent{Type: loop.EventTypeSteering, Steering: &loop.Steering{Cleared: true}}})
+	if got := model.(Model).steering; len(got) != 0 {
+		t.Errorf("steering after clear = %v, want none", got)
+	}
+}
@@ -22,260 +22,321 @@ var (
 	BorderNormal  = lipgloss.NormalBorder()
 	BorderRounded = lipgloss.RoundedBorder()
 
-	// Subtle box style with Charmtone colors
-	StyleBox = lipgloss.NewStyle().
-			Border(BorderNormal, true, true, true, true).
-			BorderForeground(Charcoal).
-			Padding(1)
+	StyleBox, StyleBoxRounded, StyleBoxError lipgloss.Style
+)
 
-	StyleBoxRounded = lipgloss.NewStyle().
-			Border(BorderRounded, true, true, true, true).
-			BorderForeground(Charcoal).
-			Padding(1)
+// Divider styles - subtle horizontal lines
+var (
+	StyleDivider, StyleDividerSubtle lipgloss.Style
 
-	StyleBoxError = lipgloss.NewStyle().
-			Border(BorderNormal, true, true, true, true).
-			BorderForeground(Sriracha).
-			Padding(1)
+	DividerChar       = "─"
+	DividerCharSubtle = "┈"
 )
 
-// Divider styles - subtle horizontal lines
+// Progress bar styles
 var (
-	StyleDivider = lipgloss.NewStyle().
-			Foreground(Charcoal)
+	StyleProgressEmpty, StyleProgressFilled, StyleProgressBar lipgloss.Style
+)
 
-	DividerChar = "─"
+// Circuit breaker styles
+var (
+	StyleCircuitClosed, StyleCircuitHalfOpen, StyleCircuitOpen lipgloss.Style
+)
 
-	StyleDividerSubtle = lipgloss.NewStyle().
-				Foreground(Iron)
+// Error panel styles
+var (
+	StyleErrorPanel, StyleErrorTitle, StyleErrorStack lipgloss.Style
+)
 
-	DividerCharSubtle = "┈"
+// Spinner styles
+var (
+	StyleSpinner, StyleSpinnerActive lipgloss.Style
 )
 
-// Progress bar styles
+// Header styles - Crush-inspired
 var (
+	StyleHeader, StyleHeaderMeta                    lipgloss.Style
+	StyleBrandPrefix, StyleBrandName, StyleDiagonal lipgloss.Style
+
+	// Dot separator for metadata
+	MetaDotSeparator = " • "
+)
+
+// Status bar styles
+var (
+	StyleStatus                                                    lipgloss.Style
+	StyleStatusInitializing, StyleStatusRunning, StyleStatusPaused lipgloss.Style
+	StyleStatusError, StyleStatusComplete                          lipgloss.Style
+)
+
+// Task/Todo styles - Crush icons
+var (
+	StyleTaskCompleted, StyleTaskInProgress, StyleTaskPending         lipgloss.Style
+	StyleTaskTextCompleted, StyleTaskTextActive, StyleTaskTextPending lipgloss.Style
+)
+
+// Text styles
+var (
+	StyleTextBase, StyleTextSelected, StyleTextMuted, StyleTextSubtle lipgloss.Style
+	StyleInfoMsg, StyleErrorMsg, StyleSuccessMsg, StyleWarningMsg     lipgloss.Style
+)
+
+// Footer/help styles
+var (
+	StyleHelpKey, StyleHelpDesc, StyleFooter lipgloss.Style
+)
+
+// Pane styles
+var (
+	StylePane, StylePaneHeader, StylePaneContent lipgloss.Style
+)
+
+// Reasoning/thinking styles
+var (
+	StyleReasoning, StyleReasoningHeader lipgloss.Style
+)
+
+// current is the theme the styles were built from
+var current *Theme
+
+func init() {
+	Apply(DefaultTheme())
+}
+
+// Current returns the theme in use
+func Current() *Theme { return current }
+
+// Apply makes t the current theme and rebuilds every style from it. Call
+// it before rendering starts; styles are not safe to swap mid-frame.
+func Apply(t *Theme) {
+	current = t
+
+	// Border styles: subtle boxes
+	StyleBox = lipgloss.NewStyle().
+		Border(BorderNormal, true, true, true, true).
+		BorderForeground(t.BgBorder).
+		Padding(1)
+
+	StyleBoxRounded = lipgloss.NewStyle().
+		Border(BorderRounded, true, true, true, true).
+		BorderForeground(t.BgBorder).
+		Padding(1)
+
+	StyleBoxError = lipgloss.NewStyle().
+		Border(BorderNormal, true, true, true, true).
+		BorderForeground(t.Error).
+		Padding(1)
+
+	// Divider styles - subtle horizontal lines
+	StyleDivider = lipgloss.NewStyle().
+		Foreground(t.BgBorder)
+
+	StyleDividerSubtle = lipgloss.NewStyle().
+		Foreground(t.BgOverlay)
+
+	// Progress bar styles
 	StyleProgressEmpty = lipgloss.NewStyle().
-				Foreground(Oyster)
+		Foreground(t.FgSubtle)
 
 	StyleProgressFilled = lipgloss.NewStyle().
-				Foreground(Guac)
+		Foreground(t.Success)
 
 	StyleProgressBar = lipgloss.NewStyle().
-				Foreground(Guac)
-)
+		Foreground(t.Success)
 
-// Circuit breaker styles
-var (
+	// Circuit breaker styles
 	StyleCircuitClosed = lipgloss.NewStyle().
-				Foreground(Guac).
-				Bold(true)
+		Foreground(t.Success).
+		Bold(true)
 
 	StyleCircuitHalfOpen = lipgloss.NewStyle().
-				Foreground(Zest).
-				Bold(true)
+		Foreground(t.Warning).
+		Bold(true)
 
 	StyleCircuitOpen = lipgloss.NewStyle().
-				Foreground(Sriracha).
-				Bold(true)
-)
+		Foreground(t.Error).
+		Bold(true)
 
-// Error panel styles
-var (
+	// Error panel styles
 	StyleErrorPanel = lipgloss.NewStyle().
-			Foreground(Salt).
-			Background(Sriracha).
-			Padding(1, 2)
+		Foreground(t.FgSelected).
+		Background(t.Error).
+		Padding(1, 2)
 
 	StyleErrorTitle = lipgloss.NewStyle().
-			Foreground(Salt).
-			Bold(true).
-			Underline(true)
+		Foreground(t.FgSelected).
+		Bold(true).
+		Underline(true)
 
 	StyleErrorStack = lipgloss.NewStyle().
-			Foreground(Smoke).
-			Italic(true)
-)
+		Foreground(t.FgSecondary).
+		Italic(true)
 
-// Spinner styles
-var (
+	// Spinner styles
 	StyleSpinner = lipgloss.NewStyle().
-			Foreground(Guac)
+		Foreground(t.Success)
 
 	StyleSpinnerActive = lipgloss.NewStyle().
-				Foreground(Julep)
-)
+		Foreground(t.Active)
+
+	// Header styles - Crush-inspired
 
-// Header styles - Crush-inspired
-var (
 	// Main header with brand gradient
 	StyleHeader = lipgloss.NewStyle().
-			Foreground(Salt).
-			Background(BBQ).
-			Padding(0, 1)
+		Foreground(t.FgSelected).
+		Background(t.BgPanel).
+		Padding(0, 1)
 
 	// Brand text "Charm" style
 	StyleBrandPrefix = lipgloss.NewStyle().
-				Foreground(Dolly).
-				Bold(false)
+		Foreground(t.Secondary).
+		Bold(false)
 
 	// Brand name "RALPH" in gradient
 	StyleBrandName = lipgloss.NewStyle().
-			Foreground(Charple).
-			Bold(true)
+		Foreground(t.Primary).
+		Bold(true)
 
 	// Diagonal separator
 	StyleDiagonal = lipgloss.NewStyle().
-			Foreground(Charple)
+		Foreground(t.Primary)
 
 	// Header metadata (right side)
 	StyleHeaderMeta = lipgloss.NewStyle().
-			Foreground(Squid)
+		Foreground(t.FgMuted)
 
-	// Dot separator for metadata
-	MetaDotSeparator = " • "
-)
-
-// Status bar styles
-var (
+	// Status bar styles
 	StyleStatus = lipgloss.NewStyle().
-			Foreground(Ash).
-			Background(Pepper).
-			Padding(0, 1)
+		Foreground(t.FgBase).
+		Background(t.BgBase).
+		Padding(0, 1)
 
-	// Status badges
+	// Status badges, on status colors
 	StyleStatusInitializing = lipgloss.NewStyle().
-				Foreground(Pepper).
-				Background(Malibu).
-				Padding(0, 1).
-				Bold(true)
+		Foreground(t.FgInverse).
+		Background(t.Info).
+		Padding(0, 1).
+		Bold(true)
 
 	StyleStatusRunning = lipgloss.NewStyle().
-				Foreground(Pepper).
-				Background(Guac).
-				Padding(0, 1).
-				Bold(true)
+		Foreground(t.FgInverse).
+		Background(t.Success).
+		Padding(0, 1).
+		Bold(true)
 
 	StyleStatusPaused = lipgloss.NewStyle().
-				Foreground(Pepper).
-				Background(Zest).
-				Padding(0, 1).
-				Bold(true)
+		Foreground(t.FgInverse).
+		Background(t.Warning).
+		Padding(0, 1).
+		Bold(true)
 
 	StyleStatusError = lipgloss.NewStyle().
-				Foreground(Salt).
-				Background(Sriracha).
-				Padding(0, 1).
-				Bold(true)
+		Foreground(t.FgSelected).
+		Background(t.Error).
+		Padding(0, 1).
+		Bold(true)
 
 	StyleStatusComplete = lipgloss.NewStyle().
-				Foreground(Pepper).
-				Background(Charple).
-				Padding(0, 1).
-				Bold(true)
-)
+		Foreground(t.FgInverse).
+		Background(t.Primary).
+		Padding(0, 1).
+		Bold(true)
+
+	// Task/Todo styles - Crush icons
 
-// Task/Todo styles - Crush icons
-var (
 	// Completed task: green checkmark
 	StyleTaskCompleted = lipgloss.NewStyle().
-				Foreground(Guac)
+		Foreground(t.Success)
 
 	// In-progress task: darker green dot with spinner
 	StyleTaskInProgress = lipgloss.NewStyle().
-				Foreground(Julep)
+		Foreground(t.Active)
 
 	// Pending task: muted bullet
 	StyleTaskPending = lipgloss.NewStyle().
-				Foreground(Squid)
+		Foreground(t.FgMuted)
 
 	// Task text styles
 	StyleTaskTextCompleted = lipgloss.NewStyle().
-				Foreground(Smoke)
+		Foreground(t.FgSecondary)
 
 	StyleTaskTextActive = lipgloss.NewStyle().
-				Foreground(Salt)
+		Foreground(t.FgSelected)
 
 	StyleTaskTextPending = lipgloss.NewStyle().
-				Foreground(Squid)
-)
+		Foreground(t.FgMuted)
+
+	// Text styles
 
-// Text styles
-var (
 	// Primary text
 	StyleTextBase = lipgloss.NewStyle().
-			Foreground(Ash)
+		Foreground(t.FgBase)
 
 	// Selected/highlighted text
 	StyleTextSelected = lipgloss.NewStyle().
-				Foreground(Salt)
+		Foreground(t.FgSelected)
 
 	// Muted/secondary text
 	StyleTextMuted = lipgloss.NewStyle().
-			Foreground(Squid)
+		Foreground(t.FgMuted)
 
 	// Subtle/hint text
 	StyleTextSubtle = lipgloss.NewStyle().
-			Foreground(Oyster)
+		Foreground(t.FgSubtle)
 
 	// Info message
 	StyleInfoMsg = lipgloss.NewStyle().
-			Foreground(Malibu).
-			Bold(true)
+		Foreground(t.Info).
+		Bold(true)
 
 	// Error message
 	StyleErrorMsg = lipgloss.NewStyle().
-			Foreground(Sriracha).
-			Bold(true)
+		Foreground(t.Error).
+		Bold(true)
 
 	// Success message
 	StyleSuccessMsg = lipgloss.NewStyle().
-			Foreground(Guac).
-			Bold(true)
+		Foreground(t.Success).
+		Bold(true)
 
 	// Warning message
 	StyleWarningMsg = lipgloss.NewStyle().
-			Foreground(Zest).
-			Bold(true)
-)
+		Foreground(t.Warning).
+		Bold(true)
 
-// Footer/help styles
-var (
+	// Footer/help styles
 	StyleHelpKey = lipgloss.NewStyle().
-			Foreground(Charple).
-			Bold(true)
+		Foreground(t.Primary).
+		Bold(true)
 
 	StyleHelpDesc = lipgloss.NewStyle().
-			Foreground(Squid)
+		Foreground(t.FgMuted)
 
 	StyleFooter = lipgloss.NewStyle().
-			Foreground(Squid).
-			Background(Pepper).
-			Padding(0, 1)
-)
+		Foreground(t.FgMuted).
+		Background(t.BgBase).
+		Padding(0, 1)
 
-// Pane styles
-var (
+	// Pane styles
 	StylePane = lipgloss.NewStyle().
-			Background(Pepper)
+		Background(t.BgBase)
 
 	StylePaneHeader = lipgloss.NewStyle().
-			Foreground(Ash).
-			Bold(true)
+		Foreground(t.FgBase).
+		Bold(true)
 
 	StylePaneContent = lipgloss.NewStyle().
-				Foreground(Smoke)
-)
+		Foreground(t.FgSecondary)
 
-// Reasoning/thinking styles
-var (
+	// Reasoning/thinking styles
 	StyleReasoning = lipgloss.NewStyle().
-			Foreground(Squid).
-			Italic(true)
+		Foreground(t.FgMuted).
+		Italic(true)
 
 	StyleReasoningHeader = lipgloss.NewStyle().
-				Foreground(Oyster).
-				Italic(true)
-)
+		Foreground(t.FgSubtle).
+		Italic(true)
+}
 
 // StyledLogEntry returns a styled log entry with Crush-style icons.
 func StyledLogEntry(level, message string) string {
@@ -65,38 +65,51 @@ var ThinkingWave = []string{
 	"∿",
 }
 
-// Theme holds all the semantic color mappings.
+// Theme holds all the semantic color mappings. Every style is built from
+// these roles; the palette above is only where the default theme gets them.
 type Theme struct {
-	Name string
+	Name string `yaml:"name"`
 
 	// Brand
-	Primary   lipgloss.Color
-	Secondary lipgloss.Color
-	Accent    lipgloss.Color
+	Primary   lipgloss.Color `yaml:"primary"`
+	Secondary lipgloss.Color `yaml:"secondary"`
+	Accent    lipgloss.Color `yaml:"accent"`
 
 	// Backgrounds
-	BgBase    lipgloss.Color
-	BgPanel   lipgloss.Color
-	BgBorder  lipgloss.Color
-	BgOverlay lipgloss.Color
+	BgBase    lipgloss.Color `yaml:"bg_base"`
+	BgPanel   lipgloss.Color `yaml:"bg_panel"`
+	BgBorder  lipgloss.Color `yaml:"bg_border"`
+	BgOverlay lipgloss.Color `yaml:"bg_overlay"`
 
 	// Foregrounds
-	FgBase     lipgloss.Color
-	FgSelected lipgloss.Color
-	FgMuted    lipgloss.Color
-	FgSubtle   lipgloss.Color
+	FgBase      lipgloss.Color `yaml:"fg_base"`
+	FgSelected  lipgloss.Color `yaml:"fg_selected"`
+	FgSecondary lipgloss.Color `yaml:"fg_secondary"`
+	FgMuted     lipgloss.Color `yaml:"fg_muted"`
+	FgSubtle    lipgloss.Color `yaml:"fg_subtle"`
+	FgInverse   lipgloss.Color `yaml:"fg_inverse"` // Text on status and accent backgrounds
 
 	// Status
-	Success lipgloss.Color
-	Error   lipgloss.Color
-	Warning lipgloss.Color
-	Info    lipgloss.Color
+	Success lipgloss.Color `yaml:"success"`
+	Active  lipgloss.Color `yaml:"active"` // Work in progress: spinners, the running task
+	Error   lipgloss.Color `yaml:"error"`
+	Warning lipgloss.Color `yaml:"warning"`
+	Info    lipgloss.Color `yaml:"info"`
+
+	// Diffs
+	DiffAddBg    lipgloss.Color `yaml:"diff_add_bg"`
+	DiffDeleteBg lipgloss.Color `yaml:"diff_delete_bg"`
+
+	// Syntax is the Chroma style for code in diffs, empty for none. Markdown
+	// is the Glamour style for rendered messages: dark, light, notty, ...
+	Syntax   string `yaml:"syntax"`
+	Markdown string `yaml:"markdown"`
 }
 
 // DefaultTheme returns the Charmtone-based dark theme.
 func DefaultTheme() *Theme {
 	return &Theme{
-		Name: "charmtone",
+		Name: "dark",
 
 		// Brand colors
 		Primary:   Charple,
@@ -110,16 +123,26 @@ func DefaultTheme() *Theme {
 		BgOverlay: Iron,
 
 		// Foregrounds
-		FgBase:     Ash,
-		FgSelected: Salt,
-		FgMuted:    Squid,
-		FgSubtle:   Oyster,
+		FgBase:      Ash,
+		FgSelected:  Salt,
+		FgSecondary: Smoke,
+		FgMuted:     Squid,
+		FgSubtle:    Oyster,
+		FgInverse:   Pepper,
 
 		// Status
 		Success: Guac,
+		Active:  Julep,
 		Error:   Sriracha,
 		Warning: Zest,
 		Info:    Malibu,
+
+		// Diffs
+		DiffAddBg:    lipgloss.Color("#1B3A30"),
+		DiffDeleteBg: lipgloss.Color("#3D1E29"),
+
+		Syntax:   "monokai",
+		Markdown: "dark",
 	}
 }
 
@@ -1,6 +1,14 @@
 package style
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
 
 func TestDefaultTheme(t *testing.T) {
 	th := DefaultTheme()
@@ -14,3 +22,80 @@ func TestDefaultTheme(t *testing.T) {
 		t.Fatalf("expected Primary Charple, got %q", th.Primary)
 	}
 }
+
+func TestBuiltinThemesSetEveryColor(t *testing.T) {
+	for _, name := range BuiltinThemeNames() {
+		if name == "mono" {
+			continue
+		}
+		th, err := LoadTheme(name, "")
+		if err != nil {
+			t.Fatalf("LoadTheme(%q) error = %v", name, err)
+		}
+		v := reflect.ValueOf(th).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			if c, ok := v.Field(i).Interface().(lipgloss.Color); ok && c == "" {
+				t.Errorf("theme %s leaves %s unset", name, v.Type().Field(i).Name)
+			}
+		}
+		if err := validateColors(th); err != nil {
+			t.Errorf("theme %s: %v", name, err)
+		}
+	}
+}
+
+func TestLoadThemeFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("solar.yaml", "base: light\nprimary: \"#268BD2\"\nerror: \"160\"\nsyntax: solarized-light\n")
+	write("blank.yml", "")
+	write("typo.yaml", "primry: \"#268BD2\"\n")
+	write("bad.yaml", "error: red\n")
+	write("nobase.yaml", "base: sepia\n")
+
+	th, err := LoadTheme("solar", dir)
+	if err != nil {
+		t.Fatalf("LoadTheme(solar) error = %v", err)
+	}
+	want := LightTheme()
+	if th.Name != "solar" || th.Primary != "#268BD2" || th.Error != "160" || th.Syntax != "solarized-light" {
+		t.Errorf("LoadTheme(solar) = %+v", th)
+	}
+	if th.BgBase != want.BgBase || th.Markdown != want.Markdown {
+		t.Errorf("LoadTheme(solar) did not inherit from light: BgBase %q, Markdown %q", th.BgBase, th.Markdown)
+	}
+
+	if th, err := LoadTheme("blank", dir); err != nil || th.BgBase != Pepper || th.Name != "blank" {
+		t.Errorf("LoadTheme(blank) = %+v, %v; want the dark theme", th, err)
+	}
+
+	for name, wantErr := range map[string]string{
+		"typo":      "field primry not found",
+		"bad":       `error: "red" is not a color`,
+		"nobase":    `base "sepia" is not one of`,
+		"missing":   `unknown theme "missing"`,
+		"../secret": "unknown theme",
+	} {
+		if _, err := LoadTheme(name, dir); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("LoadTheme(%q) error = %v, want %q", name, err, wantErr)
+		}
+	}
+}
+
+func TestApplyRebuildsStyles(t *testing.T) {
+	defer Apply(DefaultTheme())
+
+	Apply(LightTheme())
+	if Current().Name != "light" || StyleTextBase.GetForeground() != Charcoal || StyleStatusRunning.GetForeground() != Butter {
+		t.Errorf("light theme: text %v, badge %v", StyleTextBase.GetForeground(), StyleStatusRunning.GetForeground())
+	}
+
+	Apply(DefaultTheme())
+	if StyleTextBase.GetForeground() != Ash || StyleStatusRunning.GetForeground() != Pepper {
+		t.Errorf("dark theme: text %v, badge %v", StyleTextBase.GetForeground(), StyleStatusRunning.GetForeground())
+	}
+}
@@ -0,0 +1,212 @@
+package style
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"gopkg.in/yaml.v3"
+)
+
+// builtinThemes are the themes that need no file, by name
+var builtinThemes = map[string]func() *Theme{
+	"dark":          DefaultTheme,
+	"light":         LightTheme,
+	"high-contrast": HighContrastTheme,
+	"mono":          MonochromeTheme,
+}
+
+// LightTheme returns a theme for light terminal backgrounds.
+func LightTheme() *Theme {
+	return &Theme{
+		Name: "light",
+
+		// Brand colors
+		Primary:   Charple,
+		Secondary: lipgloss.Color("#C03CC0"),
+		Accent:    lipgloss.Color("#8A6A00"),
+
+		// Backgrounds
+		BgBase:    Butter,
+		BgPanel:   Salt,
+		BgBorder:  Ash,
+		BgOverlay: Smoke,
+
+		// Foregrounds
+		FgBase:      Charcoal,
+		FgSelected:  Pepper,
+		FgSecondary: Iron,
+		FgMuted:     Oyster,
+		FgSubtle:    Squid,
+		FgInverse:   Butter,
+
+		// Status
+		Success: lipgloss.Color("#0A8F66"),
+		Active:  lipgloss.Color("#00A37A"),
+		Error:   lipgloss.Color("#D12F55"),
+		Warning: lipgloss.Color("#A66A00"),
+		Info:    lipgloss.Color("#0075C4"),
+
+		// Diffs
+		DiffAddBg:    lipgloss.Color("#DDF4E8"),
+		DiffDeleteBg: lipgloss.Color("#FBE1E7"),
+
+		Syntax:   "github",
+		Markdown: "light",
+	}
+}
+
+// HighContrastTheme returns a theme of pure, saturated colors on black for
+// low vision and washed-out displays.
+func HighContrastTheme() *Theme {
+	return &Theme{
+		Name: "high-contrast",
+
+		// Brand colors
+		Primary:   lipgloss.Color("#5FD7FF"),
+		Secondary: lipgloss.Color("#FF87FF"),
+		Accent:    lipgloss.Color("#FFFF00"),
+
+		// Backgrounds
+		BgBase:    lipgloss.Color("#000000"),
+		BgPanel:   lipgloss.Color("#000000"),
+		BgBorder:  lipgloss.Color("#FFFFFF"),
+		BgOverlay: lipgloss.Color("#A8A8A8"),
+
+		// Foregrounds
+		FgBase:      lipgloss.Color("#FFFFFF"),
+		FgSelected:  lipgloss.Color("#FFFFFF"),
+		FgSecondary: lipgloss.Color("#FFFFFF"),
+		FgMuted:     lipgloss.Color("#D0D0D0"),
+		FgSubtle:    lipgloss.Color("#B2B2B2"),
+		FgInverse:   lipgloss.Color("#000000"),
+
+		// Status
+		Success: lipgloss.Color("#00FF00"),
+		Active:  lipgloss.Color("#00FFFF"),
+		Error:   lipgloss.Color("#FF5F5F"),
+		Warning: lipgloss.Color("#FFFF00"),
+		Info:    lipgloss.Color("#00AFFF"),
+
+		// Diffs
+		DiffAddBg:    lipgloss.Color("#003A00"),
+		DiffDeleteBg: lipgloss.Color("#4D0000"),
+
+		Syntax:   "modus-vivendi",
+		Markdown: "dark",
+	}
+}
+
+// MonochromeTheme returns a theme without colors, for NO_COLOR and terminals
+// that can't show them.
+func MonochromeTheme() *Theme {
+	return &Theme{Name: "mono", Markdown: "notty"}
+}
+
+// BuiltinThemeNames lists the built-in themes, sorted
+func BuiltinThemeNames() []string {
+	names := make([]string, 0, len(builtinThemes))
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ThemesDir returns where user themes live (~/.lisa/themes)
+func ThemesDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, ".lisa", "themes"), nil
+}
+
+// LoadTheme returns the built-in theme called name, or the one in dir's
+// name.yaml (or name.yml).
+func LoadTheme(name, dir string) (*Theme, error) {
+	if builtin, ok := builtinThemes[name]; ok {
+		return builtin(), nil
+	}
+	if dir != "" && name != "" && !strings.ContainsAny(name, `/\`) {
+		for _, ext := range []string{".yaml", ".yml"} {
+			path := filepath.Join(dir, name+ext)
+			if _, err := os.Stat(path); err == nil {
+				return LoadThemeFile(path)
+			}
+		}
+	}
+	return nil, fmt.Errorf("unknown theme %q: use %s, or add %s.yaml to %s",
+		name, strings.Join(BuiltinThemeNames(), ", "), name, dir)
+}
+
+// themeFile is a theme file: a built-in theme to start from and the roles it
+// overrides
+type themeFile struct {
+	Base  string `yaml:"base"`
+	Theme `yaml:",inline"`
+}
+
+// LoadThemeFile reads a YAML theme file. Roles the file leaves out come from
+// its base theme, dark unless it names another built-in. Colors are hex
+// (#RGB or #RRGGBB) or ANSI numbers (0-255).
+func LoadThemeFile(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read theme: %w", err)
+	}
+
+	// A first pass finds the base, the second lays the file over it
+	var head struct {
+		Base string `yaml:"base"`
+	}
+	if err := yaml.Unmarshal(data, &head); err != nil {
+		return nil, fmt.Errorf("invalid theme %s: %w", path, err)
+	}
+	if head.Base == "" {
+		head.Base = "dark"
+	}
+	base, ok := builtinThemes[head.Base]
+	if !ok {
+		return nil, fmt.Errorf("invalid theme %s: base %q is not one of %s", path, head.Base, strings.Join(BuiltinThemeNames(), ", "))
+	}
+
+	file := themeFile{Theme: *base()}
+	file.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid theme %s: %w", path, err)
+	}
+	if err := validateColors(&file.Theme); err != nil {
+		return nil, fmt.Errorf("invalid theme %s: %w", path, err)
+	}
+	return &file.Theme, nil
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// validateColors checks that every color role holds a color lipgloss can use
+func validateColors(t *Theme) error {
+	v := reflect.ValueOf(t).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		c, ok := v.Field(i).Interface().(lipgloss.Color)
+		if !ok || c == "" || hexColor.MatchString(string(c)) {
+			continue
+		}
+		if n, err := strconv.Atoi(string(c)); err == nil && n >= 0 && n <= 255 {
+			continue
+		}
+		return fmt.Errorf("%s: %q is not a color (#RRGGBB, #RGB or 0-255)", v.Type().Field(i).Tag.Get("yaml"), c)
+	}
+	return nil
+}
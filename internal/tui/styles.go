@@ -130,6 +130,60 @@ var (
 	StyleReasoningHeader = style.StyleReasoningHeader
 )
 
+// syncStyles copies the style package's styles again after it rebuilt them
+// for a new theme
+func syncStyles() {
+	StyleBox = style.StyleBox
+	StyleBoxRounded = style.StyleBoxRounded
+	StyleBoxError = style.StyleBoxError
+	StyleDivider = style.StyleDivider
+	StyleDividerSubtle = style.StyleDividerSubtle
+	StyleProgressEmpty = style.StyleProgressEmpty
+	StyleProgressFilled = style.StyleProgressFilled
+	StyleProgressBar = style.StyleProgressBar
+	StyleCircuitClosed = style.StyleCircuitClosed
+	StyleCircuitHalfOpen = style.StyleCircuitHalfOpen
+	StyleCircuitOpen = style.StyleCircuitOpen
+	StyleErrorPanel = style.StyleErrorPanel
+	StyleErrorTitle = style.StyleErrorTitle
+	StyleErrorStack = style.StyleErrorStack
+	StyleSpinner = style.StyleSpinner
+	StyleSpinnerActive = style.StyleSpinnerActive
+	StyleHeader = style.StyleHeader
+	StyleBrandPrefix = style.StyleBrandPrefix
+	StyleBrandName = style.StyleBrandName
+	StyleDiagonal = style.StyleDiagonal
+	StyleHeaderMeta = style.StyleHeaderMeta
+	StyleStatus = style.StyleStatus
+	StyleStatusInitializing = style.StyleStatusInitializing
+	StyleStatusRunning = style.StyleStatusRunning
+	StyleStatusPaused = style.StyleStatusPaused
+	StyleStatusError = style.StyleStatusError
+	StyleStatusComplete = style.StyleStatusComplete
+	StyleTaskCompleted = style.StyleTaskCompleted
+	StyleTaskInProgress = style.StyleTaskInProgress
+	StyleTaskPending = style.StyleTaskPending
+	StyleTaskTextCompleted = style.StyleTaskTextCompleted
+	StyleTaskTextActive = style.StyleTaskTextActive
+	StyleTaskTextPending = style.StyleTaskTextPending
+	StyleTextBase = style.StyleTextBase
+	StyleTextSelected = style.StyleTextSelected
+	StyleTextMuted = style.StyleTextMuted
+	StyleTextSubtle = style.StyleTextSubtle
+	StyleInfoMsg = style.StyleInfoMsg
+	StyleErrorMsg = style.StyleErrorMsg
+	StyleSuccessMsg = style.StyleSuccessMsg
+	StyleWarningMsg = style.StyleWarningMsg
+	StyleHelpKey = style.StyleHelpKey
+	StyleHelpDesc = style.StyleHelpDesc
+	StyleFooter = style.StyleFooter
+	StylePane = style.StylePane
+	StylePaneHeader = style.StylePaneHeader
+	StylePaneContent = style.StylePaneContent
+	StyleReasoning = style.StyleReasoning
+	StyleReasoningHeader = style.StyleReasoningHeader
+}
+
 func StyledLogEntry(level, message string) string {
 	return style.StyledLogEntry(level, message)
 }
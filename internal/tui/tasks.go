@@ -0,0 +1,174 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/effects"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/keymap"
+	tuimsg "github.com/brainwhocodes/lisa-loop/internal/tui/msg"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/plan"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// taskEdits are the Tasks view's actions that change the task under the cursor
+var taskEdits = map[keymap.Action]plan.EditKind{
+	keymap.TaskToggle:   plan.EditToggle,
+	keymap.TaskSkip:     plan.EditSkip,
+	keymap.TaskBlock:    plan.EditBlock,
+	keymap.TaskMoveUp:   plan.EditMoveUp,
+	keymap.TaskMoveDown: plan.EditMoveDown,
+	keymap.TaskPin:      plan.EditPin,
+	keymap.TaskDelete:   plan.EditDelete,
+}
+
+// handleTaskKey drives the Tasks view's cursor and plan edits. It reports
+// whether the key was used.
+func (m *Model) handleTaskKey(key string) (tea.Cmd, bool) {
+	action := m.keymap().Lookup(keymap.ScopeTasks, key)
+	switch action {
+	case keymap.TaskDown:
+		if m.taskCursor < len(m.tasks)-1 {
+			m.taskCursor++
+		}
+		return nil, true
+	case keymap.TaskUp:
+		if m.taskCursor > 0 {
+			m.taskCursor--
+		}
+		return nil, true
+	case keymap.TaskAdd:
+		m.addingTask = true
+		m.taskInput = nil
+		return nil, true
+	}
+
+	kind, ok := taskEdits[action]
+	if !ok {
+		return nil, false
+	}
+	if len(m.tasks) == 0 {
+		return nil, true
+	}
+	return m.editPlan(plan.EditFor(m.tasks, m.taskCursor, kind)), true
+}
+
+// handleTaskInputKey edits the new task's text while it is being typed.
+// Enter adds it below the cursor, Esc drops it.
+func (m Model) handleTaskInputKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		text := strings.TrimSpace(string(m.taskInput))
+		m.addingTask = false
+		m.taskInput = nil
+		if text == "" {
+			return m, nil
+		}
+		e := plan.EditFor(m.tasks, m.taskCursor, plan.EditAdd)
+		e.Text = text
+		return m, m.editPlan(e)
+	case tea.KeyEsc:
+		m.addingTask = false
+		m.taskInput = nil
+	case tea.KeyBackspace:
+		if len(m.taskInput) > 0 {
+			m.taskInput = m.taskInput[:len(m.taskInput)-1]
+		}
+	case tea.KeySpace:
+		m.taskInput = append(m.taskInput, ' ')
+	case tea.KeyRunes:
+		m.taskInput = append(m.taskInput, msg.Runes...)
+	}
+	return m, nil
+}
+
+// editPlan writes an edit to the plan file. Edits go one at a time, each
+// made against the plan the previous one left, so quick keypresses queue.
+func (m *Model) editPlan(e plan.Edit) tea.Cmd {
+	if m.planFile == "" {
+		m.addLog(string(loop.LogLevelWarn), "No plan file to edit")
+		return nil
+	}
+	if m.planEditing {
+		m.planEdits = append(m.planEdits, e)
+		return nil
+	}
+	m.planEditing = true
+	return effects.EditPlanTasks(m.planFile, m.planContent, e, m.readFile)
+}
+
+// applyPlanTaskEdit takes the plan as an edit left it, then sends the next
+// queued edit
+func (m *Model) applyPlanTaskEdit(msg tuimsg.PlanTaskEditedMsg) tea.Cmd {
+	m.planEditing = false
+
+	switch {
+	case errors.Is(msg.Err, plan.ErrTaskNotFound):
+		m.addLog(string(loop.LogLevelWarn), "The plan changed on disk and no longer has that task; reloaded it")
+	case errors.Is(msg.Err, effects.ErrPlanChanged):
+		m.addLog(string(loop.LogLevelWarn), "The plan changed on disk while saving; edit not applied, reloaded it")
+	case msg.Err != nil:
+		m.addLog(string(loop.LogLevelWarn), fmt.Sprintf("Could not edit %s: %v", msg.Filename, msg.Err))
+	case msg.Conflict:
+		m.addLog(string(loop.LogLevelWarn), "The plan changed on disk since it was loaded; edit applied to the latest version")
+	}
+
+	// After a conflict the reply carries the plan as it now is, so the view
+	// catches up with whatever changed it
+	if msg.Err == nil || msg.Conflict {
+		m.planContent = msg.Content
+		m.tasks = msg.Tasks
+		m.phases = msg.Phases
+		m.currentPhase = findFirstIncompletePhase(m.phases)
+		if msg.Task >= 0 {
+			m.taskCursor = msg.Task
+		}
+		m.clampTaskCursor()
+		m.updateActiveTask()
+	}
+
+	if len(m.planEdits) == 0 {
+		return nil
+	}
+	next := m.planEdits[0]
+	m.planEdits = m.planEdits[1:]
+	return m.editPlan(next)
+}
+
+// clampTaskCursor keeps the Tasks view's cursor on a task
+func (m *Model) clampTaskCursor() {
+	m.taskCursor = max(0, min(m.taskCursor, len(m.tasks)-1))
+}
+
+// setAsideTaskStyle is how a skipped or blocked task is drawn
+func setAsideTaskStyle(task Task) (string, lipgloss.Style) {
+	if task.Blocked {
+		return StyleWarningMsg.Render("!"), StyleWarningMsg
+	}
+	return StyleTextSubtle.Render("-"), StyleTextSubtle.Strikethrough(true)
+}
+
+// tasksFooter lists the Tasks view's keys
+func (m Model) tasksFooter(width int) string {
+	var parts []string
+	if m.addingTask {
+		parts = []string{StyleHelpKey.Render("enter") + " add task", StyleHelpKey.Render("esc") + " cancel"}
+	} else {
+		parts = []string{
+			m.hint("done", keymap.TaskToggle),
+			m.hint("move", keymap.TaskMoveDown, keymap.TaskMoveUp),
+			m.hint("do next", keymap.TaskPin),
+			m.hint("skip", keymap.TaskSkip),
+			m.hint("blocked", keymap.TaskBlock),
+			m.hint("add", keymap.TaskAdd),
+			m.hint("delete", keymap.TaskDelete),
+			m.hint("return", keymap.Tasks),
+			m.hint("quit", keymap.Quit),
+		}
+	}
+	return StyleFooter.Width(width).Render(ansi.Truncate(footerHints(parts...), width, "…"))
+}
@@ -0,0 +1,128 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tuimsg "github.com/brainwhocodes/lisa-loop/internal/tui/msg"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/plan"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newTasksModel opens the Tasks view on a plan file written to a temp dir
+func newTasksModel(t *testing.T, content string) (Model, string) {
+	planFile := filepath.Join(t.TempDir(), "@fix_plan.md")
+	if err := os.WriteFile(planFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return Model{
+		screen:      ScreenTasks,
+		planFile:    planFile,
+		planContent: content,
+		tasks:       plan.ParseTasks(content),
+		phases:      plan.ParsePhases(content),
+		readFile:    os.ReadFile,
+	}, planFile
+}
+
+// pressTaskKeys sends keys to the model, running plan edits as they come
+func pressTaskKeys(model tea.Model, keys ...tea.KeyMsg) tea.Model {
+	for _, k := range keys {
+		var cmd tea.Cmd
+		model, cmd = model.Update(k)
+		for cmd != nil {
+			msg, ok := cmd().(tuimsg.PlanTaskEditedMsg)
+			if !ok {
+				break
+			}
+			model, cmd = model.Update(msg)
+		}
+	}
+	return model
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestTasksView_EditsPlan(t *testing.T) {
+	model, planFile := newTasksModel(t, "# Fix Plan\n\n## High Priority\n- [ ] First task\n- [ ] Second task\n- [ ] Third task\n")
+
+	got := pressTaskKeys(model,
+		runes("j"),
+		tea.KeyMsg{Type: tea.KeySpace}, // Second done
+		runes("j"),
+		runes("N"), // Third first
+		runes("j"), runes("j"),
+		runes("-"), // Second skipped
+		runes("a"), runes("Fourth"), tea.KeyMsg{Type: tea.KeySpace}, runes("task"), tea.KeyMsg{Type: tea.KeyEnter},
+	).(Model)
+
+	want := "# Fix Plan\n\n## High Priority\n- [ ] Third task\n- [ ] First task\n- [-] Second task\n- [ ] Fourth task\n"
+	if b, _ := os.ReadFile(planFile); string(b) != want {
+		t.Fatalf("plan =\n%s\nwant\n%s", b, want)
+	}
+	if got.planContent != want || len(got.tasks) != 4 || !got.tasks[2].Skipped || got.taskCursor != 3 || got.addingTask {
+		t.Errorf("model after edits: content %q, tasks %+v, cursor %d", got.planContent, got.tasks, got.taskCursor)
+	}
+
+	view := got.renderTasksFullView()
+	if !strings.Contains(view, IconBorderThick+"• Fourth task") || strings.Contains(view, "New task") {
+		t.Errorf("tasks view does not show the added task under the cursor:\n%s", view)
+	}
+}
+
+func TestTasksView_UncheckOverridesInMemoryCompletion(t *testing.T) {
+	model, planFile := newTasksModel(t, "- [x] First task\n- [ ] Second task\n")
+
+	got := pressTaskKeys(model, tea.KeyMsg{Type: tea.KeyEnter}).(Model)
+	if b, _ := os.ReadFile(planFile); string(b) != "- [ ] First task\n- [ ] Second task\n" {
+		t.Fatalf("plan = %q", b)
+	}
+	if got.tasks[0].Completed || got.phases[0].Tasks[0].Completed {
+		t.Errorf("unchecked task still shown completed: %+v", got.tasks)
+	}
+}
+
+func TestTasksView_ConflictWithAgentEdit(t *testing.T) {
+	model, planFile := newTasksModel(t, "- [ ] First task\n- [ ] Second task\n")
+
+	// The agent finishes the first task and rewrites the second
+	os.WriteFile(planFile, []byte("- [x] First task\n- [ ] Second task, split up\n"), 0644)
+	got := pressTaskKeys(model, runes("j"), runes("D")).(Model)
+
+	if b, _ := os.ReadFile(planFile); string(b) != "- [x] First task\n- [ ] Second task, split up\n" {
+		t.Errorf("conflicting edit overwrote the agent's plan: %q", b)
+	}
+	if len(got.tasks) != 2 || got.tasks[1].Text != "Second task, split up" || !got.tasks[0].Completed {
+		t.Errorf("view did not reload the agent's plan: %+v", got.tasks)
+	}
+	if last := got.logs[len(got.logs)-1].Text; !strings.Contains(last, "no longer has that task") {
+		t.Errorf("conflict not reported, last log %q", last)
+	}
+}
+
+func TestTasksView_QueuesEditsWhileSaving(t *testing.T) {
+	model, planFile := newTasksModel(t, "- [ ] First task\n- [ ] Second task\n- [ ] Third task\n")
+
+	// Two moves before the first write comes back
+	next, first := model.Update(runes("J"))
+	next, second := next.Update(runes("J"))
+	if first == nil || second != nil || len(next.(Model).planEdits) != 1 {
+		t.Fatalf("second edit not queued behind the first")
+	}
+	next, cmd := next.Update(first())
+	if cmd == nil {
+		t.Fatalf("queued edit not sent after the first finished")
+	}
+	next, _ = next.Update(cmd())
+
+	if b, _ := os.ReadFile(planFile); string(b) != "- [ ] Second task\n- [ ] Third task\n- [ ] First task\n" {
+		t.Errorf("plan = %q", b)
+	}
+	if m := next.(Model); m.taskCursor != 2 || m.planEditing {
+		t.Errorf("cursor = %d, editing = %v", m.taskCursor, m.planEditing)
+	}
+}
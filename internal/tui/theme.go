@@ -3,6 +3,7 @@ package tui
 import (
 	"github.com/brainwhocodes/lisa-loop/internal/tui/style"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 // Re-export palette and iconography from internal/tui/style to keep the tui package surface stable.
@@ -52,6 +53,61 @@ type Theme = style.Theme
 
 func DefaultTheme() *Theme { return style.DefaultTheme() }
 
+// CurrentTheme returns the theme in use
+func CurrentTheme() *Theme { return style.Current() }
+
+func init() {
+	applyTheme(style.Current())
+}
+
+// hasDarkBackground asks the terminal whether its background is dark
+var hasDarkBackground = termenv.HasDarkBackground
+
+// selectTheme resolves the theme setting. NO_COLOR wins and selects the mono
+// theme; "auto" picks dark or light to suit the terminal's background; any
+// other name is a built-in or a theme file in ~/.lisa/themes. When that
+// fails, the error comes with the auto theme.
+func selectTheme(name string) (*Theme, error) {
+	if termenv.EnvNoColor() {
+		return style.MonochromeTheme(), nil
+	}
+
+	var err error
+	if name != "" && name != "auto" {
+		dir, _ := style.ThemesDir()
+		var t *Theme
+		if t, err = style.LoadTheme(name, dir); err == nil {
+			return t, nil
+		}
+	}
+	if hasDarkBackground() {
+		return style.DefaultTheme(), err
+	}
+	return style.LightTheme(), err
+}
+
+// applyTheme switches the TUI to theme t: the style package rebuilds its
+// styles, and the copies and styles of this package follow. Like
+// style.Apply, it must run before the program starts rendering.
+func applyTheme(t *Theme) {
+	style.Apply(t)
+	syncStyles()
+
+	// Without colors, reverse video is what sets matches and the active tab apart
+	plain := t.Accent == ""
+
+	styleSearchMatch = lipgloss.NewStyle().Foreground(t.FgInverse).Background(t.Accent).Reverse(plain)
+	styleSearchFocused = lipgloss.NewStyle().Foreground(t.FgInverse).Background(t.Secondary).Bold(true).Reverse(plain)
+
+	styleDiffAdd = lipgloss.NewStyle().Foreground(t.Success)
+	styleDiffDelete = lipgloss.NewStyle().Foreground(t.Error)
+	styleDiffHunk = lipgloss.NewStyle().Foreground(t.Info)
+	diffViewerStyles = newDiffViewerStyles(t)
+
+	styleTabActive = lipgloss.NewStyle().Foreground(t.FgInverse).Background(t.Success).Padding(0, 1).Bold(true).Reverse(plain)
+	styleTabInactive = lipgloss.NewStyle().Foreground(t.FgMuted).Background(t.BgPanel).Padding(0, 1)
+}
+
 func GradientText(text string, from, to lipgloss.Color) string {
 	return style.GradientText(text, from, to)
 }
@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brainwhocodes/lisa-loop/internal/tui/style"
+)
+
+func TestSelectTheme(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR", "")
+	dir := filepath.Join(home, ".lisa", "themes")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "mine.yaml"), []byte("base: high-contrast\nprimary: \"#FF8700\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dark := true
+	defer func(f func() bool) { hasDarkBackground = f }(hasDarkBackground)
+	hasDarkBackground = func() bool { return dark }
+
+	tests := []struct {
+		name    string
+		setting string
+		dark    bool
+		want    string
+		wantErr string
+	}{
+		{"auto on dark", "auto", true, "dark", ""},
+		{"auto on light", "auto", false, "light", ""},
+		{"unset", "", false, "light", ""},
+		{"builtin", "high-contrast", false, "high-contrast", ""},
+		{"theme file", "mine", true, "mine", ""},
+		{"unknown", "sepia", false, "light", `unknown theme "sepia"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dark = tt.dark
+			got, err := selectTheme(tt.setting)
+			if got.Name != tt.want {
+				t.Errorf("selectTheme(%q) = %s, want %s", tt.setting, got.Name, tt.want)
+			}
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("selectTheme(%q) error = %v, want %q", tt.setting, err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if got, err := selectTheme("light"); err != nil || got.Name != "mono" {
+		t.Errorf("selectTheme() under NO_COLOR = %s, %v; want mono", got.Name, err)
+	}
+}
+
+func TestApplyTheme(t *testing.T) {
+	defer applyTheme(DefaultTheme())
+
+	light := style.LightTheme()
+	applyTheme(light)
+	if StyleTextBase.GetForeground() != light.FgBase || styleDiffAdd.GetForeground() != light.Success ||
+		diffViewerStyles.AddBackground != light.DiffAddBg || diffViewerStyles.Chroma == nil || CurrentTheme() != light {
+		t.Errorf("applyTheme(light) left styles from another theme")
+	}
+
+	mono := style.MonochromeTheme()
+	applyTheme(mono)
+	if diffViewerStyles.Chroma != nil || !styleSearchMatch.GetReverse() || !styleTabActive.GetReverse() {
+		t.Errorf("applyTheme(mono) should drop syntax colors and mark matches in reverse video")
+	}
+}
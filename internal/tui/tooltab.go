@@ -0,0 +1,382 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/effects"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/keymap"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// maxToolRecords caps the tool calls the Tools tab keeps, oldest dropped first
+const maxToolRecords = 500
+
+// toolRecord is one tool call, put together from its start and end events
+type toolRecord struct {
+	ID        string
+	Iteration int
+	Name      string
+	Target    string
+	Input     json.RawMessage
+	Output    string
+	ExitCode  *int
+	Error     string
+	Started   time.Time // Zero when the backend only reported the end
+	Ended     time.Time // Zero while running
+}
+
+// running reports whether the call has not finished yet
+func (r toolRecord) running() bool {
+	return r.Ended.IsZero()
+}
+
+// failed reports whether the call errored or exited non-zero
+func (r toolRecord) failed() bool {
+	return r.Error != "" || (r.ExitCode != nil && *r.ExitCode != 0)
+}
+
+// duration is how long the call took, or 0 when that is not known
+func (r toolRecord) duration() time.Duration {
+	if r.Started.IsZero() || r.Ended.IsZero() {
+		return 0
+	}
+	return r.Ended.Sub(r.Started)
+}
+
+// toolInspector is the Tools tab: every tool call, browsed an iteration at a
+// time
+type toolInspector struct {
+	calls  []toolRecord
+	loop   int  // Iteration shown, 0 for the latest
+	cursor int  // Selected call in the iteration, when pinned
+	pinned bool // Otherwise the latest call is selected
+	detail bool // Showing the selected call in full
+	scroll int  // First line of the detail shown
+}
+
+// recordToolCall adds a tool event to the Tools tab. An event updates the
+// call with the same ID; without an ID, an end event completes the last
+// running call to the same tool and target.
+func (m *Model) recordToolCall(tool *loop.ToolCall, iteration int, at time.Time) {
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if iteration == 0 {
+		iteration = m.loopNumber
+	}
+
+	calls := m.tools.calls
+	i := -1
+	for j := len(calls) - 1; j >= 0 && (tool.ID != "" || tool.Status != loop.ToolStatusStarted); j-- {
+		c := calls[j]
+		if tool.ID != "" && c.ID == tool.ID ||
+			tool.ID == "" && c.running() && c.Name == tool.Name && (tool.Target == "" || c.Target == tool.Target) {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		calls = append(calls, toolRecord{ID: tool.ID, Iteration: iteration, Name: tool.Name, Target: tool.Target})
+		i = len(calls) - 1
+	}
+
+	r := &calls[i]
+	if r.Target == "" {
+		r.Target = tool.Target
+	}
+	if len(tool.Input) > 0 {
+		r.Input = tool.Input
+	}
+	if tool.Status == loop.ToolStatusStarted {
+		if r.Started.IsZero() {
+			r.Started = at
+		}
+	} else {
+		r.Ended = at
+		r.Output = tool.Output
+		r.ExitCode = tool.ExitCode
+		r.Error = tool.Error
+	}
+
+	if over := len(calls) - maxToolRecords; over > 0 {
+		calls = calls[over:]
+	}
+	m.tools.calls = calls
+}
+
+// toolLoops lists the iterations with tool calls, oldest first
+func (m Model) toolLoops() []int {
+	var loops []int
+	for _, c := range m.tools.calls {
+		if len(loops) == 0 || loops[len(loops)-1] != c.Iteration {
+			loops = append(loops, c.Iteration)
+		}
+	}
+	return loops
+}
+
+// shownToolLoop is the iteration the Tools tab shows
+func (m Model) shownToolLoop() int {
+	if m.tools.loop != 0 {
+		return m.tools.loop
+	}
+	if loops := m.toolLoops(); len(loops) > 0 {
+		return loops[len(loops)-1]
+	}
+	return 0
+}
+
+// shownToolCalls are the shown iteration's tool calls
+func (m Model) shownToolCalls() []toolRecord {
+	shown := m.shownToolLoop()
+	var calls []toolRecord
+	for _, c := range m.tools.calls {
+		if c.Iteration == shown {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// selectedToolCall is the index of the selected call in shownToolCalls, -1
+// when there are none
+func (m Model) selectedToolCall(calls []toolRecord) int {
+	if !m.tools.pinned || m.tools.cursor >= len(calls) {
+		return len(calls) - 1
+	}
+	return m.tools.cursor
+}
+
+// handleToolKey drives the Tools tab. It reports whether the key was used.
+func (m *Model) handleToolKey(key string) (tea.Cmd, bool) {
+	calls := m.shownToolCalls()
+	selected := m.selectedToolCall(calls)
+
+	action := m.keymap().Lookup(keymap.ScopeTools, key)
+	switch action {
+	case keymap.ToolPrevLoop, keymap.ToolNextLoop:
+		loops := m.toolLoops()
+		shown := m.shownToolLoop()
+		m.tools.loop = 0 // Unless the shown iteration is still there to move from
+		for i, l := range loops {
+			if l != shown {
+				continue
+			}
+			m.tools.loop = shown
+			switch {
+			case action == keymap.ToolPrevLoop && i > 0:
+				m.tools.loop = loops[i-1]
+			case action == keymap.ToolNextLoop && i == len(loops)-2:
+				m.tools.loop = 0 // Back to following the latest
+			case action == keymap.ToolNextLoop && i < len(loops)-1:
+				m.tools.loop = loops[i+1]
+			}
+		}
+		m.tools.pinned = false
+		m.tools.scroll = 0
+	case keymap.ToolDetail:
+		m.tools.detail = !m.tools.detail && selected >= 0
+		if m.tools.detail {
+			// Stay on this call as new ones arrive
+			m.tools.cursor, m.tools.pinned = selected, true
+		}
+		m.tools.scroll = 0
+	case keymap.ToolClose:
+		if !m.tools.detail {
+			return nil, false
+		}
+		m.tools.detail = false
+	case keymap.ToolDown:
+		if m.tools.detail {
+			m.tools.scroll++
+		} else if selected >= 0 && selected < len(calls)-2 {
+			m.tools.cursor = selected + 1
+		} else {
+			m.tools.pinned = false // The newest call, and the ones after it
+		}
+	case keymap.ToolUp:
+		if m.tools.detail {
+			m.tools.scroll = max(0, m.tools.scroll-1)
+		} else if selected > 0 {
+			m.tools.cursor, m.tools.pinned = selected-1, true
+		}
+	case keymap.ToolCopyInput, keymap.ToolCopyOutput:
+		if selected < 0 {
+			return nil, true
+		}
+		c := calls[selected]
+		if action == keymap.ToolCopyInput {
+			return effects.CopyToClipboard("input", toolInputText(c.Input), m.clipboard), true
+		}
+		return effects.CopyToClipboard("output", toolResultText(c), m.clipboard), true
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// toolInputText is a call's input as indented JSON
+func toolInputText(input json.RawMessage) string {
+	if len(input) == 0 {
+		return ""
+	}
+	var b bytes.Buffer
+	if err := json.Indent(&b, input, "", "  "); err != nil {
+		return string(input)
+	}
+	return b.String()
+}
+
+// toolResultText is a call's output, or its error when it has no output
+func toolResultText(c toolRecord) string {
+	if c.Output == "" {
+		return c.Error
+	}
+	return c.Output
+}
+
+// toolStatus is a call's status icon and a word for it
+func toolStatus(c toolRecord) (string, string) {
+	switch {
+	case c.running():
+		return StyleSpinnerActive.Render(IconInProgress), "running"
+	case c.failed():
+		return StyleErrorMsg.Render(IconError), "failed"
+	default:
+		return StyleSuccessMsg.Render(IconCheck), "done"
+	}
+}
+
+// toolSummary is a call's duration and exit code, as far as they are known
+func toolSummary(c toolRecord) string {
+	var parts []string
+	if d := c.duration(); d > 0 {
+		parts = append(parts, formatToolDuration(d))
+	}
+	if c.ExitCode != nil {
+		parts = append(parts, fmt.Sprintf("exit %d", *c.ExitCode))
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatToolDuration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// renderToolsTab lists the shown iteration's tool calls, or shows the
+// selected one in full
+func (m Model) renderToolsTab(width, height int) string {
+	calls := m.shownToolCalls()
+	selected := m.selectedToolCall(calls)
+
+	var lines []string
+	switch {
+	case len(calls) == 0:
+		lines = append(lines, StyleTextSubtle.Render("No tool calls yet."))
+	case m.tools.detail && selected >= 0:
+		lines = m.renderToolDetail(calls[selected], width-2, height)
+	default:
+		lines = m.renderToolList(calls, selected, width-2, height)
+	}
+
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return lipgloss.NewStyle().Width(width).Height(height).Padding(0, 1).Render(strings.Join(lines[:height], "\n"))
+}
+
+// renderToolList is one line per call, scrolled to keep the selection in view
+func (m Model) renderToolList(calls []toolRecord, selected, width, height int) []string {
+	header := fmt.Sprintf("Loop %d%s%d calls", m.shownToolLoop(), MetaDotSeparator, len(calls))
+	if loops := m.toolLoops(); len(loops) > 1 {
+		header += fmt.Sprintf("%s%d loops with tool calls", MetaDotSeparator, len(loops))
+	}
+	lines := []string{StyleTextMuted.Render(header)}
+
+	nameWidth := 0
+	for _, c := range calls {
+		nameWidth = max(nameWidth, min(ansi.StringWidth(c.Name), 16))
+	}
+
+	rows := max(1, height-1)
+	start := max(0, min(selected-rows/2, len(calls)-rows))
+	for i := start; i < min(len(calls), start+rows); i++ {
+		c := calls[i]
+		icon, _ := toolStatus(c)
+		name := ansi.Truncate(c.Name, nameWidth, "…")
+		name += strings.Repeat(" ", nameWidth-ansi.StringWidth(name))
+		summary := toolSummary(c)
+		if c.running() {
+			summary = "running"
+		}
+
+		prefix := " "
+		nameStyle := StyleTextBase
+		if i == selected {
+			prefix = StyleSpinnerActive.Render(IconBorderThick)
+			nameStyle = StyleTextSelected
+		}
+		left := prefix + icon + " " + nameStyle.Render(name) + " "
+		right := " " + StyleTextMuted.Render(summary)
+		avail := width - ansi.StringWidth(left) - ansi.StringWidth(right)
+		target := ansi.Truncate(strings.ReplaceAll(c.Target, "\n", " "), max(0, avail), "…")
+		pad := max(0, avail-ansi.StringWidth(target))
+		lines = append(lines, left+StyleTextBase.Render(target)+strings.Repeat(" ", pad)+right)
+	}
+	return lines
+}
+
+// renderToolDetail is a call's input and result, wrapped and scrolled
+func (m Model) renderToolDetail(c toolRecord, width, height int) []string {
+	icon, status := toolStatus(c)
+	title := icon + " " + StyleTextSelected.Render(c.Name)
+	if summary := toolSummary(c); summary != "" {
+		status += MetaDotSeparator + summary
+	}
+	title += " " + StyleTextMuted.Render(status)
+	if c.ID != "" {
+		title += " " + StyleTextSubtle.Render(c.ID)
+	}
+
+	var body []string
+	section := func(name, text, empty string) {
+		body = append(body, "", StyleHelpKey.Render(name))
+		if text == "" {
+			body = append(body, StyleTextSubtle.Render("  "+empty))
+			return
+		}
+		for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+			for _, wrapped := range strings.Split(ansi.Hardwrap(line, max(1, width-2), true), "\n") {
+				body = append(body, "  "+StyleTextBase.Render(wrapped))
+			}
+		}
+	}
+	if c.Target != "" {
+		body = append(body, StyleTextMuted.Render(ansi.Truncate(c.Target, width, "…")))
+	}
+	section("Input", toolInputText(c.Input), "Not reported by the backend")
+	if c.Error != "" {
+		section("Error", c.Error, "")
+	}
+	switch {
+	case c.running():
+		section("Output", "", "Still running")
+	default:
+		section("Output", c.Output, "Not reported by the backend")
+	}
+
+	rows := max(1, height-1)
+	scroll := max(0, min(m.tools.scroll, len(body)-rows))
+	end := min(len(body), scroll+rows)
+	return append([]string{title}, body[scroll:end]...)
+}
@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	tuimsg "github.com/brainwhocodes/lisa-loop/internal/tui/msg"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// toolEvents sends tool call events to the model, a second apart
+func toolEvents(model tea.Model, calls ...loop.LoopEvent) tea.Model {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, e := range calls {
+		e.Type = loop.EventTypeCodexTool
+		e.Time = start.Add(time.Duration(i) * time.Second)
+		model, _ = model.Update(tuimsg.ControllerEventMsg{Event: e})
+	}
+	return model
+}
+
+func TestToolsTab_RecordsCalls(t *testing.T) {
+	exit := 1
+	var m tea.Model = Model{screen: ScreenOutput, outputTab: OutputTabTools, width: 100, height: 24}
+	m = toolEvents(m,
+		loop.LoopEvent{Iteration: 1, Tool: &loop.ToolCall{ID: "c1", Name: "read", Target: "main.go", Status: loop.ToolStatusStarted}},
+		loop.LoopEvent{Iteration: 1, Tool: &loop.ToolCall{ID: "c1", Name: "read", Status: loop.ToolStatusCompleted, Output: "package main"}},
+		loop.LoopEvent{Iteration: 2, Tool: &loop.ToolCall{Name: "shell", Target: "go test ./...", Status: loop.ToolStatusStarted,
+			Input: json.RawMessage(`{"command":"go test ./... -run TestSomethingWithAVeryLongNameThatTheTargetCutsOff"}`)}},
+		loop.LoopEvent{Iteration: 2, Tool: &loop.ToolCall{Name: "shell", Target: "go test ./...", Status: loop.ToolStatusCompleted, Output: "FAIL", ExitCode: &exit}},
+		loop.LoopEvent{Iteration: 2, Tool: &loop.ToolCall{Name: "edit", Target: "plan.go", Status: loop.ToolStatusStarted}},
+	)
+
+	got := m.(Model)
+	if len(got.tools.calls) != 3 {
+		t.Fatalf("recorded %d calls, want 3: %+v", len(got.tools.calls), got.tools.calls)
+	}
+	if read := got.tools.calls[0]; read.Target != "main.go" || read.Output != "package main" || read.duration() != time.Second {
+		t.Errorf("read call = %+v", read)
+	}
+
+	// The latest iteration is shown, its newest call selected
+	tab := ansi.Strip(got.renderToolsTab(100, 10))
+	for _, want := range []string{"Loop 2", "2 calls", IconError + " shell", "1s exit 1", IconBorderThick + IconInProgress + " edit", "running"} {
+		if !strings.Contains(tab, want) {
+			t.Errorf("tools tab does not show %q:\n%s", want, tab)
+		}
+	}
+	if strings.Contains(tab, "main.go") {
+		t.Errorf("tools tab shows another iteration's calls:\n%s", tab)
+	}
+
+	// Drill down into the failed command: full input and output
+	m = typeKeys(m, "k", "enter")
+	tab = ansi.Strip(m.(Model).renderToolsTab(100, 20))
+	for _, want := range []string{"shell failed", `"command": "go test ./... -run TestSomethingWithAVeryLongNameThatTheTargetCutsOff"`, "FAIL"} {
+		if !strings.Contains(tab, want) {
+			t.Errorf("tool detail does not show %q:\n%s", want, tab)
+		}
+	}
+
+	// The previous iteration
+	m = typeKeys(m, "enter", "<")
+	if tab := ansi.Strip(m.(Model).renderToolsTab(100, 10)); !strings.Contains(tab, "Loop 1") || !strings.Contains(tab, "main.go") {
+		t.Errorf("< did not show the first iteration:\n%s", tab)
+	}
+	if m = typeKeys(m, ">"); m.(Model).tools.loop != 0 {
+		t.Errorf("> back to the latest iteration should follow it, loop = %d", m.(Model).tools.loop)
+	}
+}
+
+func TestToolsTab_CopiesInput(t *testing.T) {
+	t.Setenv("TMUX", "")
+	t.Setenv("TERM", "xterm")
+	var clipboard bytes.Buffer
+	var m tea.Model = Model{screen: ScreenOutput, outputTab: OutputTabTools, clipboard: &clipboard}
+	m = toolEvents(m, loop.LoopEvent{Iteration: 1, Tool: &loop.ToolCall{Name: "shell", Status: loop.ToolStatusCompleted, Input: json.RawMessage(`{"command":"ls"}`)}})
+
+	m, cmd := m.Update(runes("y"))
+	if cmd == nil {
+		t.Fatalf("y did not copy")
+	}
+	m, _ = m.Update(cmd())
+
+	want := base64.StdEncoding.EncodeToString([]byte("{\n  \"command\": \"ls\"\n}"))
+	if !strings.Contains(clipboard.String(), want) {
+		t.Errorf("clipboard got %q, want the indented input %q", clipboard.String(), want)
+	}
+	if logs := m.(Model).logs; len(logs) == 0 || !strings.Contains(logs[len(logs)-1].Text, "Copied the tool input") {
+		t.Errorf("copy not logged: %+v", logs)
+	}
+}
@@ -0,0 +1,27 @@
+package view
+
+import "math"
+
+// sparkBars are a sparkline's bars, lowest first
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws one bar per value, scaled so the largest value gets the
+// tallest bar. Zero and negative values get the lowest bar, which no positive
+// value shares, so "nothing" stays distinguishable from "a little".
+func Sparkline(values []float64) string {
+	peak := 0.0
+	for _, v := range values {
+		peak = math.Max(peak, v)
+	}
+
+	bars := make([]rune, len(values))
+	for i, v := range values {
+		if v <= 0 || peak <= 0 {
+			bars[i] = sparkBars[0]
+			continue
+		}
+		level := 1 + int(math.Round(v/peak*float64(len(sparkBars)-2)))
+		bars[i] = sparkBars[min(level, len(sparkBars)-1)]
+	}
+	return string(bars)
+}
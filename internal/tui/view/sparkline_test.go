@@ -0,0 +1,25 @@
+package view
+
+import "testing"
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   string
+	}{
+		{"empty", nil, ""},
+		{"all zero", []float64{0, 0, 0}, "▁▁▁"},
+		{"scaled to the peak", []float64{0, 1, 2, 4, 8}, "▁▃▄▅█"},
+		{"small values stay above zero", []float64{0.01, 100}, "▂█"},
+		{"negative", []float64{-3, 3}, "▁█"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sparkline(tt.values); got != tt.want {
+				t.Errorf("Sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"strings"
 
 	"github.com/brainwhocodes/lisa-loop/internal/config"
+	"github.com/brainwhocodes/lisa-loop/internal/tui/keymap"
 	"github.com/brainwhocodes/lisa-loop/internal/tui/transcript"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // renderSplitView renders the main split pane layout (tasks top, output bottom)
@@ -64,7 +66,7 @@ func (m Model) renderSplitView() string {
 func (m Model) renderHeader(width int) string {
 	// Brand prefix and name
 	brandPrefix := StyleBrandPrefix.Render("Charm")
-	brandName := GradientText("LISA", Dolly, Charple)
+	brandName := GradientText("LISA", CurrentTheme().Secondary, CurrentTheme().Primary)
 
 	// Animated SAX with musical notes when running
 	var saxAnim string
@@ -83,6 +85,15 @@ func (m Model) renderHeader(width int) string {
 	}
 	metaParts = append(metaParts, modeName)
 
+	// Active profile
+	if m.profile != "" {
+		metaParts = append(metaParts, "profile "+m.profile)
+	}
+
+	if m.attached != "" {
+		metaParts = append(metaParts, "attached")
+	}
+
 	// Loop number
 	metaParts = append(metaParts, fmt.Sprintf("loop %d", m.loopNumber))
 
@@ -187,6 +198,14 @@ func (m Model) renderStatusBar(width int) string {
 			midStatus += StyleSuccessMsg.Render(" EXIT")
 		}
 	}
+	if m.testSummary != nil {
+		testStyle := StyleSuccessMsg
+		if m.testSummary.Failed > 0 {
+			testStyle = StyleErrorMsg
+		}
+		midStatus += StyleTextMuted.Render(" │ tests ") +
+			testStyle.Render(fmt.Sprintf("%d✓ %d✗", m.testSummary.Passed, m.testSummary.Failed))
+	}
 
 	// Context usage indicator (before circuit)
 	var contextIndicator string
@@ -416,6 +435,8 @@ func (m Model) renderPhaseTaskLine(task Task, taskIdx, phaseIdx int, maxWidth in
 	if task.Completed {
 		icon = StyleTaskCompleted.Render(IconCheck)
 		textStyle = StyleTaskTextCompleted
+	} else if task.SetAside() {
+		icon, textStyle = setAsideTaskStyle(task)
 	} else if isActive {
 		spinnerFrame := BrailleSpinnerFrames[m.tick%len(BrailleSpinnerFrames)]
 		icon = StyleTaskInProgress.Render(spinnerFrame)
@@ -445,6 +466,8 @@ func (m Model) renderTaskLine(task Task, index int, maxWidth int) string {
 	if task.Completed {
 		icon = StyleTaskCompleted.Render(IconCheck)
 		textStyle = StyleTaskTextCompleted
+	} else if task.SetAside() {
+		icon, textStyle = setAsideTaskStyle(task)
 	} else if isActive {
 		// Use animated spinner for active task
 		spinnerFrame := BrailleSpinnerFrames[m.tick%len(BrailleSpinnerFrames)]
@@ -536,7 +559,7 @@ func (m Model) renderOutputPane(width, height int) string {
 // Format: r run • p pause • l logs • c circuit • ? help • q quit
 func (m Model) renderFooter(width int) string {
 	var parts []string
-	for _, b := range footerBindings() {
+	for _, b := range footerBindings(m.keymap()) {
 		parts = append(parts, StyleHelpKey.Render(b.Key)+" "+StyleHelpDesc.Render(b.Description))
 	}
 
@@ -555,6 +578,10 @@ func (m Model) renderTasksFullView() string {
 
 	header := m.renderHeader(width)
 
+	// Everything but the header, the footer and the blank lines around the content
+	avail := m.height - 4
+	cursorLine := 0
+
 	var lines []string
 
 	// If we have phases, show phase-organized view
@@ -594,6 +621,8 @@ func (m Model) renderTasksFullView() string {
 		// Render each phase
 		globalTaskIdx := 0
 		for phaseIdx, phase := range m.phases {
+			hasCursor := m.taskCursor >= globalTaskIdx && m.taskCursor < globalTaskIdx+len(phase.Tasks)
+
 			// Phase header with icon
 			var phaseIcon string
 			if phase.Completed {
@@ -622,10 +651,13 @@ func (m Model) renderTasksFullView() string {
 				lines = append(lines, StyleTextMuted.Render(phaseHeader))
 			}
 
-			// Show tasks for current phase, collapse others
-			if phaseIdx == currentPhaseIdx {
+			// Show tasks for the current phase and the cursor's, collapse others
+			if phaseIdx == currentPhaseIdx || hasCursor {
 				for taskIdx, task := range phase.Tasks {
-					lines = append(lines, m.renderPhaseTaskLine(task, taskIdx, phaseIdx, width-4))
+					if globalTaskIdx == m.taskCursor {
+						cursorLine = len(lines)
+					}
+					lines = append(lines, m.withTaskCursor(m.renderPhaseTaskLine(task, taskIdx, phaseIdx, width-4), globalTaskIdx))
 					globalTaskIdx++
 				}
 			} else {
@@ -662,18 +694,31 @@ func (m Model) renderTasksFullView() string {
 		lines = append(lines, "")
 
 		for i, task := range m.tasks {
-			lines = append(lines, m.renderTaskLine(task, i, width-4))
+			if i == m.taskCursor {
+				cursorLine = len(lines)
+			}
+			lines = append(lines, m.withTaskCursor(m.renderTaskLine(task, i, width-4), i))
 		}
 	}
+	if len(m.tasks) == 0 {
+		lines = append(lines, StyleTextSubtle.Render(" No tasks yet (a adds one)"))
+	}
+
+	// Scroll to keep the cursor on screen, leaving room for the input
+	if m.addingTask {
+		avail -= 2
+	}
+	if avail > 0 && len(lines) > avail {
+		start := max(0, min(cursorLine-avail/2, len(lines)-avail))
+		lines = lines[start : start+avail]
+	}
+	if m.addingTask {
+		lines = append(lines, "", StyleHelpKey.Render(" New task > ")+StyleTextSelected.Render(string(m.taskInput))+StyleSpinnerActive.Render("█"))
+	}
 
 	content := strings.Join(lines, "\n")
 
-	footer := StyleFooter.Width(width).Render(
-		fmt.Sprintf(" %s return%s%s quit",
-			StyleHelpKey.Render("t"),
-			StyleTextSubtle.Render(MetaDotSeparator),
-			StyleHelpKey.Render("q")),
-	)
+	footer := m.tasksFooter(width)
 
 	return lipgloss.JoinVertical(lipgloss.Left,
 		header,
@@ -684,10 +729,22 @@ func (m Model) renderTasksFullView() string {
 	)
 }
 
-// renderOutputFullView renders output in full screen mode
-func (m Model) renderOutputFullView() string {
-	width := m.width
-	height := m.height
+// withTaskCursor marks the Tasks view's line for the task under the cursor
+func (m Model) withTaskCursor(line string, index int) string {
+	if index != m.taskCursor {
+		return line
+	}
+	return StyleSpinnerActive.Render(IconBorderThick) + strings.TrimPrefix(line, " ")
+}
+
+// outputContentTop is the screen row the output view's tab content starts on
+// (below the header, the tabs and a blank line)
+const outputContentTop = 3
+
+// outputContentSize is the size of the output view's tab content
+func (m Model) outputContentSize() (width, height int) {
+	width = m.width
+	height = m.height
 	if width < 60 {
 		width = 60
 	}
@@ -695,10 +752,6 @@ func (m Model) renderOutputFullView() string {
 		height = 20
 	}
 
-	header := m.renderHeader(width)
-
-	tabs := m.renderOutputTabs(width)
-
 	// Layout
 	const headerHeight = 1
 	const footerHeight = 1
@@ -707,19 +760,57 @@ func (m Model) renderOutputFullView() string {
 	if contentHeight < 8 {
 		contentHeight = 8
 	}
+	return width, contentHeight
+}
+
+// renderOutputFullView renders output in full screen mode
+func (m Model) renderOutputFullView() string {
+	width, contentHeight := m.outputContentSize()
+
+	header := m.renderHeader(width)
+
+	tabs := m.renderOutputTabs(width)
 
 	content := m.renderOutputTabContent(width, contentHeight)
 
-	footer := StyleFooter.Width(width).Render(
-		fmt.Sprintf(" %s return%s%s tabs%s%s reasoning%s%s quit",
-			StyleHelpKey.Render("o"),
-			StyleTextSubtle.Render(MetaDotSeparator),
-			StyleHelpKey.Render("[ ]"),
-			StyleTextSubtle.Render(MetaDotSeparator),
-			StyleHelpKey.Render("y"),
-			StyleTextSubtle.Render(MetaDotSeparator),
-			StyleHelpKey.Render("q")),
-	)
+	var parts []string
+	if m.outputTab == OutputTabDiffs {
+		parts = []string{
+			m.hint("view", keymap.DiffView),
+			m.hint("loop", keymap.DiffPrevLoop, keymap.DiffNextLoop),
+			m.hint("file", keymap.DiffNextFile, keymap.DiffPrevFile),
+			m.hint("hunk", keymap.DiffPrevHunk, keymap.DiffNextHunk),
+			m.hint("fold", keymap.DiffFold, keymap.DiffFoldAll),
+			m.hint("split", keymap.DiffSplit),
+			m.hint("tabs", keymap.PrevTab, keymap.NextTab),
+			m.hint("return", keymap.Output),
+		}
+	} else {
+		parts = []string{
+			m.hint("return", keymap.Output),
+			m.hint("tabs", keymap.PrevTab, keymap.NextTab),
+		}
+		if view := m.currentSearchView(); view != searchNone {
+			parts = append(parts, m.searchFooter(view)...)
+		}
+		switch {
+		case m.outputTab == OutputTabReasoning:
+			parts = append(parts, m.hint("reasoning", keymap.ToggleReasoning))
+		case m.outputTab == OutputTabTools && m.tools.detail:
+			parts = append(parts,
+				m.hint("scroll", keymap.ToolDown, keymap.ToolUp),
+				m.hint("back", keymap.ToolDetail),
+				m.hint("copy input/output", keymap.ToolCopyInput, keymap.ToolCopyOutput))
+		case m.outputTab == OutputTabTools:
+			parts = append(parts,
+				m.hint("select", keymap.ToolDown, keymap.ToolUp),
+				m.hint("details", keymap.ToolDetail),
+				m.hint("loop", keymap.ToolPrevLoop, keymap.ToolNextLoop),
+				m.hint("copy input/output", keymap.ToolCopyInput, keymap.ToolCopyOutput))
+		}
+		parts = append(parts, m.hint("quit", keymap.Quit))
+	}
+	footer := StyleFooter.Width(width).Render(ansi.Truncate(footerHints(parts...), width, "…"))
 
 	return lipgloss.JoinVertical(lipgloss.Left,
 		header,
@@ -731,25 +822,19 @@ func (m Model) renderOutputFullView() string {
 	)
 }
 
+// Output tab styles, set by applyTheme
+var styleTabActive, styleTabInactive lipgloss.Style
+
 func (m Model) renderOutputTabs(width int) string {
-	tabs := []OutputTab{OutputTabTranscript, OutputTabDiffs, OutputTabReasoning}
+	tabs := []OutputTab{OutputTabTranscript, OutputTabDiffs, OutputTabReasoning, OutputTabTools, OutputTabMetrics}
 
 	var parts []string
 	for _, t := range tabs {
 		label := t.String()
 		if t == m.outputTab {
-			parts = append(parts, lipgloss.NewStyle().
-				Foreground(Pepper).
-				Background(Guac).
-				Padding(0, 1).
-				Bold(true).
-				Render(label))
+			parts = append(parts, styleTabActive.Render(label))
 		} else {
-			parts = append(parts, lipgloss.NewStyle().
-				Foreground(Squid).
-				Background(BBQ).
-				Padding(0, 1).
-				Render(label))
+			parts = append(parts, styleTabInactive.Render(label))
 		}
 	}
 
@@ -763,6 +848,10 @@ func (m Model) renderOutputTabContent(width, height int) string {
 		return m.renderDiffTab(width, height)
 	case OutputTabReasoning:
 		return m.renderReasoningTab(width, height)
+	case OutputTabTools:
+		return m.renderToolsTab(width, height)
+	case OutputTabMetrics:
+		return m.renderMetricsTab(width, height)
 	default:
 		return m.renderTranscriptTab(width, height)
 	}
@@ -774,21 +863,11 @@ func (m Model) renderTranscriptTab(width, height int) string {
 		return m.renderOutputPane(width, height)
 	}
 
-	items := m.transcript.Items()
-
-	max := height
-	start := 0
-	if len(items) > max {
-		start = len(items) - max
-	}
-
-	lines := make([]string, 0, max)
-	for i := start; i < len(items); i++ {
-		it := items[i]
-		lines = append(lines, " "+m.formatTranscriptLine(width-2, it))
-		if len(lines) >= height {
-			break
-		}
+	var lines []string
+	if rows := m.searchRows(searchTranscript); len(rows) == 0 {
+		lines = append(lines, StyleTextSubtle.Render(" No "+m.transcriptFilter.String()+" yet."))
+	} else {
+		lines = m.renderSearchRows(searchTranscript, rows, width-2, height)
 	}
 
 	for len(lines) < height {
@@ -798,7 +877,8 @@ func (m Model) renderTranscriptTab(width, height int) string {
 	return lipgloss.NewStyle().Width(width).Height(height).Padding(0, 1).Render(strings.Join(lines[:height], "\n"))
 }
 
-func (m Model) formatTranscriptLine(maxWidth int, it transcript.Item) string {
+// transcriptRow is an item as one line: time, role and the body flattened
+func (m Model) transcriptRow(it transcript.Item) string {
 	ts := ""
 	if !it.At.IsZero() {
 		ts = it.At.Format("15:04:05")
@@ -824,75 +904,19 @@ func (m Model) formatTranscriptLine(maxWidth int, it transcript.Item) string {
 		body = it.Title
 	}
 
-	line := fmt.Sprintf("%s %s %s", ts, role, body)
-	if maxWidth > 10 && lipgloss.Width(line) > maxWidth {
-		// Best-effort truncation for plain text; ANSI width can be imperfect but should be acceptable here.
-		runes := []rune(line)
-		if len(runes) > maxWidth-1 {
-			line = string(runes[:maxWidth-1]) + "…"
-		}
-	}
-	return line
+	return fmt.Sprintf("%s %s %s", ts, role, body)
 }
 
-func (m Model) renderDiffTab(width, height int) string {
-	// Prefer git diff output; fall back to pending changes if git is unavailable.
-	var md strings.Builder
-	md.WriteString("## Diffs\n\n")
-
-	if m.diffPending {
-		md.WriteString("Status: **pending** (collecting `git diff`)\n\n")
-	}
-
-	if m.diffErr != nil {
-		md.WriteString("Status: **error**\n\n")
-		md.WriteString("```text\n")
-		md.WriteString(m.diffErr.Error())
-		md.WriteString("\n```\n\n")
-	}
-
-	if m.gitDiffNameStatus == "" && m.gitDiffPatch == "" {
-		if len(m.pendingChanges) == 0 {
-			md.WriteString("_No changes detected._\n")
-		} else {
-			md.WriteString("### Touched files (unverified)\n\n")
-			for _, pc := range m.pendingChanges {
-				md.WriteString(fmt.Sprintf("- `%s` (%s %s)\n", pc.Path, pc.Tool, pc.Status))
-			}
-		}
-	} else {
-		md.WriteString("### Changed files\n\n")
-		md.WriteString("```text\n")
-		md.WriteString(m.gitDiffNameStatus)
-		md.WriteString("\n```\n\n")
-
-		md.WriteString("### Patch\n\n")
-		md.WriteString("```diff\n")
-		md.WriteString(m.gitDiffPatch)
-		md.WriteString("\n```\n")
-	}
-
-	rendered := md.String()
-	if m.md != nil {
-		out, err := m.md.Render(width-2, rendered)
-		if err == nil {
-			rendered = out
+func (m Model) renderReasoningTab(width, height int) string {
+	// A search shows the whole reasoning as plain text, matches highlighted
+	if m.search.queryFor(searchReasoning) != "" {
+		lines := m.renderSearchRows(searchReasoning, m.searchRows(searchReasoning), width-2, height)
+		for len(lines) < height {
+			lines = append(lines, "")
 		}
+		return lipgloss.NewStyle().Width(width).Height(height).Padding(0, 1).Render(strings.Join(lines, "\n"))
 	}
 
-	// Clamp to height.
-	lines := strings.Split(rendered, "\n")
-	if len(lines) > height {
-		lines = lines[:height]
-	}
-	for len(lines) < height {
-		lines = append(lines, "")
-	}
-
-	return lipgloss.NewStyle().Width(width).Height(height).Padding(0, 1).Render(strings.Join(lines, "\n"))
-}
-
-func (m Model) renderReasoningTab(width, height int) string {
 	reasoning := m.currentReasoning
 	if reasoning == "" && len(m.reasoningLines) > 0 {
 		reasoning = m.reasoningLines[len(m.reasoningLines)-1]
@@ -958,29 +982,21 @@ func (m Model) renderLogsFullView() string {
 
 	var lines []string
 
-	if len(m.logs) == 0 {
+	rows := m.searchRows(searchLogs)
+	switch {
+	case len(m.logs) == 0:
 		lines = append(lines, StyleTextMuted.Render(" No log entries yet..."))
-	} else {
-		// Show all available logs
-		maxLines := height - 6
-		start := 0
-		if len(m.logs) > maxLines {
-			start = len(m.logs) - maxLines
-		}
-
-		for i := start; i < len(m.logs); i++ {
-			lines = append(lines, " "+m.logs[i])
-		}
+	case len(rows) == 0:
+		lines = append(lines, StyleTextMuted.Render(" No "+strings.ToLower(logLevelFilters[m.logFilter])+" entries"))
+	default:
+		lines = m.renderSearchRows(searchLogs, rows, width, height-6)
 	}
 
 	content := strings.Join(lines, "\n")
 
-	footer := StyleFooter.Width(width).Render(
-		fmt.Sprintf(" %s return%s%d entries",
-			StyleHelpKey.Render("l"),
-			StyleTextSubtle.Render(MetaDotSeparator),
-			len(m.logs)),
-	)
+	parts := append([]string{m.hint("return", keymap.Logs)}, m.searchFooter(searchLogs)...)
+	parts = append(parts, fmt.Sprintf("%d/%d entries", len(rows), len(m.logs)))
+	footer := StyleFooter.Width(width).Render(ansi.Truncate(footerHints(parts...), width, "…"))
 
 	return lipgloss.JoinVertical(lipgloss.Left,
 		header,
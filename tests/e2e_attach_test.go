@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brainwhocodes/lisa-loop/internal/api"
+	"github.com/brainwhocodes/lisa-loop/internal/circuit"
+	"github.com/brainwhocodes/lisa-loop/internal/config"
+	"github.com/brainwhocodes/lisa-loop/internal/loop"
+	"github.com/brainwhocodes/lisa-loop/internal/runner"
+)
+
+// gatedRunner blocks each call until it is released, then completes a task
+type gatedRunner struct {
+	fake    *fakeRunner
+	started chan struct{}
+	release chan struct{}
+}
+
+func (g *gatedRunner) Run(ctx context.Context, prompt string) (string, string, error) {
+	g.started <- struct{}{}
+	select {
+	case <-g.release:
+	case <-ctx.Done():
+		return "", "", ctx.Err()
+	}
+	return g.fake.Run(ctx, prompt)
+}
+
+func (g *gatedRunner) Stop() error { return nil }
+
+func (g *gatedRunner) SetOutputCallback(cb runner.OutputCallback) {}
+
+func TestE2E_AttachControlsRunningLoop(t *testing.T) {
+	project := setupTestProject(t, "fix")
+	origDir, _ := os.Getwd()
+	os.Chdir(project.Dir)
+	defer os.Chdir(origDir)
+
+	// Unix socket paths are short-lived and length-limited, so avoid the long test temp dir
+	socketDir, err := os.MkdirTemp("", "lisa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(socketDir)
+
+	controller := loop.NewController(config.Config{MaxCalls: 10, Backend: "test", Timeout: 60}, loop.NewRateLimiter(100, 1), circuit.NewBreaker(3, 5))
+	gated := &gatedRunner{
+		fake:    &fakeRunner{projectDir: project.Dir, planFile: project.PlanFile, maxCalls: 10},
+		started: make(chan struct{}, 1),
+		release: make(chan struct{}),
+	}
+	controller.SetRunner(gated)
+
+	server, err := api.Listen(api.UnixPrefix+filepath.Join(socketDir, "lisa.sock"), controller)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer server.Close()
+
+	runDone := make(chan error, 1)
+	go func() { runDone <- controller.Run(context.Background()) }()
+	<-gated.started
+
+	client, err := api.Dial(server.Addr(), server.Token())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer client.Close()
+
+	sub := client.Subscribe(1024, loop.DropOldest)
+	var snapshot *loop.Status
+	for snapshot == nil {
+		select {
+		case event := <-sub.Events():
+			snapshot = event.Snapshot
+		case <-time.After(5 * time.Second):
+			t.Fatal("no snapshot after attaching")
+		}
+	}
+	if snapshot.State != loop.RunStateRunning || snapshot.Iteration != 1 || snapshot.Plan.Remaining == 0 {
+		t.Errorf("snapshot = %+v, want iteration 1 running with tasks remaining", snapshot)
+	}
+
+	// Detaching a second client leaves the run alone
+	other, err := api.Dial(server.Addr(), server.Token())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	other.Run(ctx)
+	other.Close()
+	if state := controller.State(); state != loop.RunStateRunning {
+		t.Errorf("State() after detaching = %s, want running", state)
+	}
+
+	client.StopAfterIteration()
+	close(gated.release)
+
+	followed := make(chan error, 1)
+	go func() { followed <- client.Run(context.Background()) }()
+	select {
+	case err := <-followed:
+		if err != nil {
+			t.Errorf("client Run() error = %v, want nil when the run stops", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("client Run() did not return when the run stopped")
+	}
+	if err := <-runDone; err != nil {
+		t.Fatalf("controller Run() error = %v", err)
+	}
+	if got := countCompletedTasks(project.Dir, project.PlanFile); got != 1 {
+		t.Errorf("completed tasks = %d, want 1 after stopping after the first iteration", got)
+	}
+}
@@ -23,7 +23,7 @@ type fakeRunner struct {
 	maxCalls     int
 }
 
-func (f *fakeRunner) Run(prompt string) (string, string, error) {
+func (f *fakeRunner) Run(ctx context.Context, prompt string) (string, string, error) {
 	f.callCount++
 
 	if f.callCount > f.maxCalls {
@@ -61,6 +61,20 @@ func (f *fakeRunner) completeOneTask() error {
 	return nil
 }
 
+// collectEvents subscribes to the controller. The returned function ends the
+// subscription and returns every event published in the meantime.
+func collectEvents(controller *loop.Controller) func() []loop.LoopEvent {
+	sub := controller.Subscribe(1<<16, loop.DropNewest)
+	return func() []loop.LoopEvent {
+		sub.Unsubscribe()
+		var events []loop.LoopEvent
+		for event := range sub.Events() {
+			events = append(events, event)
+		}
+		return events
+	}
+}
+
 // setupTestProject creates a temp project with fixtures
 type testProject struct {
 	Dir      string
@@ -153,22 +167,24 @@ func TestE2E_FixMode_RunLoop(t *testing.T) {
 	controller.SetRunner(fake)
 
 	// Collect events
+	events := collectEvents(controller)
+
+	// Run the loop with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err := controller.Run(ctx)
+
 	var preflightEvents []*loop.PreflightSummary
 	var outcomeEvents []*loop.LoopOutcome
-	controller.SetEventCallback(func(event loop.LoopEvent) {
+	for _, event := range events() {
 		if event.Preflight != nil {
 			preflightEvents = append(preflightEvents, event.Preflight)
 		}
 		if event.Outcome != nil {
 			outcomeEvents = append(outcomeEvents, event.Outcome)
 		}
-	})
-
-	// Run the loop with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	err := controller.Run(ctx)
+	}
 
 	// Verify loop completed successfully
 	if err != nil {
@@ -372,11 +388,17 @@ func TestE2E_PreflightAndOutcomeEvents(t *testing.T) {
 	controller.SetRunner(fake)
 
 	// Collect all events
+	events := collectEvents(controller)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err := controller.Run(ctx)
+
 	var preflightEvents []*loop.PreflightSummary
 	var outcomeEvents []*loop.LoopOutcome
 	var logMessages []string
-
-	controller.SetEventCallback(func(event loop.LoopEvent) {
+	for _, event := range events() {
 		switch event.Type {
 		case loop.EventTypePreflight:
 			if event.Preflight != nil {
@@ -387,14 +409,9 @@ func TestE2E_PreflightAndOutcomeEvents(t *testing.T) {
 				outcomeEvents = append(outcomeEvents, event.Outcome)
 			}
 		case loop.EventTypeLog:
-			logMessages = append(logMessages, event.LogMessage)
+			logMessages = append(logMessages, event.Log.Message)
 		}
-	})
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	err := controller.Run(ctx)
+	}
 
 	if err != nil {
 		t.Errorf("Loop failed: %v", err)